@@ -98,3 +98,16 @@ Get the appropriate image repository based on proxy configuration
 {{ .Values.image.repository }}
 {{- end -}}
 {{- end -}}
+
+{{/*
+Address the registry proxy forwards to, over mTLS when the proxy certificates are set
+*/}}
+{{- define "proxy.upstream" -}}
+{{- if .Values.proxy.tls.enabled -}}
+openssl:{{ template "docker-registry.fullname" . }}:{{ .Values.proxy.tls.port }},{{ if .Values.ipv6Only }}pf=ip6,{{ end }}cert=/tls/client.crt,key=/tls/client.key,cafile=/tls/ca.crt
+{{- else if .Values.ipv6Only -}}
+tcp6:{{ template "docker-registry.fullname" . }}:5000
+{{- else -}}
+tcp4:{{ template "docker-registry.fullname" . }}:5000
+{{- end -}}
+{{- end -}}
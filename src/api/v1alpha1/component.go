@@ -64,7 +64,30 @@ type NamespacedObjectKindReference struct {
 	// Namespace of the resource
 	Namespace string `json:"namespace"`
 	// Name of the resource
-	Name string `json:"name"`
+	Name string `json:"name,omitempty"`
+	// Label selector of the resources to check instead of a single name, at least one resource must match
+	Selector string `json:"selector,omitempty"`
+	// Condition the resources must report instead of reaching the kstatus Current status
+	Condition *HealthCheckCondition `json:"condition,omitempty"`
+	// JSONPath expression to evaluate on the resources instead of reaching the kstatus Current status (e.g. {.status.phase})
+	JSONPath string `json:"jsonPath,omitempty"`
+	// Value the JSONPath expression must evaluate to, when empty the expression only needs to return a result
+	Value string `json:"value,omitempty"`
+	// Timeout in seconds for the health check, defaults to the deploy timeout
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" jsonschema:"minimum=0"`
+}
+
+// HealthCheckCondition is a status condition a resource must report to be healthy.
+type HealthCheckCondition struct {
+	// Type of the condition (e.g. Ready)
+	Type string `json:"type"`
+	// Status of the condition, defaults to True
+	Status string `json:"status,omitempty"`
+}
+
+// IsStructured returns if the health check uses a selector, condition or JSONPath instead of a kstatus check on a single resource.
+func (r NamespacedObjectKindReference) IsStructured() bool {
+	return r.Selector != "" || r.Condition != nil || r.JSONPath != ""
 }
 
 // RequiresCluster returns if the component requires a cluster connection to deploy.
@@ -168,6 +191,26 @@ type ZarfChart struct {
 	Values []ZarfChartValue `json:"values,omitempty"`
 	// Whether or not to validate the values.yaml schema, defaults to true. Necessary in the air-gap when the JSON Schema references resources on the internet.
 	SchemaValidation *bool `json:"schemaValidation,omitempty"`
+	// Whether to roll back a failed upgrade or uninstall a failed install, waits for chart resources to be ready even with noWait.
+	Atomic bool `json:"atomic,omitempty"`
+	// Whether to skip running the chart's Helm hooks.
+	DisableHooks bool `json:"disableHooks,omitempty"`
+	// Whether to skip installing the CRDs in the chart's crds directory, they are only installed on the first install by default.
+	SkipCRDs bool `json:"skipCRDs,omitempty"`
+	// The maximum number of release revisions to keep, defaults to 10.
+	MaxHistory int `json:"maxHistory,omitempty" jsonschema:"minimum=0"`
+	// Whether to force resource updates through a replacement strategy.
+	Force bool `json:"force,omitempty"`
+	// List of Helm post-renderers to run in order before the Zarf post-renderer.
+	PostRenderers []ZarfChartPostRenderer `json:"postRenderers,omitempty"`
+}
+
+// ZarfChartPostRenderer is an executable that receives the rendered manifests on stdin and writes the modified manifests to stdout.
+type ZarfChartPostRenderer struct {
+	// The path of the executable or the name of an executable in the PATH.
+	Command string `json:"command"`
+	// List of arguments to pass to the executable.
+	Args []string `json:"args,omitempty"`
 }
 
 // ShouldRunSchemaValidation returns if Helm schema validation should be run or not
@@ -208,6 +251,10 @@ type ZarfManifest struct {
 	Kustomizations []string `json:"kustomizations,omitempty"`
 	// Enable kustomize plugins during kustomize builds.
 	EnableKustomizePlugins bool `json:"enableKustomizePlugins,omitempty"`
+	// List of local kustomize component paths or remote URLs to apply to each kustomization.
+	KustomizeComponents []string `json:"kustomizeComponents,omitempty"`
+	// Inline strategic merge or JSON 6902 patches to apply to each kustomization.
+	KustomizePatches []ZarfKustomizePatch `json:"kustomizePatches,omitempty"`
 	// Whether to not wait for manifest resources to be ready before continuing.
 	NoWait bool `json:"noWait,omitempty"`
 	// [alpha]
@@ -226,6 +273,32 @@ func (m ZarfManifest) IsTemplate() bool {
 	return false
 }
 
+// ZarfKustomizePatch is an inline patch applied to the resources of a kustomization.
+type ZarfKustomizePatch struct {
+	// The strategic merge patch or list of JSON 6902 operations to apply.
+	Patch string `json:"patch"`
+	// The resources to patch, defaults to the resource named in a strategic merge patch.
+	Target *ZarfKustomizePatchTarget `json:"target,omitempty"`
+}
+
+// ZarfKustomizePatchTarget selects the resources a kustomize patch is applied to.
+type ZarfKustomizePatchTarget struct {
+	// The API group of the resources to patch.
+	Group string `json:"group,omitempty"`
+	// The API version of the resources to patch.
+	Version string `json:"version,omitempty"`
+	// The kind of the resources to patch.
+	Kind string `json:"kind,omitempty"`
+	// The name of the resources to patch, can be a regular expression.
+	Name string `json:"name,omitempty"`
+	// The namespace of the resources to patch, can be a regular expression.
+	Namespace string `json:"namespace,omitempty"`
+	// A label selector for the resources to patch.
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// An annotation selector for the resources to patch.
+	AnnotationSelector string `json:"annotationSelector,omitempty"`
+}
+
 // DeprecatedZarfComponentScripts are scripts that run before or after a component is deployed.
 type DeprecatedZarfComponentScripts struct {
 	// Show the output of the script during package deployment.
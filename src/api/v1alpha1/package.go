@@ -70,6 +70,10 @@ type ZarfPackage struct {
 	Variables []InteractiveVariable `json:"variables,omitempty"`
 	// Values imports Zarf values files for templating and overriding Helm values.
 	Values ZarfValues `json:"values,omitempty"`
+	// Image extractors used by find-images to find container images in custom resources.
+	ImageExtractors []ImageExtractor `json:"imageExtractors,omitempty"`
+	// Rules that change the names images and repositories are stored under in the registry and git server.
+	Transforms ZarfTransforms `json:"transforms,omitempty"`
 }
 
 // IsInitConfig returns whether a Zarf package is an init config.
@@ -271,8 +275,38 @@ type ZarfBuildData struct {
 	Flavor string `json:"flavor,omitempty"`
 	// Whether this package was signed
 	Signed *bool `json:"signed,omitempty"`
+	// Whether this package was created reproducibly, without build machine information and with a fixed timestamp.
+	Reproducible bool `json:"reproducible,omitempty"`
 	// Requirements for specific package operations.
 	VersionRequirements []VersionRequirement `json:"versionRequirements,omitempty"`
+	// The resolved dependencies of the Helm charts in this package.
+	Charts []ZarfBuildChart `json:"charts,omitempty"`
+}
+
+// ZarfBuildChart records the dependencies that were packed into a Helm chart.
+type ZarfBuildChart struct {
+	// The name of the component containing the chart.
+	Component string `json:"component"`
+	// The name of the chart.
+	Name string `json:"name"`
+	// The digest of the Chart.lock, empty when the chart dependencies are not locked.
+	LockDigest string `json:"lockDigest,omitempty"`
+	// The dependencies packed into the chart.
+	Dependencies []ZarfBuildChartDependency `json:"dependencies,omitempty"`
+}
+
+// ZarfBuildChartDependency is a resolved Helm chart dependency.
+type ZarfBuildChartDependency struct {
+	// The name of the dependency.
+	Name string `json:"name"`
+	// The resolved version of the dependency.
+	Version string `json:"version"`
+	// The repository of the dependency.
+	Repository string `json:"repository,omitempty"`
+	// The digest of the files of the dependency as packed into the chart.
+	Digest string `json:"digest,omitempty"`
+	// The dependencies of the dependency.
+	Dependencies []ZarfBuildChartDependency `json:"dependencies,omitempty"`
 }
 
 // ZarfValues imports package-level values files and validation.
@@ -283,6 +317,63 @@ type ZarfValues struct {
 	Schema string `json:"schema,omitempty"`
 }
 
+// ImageExtractor finds container images in Kubernetes resources of a kind that find-images does not know about.
+type ImageExtractor struct {
+	// The API version of the resources to extract images from (e.g. argoproj.io/v1alpha1).
+	APIVersion string `json:"apiVersion"`
+	// The kind of the resources to extract images from.
+	Kind string `json:"kind"`
+	// Only extract images from resources with this name, useful for ConfigMaps read by operators.
+	Name string `json:"name,omitempty"`
+	// JSONPath expressions that return full image references (e.g. {.spec.image}).
+	Paths []string `json:"paths,omitempty"`
+	// Image references assembled from a repository and a tag or digest held in separate fields.
+	References []ImageExtractorReference `json:"references,omitempty"`
+}
+
+// ImageExtractorReference assembles an image reference from separate fields of a resource.
+type ImageExtractorReference struct {
+	// JSONPath expression that returns the image repository (e.g. {.spec.baseImage}).
+	Repository string `json:"repository"`
+	// JSONPath expression that returns the image tag (e.g. {.spec.version}).
+	Tag string `json:"tag,omitempty"`
+	// JSONPath expression that returns the image digest.
+	Digest string `json:"digest,omitempty"`
+}
+
+// ZarfTransforms are the rules that change the names the images and repositories of a package are stored under.
+// The first rule that matches an image or repository is used, those that match no rule keep the default naming.
+type ZarfTransforms struct {
+	// Rules applied to the images of the package.
+	Images []ZarfImageTransform `json:"images,omitempty"`
+	// Rules applied to the git repositories of the package.
+	Repos []ZarfRepoTransform `json:"repos,omitempty"`
+}
+
+// ZarfImageTransform changes the repository and tag an image is stored under in the registry.
+type ZarfImageTransform struct {
+	// Regular expression matched against the repository of the image including its registry (e.g. docker.io/library/nginx).
+	Match string `json:"match"`
+	// The repository path in the registry, may reference capture groups of match (e.g. platform/$1). Defaults to the path of the image.
+	Path string `json:"path,omitempty"`
+	// Join the elements of the path after the first with dashes (e.g. platform/library/nginx becomes platform/library-nginx).
+	Flatten bool `json:"flatten,omitempty"`
+	// Suffix appended to the tag of the image.
+	TagSuffix string `json:"tagSuffix,omitempty"`
+	// Do not append the checksum of the original image name to the tag.
+	NoChecksum bool `json:"noChecksum,omitempty"`
+}
+
+// ZarfRepoTransform changes the name a git repository is stored under in the git server.
+type ZarfRepoTransform struct {
+	// Regular expression matched against the host and path of the repository URL without its scheme, .git suffix and ref (e.g. github.com/stefanprodan/podinfo).
+	Match string `json:"match"`
+	// The repository name in the git server, may reference capture groups of match. Defaults to the name of the repository.
+	Name string `json:"name,omitempty"`
+	// Do not append the checksum of the original repository URL to the name.
+	NoChecksum bool `json:"noChecksum,omitempty"`
+}
+
 // VersionRequirement specifies minimum version requirements for the package
 type VersionRequirement struct {
 	// The minimum version of Zarf required to use this package
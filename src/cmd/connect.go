@@ -5,11 +5,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/zarf-dev/zarf/src/config/lang"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
@@ -20,15 +27,17 @@ import (
 )
 
 type connectOptions struct {
-	open bool
-	zt   cluster.TunnelInfo
+	open    bool
+	all     bool
+	gateway string
+	zt      cluster.TunnelInfo
 }
 
 func newConnectCommand() *cobra.Command {
 	o := &connectOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "connect { REGISTRY | GIT | connect-name }",
+		Use:     "connect { REGISTRY | GIT | connect-name }...",
 		Aliases: []string{"c"},
 		Short:   lang.CmdConnectShort,
 		Long:    lang.CmdConnectLong,
@@ -42,6 +51,8 @@ func newConnectCommand() *cobra.Command {
 	cmd.Flags().IntVar(&o.zt.LocalPort, "local-port", 0, lang.CmdConnectFlagLocalPort)
 	cmd.Flags().IntVar(&o.zt.RemotePort, "remote-port", 0, lang.CmdConnectFlagRemotePort)
 	cmd.Flags().BoolVar(&o.open, "open", false, lang.CmdConnectFlagOpen)
+	cmd.Flags().BoolVar(&o.all, "all", false, lang.CmdConnectFlagAll)
+	cmd.Flags().StringVar(&o.gateway, "gateway", "", lang.CmdConnectFlagGateway)
 
 	// TODO(soltysh): consider splitting sub-commands into separate files
 	cmd.AddCommand(newConnectListCommand())
@@ -50,6 +61,10 @@ func newConnectCommand() *cobra.Command {
 }
 
 func (o *connectOptions) run(cmd *cobra.Command, args []string) error {
+	if o.all || o.gateway != "" || len(args) > 1 {
+		return o.runMultiple(cmd, args)
+	}
+
 	ctx := cmd.Context()
 	l := logger.From(ctx)
 	target := ""
@@ -104,13 +119,99 @@ func (o *connectOptions) run(cmd *cobra.Command, args []string) error {
 		l.Info("Tunnel established, waiting for user to interrupt (ctrl-c to end)", "urls", strings.Join(urls, ", "))
 	}
 
-	// Wait for the interrupt signal or an error.
-	select {
-	case <-ctx.Done():
-		return nil
-	case err = <-tunnel.ErrChan():
-		return fmt.Errorf("lost connection to the service: %w", err)
+	// Wait for the interrupt signal, reconnecting if the connection to the service is lost.
+	return tunnel.KeepAlive(ctx)
+}
+
+// runMultiple connects to several services at once, optionally behind a single local HTTP gateway.
+func (o *connectOptions) runMultiple(cmd *cobra.Command, names []string) error {
+	if o.all && len(names) > 0 {
+		return errors.New("connect names can not be specified with --all")
+	}
+	if !o.all && len(names) == 0 {
+		return errors.New("specify the connect names or --all to connect to every service with a connect name")
+	}
+	for _, flag := range []string{"name", "namespace", "type", "local-port", "remote-port"} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("--%s can not be used when connecting to multiple services", flag)
+		}
+	}
+
+	ctx := cmd.Context()
+	l := logger.From(ctx)
+	c, err := cluster.New(ctx)
+	if err != nil {
+		return err
+	}
+	tunnels, err := c.ConnectAll(ctx, names, o.zt.ListenAddresses)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, tunnel := range tunnels {
+			tunnel.Close()
+		}
+	}()
+
+	connectData := [][]string{}
+	for name, tunnel := range tunnels {
+		connectData = append(connectData, []string{name, strings.Join(tunnel.FullURLs(), ", ")})
+	}
+	slices.SortFunc(connectData, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	message.TableWithWriter(OutputWriter, []string{"Connect Name", "URL"}, connectData)
+
+	g, gCtx := errgroup.WithContext(ctx)
+	urls := []string{}
+	if o.gateway != "" {
+		gateway, err := cluster.NewTunnelGateway(cluster.TunnelGatewayTargets(tunnels))
+		if err != nil {
+			return err
+		}
+		listener, err := net.Listen("tcp", o.gateway)
+		if err != nil {
+			return fmt.Errorf("unable to start the gateway on %s: %w", o.gateway, err)
+		}
+		server := &http.Server{Handler: gateway, ReadHeaderTimeout: 10 * time.Second}
+		g.Go(func() error {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("the gateway stopped: %w", err)
+			}
+			return nil
+		})
+		g.Go(func() error {
+			<-gCtx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(gCtx), 5*time.Second)
+			defer cancel()
+			return server.Shutdown(shutdownCtx)
+		})
+		gatewayURL := fmt.Sprintf("http://%s/", listener.Addr().String())
+		urls = append(urls, gatewayURL)
+		l.Info("gateway started, services are routed by path or by host name (e.g. http://<connect-name>.localhost)", "url", gatewayURL)
+	} else {
+		for _, tunnel := range tunnels {
+			urls = append(urls, tunnel.FullURLs()...)
+		}
+	}
+
+	for _, tunnel := range tunnels {
+		g.Go(func() error {
+			return tunnel.KeepAlive(gCtx)
+		})
+	}
+
+	if o.open {
+		l.Info("Tunnels established, opening your default web browser (ctrl-c to end)", "urls", strings.Join(urls, ", "))
+		for _, u := range urls {
+			if err := exec.LaunchURL(u); err != nil {
+				return err
+			}
+		}
+	} else {
+		l.Info("Tunnels established, waiting for user to interrupt (ctrl-c to end)", "count", len(tunnels))
 	}
+	return g.Wait()
 }
 
 // connectListOptions holds the command-line options for 'connect list' sub-command.
@@ -360,11 +360,15 @@ func doPruneImagesForPackages(ctx context.Context, options []crane.Option, s *st
 			deployedComponents[depComponent.Name] = true
 		}
 
+		rules, err := transform.NewImageRules(pkg.Data.Transforms.Images)
+		if err != nil {
+			return err
+		}
 		for _, component := range pkg.Data.Components {
 			if _, ok := deployedComponents[component.Name]; ok {
 				for _, image := range component.Images {
 					// We use the no checksum image since it will always exist and will share the same digest with other tags
-					transformedImageNoCheck, err := transform.ImageTransformHostWithoutChecksum(registryEndpoint, image)
+					transformedImageNoCheck, err := transform.ImageTransformHostWithRulesWithoutChecksum(registryEndpoint, image, rules)
 					if err != nil {
 						return err
 					}
@@ -64,6 +64,11 @@ func (o *destroyOptions) run(cmd *cobra.Command, _ []string) error {
 		l.Warn(err.Error())
 	}
 
+	// The destroy is logged as the audit log is removed with the Zarf namespace
+	if state != nil {
+		c.RecordDestroy(ctx)
+	}
+
 	// If Zarf deployed the cluster, burn it all down
 	if state != nil && (state.ZarfAppliance || (state.Distro == "")) {
 		// Check if we have the scripts to destroy everything
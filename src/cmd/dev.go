@@ -28,6 +28,7 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/archive"
 	"github.com/zarf-dev/zarf/src/pkg/lint"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/message"
 	"github.com/zarf-dev/zarf/src/pkg/packager"
 	"github.com/zarf-dev/zarf/src/pkg/packager/layout"
 	"github.com/zarf-dev/zarf/src/pkg/packager/load"
@@ -621,6 +622,8 @@ type devFindImagesOptions struct {
 	skipCosign          bool
 	registryURL         string
 	update              bool
+	pin                 bool
+	prune               bool
 }
 
 func newDevFindImagesCommand(v *viper.Viper) *cobra.Command {
@@ -658,6 +661,8 @@ func newDevFindImagesCommand(v *viper.Viper) *cobra.Command {
 	cmd.Flags().BoolVar(&o.skipCosign, "skip-cosign", false, lang.CmdDevFlagFindImagesSkipCosign)
 	// update images in zarf.yaml file
 	cmd.Flags().BoolVarP(&o.update, "update", "u", false, lang.CmdDevFlagFindImagesUpdate)
+	cmd.Flags().BoolVar(&o.pin, "pin", false, lang.CmdDevFlagFindImagesPin)
+	cmd.Flags().BoolVar(&o.prune, "prune", false, lang.CmdDevFlagFindImagesPrune)
 
 	cmd.Flags().StringVar(&o.registryURL, "registry-url", defaultRegistry, lang.CmdDevFlagRegistry)
 
@@ -668,6 +673,10 @@ func (o *devFindImagesOptions) run(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 	baseDir := setBaseDirectory(args)
 
+	if (o.pin || o.prune) && !o.update {
+		return errors.New("--pin and --prune can only be used with --update")
+	}
+
 	v := getViper()
 
 	o.createSetVariables = helpers.TransformAndMergeMap(
@@ -679,6 +688,10 @@ func (o *devFindImagesOptions) run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	imageExtractors, err := getImageExtractors(v)
+	if err != nil {
+		return err
+	}
 
 	findImagesOptions := packager.FindImagesOptions{
 		RepoHelmChartPath:   o.repoHelmChartPath,
@@ -691,6 +704,7 @@ func (o *devFindImagesOptions) run(cmd *cobra.Command, args []string) error {
 		SkipCosign:          o.skipCosign,
 		CachePath:           cachePath,
 		IsInteractive:       true,
+		ImageExtractors:     imageExtractors,
 	}
 	imagesScans, err := packager.FindImages(ctx, baseDir, findImagesOptions)
 	var lintErr *lint.LintError
@@ -742,9 +756,23 @@ func (o *devFindImagesOptions) run(cmd *cobra.Command, args []string) error {
 	fmt.Println(componentDefinition)
 
 	if o.update {
-		if err := packager.UpdateImages(ctx, baseDir, imagesScans); err != nil {
+		updateOpts := packager.UpdateImagesOptions{
+			Pin:           o.pin,
+			Prune:         o.prune,
+			CachePath:     cachePath,
+			RemoteOptions: defaultRemoteOptions(),
+		}
+		changes, err := packager.UpdateImages(ctx, baseDir, imagesScans, updateOpts)
+		if err != nil {
 			return fmt.Errorf("unable to create update: %w", err)
 		}
+		if len(changes) > 0 {
+			data := [][]string{}
+			for _, change := range changes {
+				data = append(data, []string{change.ComponentName, string(change.Type), change.Image, change.Previous})
+			}
+			message.TableWithWriter(OutputWriter, []string{"Component", "Change", "Image", "Previous"}, data)
+		}
 	}
 
 	return nil
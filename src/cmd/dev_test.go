@@ -11,7 +11,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/pkg/lint"
 	"github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/test/testutil"
@@ -176,3 +178,35 @@ func TestDevInspectValuesFiles(t *testing.T) {
 		})
 	}
 }
+
+func TestGetImageExtractors(t *testing.T) {
+	t.Parallel()
+
+	cfg := `
+[dev.find_images]
+image_extractors = [
+  { apiVersion = "v1", kind = "ConfigMap", name = "operator-config", references = [{ repository = "{.data.repository}", tag = "{.data.tag}" }] },
+  { apiVersion = "example.com/v1", kind = "App", paths = ["{.spec.image}"] },
+]
+`
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(bytes.NewBufferString(cfg)))
+
+	extractors, err := getImageExtractors(v)
+	require.NoError(t, err)
+	expected := []v1alpha1.ImageExtractor{
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       "operator-config",
+			References: []v1alpha1.ImageExtractorReference{{Repository: "{.data.repository}", Tag: "{.data.tag}"}},
+		},
+		{
+			APIVersion: "example.com/v1",
+			Kind:       "App",
+			Paths:      []string{"{.spec.image}"},
+		},
+	}
+	require.Equal(t, expected, extractors)
+}
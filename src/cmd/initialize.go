@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
 	"strings"
@@ -21,12 +22,15 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/packager"
 	"github.com/zarf-dev/zarf/src/pkg/packager/filters"
+	"github.com/zarf-dev/zarf/src/pkg/pki"
 	"github.com/zarf-dev/zarf/src/pkg/state"
 	"github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/yaml"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 type initOptions struct {
@@ -36,6 +40,11 @@ type initOptions struct {
 	gitServer               state.GitServerInfo
 	registryInfo            state.RegistryInfo
 	artifactServer          state.ArtifactServerInfo
+	agentInfo               state.AgentInfo
+	agentTLS                agentTLSOptions
+	credentialStore         credentialStoreOptions
+	registryS3              registryS3Options
+	routingConfig           string
 	injectorPort            int
 	adoptExistingResources  bool
 	timeout                 time.Duration
@@ -69,11 +78,10 @@ func newInitCommand() *cobra.Command {
 	cmd.Flags().StringVar(&o.storageClass, "storage-class", v.GetString(VInitStorageClass), lang.CmdInitFlagStorageClass)
 
 	cmd.Flags().StringVar((*string)(&o.registryInfo.RegistryMode), "registry-mode", "",
-		fmt.Sprintf("how to access the registry (valid values: %s, %s, %s). Proxy mode is an alpha feature", state.RegistryModeNodePort, state.RegistryModeProxy, state.RegistryModeExternal))
+		fmt.Sprintf("how to access the registry (valid values: %s, %s, %s, %s). Proxy mode is a beta feature, mirror mode configures containerd registry mirrors on each node instead of rewriting images",
+			state.RegistryModeNodePort, state.RegistryModeProxy, state.RegistryModeMirror, state.RegistryModeExternal))
 	cmd.Flags().IntVar(&o.injectorPort, "injector-port", v.GetInt(InjectorPort),
 		"the port that the injector will be exposed through. Affects the service nodeport in nodeport mode and pod hostport in proxy mode")
-	// While this feature is in early alpha we will hide the flags
-	cmd.Flags().MarkHidden("registry-mode")
 
 	// Flags for using an external Git server
 	cmd.Flags().StringVar(&o.gitServer.Address, "git-url", v.GetString(VInitGitURL), lang.CmdInitFlagGitURL)
@@ -91,11 +99,29 @@ func newInitCommand() *cobra.Command {
 	cmd.Flags().StringVar(&o.registryInfo.PullPassword, "registry-pull-password", v.GetString(VInitRegistryPullPass), lang.CmdInitFlagRegPullPass)
 	cmd.Flags().StringVar(&o.registryInfo.Secret, "registry-secret", v.GetString(VInitRegistrySecret), lang.CmdInitFlagRegSecret)
 
+	// Flags for storing the internal registry images in S3-compatible object storage
+	o.registryS3.addFlags(cmd, v)
+
 	// Flags for using an external artifact server
 	cmd.Flags().StringVar(&o.artifactServer.Address, "artifact-url", v.GetString(VInitArtifactURL), lang.CmdInitFlagArtifactURL)
 	cmd.Flags().StringVar(&o.artifactServer.PushUsername, "artifact-push-username", v.GetString(VInitArtifactPushUser), lang.CmdInitFlagArtifactPushUser)
 	cmd.Flags().StringVar(&o.artifactServer.PushToken, "artifact-push-token", v.GetString(VInitArtifactPushToken), lang.CmdInitFlagArtifactPushToken)
 
+	// Flags for the Zarf agent image policy and audit mode
+	cmd.Flags().BoolVar(&o.agentInfo.ImagePolicy.RequireImagesInRegistry, "agent-require-images-in-registry", v.GetBool(VInitAgentRequireImagesInRegistry), lang.CmdInitFlagAgentRequireImagesInRegistry)
+	cmd.Flags().StringSliceVar(&o.agentInfo.ImagePolicy.AllowedRegistries, "agent-allowed-registries", v.GetStringSlice(VInitAgentAllowedRegistries), lang.CmdInitFlagAgentAllowedRegistries)
+	cmd.Flags().BoolVar(&o.agentInfo.Audit.All, "agent-audit", v.GetBool(VInitAgentAudit), lang.CmdInitFlagAgentAudit)
+	cmd.Flags().StringSliceVar(&o.agentInfo.Audit.Hooks, "agent-audit-hooks", v.GetStringSlice(VInitAgentAuditHooks), lang.CmdInitFlagAgentAuditHooks)
+
+	// Flags for issuing the Zarf agent TLS certificate
+	o.agentTLS.addFlags(cmd, v)
+
+	// Flags for storing the Zarf credentials in an external backend
+	o.credentialStore.addFlags(cmd, v)
+
+	// Flags for routing images and repositories to named registries and git servers
+	cmd.Flags().StringVar(&o.routingConfig, "routing-config", v.GetString(VInitRoutingConfig), lang.CmdInitFlagRoutingConfig)
+
 	// Flags that control how a deployment proceeds
 	// Always require adopt-existing-resources flag (no viper)
 	cmd.Flags().BoolVar(&o.adoptExistingResources, "adopt-existing-resources", false, lang.CmdPackageDeployFlagAdoptExistingResources)
@@ -110,6 +136,7 @@ func newInitCommand() *cobra.Command {
 	cmd.MarkFlagsMutuallyExclusive("registry-url", "registry-mode")
 	cmd.MarkFlagsMutuallyExclusive("registry-url", "injector-port")
 	cmd.MarkFlagsMutuallyExclusive("registry-url", "nodeport")
+	cmd.MarkFlagsMutuallyExclusive("registry-url", "registry-s3-bucket")
 
 	cmd.Flags().SortFlags = true
 
@@ -123,10 +150,40 @@ func (o *initOptions) run(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("invalid command flags were provided: %w", err)
 	}
 
+	agentTLS, err := o.agentTLS.config()
+	if err != nil {
+		return fmt.Errorf("invalid command flags were provided: %w", err)
+	}
+	if agentTLS != nil {
+		o.agentInfo.TLS = *agentTLS
+	}
+	if err := o.credentialStore.validate(); err != nil {
+		return fmt.Errorf("invalid command flags were provided: %w", err)
+	}
+	if err := o.registryS3.validate(); err != nil {
+		return fmt.Errorf("invalid command flags were provided: %w", err)
+	}
+	o.registryInfo.S3 = o.registryS3.config()
+	routing, err := loadRoutingConfig(o.routingConfig)
+	if err != nil {
+		return err
+	}
+
 	if err := validateExistingStateMatchesInput(cmd.Context(), o.registryInfo, o.gitServer, o.artifactServer); err != nil {
 		return err
 	}
 
+	// The agent image policy and audit hooks are only changed when they are set so that init can also clear them
+	v := getViper()
+	var agentImagePolicy *state.ImagePolicy
+	if isFlagSet(cmd, v, "agent-require-images-in-registry", VInitAgentRequireImagesInRegistry) || isFlagSet(cmd, v, "agent-allowed-registries", VInitAgentAllowedRegistries) {
+		agentImagePolicy = &o.agentInfo.ImagePolicy
+	}
+	var agentAudit *state.AgentAudit
+	if isFlagSet(cmd, v, "agent-audit", VInitAgentAudit) || isFlagSet(cmd, v, "agent-audit-hooks", VInitAgentAuditHooks) {
+		agentAudit = &o.agentInfo.Audit
+	}
+
 	if o.registryInfo.RegistryMode == "" {
 		if o.registryInfo.Address == "" {
 			o.registryInfo.RegistryMode = state.RegistryModeNodePort
@@ -143,7 +200,6 @@ func (o *initOptions) run(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	v := getViper()
 	o.setVariables = helpers.TransformAndMergeMap(
 		v.GetStringMapString(VPkgDeploySet), o.setVariables, strings.ToUpper)
 
@@ -171,6 +227,12 @@ func (o *initOptions) run(cmd *cobra.Command, _ []string) error {
 		GitServer:              o.gitServer,
 		RegistryInfo:           o.registryInfo,
 		ArtifactServer:         o.artifactServer,
+		AgentTLS:               o.agentInfo.TLS,
+		AgentImagePolicy:       agentImagePolicy,
+		AgentAudit:             agentAudit,
+		CredentialStore:        o.credentialStore.config(),
+		VaultToken:             o.credentialStore.vaultToken,
+		Routing:                routing,
 		AdoptExistingResources: o.adoptExistingResources,
 		Timeout:                o.timeout,
 		Retries:                o.retries,
@@ -178,6 +240,7 @@ func (o *initOptions) run(cmd *cobra.Command, _ []string) error {
 		SetVariables:           o.setVariables,
 		StorageClass:           o.storageClass,
 		InjectorPort:           o.injectorPort,
+		SkipRegistryS3Check:    o.registryS3.skipCheck,
 		RemoteOptions:          defaultRemoteOptions(),
 		IsInteractive:          !o.confirm,
 	}
@@ -308,6 +371,11 @@ func validateExistingStateMatchesInput(ctx context.Context, registryInfo state.R
 	return nil
 }
 
+// isFlagSet returns true if the flag was set on the command line or its key is set in the Zarf config.
+func isFlagSet(cmd *cobra.Command, v *viper.Viper, flag, key string) bool {
+	return cmd.Flags().Changed(flag) || v.IsSet(key)
+}
+
 func (o *initOptions) validateInitFlags() error {
 	// If 'git-url' is provided, make sure they provided values for the username and password of the push user
 	if o.gitServer.Address != "" {
@@ -331,12 +399,176 @@ func (o *initOptions) validateInitFlags() error {
 	}
 
 	if o.registryInfo.RegistryMode != "" {
-		if o.registryInfo.RegistryMode != state.RegistryModeNodePort &&
-			o.registryInfo.RegistryMode != state.RegistryModeProxy && o.registryInfo.RegistryMode != state.RegistryModeExternal {
-			return fmt.Errorf("invalid registry mode %q, must be %q, %q, or %q", o.registryInfo.RegistryMode,
-				state.RegistryModeNodePort, state.RegistryModeProxy, state.RegistryModeExternal)
+		if o.registryInfo.RegistryMode != state.RegistryModeNodePort && o.registryInfo.RegistryMode != state.RegistryModeProxy &&
+			o.registryInfo.RegistryMode != state.RegistryModeMirror && o.registryInfo.RegistryMode != state.RegistryModeExternal {
+			return fmt.Errorf("invalid registry mode %q, must be %q, %q, %q, or %q", o.registryInfo.RegistryMode,
+				state.RegistryModeNodePort, state.RegistryModeProxy, state.RegistryModeMirror, state.RegistryModeExternal)
+		}
+	}
+
+	return nil
+}
+
+// agentTLSOptions holds the flags used to configure how the Zarf agent TLS certificate is issued.
+type agentTLSOptions struct {
+	caCertPath            string
+	caKeyPath             string
+	keyAlgorithm          string
+	certManagerIssuer     string
+	certManagerIssuerKind string
+}
+
+func (o *agentTLSOptions) addFlags(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().StringVar(&o.caCertPath, "agent-tls-ca-cert", v.GetString(VInitAgentTLSCACert), lang.CmdInitFlagAgentTLSCACert)
+	cmd.Flags().StringVar(&o.caKeyPath, "agent-tls-ca-key", v.GetString(VInitAgentTLSCAKey), lang.CmdInitFlagAgentTLSCAKey)
+	cmd.Flags().StringVar(&o.keyAlgorithm, "agent-tls-key-algorithm", v.GetString(VInitAgentTLSKeyAlgorithm), lang.CmdInitFlagAgentTLSKeyAlgorithm)
+	cmd.Flags().StringVar(&o.certManagerIssuer, "agent-tls-cert-manager-issuer", v.GetString(VInitAgentTLSCertManagerIssuer), lang.CmdInitFlagAgentTLSCertManagerIssuer)
+	cmd.Flags().StringVar(&o.certManagerIssuerKind, "agent-tls-cert-manager-issuer-kind", v.GetString(VInitAgentTLSCertManagerIssuerKind), lang.CmdInitFlagAgentTLSCertManagerIssuerKind)
+	cmd.MarkFlagsRequiredTogether("agent-tls-ca-cert", "agent-tls-ca-key")
+	cmd.MarkFlagsMutuallyExclusive("agent-tls-ca-cert", "agent-tls-cert-manager-issuer")
+}
+
+// config returns the agent TLS configuration from the flags or nil if none were set.
+func (o *agentTLSOptions) config() (*state.AgentTLSConfig, error) {
+	if o.caCertPath == "" && o.caKeyPath == "" && o.keyAlgorithm == "" && o.certManagerIssuer == "" {
+		return nil, nil
+	}
+	if (o.caCertPath == "") != (o.caKeyPath == "") {
+		return nil, errors.New("both an agent TLS CA certificate and key must be provided")
+	}
+	if o.caCertPath != "" && o.certManagerIssuer != "" {
+		return nil, errors.New("an agent TLS CA cannot be used with a cert-manager issuer")
+	}
+	cfg := &state.AgentTLSConfig{
+		KeyAlgorithm: pki.KeyAlgorithm(o.keyAlgorithm),
+	}
+	if cfg.KeyAlgorithm != "" && cfg.KeyAlgorithm != pki.KeyAlgorithmRSA && cfg.KeyAlgorithm != pki.KeyAlgorithmECDSA {
+		return nil, fmt.Errorf("invalid agent TLS key algorithm %q, must be %q or %q", cfg.KeyAlgorithm, pki.KeyAlgorithmRSA, pki.KeyAlgorithmECDSA)
+	}
+	if o.caCertPath != "" {
+		var err error
+		cfg.CACert, err = os.ReadFile(o.caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the agent TLS CA certificate: %w", err)
+		}
+		cfg.CAKey, err = os.ReadFile(o.caKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the agent TLS CA key: %w", err)
+		}
+	}
+	if o.certManagerIssuer != "" {
+		kind := o.certManagerIssuerKind
+		if kind == "" {
+			kind = "Issuer"
+		}
+		if kind != "Issuer" && kind != "ClusterIssuer" {
+			return nil, fmt.Errorf("invalid cert-manager issuer kind %q, must be Issuer or ClusterIssuer", kind)
+		}
+		cfg.CertManagerIssuer = &state.CertManagerIssuerRef{Name: o.certManagerIssuer, Kind: kind}
+	}
+	return cfg, nil
+}
+
+// credentialStoreOptions holds the flags used to configure the backend that stores the Zarf credentials.
+type credentialStoreOptions struct {
+	backend      string
+	vault        state.VaultCredentialStore
+	vaultToken   string
+	transitMount string
+	transitKey   string
+}
+
+func (o *credentialStoreOptions) addFlags(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().StringVar(&o.backend, "credential-store", v.GetString(VInitCredentialStore), lang.CmdInitFlagCredentialStore)
+	cmd.Flags().StringVar(&o.vault.Address, "vault-address", v.GetString(VInitVaultAddress), lang.CmdInitFlagVaultAddress)
+	cmd.Flags().StringVar(&o.vault.Mount, "vault-mount", v.GetString(VInitVaultMount), lang.CmdInitFlagVaultMount)
+	cmd.Flags().StringVar(&o.vault.Path, "vault-path", v.GetString(VInitVaultPath), lang.CmdInitFlagVaultPath)
+	cmd.Flags().StringVar(&o.vaultToken, "vault-token", v.GetString(VInitVaultToken), lang.CmdInitFlagVaultToken)
+	cmd.Flags().StringVar(&o.transitMount, "vault-transit-mount", v.GetString(VInitVaultTransitMount), lang.CmdInitFlagVaultTransitMount)
+	cmd.Flags().StringVar(&o.transitKey, "vault-transit-key", v.GetString(VInitVaultTransitKey), lang.CmdInitFlagVaultTransitKey)
+}
+
+// config returns the credential store configuration from the flags, the backend is empty if it was not set.
+func (o *credentialStoreOptions) config() state.CredentialStore {
+	cs := state.CredentialStore{Backend: state.CredentialStoreBackend(o.backend)}
+	switch cs.Backend {
+	case state.CredentialStoreVault:
+		vault := o.vault
+		cs.Vault = &vault
+	case state.CredentialStoreEnvelope:
+		cs.Envelope = &state.EnvelopeCredentialStore{
+			Address: o.vault.Address,
+			Mount:   o.transitMount,
+			Key:     o.transitKey,
 		}
 	}
+	return cs
+}
 
+func (o *credentialStoreOptions) validate() error {
+	if o.backend == "" {
+		if o.vault.Address != "" || o.vaultToken != "" || o.transitKey != "" {
+			return errors.New("the Vault flags require --credential-store=vault or --credential-store=envelope")
+		}
+		return nil
+	}
+	if o.backend != string(state.CredentialStoreEnvelope) && (o.transitMount != "" || o.transitKey != "") {
+		return errors.New("the Vault transit flags require --credential-store=envelope")
+	}
+	return o.config().Validate()
+}
+
+type registryS3Options struct {
+	storage   state.RegistryS3Storage
+	skipCheck bool
+}
+
+func (o *registryS3Options) addFlags(cmd *cobra.Command, v *viper.Viper) {
+	cmd.Flags().StringVar(&o.storage.Bucket, "registry-s3-bucket", v.GetString(VInitRegistryS3Bucket), lang.CmdInitFlagRegS3Bucket)
+	cmd.Flags().StringVar(&o.storage.Region, "registry-s3-region", v.GetString(VInitRegistryS3Region), lang.CmdInitFlagRegS3Region)
+	cmd.Flags().StringVar(&o.storage.Endpoint, "registry-s3-endpoint", v.GetString(VInitRegistryS3Endpoint), lang.CmdInitFlagRegS3Endpoint)
+	cmd.Flags().StringVar(&o.storage.RootDirectory, "registry-s3-root-directory", v.GetString(VInitRegistryS3RootDirectory), lang.CmdInitFlagRegS3RootDirectory)
+	cmd.Flags().StringVar(&o.storage.AccessKey, "registry-s3-access-key", v.GetString(VInitRegistryS3AccessKey), lang.CmdInitFlagRegS3AccessKey)
+	cmd.Flags().StringVar(&o.storage.SecretKey, "registry-s3-secret-key", v.GetString(VInitRegistryS3SecretKey), lang.CmdInitFlagRegS3SecretKey)
+	cmd.Flags().BoolVar(&o.storage.Insecure, "registry-s3-insecure", v.GetBool(VInitRegistryS3Insecure), lang.CmdInitFlagRegS3Insecure)
+	cmd.Flags().BoolVar(&o.storage.ForcePathStyle, "registry-s3-force-path-style", v.GetBool(VInitRegistryS3ForcePathStyle), lang.CmdInitFlagRegS3ForcePathStyle)
+	cmd.Flags().BoolVar(&o.skipCheck, "registry-s3-skip-check", v.GetBool(VInitRegistryS3SkipCheck), lang.CmdInitFlagRegS3SkipCheck)
+}
+
+// config returns the S3 storage of the registry from the flags, nil is returned if no bucket was set.
+func (o *registryS3Options) config() *state.RegistryS3Storage {
+	if o.storage.Bucket == "" {
+		return nil
+	}
+	storage := o.storage
+	return &storage
+}
+
+func (o *registryS3Options) validate() error {
+	if o.storage.Bucket != "" {
+		return nil
+	}
+	if o.storage != (state.RegistryS3Storage{}) || o.skipCheck {
+		return errors.New("the registry S3 flags require --registry-s3-bucket")
+	}
 	return nil
 }
+
+// loadRoutingConfig reads the named registries and git servers from a YAML file, nil is returned if no file is provided.
+func loadRoutingConfig(path string) (*state.RoutingConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the routing config: %w", err)
+	}
+	var routing state.RoutingConfig
+	if err := yaml.UnmarshalStrict(b, &routing); err != nil {
+		return nil, fmt.Errorf("unable to parse the routing config %s: %w", path, err)
+	}
+	if err := routing.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid routing config %s: %w", path, err)
+	}
+	return &routing, nil
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
@@ -19,8 +20,10 @@ import (
 	"github.com/zarf-dev/zarf/src/config/lang"
 	"github.com/zarf-dev/zarf/src/internal/agent"
 	"github.com/zarf-dev/zarf/src/internal/gitea"
+	"github.com/zarf-dev/zarf/src/internal/registrymirror"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/packager"
 	"github.com/zarf-dev/zarf/src/pkg/state"
 )
 
@@ -39,6 +42,8 @@ func newInternalCommand(rootCmd *cobra.Command) *cobra.Command {
 	cmd.AddCommand(newInternalUpdateGiteaPVCCommand())
 	cmd.AddCommand(newInternalIsValidHostnameCommand())
 	cmd.AddCommand(newInternalCrc32Command())
+	cmd.AddCommand(newInternalRotateCredentialsCommand())
+	cmd.AddCommand(newInternalRegistryMirrorCommand())
 
 	return cmd
 }
@@ -333,7 +338,7 @@ func (o *internalCreateArtifactRegistryTokenOptions) run(cmd *cobra.Command, _ [
 		if err != nil {
 			return err
 		}
-		if err := c.SaveState(ctx, s); err != nil {
+		if err := c.SaveState(ctx, s, state.AuditOperationInit); err != nil {
 			return err
 		}
 	}
@@ -419,3 +424,86 @@ func (o *internalCrc32Options) run(_ *cobra.Command, args []string) {
 	hash := helpers.GetCRCHash(text)
 	fmt.Printf("%d\n", hash)
 }
+
+type internalRotateCredentialsOptions struct {
+	services []string
+}
+
+func newInternalRotateCredentialsCommand() *cobra.Command {
+	o := &internalRotateCredentialsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rotate-credentials",
+		Short: lang.CmdInternalRotateCredentialsShort,
+		Long:  lang.CmdInternalRotateCredentialsLong,
+		Args:  cobra.NoArgs,
+		RunE:  o.run,
+	}
+
+	cmd.Flags().StringSliceVar(&o.services, "services", []string{}, lang.CmdInternalFlagRotateCredentialsServices)
+
+	return cmd
+}
+
+func (o *internalRotateCredentialsOptions) run(cmd *cobra.Command, _ []string) error {
+	validKeys := []string{state.RegistryKey, state.GitKey, state.ArtifactKey, state.AgentKey}
+	for _, service := range o.services {
+		if !slices.Contains(validKeys, service) {
+			return fmt.Errorf("invalid service key %s specified, valid key choices are: %v", service, validKeys)
+		}
+	}
+
+	ctx := cmd.Context()
+	timeoutCtx, cancel := context.WithTimeout(ctx, cluster.DefaultTimeout)
+	defer cancel()
+	c, err := cluster.NewWithWait(timeoutCtx)
+	if err != nil {
+		return err
+	}
+	_, err = packager.RotateCredentials(ctx, c, packager.RotateCredentialsOptions{Services: o.services})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type internalRegistryMirrorOptions struct {
+	mirrorsDir       string
+	certsDir         string
+	containerdConfig string
+	configPath       string
+	wait             bool
+}
+
+func newInternalRegistryMirrorCommand() *cobra.Command {
+	o := &internalRegistryMirrorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "registry-mirror",
+		Short: lang.CmdInternalRegistryMirrorShort,
+		Long:  lang.CmdInternalRegistryMirrorLong,
+		Args:  cobra.NoArgs,
+		RunE:  o.run,
+	}
+
+	cmd.Flags().StringVar(&o.mirrorsDir, "mirrors-dir", "/zarf-registry-mirrors", lang.CmdInternalFlagRegistryMirrorMirrorsDir)
+	cmd.Flags().StringVar(&o.certsDir, "certs-dir", "/certs.d", lang.CmdInternalFlagRegistryMirrorCertsDir)
+	cmd.Flags().StringVar(&o.containerdConfig, "containerd-config", "/containerd/config.toml", lang.CmdInternalFlagRegistryMirrorContainerdConfig)
+	cmd.Flags().StringVar(&o.configPath, "config-path", "/etc/containerd/certs.d", lang.CmdInternalFlagRegistryMirrorConfigPath)
+	cmd.Flags().BoolVar(&o.wait, "wait", false, lang.CmdInternalFlagRegistryMirrorWait)
+
+	return cmd
+}
+
+func (o *internalRegistryMirrorOptions) run(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	// The context is canceled once the pod is terminated
+	if o.wait {
+		<-ctx.Done()
+		return nil
+	}
+	if err := registrymirror.CheckConfigPath(o.containerdConfig, o.configPath); err != nil {
+		return err
+	}
+	return registrymirror.Sync(ctx, o.mirrorsDir, o.certsDir)
+}
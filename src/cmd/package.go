@@ -39,6 +39,7 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/packager/filters"
 	"github.com/zarf-dev/zarf/src/pkg/packager/layout"
 	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
 	"github.com/zarf-dev/zarf/src/pkg/utils"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
 )
@@ -62,6 +63,7 @@ func newPackageCommand() *cobra.Command {
 	cmd.AddCommand(newPackagePullCommand(v))
 	cmd.AddCommand(newPackageSignCommand(v))
 	cmd.AddCommand(newPackageVerifyCommand(v))
+	cmd.AddCommand(newPackageVerifyBuildCommand(v))
 
 	return cmd
 }
@@ -82,6 +84,7 @@ type packageCreateOptions struct {
 	ociConcurrency          int
 	skipVersionCheck        bool
 	withBuildMachineInfo    bool
+	reproducible            bool
 }
 
 func newPackageCreateCommand(v *viper.Viper) *cobra.Command {
@@ -126,6 +129,7 @@ func newPackageCreateCommand(v *viper.Viper) *cobra.Command {
 	cmd.Flags().StringVar(&o.signingKeyPassword, "signing-key-pass", v.GetString(VPkgCreateSigningKeyPassword), lang.CmdPackageCreateFlagSigningKeyPassword)
 
 	cmd.Flags().BoolVar(&o.withBuildMachineInfo, "with-build-machine-info", v.GetBool(VPkgCreateWithBuildMachineInfo), lang.CmdPackageCreateFlagWithBuildMachineInfo)
+	cmd.Flags().BoolVar(&o.reproducible, "reproducible", v.GetBool(VPkgCreateReproducible), lang.CmdPackageCreateFlagReproducible)
 
 	cmd.Flags().StringVarP(&o.signingKeyPath, "key", "k", v.GetString(VPkgCreateSigningKey), lang.CmdPackageCreateFlagDeprecatedKey)
 	cmd.Flags().StringVar(&o.signingKeyPassword, "key-pass", v.GetString(VPkgCreateSigningKeyPassword), lang.CmdPackageCreateFlagDeprecatedKeyPassword)
@@ -224,6 +228,7 @@ func (o *packageCreateOptions) run(ctx context.Context, args []string) error {
 		IsInteractive:           !o.confirm,
 		SkipVersionCheck:        o.skipVersionCheck,
 		WithBuildMachineInfo:    o.withBuildMachineInfo,
+		Reproducible:            o.reproducible,
 	}
 	pkgPath, err := packager.Create(ctx, baseDir, o.output, opt)
 	// NOTE(mkcp): LintErrors are rendered with a table
@@ -240,6 +245,7 @@ func (o *packageCreateOptions) run(ctx context.Context, args []string) error {
 
 type packageDeployOptions struct {
 	valuesFiles             []string
+	overlayDir              string
 	namespaceOverride       string
 	confirm                 bool
 	adoptExistingResources  bool
@@ -277,6 +283,7 @@ func newPackageDeployCommand(v *viper.Viper) *cobra.Command {
 	cmd.Flags().DurationVar(&o.timeout, "timeout", v.GetDuration(VPkgDeployTimeout), lang.CmdPackageDeployFlagTimeout)
 
 	cmd.Flags().StringSliceVarP(&o.valuesFiles, "values", "v", GetStringSlice(v, VPkgDeployValues), lang.CmdPackageDeployFlagValuesFiles)
+	cmd.Flags().StringVar(&o.overlayDir, "overlay", v.GetString(VPkgDeployOverlay), lang.CmdPackageDeployFlagOverlay)
 	cmd.Flags().IntVar(&o.retries, "retries", v.GetInt(VPkgRetries), lang.CmdPackageFlagRetries)
 	cmd.Flags().StringToStringVar(&o.setVariables, "set", v.GetStringMapString(VPkgDeploySet), lang.CmdPackageDeployFlagSet)
 	cmd.Flags().StringVar(&o.optionalComponents, "components", v.GetString(VPkgDeployComponents), lang.CmdPackageDeployFlagComponents)
@@ -314,6 +321,14 @@ func (o *packageDeployOptions) run(cmd *cobra.Command, args []string) (err error
 		return err
 	}
 
+	var overlays []state.DeployedOverlay
+	if o.overlayDir != "" {
+		overlays, err = packager.LoadOverlays(o.overlayDir)
+		if err != nil {
+			return err
+		}
+	}
+
 	cachePath, err := getCachePath(ctx)
 	if err != nil {
 		return err
@@ -345,6 +360,7 @@ func (o *packageDeployOptions) run(cmd *cobra.Command, args []string) (err error
 		OCIConcurrency:         o.ociConcurrency,
 		SetVariables:           o.setVariables,
 		NamespaceOverride:      o.namespaceOverride,
+		Overlays:               overlays,
 		RemoteOptions:          defaultRemoteOptions(),
 		IsInteractive:          !o.confirm,
 		SkipVersionCheck:       o.SkipVersionCheck,
@@ -592,6 +608,7 @@ func (o *packageMirrorResourcesOptions) run(cmd *cobra.Command, args []string) (
 
 	if o.mirrorImages && images > 0 {
 		logger.From(ctx).Info("mirroring images", "images", images)
+		var registries []state.NamedRegistryInfo
 		if o.registryInfo.Address == "" {
 			// if empty flag & zarf state available - execute
 			// otherwise return error
@@ -604,12 +621,14 @@ func (o *packageMirrorResourcesOptions) run(cmd *cobra.Command, args []string) (
 			}
 			logger.From(ctx).Debug("no registry URL provided, using zarf state", "address", state.RegistryInfo.Address)
 			o.registryInfo = state.RegistryInfo
+			registries = state.Registries
 		}
 		mirrorOpt := packager.ImagePushOptions{
 			Cluster:         c,
 			NoImageChecksum: o.noImgChecksum,
 			Retries:         o.retries,
 			OCIConcurrency:  o.ociConcurrency,
+			Registries:      registries,
 			RemoteOptions:   defaultRemoteOptions(),
 		}
 		err = packager.PushImagesToRegistry(ctx, pkgLayout, o.registryInfo, mirrorOpt)
@@ -624,6 +643,7 @@ func (o *packageMirrorResourcesOptions) run(cmd *cobra.Command, args []string) (
 
 	if o.mirrorRepos && repos > 0 {
 		logger.From(ctx).Info("mirroring repos", "repos", repos)
+		var gitServers []state.NamedGitServerInfo
 		if o.gitServer.Address == "" {
 			if c == nil {
 				return fmt.Errorf("no cluster connection detected - unable to obtain state")
@@ -634,11 +654,13 @@ func (o *packageMirrorResourcesOptions) run(cmd *cobra.Command, args []string) (
 			}
 			logger.From(ctx).Debug("no git URL provided, using zarf state", "address", state.GitServer.Address)
 			o.gitServer = state.GitServer
+			gitServers = state.GitServers
 		}
 
 		mirrorOpt := packager.RepoPushOptions{
-			Cluster: c,
-			Retries: o.retries,
+			Cluster:    c,
+			Retries:    o.retries,
+			GitServers: gitServers,
 		}
 		err = packager.PushReposToRepository(ctx, pkgLayout, o.gitServer, mirrorOpt)
 		if err != nil {
@@ -996,6 +1018,7 @@ func (o *packageInspectSBOMOptions) run(cmd *cobra.Command, args []string) (err
 
 type packageInspectImagesOptions struct {
 	namespaceOverride       string
+	transformed             bool
 	skipSignatureValidation bool
 	ociConcurrency          int
 	publicKeyPath           string
@@ -1020,6 +1043,7 @@ func newPackageInspectImagesCommand(v *viper.Viper) *cobra.Command {
 	cmd.Flags().StringVarP(&o.publicKeyPath, "key", "k", v.GetString(VPkgPublicKey), lang.CmdPackageFlagFlagPublicKey)
 	cmd.Flags().StringVarP(&o.namespaceOverride, "namespace", "n", o.namespaceOverride, lang.CmdPackageInspectFlagNamespace)
 	cmd.Flags().BoolVar(&o.skipSignatureValidation, "skip-signature-validation", o.skipSignatureValidation, lang.CmdPackageFlagSkipSignatureValidation)
+	cmd.Flags().BoolVar(&o.transformed, "transformed", o.transformed, lang.CmdPackageInspectFlagTransformed)
 
 	return cmd
 }
@@ -1061,12 +1085,51 @@ func (o *packageInspectImagesOptions) run(cmd *cobra.Command, args []string) err
 		return fmt.Errorf("no images found in package")
 	}
 
+	if o.transformed {
+		images, err = transformedImages(ctx, cluster, pkg, images)
+		if err != nil {
+			return err
+		}
+	}
+
 	for _, image := range images {
 		fmt.Println("-", image)
 	}
 	return nil
 }
 
+// transformedImages returns the references the images are stored under in the Zarf registry.
+// The registry of the cluster is used when it is initialized, otherwise the default internal registry.
+func transformedImages(ctx context.Context, c *cluster.Cluster, pkg v1alpha1.ZarfPackage, images []string) ([]string, error) {
+	registryInfo := state.RegistryInfo{}
+	if c != nil {
+		if s, err := c.LoadState(ctx); err == nil {
+			registryInfo = s.RegistryInfo
+		}
+	}
+	if err := registryInfo.FillInEmptyValues(state.IPFamilyIPv4); err != nil {
+		return nil, err
+	}
+	rules, err := transform.NewImageRules(pkg.Transforms.Images)
+	if err != nil {
+		return nil, err
+	}
+	transformed := []string{}
+	for _, image := range images {
+		var ref string
+		if registryInfo.RegistryMode == state.RegistryModeMirror {
+			ref, err = transform.ImageTransformMirror(registryInfo.Address, image)
+		} else {
+			ref, err = transform.ImageTransformHostWithRules(registryInfo.Address, image, rules)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to transform the image %s: %w", image, err)
+		}
+		transformed = append(transformed, ref)
+	}
+	return transformed, nil
+}
+
 type packageInspectDefinitionOptions struct {
 	namespaceOverride       string
 	skipSignatureValidation bool
@@ -1824,6 +1887,75 @@ func (o *packageVerifyOptions) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+type packageVerifyBuildOptions struct {
+	setVariables            map[string]string
+	publicKeyPath           string
+	skipSignatureValidation bool
+	ociConcurrency          int
+}
+
+func newPackageVerifyBuildCommand(v *viper.Viper) *cobra.Command {
+	o := &packageVerifyBuildOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "verify-build DEFINITION PACKAGE_SOURCE",
+		Args:    cobra.ExactArgs(2),
+		Short:   lang.CmdPackageVerifyBuildShort,
+		Long:    lang.CmdPackageVerifyBuildLong,
+		Example: lang.CmdPackageVerifyBuildExample,
+		RunE:    o.run,
+	}
+
+	cmd.Flags().StringToStringVar(&o.setVariables, "set", v.GetStringMapString(VPkgCreateSet), lang.CmdPackageCreateFlagSet)
+	cmd.Flags().StringVarP(&o.publicKeyPath, "key", "k", v.GetString(VPkgPublicKey), lang.CmdPackageVerifyFlagKey)
+	cmd.Flags().BoolVar(&o.skipSignatureValidation, "skip-signature-validation", false, lang.CmdPackageFlagSkipSignatureValidation)
+	cmd.Flags().IntVar(&o.ociConcurrency, "oci-concurrency", v.GetInt(VPkgOCIConcurrency), lang.CmdPackageFlagConcurrency)
+
+	return cmd
+}
+
+func (o *packageVerifyBuildOptions) run(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	l := logger.From(ctx)
+
+	// The zarf.yaml of the definition can be given directly, the package is rebuilt from its directory.
+	definitionPath := args[0]
+	if filepath.Base(definitionPath) == layout.ZarfYAML {
+		definitionPath = filepath.Dir(definitionPath)
+	}
+	packageSource := args[1]
+
+	cachePath, err := getCachePath(ctx)
+	if err != nil {
+		return err
+	}
+	v := getViper()
+	o.setVariables = helpers.TransformAndMergeMap(v.GetStringMapString(VPkgCreateSet), o.setVariables, strings.ToUpper)
+
+	opts := packager.VerifyBuildOptions{
+		SetVariables:            o.setVariables,
+		OCIConcurrency:          o.ociConcurrency,
+		CachePath:               cachePath,
+		PublicKeyPath:           o.publicKeyPath,
+		SkipSignatureValidation: o.skipSignatureValidation,
+		RemoteOptions:           defaultRemoteOptions(),
+	}
+	diffs, err := packager.VerifyBuild(ctx, definitionPath, packageSource, opts)
+	if err != nil {
+		return fmt.Errorf("unable to verify the build: %w", err)
+	}
+	if len(diffs) > 0 {
+		data := [][]string{}
+		for _, diff := range diffs {
+			data = append(data, []string{diff.Path, diff.Expected, diff.Actual})
+		}
+		message.TableWithWriter(OutputWriter, []string{"Path", "Expected", "Actual"}, data)
+		return fmt.Errorf("the rebuilt package differs from %s in %d files", packageSource, len(diffs))
+	}
+	l.Info("build verification", "status", "PASSED")
+	return nil
+}
+
 func choosePackage(ctx context.Context, args []string) (string, error) {
 	if len(args) > 0 {
 		return args[0], nil
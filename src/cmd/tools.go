@@ -30,6 +30,7 @@ func newToolsCommand() *cobra.Command {
 	cmd.AddCommand(newYQCommand())
 	cmd.AddCommand(newGetCredsCommand())
 	cmd.AddCommand(newUpdateCredsCommand(v))
+	cmd.AddCommand(newAuditCommand())
 	cmd.AddCommand(newClearCacheCommand())
 	cmd.AddCommand(newDownloadInitCommand())
 	cmd.AddCommand(newGenPKICommand())
@@ -12,9 +12,11 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 	"github.com/zarf-dev/zarf/src/config"
 )
@@ -65,12 +67,50 @@ const (
 	VInitRegistryPullUser = "init.registry.pull_username"
 	VInitRegistryPullPass = "init.registry.pull_password"
 
+	// Init Registry S3 storage config keys
+
+	VInitRegistryS3Bucket         = "init.registry.s3.bucket"
+	VInitRegistryS3Region         = "init.registry.s3.region"
+	VInitRegistryS3Endpoint       = "init.registry.s3.endpoint"
+	VInitRegistryS3RootDirectory  = "init.registry.s3.root_directory"
+	VInitRegistryS3AccessKey      = "init.registry.s3.access_key"
+	VInitRegistryS3SecretKey      = "init.registry.s3.secret_key"
+	VInitRegistryS3Insecure       = "init.registry.s3.insecure"
+	VInitRegistryS3ForcePathStyle = "init.registry.s3.force_path_style"
+	VInitRegistryS3SkipCheck      = "init.registry.s3.skip_check"
+
 	// Init Package config keys
 
 	VInitArtifactURL       = "init.artifact.url"
 	VInitArtifactPushUser  = "init.artifact.push_username"
 	VInitArtifactPushToken = "init.artifact.push_token"
 
+	// Init Agent config keys
+
+	VInitAgentRequireImagesInRegistry  = "init.agent.require_images_in_registry"
+	VInitAgentAllowedRegistries        = "init.agent.allowed_registries"
+	VInitAgentAudit                    = "init.agent.audit"
+	VInitAgentAuditHooks               = "init.agent.audit_hooks"
+	VInitAgentTLSCACert                = "init.agent.tls_ca_cert"
+	VInitAgentTLSCAKey                 = "init.agent.tls_ca_key"
+	VInitAgentTLSKeyAlgorithm          = "init.agent.tls_key_algorithm"
+	VInitAgentTLSCertManagerIssuer     = "init.agent.tls_cert_manager_issuer"
+	VInitAgentTLSCertManagerIssuerKind = "init.agent.tls_cert_manager_issuer_kind"
+
+	// Init Credential Store config keys
+
+	VInitCredentialStore   = "init.credential_store.backend"
+	VInitVaultAddress      = "init.credential_store.vault_address"
+	VInitVaultMount        = "init.credential_store.vault_mount"
+	VInitVaultPath         = "init.credential_store.vault_path"
+	VInitVaultToken        = "init.credential_store.vault_token"
+	VInitVaultTransitMount = "init.credential_store.vault_transit_mount"
+	VInitVaultTransitKey   = "init.credential_store.vault_transit_key"
+
+	// Init routing config keys
+
+	VInitRoutingConfig = "init.routing_config"
+
 	// Package config keys
 
 	VPkgOCIConcurrency = "package.oci_concurrency"
@@ -90,6 +130,7 @@ const (
 	VPkgCreateRegistryOverride     = "package.create.registry_override"
 	VPkgCreateFlavor               = "package.create.flavor"
 	VPkgCreateWithBuildMachineInfo = "package.create.with_build_machine_info"
+	VPkgCreateReproducible         = "package.create.reproducible"
 
 	// Package deploy config keys
 
@@ -100,6 +141,7 @@ const (
 	VPkgDeployNamespace  = "package.deploy.namespace"
 	VPkgRetries          = "package.deploy.retries"
 	VPkgDeployValues     = "package.deploy.values"
+	VPkgDeployOverlay    = "package.deploy.overlay"
 
 	// Package publish config keys
 
@@ -122,6 +164,10 @@ const (
 	// Dev deploy config keys
 
 	VDevDeployNoYolo = "dev.deploy.no_yolo"
+
+	// Dev find-images config keys
+
+	VDevFindImagesImageExtractors = "dev.find_images.image_extractors"
 )
 
 var (
@@ -238,6 +284,19 @@ func setDefaults() {
 	v.SetDefault(VPkgPublishRetries, 1)
 }
 
+// getImageExtractors returns the image extractors declared in the config file.
+// The extractors use the same field names as the package definition (e.g. apiVersion, kind, paths).
+func getImageExtractors(v *viper.Viper) ([]v1alpha1.ImageExtractor, error) {
+	var extractors []v1alpha1.ImageExtractor
+	err := v.UnmarshalKey(VDevFindImagesImageExtractors, &extractors, func(c *mapstructure.DecoderConfig) {
+		c.TagName = "json"
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", VDevFindImagesImageExtractors, err)
+	}
+	return extractors, nil
+}
+
 // GetStringSlice returns a string slice from viper
 // it consistently returns expected results across flags and environment variables
 // https://github.com/spf13/viper/issues/380
@@ -5,11 +5,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/utils"
 
 	// Import to initialize client auth plugins.
@@ -48,6 +50,17 @@ func (o *waitForOptions) run(cmd *cobra.Command, args []string) error {
 
 	kind := args[0]
 
+	// The registry proxy is healthy once it is ready on every node, this is not a single resource condition kubectl can wait for
+	if kind == "registry-proxy" {
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		defer cancel()
+		c, err := cluster.New(ctx)
+		if err != nil {
+			return err
+		}
+		return c.WaitForRegistryProxy(ctx)
+	}
+
 	// identifier is optional to allow for commands like `zarf tools wait-for storageclass` without specifying a name.
 	identifier := ""
 	if len(args) > 1 {
@@ -12,18 +12,22 @@ import (
 	"io"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/Masterminds/semver/v3"
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/sigstore/cosign/v3/pkg/cosign"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	goyaml "github.com/goccy/go-yaml"
 	"github.com/zarf-dev/zarf/src/config"
 	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/internal/feature"
 	"github.com/zarf-dev/zarf/src/internal/packager/helm"
 	"github.com/zarf-dev/zarf/src/internal/packager/template"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
@@ -222,10 +226,16 @@ func printComponentCredential(ctx context.Context, s *state.State, componentName
 }
 
 type updateCredsOptions struct {
-	confirm        bool
-	gitServer      state.GitServerInfo
-	registryInfo   state.RegistryInfo
-	artifactServer state.ArtifactServerInfo
+	confirm         bool
+	all             bool
+	rotate          bool
+	registryMode    string
+	gitServer       state.GitServerInfo
+	registryInfo    state.RegistryInfo
+	artifactServer  state.ArtifactServerInfo
+	agentTLS        agentTLSOptions
+	credentialStore credentialStoreOptions
+	routingConfig   string
 }
 
 func newUpdateCredsCommand(v *viper.Viper) *cobra.Command {
@@ -244,6 +254,10 @@ func newUpdateCredsCommand(v *viper.Viper) *cobra.Command {
 	// Always require confirm flag (no viper)
 	cmd.Flags().BoolVarP(&o.confirm, "confirm", "c", false, lang.CmdToolsUpdateCredsConfirmFlag)
 
+	cmd.Flags().BoolVar(&o.all, "all", false, lang.CmdToolsUpdateCredsAllFlag)
+	cmd.Flags().BoolVar(&o.rotate, "rotate", false, lang.CmdToolsUpdateCredsRotateFlag)
+	cmd.Flags().StringVar(&o.registryMode, "mode", "", lang.CmdToolsUpdateCredsModeFlag)
+
 	// Flags for using an external Git server
 	cmd.Flags().StringVar(&o.gitServer.Address, "git-url", v.GetString(VInitGitURL), lang.CmdInitFlagGitURL)
 	cmd.Flags().StringVar(&o.gitServer.PushUsername, "git-push-username", v.GetString(VInitGitPushUser), lang.CmdInitFlagGitPushUser)
@@ -263,6 +277,15 @@ func newUpdateCredsCommand(v *viper.Viper) *cobra.Command {
 	cmd.Flags().StringVar(&o.artifactServer.PushUsername, "artifact-push-username", v.GetString(VInitArtifactPushUser), lang.CmdInitFlagArtifactPushUser)
 	cmd.Flags().StringVar(&o.artifactServer.PushToken, "artifact-push-token", v.GetString(VInitArtifactPushToken), lang.CmdInitFlagArtifactPushToken)
 
+	// Flags for issuing the Zarf agent TLS certificate
+	o.agentTLS.addFlags(cmd, v)
+
+	// Flags for moving the Zarf credentials to an external backend
+	o.credentialStore.addFlags(cmd, v)
+
+	// Flags for replacing the named registries and git servers
+	cmd.Flags().StringVar(&o.routingConfig, "routing-config", v.GetString(VInitRoutingConfig), lang.CmdToolsUpdateCredsRoutingConfigFlag)
+
 	cmd.Flags().SortFlags = true
 
 	return cmd
@@ -275,6 +298,9 @@ func (o *updateCredsOptions) run(cmd *cobra.Command, args []string) error {
 		state.ArtifactKey,
 		state.AgentKey,
 	}
+	if o.all && len(args) > 0 {
+		return errors.New("a service key can not be specified with --all")
+	}
 	if len(args) == 0 {
 		args = validKeys
 	} else {
@@ -283,6 +309,12 @@ func (o *updateCredsOptions) run(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("invalid service key specified, valid key choices are: %v", validKeys)
 		}
 	}
+	if o.registryMode != "" {
+		return o.runSwitchRegistryMode(cmd, args)
+	}
+	if o.rotate {
+		return o.runRotate(cmd, args)
+	}
 
 	ctx := cmd.Context()
 	l := logger.From(ctx)
@@ -302,16 +334,55 @@ func (o *updateCredsOptions) run(cmd *cobra.Command, args []string) error {
 	if oldState.Distro == "" {
 		return errors.New("zarf state secret did not load properly")
 	}
+	agentTLS, err := o.agentTLS.config()
+	if err != nil {
+		return err
+	}
+	// The agent certificate is signed with the CA key of the existing configuration unless a new one is provided
+	if slices.Contains(args, state.AgentKey) && agentTLS == nil {
+		if err := c.LoadAgentCA(ctx, oldState); err != nil {
+			return err
+		}
+	}
 	opts := state.MergeOptions{
 		GitServer:      o.gitServer,
 		RegistryInfo:   o.registryInfo,
 		ArtifactServer: o.artifactServer,
+		AgentTLS:       agentTLS,
 		Services:       args,
 	}
 	newState, err := state.Merge(oldState, opts)
 	if err != nil {
 		return fmt.Errorf("unable to update Zarf credentials: %w", err)
 	}
+	// A new Vault token can be provided on its own for a state that already uses the vault or envelope credential store
+	usesVault := oldState.CredentialStore.Backend == state.CredentialStoreVault || oldState.CredentialStore.Backend == state.CredentialStoreEnvelope
+	updateVaultTokenOnly := o.credentialStore.backend == "" && o.credentialStore.vaultToken != "" && usesVault
+	if !updateVaultTokenOnly {
+		if err := o.credentialStore.validate(); err != nil {
+			return fmt.Errorf("invalid command flags were provided: %w", err)
+		}
+	}
+	// Changing the credential store migrates the existing credentials when the state is saved
+	if o.credentialStore.backend != "" {
+		newState.CredentialStore = o.credentialStore.config()
+	}
+	routing, err := loadRoutingConfig(o.routingConfig)
+	if err != nil {
+		return err
+	}
+	if routing != nil {
+		if err := routing.FillInEmptyValues(); err != nil {
+			return err
+		}
+		newState.SetRouting(*routing)
+	}
+	// Certificates issued by cert-manager are renewed by cert-manager and synced from the cluster
+	if slices.Contains(args, state.AgentKey) && newState.AgentInfo.TLS.CertManagerIssuer != nil {
+		if err := c.RenewAgentTLS(ctx, newState); err != nil {
+			return err
+		}
+	}
 
 	printCredentialUpdates(ctx, oldState, newState, args)
 
@@ -330,14 +401,14 @@ func (o *updateCredsOptions) run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Update registry and git pull secrets
-	if slices.Contains(args, state.RegistryKey) {
+	// Update registry and git pull secrets, the pull secrets include the named registries and git servers
+	if slices.Contains(args, state.RegistryKey) || routing != nil {
 		err := c.UpdateZarfManagedImageSecrets(ctx, newState)
 		if err != nil {
 			return err
 		}
 	}
-	if slices.Contains(args, state.GitKey) {
+	if slices.Contains(args, state.GitKey) || routing != nil {
 		err := c.UpdateZarfManagedGitSecrets(ctx, newState)
 		if err != nil {
 			return err
@@ -358,8 +429,18 @@ func (o *updateCredsOptions) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// The Vault token must be in place before the credentials can be written to Vault
+	if o.credentialStore.vaultToken != "" {
+		if err := c.SaveVaultToken(ctx, o.credentialStore.vaultToken); err != nil {
+			return err
+		}
+	}
+	if oldState.CredentialStore.Backend != newState.CredentialStore.Backend {
+		l.Info("moving the Zarf credentials to a new credential store", "from", oldState.CredentialStore.Backend, "to", newState.CredentialStore.Backend)
+	}
+
 	// Save the final Zarf State
-	err = c.SaveState(ctx, newState)
+	err = c.SaveState(ctx, newState, state.AuditOperationUpdateCreds)
 	if err != nil {
 		return fmt.Errorf("failed to save the Zarf State to the cluster: %w", err)
 	}
@@ -398,6 +479,140 @@ func (o *updateCredsOptions) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runRotate regenerates the internal credentials of the services and rolls back if they can not all be applied.
+func (o *updateCredsOptions) runRotate(cmd *cobra.Command, services []string) error {
+	// Only the internal credentials are rotated, credentials for external services must be provided without --rotate
+	var credentialFlags []string
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed && !slices.Contains([]string{"confirm", "all", "rotate"}, f.Name) {
+			credentialFlags = append(credentialFlags, "--"+f.Name)
+		}
+	})
+	if len(credentialFlags) > 0 {
+		return fmt.Errorf("--rotate only regenerates internal credentials and can not be used with %s", strings.Join(credentialFlags, ", "))
+	}
+
+	ctx := cmd.Context()
+	confirm := o.confirm
+	if !confirm {
+		prompt := &survey.Confirm{
+			Message: fmt.Sprintf(lang.CmdToolsUpdateCredsConfirmRotate, strings.Join(services, ", ")),
+		}
+		if err := survey.AskOne(prompt, &confirm); err != nil {
+			return fmt.Errorf("confirm selection canceled: %w", err)
+		}
+	}
+	if !confirm {
+		return nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, cluster.DefaultTimeout)
+	defer cancel()
+	c, err := cluster.NewWithWait(timeoutCtx)
+	if err != nil {
+		return err
+	}
+	_, err = packager.RotateCredentials(ctx, c, packager.RotateCredentialsOptions{Services: services})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// runSwitchRegistryMode moves the internal registry to another registry mode without changing its credentials.
+func (o *updateCredsOptions) runSwitchRegistryMode(cmd *cobra.Command, services []string) error {
+	if len(services) != 1 || services[0] != state.RegistryKey {
+		return fmt.Errorf("--mode can only be used with the %s service key", state.RegistryKey)
+	}
+	var otherFlags []string
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed && !slices.Contains([]string{"confirm", "mode"}, f.Name) {
+			otherFlags = append(otherFlags, "--"+f.Name)
+		}
+	})
+	if len(otherFlags) > 0 {
+		return fmt.Errorf("--mode only changes the registry mode and can not be used with %s", strings.Join(otherFlags, ", "))
+	}
+	mode := state.RegistryMode(o.registryMode)
+	if mode != state.RegistryModeProxy && mode != state.RegistryModeNodePort {
+		return fmt.Errorf("invalid registry mode %q, must be %q or %q", mode, state.RegistryModeProxy, state.RegistryModeNodePort)
+	}
+	if mode == state.RegistryModeProxy && !feature.IsEnabled(feature.RegistryProxy) {
+		return errors.New("the registry proxy feature gate is not enabled")
+	}
+
+	ctx := cmd.Context()
+	l := logger.From(ctx)
+	timeoutCtx, cancel := context.WithTimeout(ctx, cluster.DefaultTimeout)
+	defer cancel()
+	c, err := cluster.NewWithWait(timeoutCtx)
+	if err != nil {
+		return err
+	}
+	oldState, err := c.LoadState(ctx)
+	if err != nil {
+		return err
+	}
+	previous := oldState.RegistryInfo
+	if previous.RegistryMode == mode {
+		l.Info("the Zarf registry is already in the requested mode", "mode", mode)
+		return nil
+	}
+	if previous.RegistryMode != state.RegistryModeProxy && previous.RegistryMode != state.RegistryModeNodePort {
+		return fmt.Errorf("the Zarf registry can not be moved from the %s registry mode", previous.RegistryMode)
+	}
+
+	newState := *oldState
+	if err := newState.RegistryInfo.SwitchMode(mode, newState.IPFamily); err != nil {
+		return err
+	}
+	newState.RegistryProxyTLS = nil
+	if mode == state.RegistryModeProxy {
+		if err := c.LoadAgentCA(ctx, &newState); err != nil {
+			return err
+		}
+		newState.RegistryProxyTLS, err = newState.AgentInfo.TLS.GenerateRegistryProxyTLS()
+		if err != nil {
+			return fmt.Errorf("unable to setup the registry proxy PKI: %w", err)
+		}
+	}
+	l.Info("registry URL address", "existing", previous.Address, "replacement", newState.RegistryInfo.Address)
+
+	confirm := o.confirm
+	if !confirm {
+		prompt := &survey.Confirm{
+			Message: fmt.Sprintf(lang.CmdToolsUpdateCredsConfirmMode, previous.RegistryMode, mode),
+		}
+		if err := survey.AskOne(prompt, &confirm); err != nil {
+			return fmt.Errorf("confirm selection canceled: %w", err)
+		}
+	}
+	if !confirm {
+		return nil
+	}
+
+	helmOpts := helm.InstallUpgradeOptions{
+		VariableConfig: template.GetZarfVariableConfig(ctx, !o.confirm),
+		State:          &newState,
+		Cluster:        c,
+		AirgapMode:     true,
+		Timeout:        config.ZarfDefaultTimeout,
+		IsInteractive:  !o.confirm,
+	}
+	// The state is only saved once the registry is reachable in the new mode
+	if err := helm.UpdateZarfRegistryMode(ctx, previous, helmOpts); err != nil {
+		return fmt.Errorf("unable to move the Zarf registry to %s mode, the Zarf state was not changed: %w", mode, err)
+	}
+	if err := c.SaveState(ctx, &newState, state.AuditOperationUpdateCreds); err != nil {
+		return fmt.Errorf("failed to save the Zarf State to the cluster: %w", err)
+	}
+	if err := c.UpdateZarfManagedImageSecrets(ctx, &newState); err != nil {
+		return err
+	}
+	l.Info("moved the Zarf registry, restart workloads to pull their images from the new registry address", "mode", mode, "address", newState.RegistryInfo.Address)
+	return nil
+}
+
 func printCredentialUpdates(ctx context.Context, oldState *state.State, newState *state.State, services []string) {
 	// Pause the logfile's output to avoid credentials being printed to the log file
 	l := logger.From(ctx)
@@ -533,6 +748,141 @@ func (o *downloadInitOptions) run(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+type auditOptions struct {
+	packageName  string
+	operation    string
+	since        string
+	until        string
+	outputFormat outputFormat
+	outputWriter io.Writer
+	cluster      *cluster.Cluster
+}
+
+func newAuditCommand() *cobra.Command {
+	o := &auditOptions{
+		outputFormat: outputTable,
+		outputWriter: OutputWriter,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "audit",
+		Short:   lang.CmdToolsAuditShort,
+		Long:    lang.CmdToolsAuditLong,
+		Example: lang.CmdToolsAuditExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			err := o.complete(ctx)
+			if err != nil {
+				return err
+			}
+			return o.run(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.packageName, "package", "", lang.CmdToolsAuditFlagPackage)
+	cmd.Flags().StringVar(&o.operation, "operation", "", lang.CmdToolsAuditFlagOperation)
+	cmd.Flags().StringVar(&o.since, "since", "", lang.CmdToolsAuditFlagSince)
+	cmd.Flags().StringVar(&o.until, "until", "", lang.CmdToolsAuditFlagUntil)
+	cmd.Flags().VarP(&o.outputFormat, "output-format", "o", "Prints the output in the specified format. Valid options: table, json, yaml")
+
+	return cmd
+}
+
+func (o *auditOptions) complete(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, cluster.DefaultTimeout)
+	defer cancel()
+	c, err := cluster.NewWithWait(timeoutCtx)
+	if err != nil {
+		return err
+	}
+	o.cluster = c
+	return nil
+}
+
+func (o *auditOptions) run(ctx context.Context) error {
+	now := time.Now()
+	since, err := parseAuditTime(o.since, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseAuditTime(o.until, now)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	filter := state.AuditFilter{
+		Package:   o.packageName,
+		Operation: state.AuditOperation(o.operation),
+		Since:     since,
+		Until:     until,
+	}
+	entries, err := o.cluster.GetAuditEntries(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("unable to read the Zarf audit log: %w", err)
+	}
+
+	switch o.outputFormat {
+	case outputJSON:
+		output, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.outputWriter, string(output))
+	case outputYAML:
+		output, err := goyaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(o.outputWriter, string(output))
+	case outputTable:
+		header := []string{"Time", "Operation", "Package", "Generation", "User", "CLI Version", "Changes"}
+		var auditData [][]string
+		for _, entry := range entries {
+			generation := ""
+			if entry.Generation != 0 {
+				generation = strconv.Itoa(entry.Generation)
+			}
+			auditData = append(auditData, []string{
+				entry.Timestamp.Local().Format(time.RFC3339), string(entry.Operation), entry.Package, generation,
+				entry.User, entry.CLIVersion, summarizeAuditChanges(entry.Changes),
+			})
+		}
+		message.TableWithWriter(o.outputWriter, header, auditData)
+	default:
+		return fmt.Errorf("unsupported output format: %s", o.outputFormat)
+	}
+	return nil
+}
+
+// parseAuditTime parses either a duration before now, e.g. 24h, or an RFC3339 timestamp.
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q must be a duration such as 24h or an RFC3339 timestamp", s)
+	}
+	return t, nil
+}
+
+// summarizeAuditChanges lists the first changed fields for the table output.
+func summarizeAuditChanges(changes []state.AuditChange) string {
+	const maxPaths = 3
+	paths := []string{}
+	for i, change := range changes {
+		if i == maxPaths {
+			paths = append(paths, fmt.Sprintf("(+%d more)", len(changes)-maxPaths))
+			break
+		}
+		paths = append(paths, change.Path)
+	}
+	return strings.Join(paths, ", ")
+}
+
 type genPKIOptions struct {
 	subAltNames []string
 }
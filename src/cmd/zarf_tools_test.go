@@ -8,10 +8,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/state"
@@ -103,3 +106,84 @@ func TestGetCreds(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateCredsArgs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		args        []string
+		expectedErr string
+	}{
+		{
+			name:        "service key with all",
+			args:        []string{"registry", "--all"},
+			expectedErr: "a service key can not be specified with --all",
+		},
+		{
+			name:        "rotate with credentials",
+			args:        []string{"--all", "--rotate", "--confirm", "--registry-push-password=secret", "--git-url=https://git.example.com"},
+			expectedErr: "--rotate only regenerates internal credentials and can not be used with --git-url, --registry-push-password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := newUpdateCredsCommand(viper.New())
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			err := cmd.ExecuteContext(context.Background())
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &cluster.Cluster{Clientset: fake.NewClientset()}
+	require.NoError(t, c.SaveState(ctx, &state.State{Distro: "k3s"}, state.AuditOperationInit))
+	// Deleting a package that does not exist is not recorded
+	require.Error(t, c.DeleteDeployedPackage(ctx, state.DeployedPackage{Name: "podinfo"}))
+
+	buf := &bytes.Buffer{}
+	o := &auditOptions{
+		operation:    string(state.AuditOperationInit),
+		since:        "1h",
+		outputFormat: outputJSON,
+		outputWriter: buf,
+		cluster:      c,
+	}
+	require.NoError(t, o.run(ctx))
+	var entries []state.AuditEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	require.Equal(t, []state.AuditChange{{Path: "distro", New: "k3s"}}, entries[0].Changes)
+
+	o.since = "yesterday"
+	require.EqualError(t, o.run(ctx), `invalid --since: "yesterday" must be a duration such as 24h or an RFC3339 timestamp`)
+}
+
+func TestParseAuditTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	parsed, err := parseAuditTime("", now)
+	require.NoError(t, err)
+	require.True(t, parsed.IsZero())
+	parsed, err = parseAuditTime("24h", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), parsed)
+	parsed, err = parseAuditTime("2024-12-01T00:00:00Z", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), parsed)
+}
+
+func TestSummarizeAuditChanges(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, summarizeAuditChanges(nil))
+	changes := []state.AuditChange{{Path: "a"}, {Path: "b"}, {Path: "c"}, {Path: "d"}, {Path: "e"}}
+	require.Equal(t, "a, b, c, (+2 more)", summarizeAuditChanges(changes))
+}
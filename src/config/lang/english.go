@@ -63,7 +63,9 @@ const (
 		"the name you will pass into the 'zarf connect' command.\n\n" +
 		"Even if the packages you deploy don't define their own shortcut connection options, you can use the command flags " +
 		"to connect into specific resources. You can read the command flag descriptions below to get a better idea how to connect " +
-		"to whatever resource you are trying to connect to."
+		"to whatever resource you are trying to connect to.\n\n" +
+		"Several connect names, or every connect name with --all, can be connected at once. Tunnels are re-established when the pod " +
+		"behind them restarts, and --gateway serves them all from one local address at /<connect-name>/ or http://<connect-name>.localhost."
 
 	// zarf connect list
 	CmdConnectListShort = "Lists all available connection shortcuts"
@@ -75,6 +77,8 @@ const (
 	CmdConnectFlagLocalPort  = "(Optional, autogenerated if not provided) Specify the local port to bind to.  E.g. local-port=42000."
 	CmdConnectFlagRemotePort = "Specify the remote port of the resource to bind to.  E.g. remote-port=8080. Ignored if connect-name is supplied."
 	CmdConnectFlagOpen       = "Enable browser auto-open"
+	CmdConnectFlagAll        = "Connect to every service with a 'zarf.dev/connect-name' label at once"
+	CmdConnectFlagGateway    = "Serve the connected services behind a single local HTTP gateway on this address, routed by path or host name.  E.g. gateway=127.0.0.1:8080."
 
 	CmdConnectPreparingTunnel = "Preparing a tunnel to connect to %s"
 	CmdConnectEstablishedCLI  = "Tunnel established at %s, waiting for user to interrupt (ctrl-c to end)"
@@ -166,6 +170,33 @@ $ zarf init --artifact-push-password={PASSWORD} --artifact-push-username={USERNA
 	CmdInitFlagArtifactPushUser  = "[alpha] Username to access to the artifact registry Zarf is configured to use. User must be able to upload package artifacts."
 	CmdInitFlagArtifactPushToken = "[alpha] API Token for the push-user to access the artifact registry"
 
+	CmdInitFlagAgentRequireImagesInRegistry  = "Have the Zarf Agent deny pods with images that have not been pushed to the Zarf registry"
+	CmdInitFlagAgentAllowedRegistries        = "Source registries (optionally with a repository path prefix) the Zarf Agent allows pod images to originate from. E.g. --agent-allowed-registries=ghcr.io,docker.io/library"
+	CmdInitFlagAgentAudit                    = "Run every Zarf Agent hook in audit mode, admitting objects unmodified and recording the would-be patch as a Kubernetes Event and metric"
+	CmdInitFlagAgentAuditHooks               = "Names of the Zarf Agent hooks to run in audit mode. E.g. --agent-audit-hooks=pod,flux-gitrepository"
+	CmdInitFlagAgentTLSCACert                = "Path to a PEM encoded CA certificate used to sign the Zarf Agent certificate instead of an ephemeral self-signed CA"
+	CmdInitFlagAgentTLSCAKey                 = "Path to the PEM encoded private key of the CA provided with --agent-tls-ca-cert"
+	CmdInitFlagAgentTLSKeyAlgorithm          = "Algorithm of the Zarf Agent private keys (rsa or ecdsa), defaults to rsa"
+	CmdInitFlagAgentTLSCertManagerIssuer     = "Name of a cert-manager issuer to issue the Zarf Agent certificate, cert-manager must already be installed in the cluster"
+	CmdInitFlagCredentialStore               = "Backend that stores the Zarf credentials (inline, vault, or envelope). Inline keeps them in the zarf-state secret"
+	CmdInitFlagVaultAddress                  = "Address of the Vault server used by the vault and envelope credential stores. E.g. --vault-address=https://vault.example.com:8200"
+	CmdInitFlagVaultMount                    = "Mount path of the KV version 2 secrets engine used by the vault credential store, defaults to secret"
+	CmdInitFlagVaultPath                     = "Path of the secret in the KV version 2 secrets engine used by the vault credential store, defaults to zarf/state"
+	CmdInitFlagVaultToken                    = "Token used to authenticate with Vault, stored in the zarf-credential-store secret for the Zarf Agent"
+	CmdInitFlagVaultTransitMount             = "Mount path of the transit secrets engine used by the envelope credential store, defaults to transit"
+	CmdInitFlagVaultTransitKey               = "Name of the transit key that encrypts the data keys of the envelope credential store, must already exist in Vault"
+	CmdInitFlagAgentTLSCertManagerIssuerKind = "Kind of the cert-manager issuer provided with --agent-tls-cert-manager-issuer (Issuer or ClusterIssuer), defaults to Issuer"
+	CmdInitFlagRoutingConfig                 = "Path to a YAML file of named registries and git servers that images and repositories are routed to by source prefix or namespace"
+	CmdInitFlagRegS3Bucket                   = "S3 bucket the Zarf registry stores images in instead of a persistent volume, must be reachable from the cluster"
+	CmdInitFlagRegS3Region                   = "Region of the S3 bucket provided with --registry-s3-bucket, defaults to us-east-1"
+	CmdInitFlagRegS3Endpoint                 = "Endpoint of an S3-compatible service, defaults to AWS S3. E.g. --registry-s3-endpoint=https://minio.example.com:9000"
+	CmdInitFlagRegS3RootDirectory            = "Prefix of the objects the Zarf registry stores in the S3 bucket"
+	CmdInitFlagRegS3AccessKey                = "Access key ID used by the Zarf registry to authenticate with the S3 bucket"
+	CmdInitFlagRegS3SecretKey                = "Secret access key used by the Zarf registry to authenticate with the S3 bucket, stored with the other Zarf credentials"
+	CmdInitFlagRegS3Insecure                 = "Connect to the S3 endpoint over plain HTTP"
+	CmdInitFlagRegS3ForcePathStyle           = "Address the S3 bucket in the path instead of the host name, required by most S3-compatible services"
+	CmdInitFlagRegS3SkipCheck                = "Skip checking that the S3 bucket can be written to from this machine before the Zarf registry is deployed, for buckets only reachable from the cluster"
+
 	// zarf internal
 	CmdInternalShort = "Internal tools used by zarf"
 
@@ -205,6 +236,21 @@ $ zarf init --artifact-push-password={PASSWORD} --artifact-push-username={USERNA
 
 	CmdInternalCrc32Short = "Generates a decimal CRC32 for the given text"
 
+	CmdInternalRotateCredentialsShort = "Rotates the internal Zarf credentials"
+	CmdInternalRotateCredentialsLong  = "Regenerates the internal registry, git server, artifact server, and agent credentials and rolls back " +
+		"if any of them can not be applied. This can be run from a CronJob to rotate the credentials on a schedule."
+	CmdInternalFlagRotateCredentialsServices = "Services to rotate the credentials of, defaults to every service"
+
+	CmdInternalRegistryMirrorShort = "Writes the containerd registry mirrors of the Zarf registry to the node"
+	CmdInternalRegistryMirrorLong  = "NOTE: This command is a hidden command and generally shouldn't be run by a human.\n" +
+		"This command is run by the zarf-registry-mirror DaemonSet to check that containerd reads registry hosts from the certs directory, " +
+		"copy the hosts.toml of every mirrored registry into it and remove the registries that are no longer mirrored."
+	CmdInternalFlagRegistryMirrorMirrorsDir       = "Directory holding the hosts.toml files written by Zarf"
+	CmdInternalFlagRegistryMirrorCertsDir         = "Directory the hosts.toml files are copied to"
+	CmdInternalFlagRegistryMirrorContainerdConfig = "Path to the containerd config of the node"
+	CmdInternalFlagRegistryMirrorConfigPath       = "The containerd config_path the certs directory is mounted from"
+	CmdInternalFlagRegistryMirrorWait             = "Wait until the pod is terminated instead of writing the mirrors, keeps the DaemonSet pod running"
+
 	// zarf package
 	CmdPackageShort                       = "Zarf package commands for creating, deploying, and inspecting packages"
 	CmdPackageFlagConcurrency             = "Number of concurrent layer operations when pulling or pushing images or packages to/from OCI registries."
@@ -272,6 +318,7 @@ $ zarf package mirror-resources <your-package.tar.zst> --repos \
 	CmdPackageCreateFlagFlavor                = "The flavor of components to include in the resulting package (i.e. have a matching or empty \"only.flavor\" key)"
 	CmdPackageCreateFlagValuesFiles           = "[alpha] Values files to use for templating and Helm overrides. Multiple files can be passed in as a comma separated list, and the flag can be provided multiple times."
 	CmdPackageCreateFlagWithBuildMachineInfo  = "Include build machine information (hostname and username) in the package metadata"
+	CmdPackageCreateFlagReproducible          = "Create a package that is identical when rebuilt from the same sources. The build timestamp is taken from SOURCE_DATE_EPOCH or defaults to the Unix epoch"
 	CmdPackageCreateCleanPathErr              = "Invalid characters in Zarf cache path, defaulting to %s"
 
 	CmdPackageDeployFlagConfirm                = "Confirms package deployment without prompting. ONLY use with packages you trust. Skips prompts to review SBOM, configure variables, select optional components and review potential breaking changes."
@@ -284,13 +331,15 @@ $ zarf package mirror-resources <your-package.tar.zst> --repos \
 	CmdPackageDeployInvalidCLIVersionWarn      = "CLIVersion is set to '%s' which can cause issues with package creation and deployment. To avoid such issues, please set the value to the valid semantic version for this version of Zarf."
 	CmdPackageDeployFlagNamespace              = "[Alpha] Override the namespace for package deployment. Requires the package to have only one distinct namespace defined."
 	CmdPackageDeployFlagValuesFiles            = CmdPackageCreateFlagValuesFiles
+	CmdPackageDeployFlagOverlay                = "Directory of kustomize components applied to the rendered charts and manifests, laid out as <component>/<chart>/kustomization.yaml. Upgrades without this flag reapply the overlays of the previous deployment."
 
 	CmdPackageMirrorFlagComponents = "Comma-separated list of components to mirror.  This list will be respected regardless of a component's 'required' or 'default' status.  Globbing component names with '*' and deselecting components with a leading '-' are also supported."
 	CmdPackageMirrorFlagNoChecksum = "Turns off the addition of a checksum to image tags (as would be used by the Zarf Agent) while mirroring images."
 
-	CmdPackageInspectFlagSbomOut    = "Specify an output directory for the SBOMs from the inspected Zarf package"
-	CmdPackageInspectFlagListImages = "List images in the package (prints to stdout)"
-	CmdPackageInspectFlagNamespace  = "[Alpha] Override the namespace for package inspection. Applicable only to packages deployed using the namespace flag."
+	CmdPackageInspectFlagSbomOut     = "Specify an output directory for the SBOMs from the inspected Zarf package"
+	CmdPackageInspectFlagListImages  = "List images in the package (prints to stdout)"
+	CmdPackageInspectFlagTransformed = "List the references the images are stored under in the Zarf registry after the transform rules of the package are applied"
+	CmdPackageInspectFlagNamespace   = "[Alpha] Override the namespace for package inspection. Applicable only to packages deployed using the namespace flag."
 
 	CmdPackageRemoveShort          = "Removes a Zarf package that has been deployed already (runs offline)"
 	CmdPackageRemoveLong           = "Removes a Zarf package that has been deployed already (runs offline). Remove reverses the deployment order, the last component is removed first."
@@ -346,6 +395,16 @@ $ zarf package verify zarf-package-demo-amd64-1.0.0.tar.zst
 `
 	CmdPackageVerifyFlagKey = "Public key for signature verification"
 
+	CmdPackageVerifyBuildShort   = "Verify a reproducible Zarf package by rebuilding it from its definition"
+	CmdPackageVerifyBuildLong    = "Rebuilds the package definition with the build timestamp, flavor and registry overrides recorded in a package created with --reproducible and compares the checksums of every file. Returns a non-zero exit code if the rebuilt package differs."
+	CmdPackageVerifyBuildExample = `
+# Verify a package was built from the definition in the current directory
+$ zarf package verify-build zarf.yaml zarf-package-demo-amd64-1.0.0.tar.zst
+
+# Verify a package published to a registry
+$ zarf package verify-build . oci://ghcr.io/defenseunicorns/packages/demo:1.0.0
+`
+
 	CmdPackagePullShort   = "Pulls a Zarf package from a remote registry and save to the local file system"
 	CmdPackagePullExample = `
 # Pull a package matching the current architecture
@@ -399,6 +458,8 @@ $ zarf package pull oci://ghcr.io/zarf-dev/packages/dos-games:1.2.0 -a skeleton`
 	CmdDevFlagFindImagesWhy        = "Prints the source manifest for the specified image"
 	CmdDevFlagFindImagesSkipCosign = "Skip searching for cosign artifacts related to discovered images"
 	CmdDevFlagFindImagesUpdate     = "Update the images in the zarf.yaml file if needed. Formatting such as comments and newlines may change."
+	CmdDevFlagFindImagesPin        = "Pin the images in the zarf.yaml file to their digest, which is the digest of the index for multi-platform images. Requires --update."
+	CmdDevFlagFindImagesPrune      = "Remove the images of components where no images were found from the zarf.yaml file. Requires --update."
 
 	CmdDevLintShort = "Lints the given package for valid schema and recommended practices"
 	CmdDevLintLong  = "Verifies the package schema, checks if any variables won't be evaluated, and checks for unpinned images/repos/files"
@@ -611,6 +672,7 @@ $ zarf tools wait-for svc zarf-docker-registry exists -n zarf           #  wait
 $ zarf tools wait-for svc zarf-docker-registry -n zarf                  #  same as above, except exists is the default condition
 $ zarf tools wait-for crd addons.k3s.cattle.io                          #  wait for crd addons.k3s.cattle.io to exist
 $ zarf tools wait-for sts test-sts '{.status.availableReplicas}'=23     #  wait for statefulset test-sts to have 23 available replicas
+$ zarf tools wait-for registry-proxy                                     #  wait for the Zarf registry proxy to be ready on every node
 
 # Wait for network endpoints:
 $ zarf tools wait-for http localhost:8080 200                           #  wait for a 200 response from http://localhost:8080
@@ -665,14 +727,44 @@ $ zarf tools update-creds git --git-push-username={USERNAME} --git-push-password
 $ zarf tools update-creds artifact --artifact-push-username={USERNAME} --artifact-push-token={PASSWORD}
 
 # NOTE: Not specifying a pull username/password will keep the previous pull username/password.
+
+# Rotate every internal Zarf credential, rolling back if any part of the rotation fails:
+$ zarf tools update-creds --all --rotate --confirm
+
+# Move the internal Zarf registry of an existing cluster to the registry proxy or back to a nodeport:
+$ zarf tools update-creds registry --mode proxy
+$ zarf tools update-creds registry --mode nodeport
 `
 	CmdToolsUpdateCredsConfirmFlag          = "Confirm updating credentials without prompting"
+	CmdToolsUpdateCredsAllFlag              = "Update the credentials of every service, the same as not passing a service key"
+	CmdToolsUpdateCredsRoutingConfigFlag    = "Path to a YAML file of named registries and git servers that replace the ones in the Zarf state"
+	CmdToolsUpdateCredsRotateFlag           = "Regenerate the internal credentials as a single rotation that is verified and rolled back if any step fails"
+	CmdToolsUpdateCredsConfirmRotate        = "Rotate the internal credentials for %s?"
+	CmdToolsUpdateCredsModeFlag             = "Move the internal registry to a registry mode (proxy or nodeport), only the registry mode is changed"
+	CmdToolsUpdateCredsConfirmMode          = "Move the Zarf registry from %s to %s mode?"
 	CmdToolsUpdateCredsConfirmProvided      = "Confirm flag specified, continuing without prompting."
 	CmdToolsUpdateCredsConfirmContinue      = "Continue with these changes?"
 	CmdToolsUpdateCredsUnableUpdateRegistry = "Unable to update Zarf Registry values: %s"
 	CmdToolsUpdateCredsUnableUpdateAgent    = "Unable to update Zarf Agent TLS secrets: %s"
 	CmdToolsUpdateCredsUnableUpdateCreds    = "Unable to update Zarf credentials"
 
+	CmdToolsAuditShort   = "Shows the audit log of changes to the Zarf state and deployed packages"
+	CmdToolsAuditLong    = "Shows the audit log of changes to the Zarf state and deployed package secrets, recorded in the zarf-audit config maps of the zarf namespace. Sensitive values are redacted."
+	CmdToolsAuditExample = `
+# Show every change to the Zarf state and deployed packages:
+$ zarf tools audit
+
+# Show the deployments of a package in the last week:
+$ zarf tools audit --package=podinfo --operation=record-package-deployment --since=168h
+
+# Show the changes to the Zarf state in a time range as JSON:
+$ zarf tools audit --operation=update-creds --since=2025-01-01T00:00:00Z --until=2025-02-01T00:00:00Z -o json
+`
+	CmdToolsAuditFlagPackage   = "Only show changes to the deployed package with this name"
+	CmdToolsAuditFlagOperation = "Only show changes made by this operation (init, update-creds, rollback-creds, update-registry-mirrors, record-package-deployment, update-deployed-package, or delete-deployed-package)"
+	CmdToolsAuditFlagSince     = "Only show changes after this time, as a duration before now (e.g. 24h) or an RFC3339 timestamp"
+	CmdToolsAuditFlagUntil     = "Only show changes before this time, as a duration before now (e.g. 1h) or an RFC3339 timestamp"
+
 	// zarf version
 	CmdVersionShort = "Shows the version of the running Zarf binary"
 	CmdVersionLong  = "Displays the version of the Zarf release that the current binary was built from."
@@ -49,35 +49,36 @@ type ApplicationSource struct {
 // NewApplicationMutationHook creates a new instance of the ArgoCD Application mutation hook.
 func NewApplicationMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
 	return operations.Hook{
-		Create: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateApplication(ctx, r, cluster)
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateApplication(ctx, r, s, cluster)
 		},
-		Update: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateApplication(ctx, r, cluster)
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateApplication(ctx, r, s, cluster)
 		},
 	}
 }
 
 // mutateApplication mutates the git repository url to point to the repository URL defined in the ZarfState.
-func mutateApplication(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutateApplication(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
-	s, err := cluster.LoadState(ctx)
-	if err != nil {
-		return nil, err
-	}
 
 	app := Application{}
-	if err = json.Unmarshal(r.Object.Raw, &app); err != nil {
+	if err := json.Unmarshal(r.Object.Raw, &app); err != nil {
 		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
 	}
 
+	rules, err := packageRepoRules(ctx, cluster, app.Labels)
+	if err != nil {
+		return nil, err
+	}
+
 	l.Info("using the Zarf git server URL to mutate the ArgoCD Application",
 		"name", app.Name,
 		"git-server", s.GitServer.Address)
 
 	patches := make([]operations.PatchOperation, 0)
 	if app.Spec.Source != nil {
-		patchedURL, err := getPatchedRepoURL(ctx, app.Spec.Source.RepoURL, s.GitServer, r)
+		patchedURL, err := getPatchedRepoURL(ctx, app.Spec.Source.RepoURL, s, r, rules)
 		if err != nil {
 			return nil, err
 		}
@@ -86,7 +87,7 @@ func mutateApplication(ctx context.Context, r *v1.AdmissionRequest, cluster *clu
 
 	if len(app.Spec.Sources) > 0 {
 		for idx, source := range app.Spec.Sources {
-			patchedURL, err := getPatchedRepoURL(ctx, source.RepoURL, s.GitServer, r)
+			patchedURL, err := getPatchedRepoURL(ctx, source.RepoURL, s, r, rules)
 			if err != nil {
 				return nil, err
 			}
@@ -102,8 +103,10 @@ func mutateApplication(ctx context.Context, r *v1.AdmissionRequest, cluster *clu
 	}, nil
 }
 
-func getPatchedRepoURL(ctx context.Context, repoURL string, gs state.GitServerInfo, r *v1.AdmissionRequest) (string, error) {
+// getPatchedRepoURL returns the repoURL transformed to the git server it is routed to in the namespace of the request.
+func getPatchedRepoURL(ctx context.Context, repoURL string, s *state.State, r *v1.AdmissionRequest, rules transform.RepoRules) (string, error) {
 	l := logger.From(ctx)
+	gs := s.GitServerFor(repoURL, r.Namespace)
 	isCreate := r.Operation == v1.Create
 	isUpdate := r.Operation == v1.Update
 	patchedURL := repoURL
@@ -123,7 +126,7 @@ func getPatchedRepoURL(ctx context.Context, repoURL string, gs state.GitServerIn
 	// Mutate the repoURL if necessary
 	if isCreate || (isUpdate && !isPatched) {
 		// Mutate the git URL so that the hostname matches the hostname in the Zarf state
-		transformedURL, err := transform.GitURL(gs.Address, patchedURL, gs.PushUsername)
+		transformedURL, err := transform.GitURLWithRules(gs.Address, patchedURL, gs.PushUsername, rules)
 		if err != nil {
 			return "", fmt.Errorf("%s: %w", AgentErrTransformGitURL, err)
 		}
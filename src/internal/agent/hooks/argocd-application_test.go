@@ -38,7 +38,7 @@ func TestArgoAppWebhook(t *testing.T) {
 		PushUsername: "a-push-user",
 	}}
 	c := createTestClientWithZarfState(ctx, t, s)
-	handler := admission.NewHandler().Serve(ctx, NewApplicationMutationHook(ctx, c))
+	handler := admission.NewHandler(c).Serve(ctx, "argocd-application", NewApplicationMutationHook(ctx, c))
 
 	tests := []admissionTest{
 		{
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplicationSet is a definition of an ArgoCD ApplicationSet resource.
+// The ArgoCD ApplicationSet structs in this file have been partially copied from upstream.
+//
+// https://github.com/argoproj/argo-cd/blob/v2.11.0/pkg/apis/application/v1alpha1/applicationset_types.go
+type ApplicationSet struct {
+	Spec              ApplicationSetSpec `json:"spec"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// ApplicationSetSpec represents a class of application set state.
+type ApplicationSetSpec struct {
+	Generators []ApplicationSetGenerator `json:"generators"`
+	Template   ApplicationSetTemplate    `json:"template"`
+}
+
+// ApplicationSetTemplate represents argocd ApplicationSpec.
+type ApplicationSetTemplate struct {
+	Spec ApplicationSpec `json:"spec"`
+}
+
+// ApplicationSetGenerator represents a generator at the top level of an ApplicationSet.
+// Matrix and merge generators are able to nest further generators.
+type ApplicationSetGenerator struct {
+	Git    *GitGenerator              `json:"git,omitempty"`
+	Matrix *ApplicationSetNestedGroup `json:"matrix,omitempty"`
+	Merge  *ApplicationSetNestedGroup `json:"merge,omitempty"`
+}
+
+// ApplicationSetNestedGroup contains the generators of a matrix or merge generator.
+type ApplicationSetNestedGroup struct {
+	Generators []ApplicationSetGenerator `json:"generators"`
+}
+
+// GitGenerator generates parameters from the files or directories of a git repository.
+type GitGenerator struct {
+	RepoURL string `json:"repoURL"`
+}
+
+// NewApplicationSetMutationHook creates a new instance of the ArgoCD ApplicationSet mutation hook.
+func NewApplicationSetMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
+	return operations.Hook{
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateApplicationSet(ctx, r, s, cluster)
+		},
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateApplicationSet(ctx, r, s, cluster)
+		},
+	}
+}
+
+// mutateApplicationSet mutates the template and git generator repository urls to point to the repository URL defined in the ZarfState.
+func mutateApplicationSet(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
+	l := logger.From(ctx)
+
+	appSet := ApplicationSet{}
+	if err := json.Unmarshal(r.Object.Raw, &appSet); err != nil {
+		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
+	}
+
+	rules, err := packageRepoRules(ctx, cluster, appSet.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Info("using the Zarf git server URL to mutate the ArgoCD ApplicationSet",
+		"name", appSet.Name,
+		"git-server", s.GitServer.Address)
+
+	patches := make([]operations.PatchOperation, 0)
+
+	// The template may use generator parameters (e.g. '{{.url}}') as the repoURL which cannot be transformed,
+	// only literal repository URLs are patched
+	templateSource := appSet.Spec.Template.Spec.Source
+	if templateSource != nil && !isTemplated(templateSource.RepoURL) {
+		patchedURL, err := getPatchedRepoURL(ctx, templateSource.RepoURL, s, r, rules)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, operations.ReplacePatchOperation("/spec/template/spec/source/repoURL", patchedURL))
+	}
+
+	for idx, source := range appSet.Spec.Template.Spec.Sources {
+		if isTemplated(source.RepoURL) {
+			continue
+		}
+		patchedURL, err := getPatchedRepoURL(ctx, source.RepoURL, s, r, rules)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, operations.ReplacePatchOperation(fmt.Sprintf("/spec/template/spec/sources/%d/repoURL", idx), patchedURL))
+	}
+
+	generatorPatches, err := populateGeneratorPatchOperations(ctx, "/spec/generators", appSet.Spec.Generators, s, r, rules)
+	if err != nil {
+		return nil, err
+	}
+	patches = append(patches, generatorPatches...)
+
+	patches = append(patches, getLabelPatch(appSet.Labels))
+
+	return &operations.Result{
+		Allowed:  true,
+		PatchOps: patches,
+	}, nil
+}
+
+// populateGeneratorPatchOperations creates patch operations for the git generators, including those nested in matrix and merge generators.
+func populateGeneratorPatchOperations(ctx context.Context, basePath string, generators []ApplicationSetGenerator, s *state.State, r *v1.AdmissionRequest, rules transform.RepoRules) ([]operations.PatchOperation, error) {
+	var patches []operations.PatchOperation
+	for idx, generator := range generators {
+		path := fmt.Sprintf("%s/%d", basePath, idx)
+		if generator.Git != nil && !isTemplated(generator.Git.RepoURL) {
+			patchedURL, err := getPatchedRepoURL(ctx, generator.Git.RepoURL, s, r, rules)
+			if err != nil {
+				return nil, err
+			}
+			patches = append(patches, operations.ReplacePatchOperation(path+"/git/repoURL", patchedURL))
+		}
+		if generator.Matrix != nil {
+			nested, err := populateGeneratorPatchOperations(ctx, path+"/matrix/generators", generator.Matrix.Generators, s, r, rules)
+			if err != nil {
+				return nil, err
+			}
+			patches = append(patches, nested...)
+		}
+		if generator.Merge != nil {
+			nested, err := populateGeneratorPatchOperations(ctx, path+"/merge/generators", generator.Merge.Generators, s, r, rules)
+			if err != nil {
+				return nil, err
+			}
+			patches = append(patches, nested...)
+		}
+	}
+	return patches, nil
+}
+
+// isTemplated returns true if the value contains an ApplicationSet template parameter.
+func isTemplated(value string) bool {
+	return strings.Contains(value, "{{")
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/internal/agent/http/admission"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	v1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func createArgoAppSetAdmissionRequest(t *testing.T, op v1.Operation, argoAppSet *ApplicationSet) *v1.AdmissionRequest {
+	t.Helper()
+	raw, err := json.Marshal(argoAppSet)
+	require.NoError(t, err)
+	return &v1.AdmissionRequest{
+		Operation: op,
+		Object: runtime.RawExtension{
+			Raw: raw,
+		},
+	}
+}
+
+func TestArgoAppSetWebhook(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := &state.State{GitServer: state.GitServerInfo{
+		Address:      "https://git-server.com",
+		PushUsername: "a-push-user",
+	}}
+	c := createTestClientWithZarfState(ctx, t, s)
+	handler := admission.NewHandler(c).Serve(ctx, "argocd-applicationset", NewApplicationSetMutationHook(ctx, c))
+
+	tests := []admissionTest{
+		{
+			name: "should mutate the template and git generators",
+			admissionReq: createArgoAppSetAdmissionRequest(t, v1.Create, &ApplicationSet{
+				Spec: ApplicationSetSpec{
+					Generators: []ApplicationSetGenerator{
+						{Git: &GitGenerator{RepoURL: "https://diff-git-server.com/peanuts"}},
+						{
+							Matrix: &ApplicationSetNestedGroup{
+								Generators: []ApplicationSetGenerator{
+									{Git: &GitGenerator{RepoURL: "https://diff-git-server.com/cashews"}},
+									{},
+								},
+							},
+						},
+					},
+					Template: ApplicationSetTemplate{
+						Spec: ApplicationSpec{
+							Source:  &ApplicationSource{RepoURL: "https://diff-git-server.com/peanuts"},
+							Sources: []ApplicationSource{{RepoURL: "https://diff-git-server.com/almonds"}},
+						},
+					},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/template/spec/source/repoURL",
+					"https://git-server.com/a-push-user/peanuts-3883081014",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/template/spec/sources/0/repoURL",
+					"https://git-server.com/a-push-user/almonds-640159520",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/generators/0/git/repoURL",
+					"https://git-server.com/a-push-user/peanuts-3883081014",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/generators/1/matrix/generators/0/git/repoURL",
+					"https://git-server.com/a-push-user/cashews-580170494",
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "should not mutate templated repo URLs",
+			admissionReq: createArgoAppSetAdmissionRequest(t, v1.Create, &ApplicationSet{
+				Spec: ApplicationSetSpec{
+					Generators: []ApplicationSetGenerator{
+						{Merge: &ApplicationSetNestedGroup{
+							Generators: []ApplicationSetGenerator{
+								{Git: &GitGenerator{RepoURL: "https://diff-git-server.com/peanuts"}},
+							},
+						}},
+					},
+					Template: ApplicationSetTemplate{
+						Spec: ApplicationSpec{
+							Source: &ApplicationSource{RepoURL: "{{.repoURL}}"},
+						},
+					},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/generators/0/merge/generators/0/git/repoURL",
+					"https://git-server.com/a-push-user/peanuts-3883081014",
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "should not mutate on update when the hostname matches the Zarf git server",
+			admissionReq: createArgoAppSetAdmissionRequest(t, v1.Update, &ApplicationSet{
+				Spec: ApplicationSetSpec{
+					Generators: []ApplicationSetGenerator{
+						{Git: &GitGenerator{RepoURL: "https://git-server.com/a-push-user/peanuts-3883081014"}},
+					},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/generators/0/git/repoURL",
+					"https://git-server.com/a-push-user/peanuts-3883081014",
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "should return an error for an invalid git generator URL",
+			admissionReq: createArgoAppSetAdmissionRequest(t, v1.Create, &ApplicationSet{
+				Spec: ApplicationSetSpec{
+					Generators: []ApplicationSetGenerator{
+						{Git: &GitGenerator{RepoURL: "https://bad-url"}},
+					},
+				},
+			}),
+			patch:       nil,
+			code:        http.StatusInternalServerError,
+			errContains: AgentErrTransformGitURL,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := sendAdmissionRequest(t, tt.admissionReq, handler)
+			verifyAdmission(t, rr, tt)
+		})
+	}
+}
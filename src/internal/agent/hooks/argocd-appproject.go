@@ -14,6 +14,7 @@ import (
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
 	v1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -37,28 +38,29 @@ type AppProjectSpec struct {
 // NewAppProjectMutationHook creates a new mutation hook for ArgoCD AppProjects.
 func NewAppProjectMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
 	return operations.Hook{
-		Create: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateAppProject(ctx, r, cluster)
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateAppProject(ctx, r, s, cluster)
 		},
-		Update: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateAppProject(ctx, r, cluster)
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateAppProject(ctx, r, s, cluster)
 		},
 	}
 }
 
 // mutateAppProject mutates the sourceRepos in ArgoCD AppProject to point to the Zarf git server.
-func mutateAppProject(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutateAppProject(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
-	s, err := cluster.LoadState(ctx)
-	if err != nil {
-		return nil, err
-	}
 
 	proj := AppProject{}
-	if err = json.Unmarshal(r.Object.Raw, &proj); err != nil {
+	if err := json.Unmarshal(r.Object.Raw, &proj); err != nil {
 		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
 	}
 
+	rules, err := packageRepoRules(ctx, cluster, proj.Labels)
+	if err != nil {
+		return nil, err
+	}
+
 	l.Info("using the Zarf git server URL to mutate the ArgoCD AppProject",
 		"name", proj.Name,
 		"git-server", s.GitServer.Address)
@@ -66,7 +68,7 @@ func mutateAppProject(ctx context.Context, r *v1.AdmissionRequest, cluster *clus
 	patches := make([]operations.PatchOperation, 0)
 
 	for idx, repo := range proj.Spec.SourceRepos {
-		patchedURL, err := getPatchedRepoURL(ctx, repo, s.GitServer, r)
+		patchedURL, err := getPatchedRepoURL(ctx, repo, s, r, rules)
 		// The AppProject can also include source repositories like '*' (as in the default project),
 		// which results in an error because '*' cannot be found in Git
 		// For this reason, we will ignore these entries and only patch the Git repositories that are found
@@ -37,40 +37,37 @@ type RepoCreds struct {
 // NewRepositorySecretMutationHook creates a new instance of the ArgoCD repository secret mutation hook.
 func NewRepositorySecretMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
 	return operations.Hook{
-		Create: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateRepositorySecret(ctx, r, cluster)
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateRepositorySecret(ctx, r, s, cluster)
 		},
-		Update: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateRepositorySecret(ctx, r, cluster)
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateRepositorySecret(ctx, r, s, cluster)
 		},
 	}
 }
 
 // mutateRepositorySecret mutates the git URL in the ArgoCD repository secret to point to the repository URL defined in the ZarfState.
-func mutateRepositorySecret(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutateRepositorySecret(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
 	isCreate := r.Operation == v1.Create
 	isUpdate := r.Operation == v1.Update
 	var isPatched bool
-
-	s, err := cluster.LoadState(ctx)
-	if err != nil {
-		return nil, err
-	}
+	var err error
 
 	secret := corev1.Secret{}
 	if err = json.Unmarshal(r.Object.Raw, &secret); err != nil {
 		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
 	}
 
-	l.Info("using the Zarf git server URL to mutate the ArgoCD Repository secret",
-		"name", secret.Name,
-		"git-server", s.GitServer.Address)
-
 	url, exists := secret.Data["url"]
 	if !exists {
 		return nil, fmt.Errorf("url field not found in argocd repository secret data")
 	}
+	gitServer := s.GitServerFor(string(url), r.Namespace)
+
+	l.Info("using the Zarf git server URL to mutate the ArgoCD Repository secret",
+		"name", secret.Name,
+		"git-server", gitServer.Address)
 
 	var repoCreds RepoCreds
 	repoCreds.URL = string(url)
@@ -79,7 +76,7 @@ func mutateRepositorySecret(ctx context.Context, r *v1.AdmissionRequest, cluster
 	// NOTE: We mutate on updates IF AND ONLY IF the hostname in the request is different from the hostname in the zarfState
 	// NOTE: We are checking if the hostname is different before because we do not want to potentially mutate a URL that has already been mutated.
 	if isUpdate {
-		isPatched, err = helpers.DoHostnamesMatch(s.GitServer.Address, repoCreds.URL)
+		isPatched, err = helpers.DoHostnamesMatch(gitServer.Address, repoCreds.URL)
 		if err != nil {
 			return nil, fmt.Errorf(lang.AgentErrHostnameMatch, err)
 		}
@@ -88,8 +85,12 @@ func mutateRepositorySecret(ctx context.Context, r *v1.AdmissionRequest, cluster
 	patchedURL := repoCreds.URL
 	// Mutate the repoURL if necessary
 	if isCreate || (isUpdate && !isPatched) {
+		rules, err := packageRepoRules(ctx, cluster, secret.Labels)
+		if err != nil {
+			return nil, err
+		}
 		// Mutate the git URL so that the hostname matches the hostname in the Zarf state
-		transformedURL, err := transform.GitURL(s.GitServer.Address, repoCreds.URL, s.GitServer.PushUsername)
+		transformedURL, err := transform.GitURLWithRules(gitServer.Address, repoCreds.URL, gitServer.PushUsername, rules)
 		if err != nil {
 			return nil, fmt.Errorf("unable the git url: %w", err)
 		}
@@ -97,7 +98,7 @@ func mutateRepositorySecret(ctx context.Context, r *v1.AdmissionRequest, cluster
 		l.Debug("mutating the ArgoCD repository secret URL to the Zarf URL", "original", repoCreds.URL, "mutated", patchedURL)
 	}
 
-	patches := populateArgoRepositoryPatchOperations(patchedURL, s.GitServer)
+	patches := populateArgoRepositoryPatchOperations(patchedURL, gitServer.GitServerInfo)
 	patches = append(patches, getLabelPatch(secret.Labels))
 
 	return &operations.Result{
@@ -43,7 +43,7 @@ func TestArgoRepoWebhook(t *testing.T) {
 		PullUsername: "a-pull-user",
 	}}
 	c := createTestClientWithZarfState(ctx, t, s)
-	handler := admission.NewHandler().Serve(ctx, NewRepositorySecretMutationHook(ctx, c))
+	handler := admission.NewHandler(c).Serve(ctx, "argocd-repository", NewRepositorySecretMutationHook(ctx, c))
 
 	tests := []admissionTest{
 		{
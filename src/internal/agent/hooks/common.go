@@ -7,19 +7,83 @@ package hooks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
 	"github.com/zarf-dev/zarf/src/internal/packager/images"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry"
 	orasRemote "oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	orasRetry "oras.land/oras-go/v2/registry/remote/retry"
 )
 
+// packageTransforms returns the transform rules of the package that deployed the resource with the labels.
+// Resources that were not deployed by a package, or whose package is no longer deployed, have no rules.
+func packageTransforms(ctx context.Context, c *cluster.Cluster, labels map[string]string) (v1alpha1.ZarfTransforms, error) {
+	pkgName := labels[cluster.PackageLabel]
+	if pkgName == "" {
+		return v1alpha1.ZarfTransforms{}, nil
+	}
+	deployedPackage, err := c.GetDeployedPackage(ctx, pkgName, state.WithPackageNamespaceOverride(labels[cluster.NamespaceOverrideLabel]))
+	if kerrors.IsNotFound(err) {
+		logger.From(ctx).Debug("the package of the resource is not deployed, using the default transforms", "package", pkgName)
+		return v1alpha1.ZarfTransforms{}, nil
+	}
+	if err != nil {
+		return v1alpha1.ZarfTransforms{}, fmt.Errorf("unable to get the deployed package %s: %w", pkgName, err)
+	}
+	return deployedPackage.Data.Transforms, nil
+}
+
+// packageImageRules returns the image transform rules of the package that deployed the resource with the labels.
+func packageImageRules(ctx context.Context, c *cluster.Cluster, labels map[string]string) (transform.ImageRules, error) {
+	transforms, err := packageTransforms(ctx, c, labels)
+	if err != nil {
+		return nil, err
+	}
+	return transform.NewImageRules(transforms.Images)
+}
+
+// packageRepoRules returns the repo transform rules of the package that deployed the resource with the labels.
+func packageRepoRules(ctx context.Context, c *cluster.Cluster, labels map[string]string) (transform.RepoRules, error) {
+	transforms, err := packageTransforms(ctx, c, labels)
+	if err != nil {
+		return nil, err
+	}
+	return transform.NewRepoRules(transforms.Repos)
+}
+
+// transformContainerImage returns the image a container used in the namespace is mutated to.
+// Images from the Zarf registry in mirror mode keep their original name as the nodes pull them through the registry mirrors.
+func transformContainerImage(ctx context.Context, s *state.State, image, namespace string, rules transform.ImageRules) (string, error) {
+	registry := s.RegistryFor(image, namespace)
+	if registry.Name != "" {
+		logger.From(ctx).Debug("routing the image to a named registry", "image", image, "registry", registry.Name, "namespace", namespace)
+	}
+	if registry.RegistryMode == state.RegistryModeMirror {
+		return image, nil
+	}
+	return transform.ImageTransformHostWithRules(registry.Address, image, rules)
+}
+
+// transformRegistryImage returns the reference an image or OCI artifact is stored under in the registry at the address.
+func transformRegistryImage(registryInfo state.RegistryInfo, registryAddress, image string, rules transform.ImageRules) (string, error) {
+	if registryInfo.RegistryMode == state.RegistryModeMirror {
+		return transform.ImageTransformMirror(registryAddress, image)
+	}
+	return transform.ImageTransformHostWithRules(registryAddress, image, rules)
+}
+
 func getLabelPatch(currLabels map[string]string) operations.PatchOperation {
 	if currLabels == nil {
 		currLabels = make(map[string]string)
@@ -28,29 +92,37 @@ func getLabelPatch(currLabels map[string]string) operations.PatchOperation {
 	return operations.ReplacePatchOperation("/metadata/labels", currLabels)
 }
 
-func getManifestConfigMediaType(ctx context.Context, zarfState *state.State, imageAddress string) (string, error) {
+// newZarfRegistryRepository returns a remote repository for the image address authenticated with the registry pull credentials.
+func newZarfRegistryRepository(ctx context.Context, registryInfo state.RegistryInfo, imageAddress string) (*orasRemote.Repository, error) {
 	ref, err := registry.ParseReference(imageAddress)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	client := &auth.Client{
 		Client: orasRetry.DefaultClient,
 		Cache:  auth.NewCache(),
 		Credential: auth.StaticCredential(ref.Registry, auth.Credential{
-			Username: zarfState.RegistryInfo.PullUsername,
-			Password: zarfState.RegistryInfo.PullPassword,
+			Username: registryInfo.PullUsername,
+			Password: registryInfo.PullPassword,
 		}),
 	}
 
 	http, err := images.ShouldUsePlainHTTP(ctx, ref.Registry, client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	registry := &orasRemote.Repository{
+	return &orasRemote.Repository{
 		PlainHTTP: http,
 		Reference: ref,
 		Client:    client,
+	}, nil
+}
+
+func getManifestConfigMediaType(ctx context.Context, registryInfo state.RegistryInfo, imageAddress string) (string, error) {
+	registry, err := newZarfRegistryRepository(ctx, registryInfo, imageAddress)
+	if err != nil {
+		return "", err
 	}
 
 	_, b, err := oras.FetchBytes(ctx, registry, imageAddress, oras.DefaultFetchBytesOptions)
@@ -66,3 +138,20 @@ func getManifestConfigMediaType(ctx context.Context, zarfState *state.State, ima
 
 	return manifest.Config.MediaType, nil
 }
+
+// imageExistsInRegistry returns true if the image address can be resolved in the registry.
+func imageExistsInRegistry(ctx context.Context, registryInfo state.RegistryInfo, imageAddress string) (bool, error) {
+	registry, err := newZarfRegistryRepository(ctx, registryInfo, imageAddress)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = registry.Resolve(ctx, registry.Reference.Reference)
+	if errors.Is(err, errdef.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("got an error when trying to resolve %s, error %w", imageAddress, err)
+	}
+	return true, nil
+}
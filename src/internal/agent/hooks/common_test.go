@@ -142,8 +142,7 @@ func TestConfigMediaTypes(t *testing.T) {
 			url, err := setupRegistry(ctx, t, port, tt.artifact, tt.Opts)
 			require.NoError(t, err)
 
-			s := &state.State{RegistryInfo: state.RegistryInfo{Address: url}}
-			mediaType, err := getManifestConfigMediaType(ctx, s, tt.image)
+			mediaType, err := getManifestConfigMediaType(ctx, state.RegistryInfo{Address: url}, tt.image)
 			require.NoError(t, err)
 			require.Equal(t, tt.expected, mediaType)
 		})
@@ -17,6 +17,7 @@ import (
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
 	"github.com/zarf-dev/zarf/src/pkg/transform"
 	v1 "k8s.io/api/admission/v1"
 )
@@ -27,45 +28,43 @@ const AgentErrTransformGitURL = "unable to transform the git url"
 // NewGitRepositoryMutationHook creates a new instance of the git repo mutation hook.
 func NewGitRepositoryMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
 	return operations.Hook{
-		Create: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateGitRepo(ctx, r, cluster)
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateGitRepo(ctx, r, s, cluster)
 		},
-		Update: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateGitRepo(ctx, r, cluster)
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateGitRepo(ctx, r, s, cluster)
 		},
 	}
 }
 
 // mutateGitRepoCreate mutates the git repository url to point to the repository URL defined in the ZarfState.
-func mutateGitRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutateGitRepo(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
 	var (
 		patches   []operations.PatchOperation
 		isPatched bool
+		err       error
 
 		isCreate = r.Operation == v1.Create
 		isUpdate = r.Operation == v1.Update
 	)
 
-	s, err := cluster.LoadState(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	repo := flux.GitRepository{}
 	if err = json.Unmarshal(r.Object.Raw, &repo); err != nil {
 		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
 	}
 
+	gitServer := s.GitServerFor(repo.Spec.URL, r.Namespace)
+
 	l.Info("using the Zarf git server URL to mutate the Flux GitRepository",
 		"name", repo.Name,
-		"git-server", s.GitServer.Address)
+		"git-server", gitServer.Address)
 
 	// Check if this is an update operation and the hostname is different from what we have in the zarfState
 	// NOTE: We mutate on updates IF AND ONLY IF the hostname in the request is different than the hostname in the zarfState
 	// NOTE: We are checking if the hostname is different before because we do not want to potentially mutate a URL that has already been mutated.
 	if isUpdate {
-		isPatched, err = helpers.DoHostnamesMatch(s.GitServer.Address, repo.Spec.URL)
+		isPatched, err = helpers.DoHostnamesMatch(gitServer.Address, repo.Spec.URL)
 		if err != nil {
 			return nil, fmt.Errorf(lang.AgentErrHostnameMatch, err)
 		}
@@ -75,8 +74,12 @@ func mutateGitRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 
 	// Mutate the git URL if necessary
 	if isCreate || (isUpdate && !isPatched) {
+		rules, err := packageRepoRules(ctx, cluster, repo.Labels)
+		if err != nil {
+			return nil, err
+		}
 		// Mutate the git URL so that the hostname matches the hostname in the Zarf state
-		transformedURL, err := transform.GitURL(s.GitServer.Address, patchedURL, s.GitServer.PushUsername)
+		transformedURL, err := transform.GitURLWithRules(gitServer.Address, patchedURL, gitServer.PushUsername, rules)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", AgentErrTransformGitURL, err)
 		}
@@ -84,8 +87,14 @@ func mutateGitRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 		l.Debug("mutating the Flux GitRepository URL to the Zarf URL", "original", repo.Spec.URL, "mutated", patchedURL)
 	}
 
+	// Named git servers only have a pull secret in the namespaces their rules select
+	secretName := ""
+	if gitServer.Name == "" || gitServer.Routes.SelectsNamespace(r.Namespace) {
+		secretName = gitServer.SecretName(config.ZarfGitServerSecretName)
+	}
+
 	// Patch updates of the repo spec
-	patches = populatePatchOperations(patchedURL)
+	patches = populatePatchOperations(patchedURL, secretName)
 	patches = append(patches, getLabelPatch(repo.Labels))
 
 	return &operations.Result{
@@ -95,12 +104,14 @@ func mutateGitRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 }
 
 // Patch updates of the repo spec.
-func populatePatchOperations(repoURL string) []operations.PatchOperation {
+func populatePatchOperations(repoURL, secretName string) []operations.PatchOperation {
 	var patches []operations.PatchOperation
 	patches = append(patches, operations.ReplacePatchOperation("/spec/url", repoURL))
 
-	newSecretRef := fluxmeta.LocalObjectReference{Name: config.ZarfGitServerSecretName}
-	patches = append(patches, operations.AddPatchOperation("/spec/secretRef", newSecretRef))
+	if secretName != "" {
+		newSecretRef := fluxmeta.LocalObjectReference{Name: secretName}
+		patches = append(patches, operations.AddPatchOperation("/spec/secretRef", newSecretRef))
+	}
 
 	return patches
 }
@@ -42,7 +42,7 @@ func TestFluxMutationWebhook(t *testing.T) {
 		PushUsername: "a-push-user",
 	}}
 	c := createTestClientWithZarfState(ctx, t, s)
-	handler := admission.NewHandler().Serve(ctx, NewGitRepositoryMutationHook(ctx, c))
+	handler := admission.NewHandler(c).Serve(ctx, "flux-gitrepository", NewGitRepositoryMutationHook(ctx, c))
 
 	tests := []admissionTest{
 		{
@@ -126,3 +126,130 @@ func TestFluxMutationWebhook(t *testing.T) {
 		})
 	}
 }
+
+func TestFluxMutationWebhookRouting(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := &state.State{
+		GitServer: state.GitServerInfo{
+			Address:      "https://git-server.com",
+			PushUsername: "a-push-user",
+		},
+		GitServers: []state.NamedGitServerInfo{
+			{
+				Name: "tenant-a",
+				GitServerInfo: state.GitServerInfo{
+					Address:      "https://git.tenant-a.example.com",
+					PushUsername: "tenant-a-user",
+				},
+				Routes: state.RoutingRules{SourcePrefixes: []string{"https://github.com/stefanprodan"}, Namespaces: []string{"flux-system"}},
+			},
+			{
+				Name: "public",
+				GitServerInfo: state.GitServerInfo{
+					Address:      "https://git.public.example.com",
+					PushUsername: "public-user",
+				},
+				Routes: state.RoutingRules{SourcePrefixes: []string{"https://github.com/public"}},
+			},
+		},
+	}
+	c := createTestClientWithZarfState(ctx, t, s)
+	handler := admission.NewHandler(c).Serve(ctx, "flux-gitrepository", NewGitRepositoryMutationHook(ctx, c))
+	inNamespace := func(req *v1.AdmissionRequest, namespace string) *v1.AdmissionRequest {
+		req.Namespace = namespace
+		return req
+	}
+
+	tests := []admissionTest{
+		{
+			name: "should be mutated to the routed git server",
+			admissionReq: inNamespace(createFluxGitRepoAdmissionRequest(t, v1.Create, &flux.GitRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mutate-this",
+				},
+				Spec: flux.GitRepositorySpec{
+					URL: "https://github.com/stefanprodan/podinfo.git",
+				},
+			}), "flux-system"),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/url",
+					"https://git.tenant-a.example.com/tenant-a-user/podinfo-1646971829.git",
+				),
+				operations.AddPatchOperation(
+					"/spec/secretRef",
+					fluxmeta.LocalObjectReference{Name: config.ZarfGitServerSecretName + "-tenant-a"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "should not mutate a url already on the routed git server",
+			admissionReq: inNamespace(createFluxGitRepoAdmissionRequest(t, v1.Update, &flux.GitRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "no-mutate",
+				},
+				Spec: flux.GitRepositorySpec{
+					URL: "https://git.tenant-a.example.com/tenant-a-user/podinfo-1646971829.git",
+				},
+			}), "flux-system"),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/url",
+					"https://git.tenant-a.example.com/tenant-a-user/podinfo-1646971829.git",
+				),
+				operations.AddPatchOperation(
+					"/spec/secretRef",
+					fluxmeta.LocalObjectReference{Name: config.ZarfGitServerSecretName + "-tenant-a"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "should not reference a secret of a git server that does not select the namespace",
+			admissionReq: inNamespace(createFluxGitRepoAdmissionRequest(t, v1.Create, &flux.GitRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mutate-this",
+				},
+				Spec: flux.GitRepositorySpec{
+					URL: "https://github.com/public/podinfo.git",
+				},
+			}), "flux-system"),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/url",
+					"https://git.public.example.com/public-user/podinfo-3564158233.git",
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := sendAdmissionRequest(t, tt.admissionReq, handler)
+			verifyAdmission(t, rr, tt)
+		})
+	}
+}
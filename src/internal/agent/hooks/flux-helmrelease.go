@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	flux "github.com/fluxcd/source-controller/api/v1"
+	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HelmRelease is a definition of a Flux HelmRelease resource.
+// The Flux HelmRelease structs in this file have been partially copied from upstream
+// as the helm-controller API is not a dependency of Zarf.
+//
+// https://github.com/fluxcd/helm-controller/blob/v1.1.0/api/v2/helmrelease_types.go
+type HelmRelease struct {
+	Spec              HelmReleaseSpec `json:"spec"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+// HelmReleaseSpec defines the desired state of a Helm release.
+type HelmReleaseSpec struct {
+	// Chart defines the template of the HelmChart that should be created for this HelmRelease.
+	Chart *HelmChartTemplate `json:"chart,omitempty"`
+	// ChartRef holds a reference to a source controller resource containing the Helm chart artifact.
+	ChartRef *CrossNamespaceSourceReference `json:"chartRef,omitempty"`
+	// PostRenderers holds an array of Helm PostRenderers, which will be applied in order of their definition.
+	PostRenderers []PostRenderer `json:"postRenderers,omitempty"`
+}
+
+// PostRenderer contains a Helm PostRenderer specification.
+type PostRenderer struct {
+	// Kustomization to apply as PostRenderer.
+	Kustomize *Kustomize `json:"kustomize,omitempty"`
+}
+
+// Kustomize Helm PostRenderer specification.
+type Kustomize struct {
+	// Images is a list of (image name, new name, new tag or digest) for changing image names, tags or digests.
+	Images []KustomizeImage `json:"images,omitempty"`
+}
+
+// KustomizeImage contains an image name, a new name, a new tag or digest, which will replace the original name and tag.
+type KustomizeImage struct {
+	// Name is a tag-less image name.
+	Name string `json:"name"`
+	// NewName is the value used to replace the original name.
+	NewName string `json:"newName,omitempty"`
+	// NewTag is the value used to replace the original tag.
+	NewTag string `json:"newTag,omitempty"`
+	// Digest is the value used to replace the original image tag.
+	Digest string `json:"digest,omitempty"`
+}
+
+// HelmChartTemplate defines the template from which the controller will generate a HelmChart object.
+type HelmChartTemplate struct {
+	Spec HelmChartTemplateSpec `json:"spec"`
+}
+
+// HelmChartTemplateSpec defines the template from which the controller will generate a HelmChartSpec object.
+type HelmChartTemplateSpec struct {
+	SourceRef CrossNamespaceSourceReference   `json:"sourceRef"`
+	Verify    *flux.OCIRepositoryVerification `json:"verify,omitempty"`
+}
+
+// CrossNamespaceSourceReference contains enough information to let you locate the typed referenced object at cluster level.
+type CrossNamespaceSourceReference struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NewHelmReleaseMutationHook creates a new instance of the helm release mutation hook.
+func NewHelmReleaseMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
+	return operations.Hook{
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateHelmRelease(ctx, r, s, cluster)
+		},
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateHelmRelease(ctx, r, s, cluster)
+		},
+	}
+}
+
+// mutateHelmRelease mutates the post-renderer images of the helm release to point to the registry defined in the ZarfState.
+// Both the chart template and chartRef reference sources (OCIRepository, HelmChart, HelmRepository) that are mutated by their own hooks.
+func mutateHelmRelease(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
+	release := HelmRelease{}
+	if err := json.Unmarshal(r.Object.Raw, &release); err != nil {
+		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
+	}
+
+	if release.Spec.Chart != nil {
+		warnUnsupportedChartSource(ctx, release.Name, release.Spec.Chart.Spec.SourceRef.Kind, release.Spec.Chart.Spec.Verify)
+	}
+	if release.Spec.ChartRef != nil {
+		warnUnsupportedChartSource(ctx, release.Name, release.Spec.ChartRef.Kind, nil)
+	}
+
+	patches, err := populateHelmReleaseImagePatchOperations(ctx, r, s, cluster, release)
+	if err != nil {
+		return nil, err
+	}
+	patches = append(patches, getLabelPatch(release.Labels))
+
+	return &operations.Result{
+		Allowed:  true,
+		PatchOps: patches,
+	}, nil
+}
+
+// populateHelmReleaseImagePatchOperations rewrites the images set by kustomize post-renderers as they replace the images the pods are mutated from.
+func populateHelmReleaseImagePatchOperations(ctx context.Context, r *v1.AdmissionRequest, zarfState *state.State, cluster *cluster.Cluster, release HelmRelease) ([]operations.PatchOperation, error) {
+	l := logger.From(ctx)
+	var rules transform.ImageRules
+	patches := []operations.PatchOperation{}
+	for i, pr := range release.Spec.PostRenderers {
+		if pr.Kustomize == nil {
+			continue
+		}
+		for j, img := range pr.Kustomize.Images {
+			// Without a new tag or digest the image keeps the tag from the chart which the pod hook mutates instead
+			if img.NewTag == "" && img.Digest == "" {
+				l.Debug("skipping the HelmRelease post-renderer image without a tag or digest", "name", release.Name, "image", img.Name)
+				continue
+			}
+			if rules == nil {
+				var err error
+				rules, err = packageImageRules(ctx, cluster, release.Labels)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			src := img.Name
+			if img.NewName != "" {
+				src = img.NewName
+			}
+			if img.NewTag != "" {
+				src = fmt.Sprintf("%s:%s", src, img.NewTag)
+			}
+			if img.Digest != "" {
+				src = fmt.Sprintf("%s@%s", src, img.Digest)
+			}
+			patched, err := transformContainerImage(ctx, zarfState, src, r.Namespace, rules)
+			if err != nil {
+				return nil, fmt.Errorf("unable to transform the HelmRelease post-renderer image %s: %w", src, err)
+			}
+			ref, err := transform.ParseImageRef(patched)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse the transformed HelmRelease post-renderer image %s: %w", patched, err)
+			}
+			patchedImg := KustomizeImage{Name: img.Name, NewName: ref.Name}
+			if ref.Digest != "" {
+				patchedImg.Digest = ref.Digest
+			} else {
+				patchedImg.NewTag = ref.Tag
+			}
+			l.Debug("mutating the Flux HelmRelease post-renderer image to the Zarf URL", "name", release.Name, "original", src, "mutated", patched)
+			patches = append(patches, operations.ReplacePatchOperation(fmt.Sprintf("/spec/postRenderers/%d/kustomize/images/%d", i, j), patchedImg))
+		}
+	}
+	return patches, nil
+}
+
+// warnUnsupportedChartSource logs a warning for chart sources that Zarf is unable to serve from the air gap.
+func warnUnsupportedChartSource(ctx context.Context, name, sourceKind string, verify *flux.OCIRepositoryVerification) {
+	l := logger.From(ctx)
+	if sourceKind == flux.BucketKind {
+		l.Warn("the chart source is a Bucket which Zarf does not mirror, the chart must be available in the air gap", "name", name)
+	}
+	// Keyless verification needs to reach the public Sigstore infrastructure
+	if verify != nil && verify.Provider == "cosign" && verify.SecretRef == nil {
+		l.Warn("the chart uses keyless cosign verification which is unlikely to succeed in the air gap", "name", name)
+	}
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	fluxmeta "github.com/fluxcd/pkg/apis/meta"
+	flux "github.com/fluxcd/source-controller/api/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/internal/agent/http/admission"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func createFluxAdmissionRequest(t *testing.T, op v1.Operation, obj any) *v1.AdmissionRequest {
+	t.Helper()
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+	return &v1.AdmissionRequest{
+		Operation: op,
+		Object: runtime.RawExtension{
+			Raw: raw,
+		},
+	}
+}
+
+func TestFluxHelmReleaseMutationWebhook(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := &state.State{RegistryInfo: state.RegistryInfo{Address: "127.0.0.1:31999"}}
+	c := createTestClientWithZarfState(ctx, t, s)
+	handler := admission.NewHandler(c).Serve(ctx, "flux-helmrelease", NewHelmReleaseMutationHook(ctx, c))
+
+	tests := []admissionTest{
+		{
+			name: "helm release with a chartRef should be labeled",
+			admissionReq: createFluxAdmissionRequest(t, v1.Create, &HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "podinfo",
+				},
+				Spec: HelmReleaseSpec{
+					ChartRef: &CrossNamespaceSourceReference{Kind: flux.OCIRepositoryKind, Name: "podinfo"},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "helm release with a chart template should be labeled",
+			admissionReq: createFluxAdmissionRequest(t, v1.Create, &HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "podinfo",
+					Labels: map[string]string{"app": "podinfo"},
+				},
+				Spec: HelmReleaseSpec{
+					Chart: &HelmChartTemplate{
+						Spec: HelmChartTemplateSpec{
+							SourceRef: CrossNamespaceSourceReference{Kind: flux.HelmRepositoryKind, Name: "podinfo"},
+							Verify: &flux.OCIRepositoryVerification{
+								Provider:  "cosign",
+								SecretRef: &fluxmeta.LocalObjectReference{Name: "cosign-pub"},
+							},
+						},
+					},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"app":        "podinfo",
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "helm release post-renderer images should be mutated",
+			admissionReq: createFluxAdmissionRequest(t, v1.Create, &HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "podinfo",
+				},
+				Spec: HelmReleaseSpec{
+					ChartRef: &CrossNamespaceSourceReference{Kind: flux.OCIRepositoryKind, Name: "podinfo"},
+					PostRenderers: []PostRenderer{
+						{},
+						{
+							Kustomize: &Kustomize{
+								Images: []KustomizeImage{
+									{Name: "ghcr.io/stefanprodan/podinfo", NewTag: "6.9.0"},
+									{Name: "nginx", NewName: "docker.io/library/nginx", Digest: "sha256:84605f731c6a18194794c51e70021c671ab064654b751aa57e905bce55be13de"},
+									{Name: "busybox", NewName: "registry1.dso.mil/ironbank/busybox"},
+								},
+							},
+						},
+					},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/postRenderers/1/kustomize/images/0",
+					KustomizeImage{Name: "ghcr.io/stefanprodan/podinfo", NewName: "127.0.0.1:31999/stefanprodan/podinfo", NewTag: "6.9.0-zarf-2985051089"},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/postRenderers/1/kustomize/images/1",
+					KustomizeImage{Name: "nginx", NewName: "127.0.0.1:31999/library/nginx", Digest: "sha256:84605f731c6a18194794c51e70021c671ab064654b751aa57e905bce55be13de"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "helm release post-renderer images that are already mutated should not change",
+			admissionReq: createFluxAdmissionRequest(t, v1.Update, &HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "podinfo",
+				},
+				Spec: HelmReleaseSpec{
+					ChartRef: &CrossNamespaceSourceReference{Kind: flux.OCIRepositoryKind, Name: "podinfo"},
+					PostRenderers: []PostRenderer{
+						{
+							Kustomize: &Kustomize{
+								Images: []KustomizeImage{
+									{Name: "ghcr.io/stefanprodan/podinfo", NewName: "127.0.0.1:31999/stefanprodan/podinfo", NewTag: "6.9.0-zarf-2985051089"},
+								},
+							},
+						},
+					},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/postRenderers/0/kustomize/images/0",
+					KustomizeImage{Name: "ghcr.io/stefanprodan/podinfo", NewName: "127.0.0.1:31999/stefanprodan/podinfo", NewTag: "6.9.0-zarf-2985051089"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{
+						"zarf-agent": "patched",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := sendAdmissionRequest(t, tt.admissionReq, handler)
+			verifyAdmission(t, rr, tt)
+		})
+	}
+}
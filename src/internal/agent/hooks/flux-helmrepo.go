@@ -18,6 +18,7 @@ import (
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
 	"github.com/zarf-dev/zarf/src/pkg/transform"
 	v1 "k8s.io/api/admission/v1"
 )
@@ -25,17 +26,17 @@ import (
 // NewHelmRepositoryMutationHook creates a new instance of the helm repo mutation hook.
 func NewHelmRepositoryMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
 	return operations.Hook{
-		Create: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateHelmRepo(ctx, r, cluster)
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateHelmRepo(ctx, r, s, cluster)
 		},
-		Update: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateHelmRepo(ctx, r, cluster)
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateHelmRepo(ctx, r, s, cluster)
 		},
 	}
 }
 
 // mutateHelmRepo mutates the repository url to point to the repository URL defined in the ZarfState.
-func mutateHelmRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutateHelmRepo(ctx context.Context, r *v1.AdmissionRequest, zarfState *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
 
 	src := &flux.HelmRepository{}
@@ -49,13 +50,14 @@ func mutateHelmRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluste
 		return &operations.Result{Allowed: true}, nil
 	}
 
-	zarfState, err := cluster.LoadState(ctx)
+	registry := zarfState.RegistryFor(src.Spec.URL, r.Namespace)
+	rules, err := packageImageRules(ctx, cluster, src.Labels)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the registry service info if this is a NodePort service to use the internal kube-dns
-	registryAddress, err := cluster.GetServiceInfoFromRegistryAddress(ctx, zarfState.RegistryInfo.Address)
+	registryAddress, err := cluster.GetServiceInfoFromRegistryAddress(ctx, registry.Address)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +88,7 @@ func mutateHelmRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluste
 
 	// Mutate the helm repo URL if necessary
 	if isCreate || (isUpdate && !isPatched) {
-		patchedSrc, err := transform.ImageTransformHost(registryAddress, src.Spec.URL)
+		patchedSrc, err := transformRegistryImage(registry.RegistryInfo, registryAddress, src.Spec.URL, rules)
 		if err != nil {
 			return nil, fmt.Errorf("unable to transform the HelmRepo URL: %w", err)
 		}
@@ -102,7 +104,7 @@ func mutateHelmRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluste
 
 	var patches []operations.PatchOperation
 
-	patches = populateHelmRepoPatchOperations(patchedURL, zarfState.RegistryInfo.IsInternal())
+	patches = populateHelmRepoPatchOperations(patchedURL, registry.IsInternal())
 	patches = append(patches, getLabelPatch(src.Labels))
 
 	return &operations.Result{
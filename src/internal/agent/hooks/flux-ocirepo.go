@@ -18,6 +18,7 @@ import (
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
 	"github.com/zarf-dev/zarf/src/pkg/transform"
 	v1 "k8s.io/api/admission/v1"
 )
@@ -30,17 +31,17 @@ const (
 // NewOCIRepositoryMutationHook creates a new instance of the oci repo mutation hook.
 func NewOCIRepositoryMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
 	return operations.Hook{
-		Create: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateOCIRepo(ctx, r, cluster)
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateOCIRepo(ctx, r, s, cluster)
 		},
-		Update: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutateOCIRepo(ctx, r, cluster)
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateOCIRepo(ctx, r, s, cluster)
 		},
 	}
 }
 
 // mutateOCIRepo mutates the oci repository url to point to the repository URL defined in the ZarfState.
-func mutateOCIRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutateOCIRepo(ctx context.Context, r *v1.AdmissionRequest, zarfState *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
 	var (
 		patches   []operations.PatchOperation
@@ -65,13 +66,14 @@ func mutateOCIRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 		l.Warn("Detected a semver OCI ref, continuing but will be unable to guarantee against collisions if multiple OCI artifacts with the same name are brought in from different registries", "ref", src.Spec.Reference.SemVer)
 	}
 
-	zarfState, err := cluster.LoadState(ctx)
+	registry := zarfState.RegistryFor(src.Spec.URL, r.Namespace)
+	rules, err := packageImageRules(ctx, cluster, src.Labels)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the registry service info if this is a NodePort service to use the internal kube-dns
-	registryAddress, err := cluster.GetServiceInfoFromRegistryAddress(ctx, zarfState.RegistryInfo.Address)
+	registryAddress, err := cluster.GetServiceInfoFromRegistryAddress(ctx, registry.Address)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +106,7 @@ func mutateOCIRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 		}
 
 		// Initially, we patch the src to include the crc32 hash
-		patchedSrc, err := transform.ImageTransformHost(registryAddress, patchedURL)
+		patchedSrc, err := transformRegistryImage(registry.RegistryInfo, registryAddress, patchedURL, rules)
 		if err != nil {
 			return nil, fmt.Errorf("unable to transform the OCIRepo URL: %w", err)
 		}
@@ -113,7 +115,7 @@ func mutateOCIRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 		defer cancel()
 
 		// Get the media type of the oci image
-		mediaType, err := getManifestConfigMediaType(timeoutCtx, zarfState, patchedSrc)
+		mediaType, err := getManifestConfigMediaType(timeoutCtx, registry.RegistryInfo, patchedSrc)
 
 		// If we get an error, we fall back to existing mutation logic
 		if err != nil {
@@ -124,8 +126,9 @@ func mutateOCIRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 		l.Debug("got the following media type", "mediaType", mediaType, "registryAddress", registryAddress)
 
 		// Check the mediaType of the oci-artifact and if it is a helm chart we patch the crc to remove the crc32 hash
-		if isChart(mediaType) {
-			patchedSrc, err = transform.ImageTransformHostWithoutChecksum(registryAddress, patchedURL)
+		// Artifacts in a mirror mode registry are stored under their source host and never have a crc32 hash
+		if isChart(mediaType) && registry.RegistryMode != state.RegistryModeMirror {
+			patchedSrc, err = transform.ImageTransformHostWithRulesWithoutChecksum(registryAddress, patchedURL, rules)
 			if err != nil {
 				return nil, fmt.Errorf("unable to transform the OCIRepo URL: %w", err)
 			}
@@ -146,7 +149,7 @@ func mutateOCIRepo(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster
 	}
 
 	l.Debug("mutating the Flux OCIRepository URL to the Zarf URL", "original", src.Spec.URL, "mutated", patchedURL)
-	patches = populateOCIRepoPatchOperations(patchedURL, zarfState.RegistryInfo.IsInternal(), patchedRef)
+	patches = populateOCIRepoPatchOperations(patchedURL, registry.IsInternal(), patchedRef)
 	patches = append(patches, getLabelPatch(src.Labels))
 
 	return &operations.Result{
@@ -356,7 +356,7 @@ func TestFluxOCIMutationWebhook(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// t.Parallel()
 			c := createTestClientWithZarfState(ctx, t, s)
-			handler := admission.NewHandler().Serve(ctx, NewOCIRepositoryMutationHook(ctx, c))
+			handler := admission.NewHandler(c).Serve(ctx, "flux-ocirepository", NewOCIRepositoryMutationHook(ctx, c))
 			if tt.svc != nil {
 				_, err := c.Clientset.CoreV1().Services("zarf").Create(ctx, tt.svc, metav1.CreateOptions{})
 				require.NoError(t, err)
@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NewPodValidationHook creates a new instance of the pod image policy validation hook.
+func NewPodValidationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
+	return operations.Hook{
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return validatePod(ctx, r, s, cluster)
+		},
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return validatePod(ctx, r, s, cluster)
+		},
+	}
+}
+
+func validatePod(ctx context.Context, r *v1.AdmissionRequest, zarfState *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
+	l := logger.From(ctx)
+	pod, err := parsePod(r.Object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf(lang.AgentErrParsePod, err)
+	}
+
+	policy := zarfState.AgentInfo.ImagePolicy
+	if !policy.IsEnabled() {
+		return &operations.Result{Allowed: true}, nil
+	}
+
+	containers := map[string]string{}
+	for _, container := range pod.Spec.InitContainers {
+		containers[container.Name] = container.Image
+	}
+	for _, container := range pod.Spec.Containers {
+		containers[container.Name] = container.Image
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		containers[container.Name] = container.Image
+	}
+
+	rules, err := packageImageRules(ctx, cluster, pod.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	patched := pod.Labels["zarf-agent"] == "patched"
+	var violations []string
+	for name, image := range containers {
+		original, ok, err := originalImage(ctx, zarfState, r.Namespace, pod, name, image, rules)
+		if err != nil {
+			return nil, err
+		}
+		// The pod hook skips patched pods so a pod that was not mutated by the agent must not carry the label
+		if r.Operation == v1.Create && patched && !ok {
+			violations = append(violations, fmt.Sprintf("container %q: the zarf-agent=patched label was not applied by the Zarf agent", name))
+			continue
+		}
+		violation, err := validateContainerImage(ctx, cluster, zarfState, r.Namespace, original, rules)
+		if err != nil {
+			return nil, err
+		}
+		if violation != "" {
+			violations = append(violations, fmt.Sprintf("container %q: %s", name, violation))
+		}
+	}
+
+	if len(violations) > 0 {
+		// Sort for a stable message as containers are tracked in a map
+		slices.Sort(violations)
+		msg := fmt.Sprintf("denied by the Zarf agent image policy: %s", strings.Join(violations, "; "))
+		l.Warn("denying pod admission", "namespace", r.Namespace, "name", pod.Name, "reason", msg)
+		return &operations.Result{
+			Allowed: false,
+			Msg:     msg,
+		}, nil
+	}
+
+	return &operations.Result{Allowed: true}, nil
+}
+
+// originalImage returns the image a container referenced before it was mutated by the Zarf agent and whether the container
+// image is the one the agent mutates it to. The annotation with the original image is only trusted when it transforms to the
+// container image, otherwise anyone creating the pod could choose the image the policy is evaluated against.
+func originalImage(ctx context.Context, s *state.State, namespace string, pod *corev1.Pod, containerName, image string, rules transform.ImageRules) (string, bool, error) {
+	if original, ok := pod.Annotations[getImageAnnotationKey(ctx, containerName)]; ok && original != "" {
+		transformed, err := transformContainerImage(ctx, s, original, namespace, rules)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to transform the image %s: %w", original, err)
+		}
+		if transformed == image {
+			return original, true, nil
+		}
+		logger.From(ctx).Warn("ignoring the original image annotation as it does not match the container image", "namespace", namespace, "container", containerName, "image", image, "annotation", original)
+		return image, false, nil
+	}
+	transformed, err := transformContainerImage(ctx, s, image, namespace, rules)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to transform the image %s: %w", image, err)
+	}
+	return image, transformed == image, nil
+}
+
+// validateContainerImage returns a description of the image policy violation for the original image or an empty string if it is allowed.
+func validateContainerImage(ctx context.Context, c *cluster.Cluster, zarfState *state.State, namespace, image string, rules transform.ImageRules) (string, error) {
+	policy := zarfState.AgentInfo.ImagePolicy
+
+	if !isAllowedRegistry(image, policy.AllowedRegistries) {
+		return fmt.Sprintf("image %s does not come from an allowed registry (%s)", image, strings.Join(policy.AllowedRegistries, ", ")), nil
+	}
+
+	if !policy.RequireImagesInRegistry {
+		return "", nil
+	}
+
+	registry := zarfState.RegistryFor(image, namespace)
+
+	// Get the registry service info if this is a NodePort service to use the internal kube-dns
+	registryAddress, err := c.GetServiceInfoFromRegistryAddress(ctx, registry.Address)
+	if err != nil {
+		return "", err
+	}
+
+	transformed, err := transformRegistryImage(registry.RegistryInfo, registryAddress, image, rules)
+	if err != nil {
+		return "", fmt.Errorf("unable to transform the image %s: %w", image, err)
+	}
+	exists, err := imageExistsInRegistry(ctx, registry.RegistryInfo, transformed)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return fmt.Sprintf("image %s has not been pushed to the Zarf registry, ensure it is included in a deployed package", image), nil
+	}
+	return "", nil
+}
+
+// isAllowedRegistry returns true if the image comes from one of the allowed registries or no allowlist is configured.
+func isAllowedRegistry(image string, allowedRegistries []string) bool {
+	if len(allowedRegistries) == 0 {
+		return true
+	}
+	ref, err := transform.ParseImageRef(image)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range allowedRegistries {
+		allowed = strings.TrimSuffix(allowed, "/")
+		if ref.Host == allowed || ref.Name == allowed || strings.HasPrefix(ref.Name, allowed+"/") {
+			return true
+		}
+	}
+	return false
+}
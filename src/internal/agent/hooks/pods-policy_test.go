@@ -0,0 +1,211 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/internal/agent/http/admission"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+	v1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+// pushTestImage pushes an empty artifact to the local registry under the Zarf transformed reference of the image.
+func pushTestImage(ctx context.Context, t *testing.T, registryURL, image string) {
+	t.Helper()
+	transformed, err := transform.ImageTransformHost(registryURL, image)
+	require.NoError(t, err)
+	ref, err := transform.ParseImageRef(transformed)
+	require.NoError(t, err)
+
+	repo, err := remote.NewRepository(ref.Name)
+	require.NoError(t, err)
+	repo.PlainHTTP = true
+
+	// The in-memory registry is started in the background so wait for it to accept connections
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(fmt.Sprintf("http://%s/v2/", registryURL))
+		if err != nil {
+			return false
+		}
+		//nolint:errcheck // ignore
+		resp.Body.Close()
+		return true
+	}, 10*time.Second, 100*time.Millisecond)
+
+	desc, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, "application/vnd.zarf.test", oras.PackManifestOptions{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Tag(ctx, desc, ref.Tag))
+}
+
+func decodeAdmissionResponse(t *testing.T, body []byte) *v1.AdmissionResponse {
+	t.Helper()
+	var review v1.AdmissionReview
+	require.NoError(t, json.Unmarshal(body, &review))
+	require.NotNil(t, review.Response)
+	return review.Response
+}
+
+func TestPodValidationWebhook(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.TestContext(t)
+	port, err := helpers.GetAvailablePort()
+	require.NoError(t, err)
+	registryURL := testutil.SetupInMemoryRegistry(ctx, t, port)
+	pushTestImage(ctx, t, registryURL, "ghcr.io/zarf-dev/images/hello-world:latest")
+	helloImage, err := transform.ImageTransformHost(registryURL, "ghcr.io/zarf-dev/images/hello-world:latest")
+	require.NoError(t, err)
+	nginxImage, err := transform.ImageTransformHost(registryURL, "nginx")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		policy     state.ImagePolicy
+		operation  v1.Operation
+		pod        *corev1.Pod
+		allowed    bool
+		msgContain string
+	}{
+		{
+			name:   "pods are allowed when the policy is disabled",
+			policy: state.ImagePolicy{},
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}}},
+			},
+			allowed: true,
+		},
+		{
+			name:   "pod with image in the registry is allowed",
+			policy: state.ImagePolicy{RequireImagesInRegistry: true},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"zarf.dev/original-image-hello": "ghcr.io/zarf-dev/images/hello-world:latest"},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "hello", Image: helloImage}}},
+			},
+			allowed: true,
+		},
+		{
+			name:   "pod created from a mutated template is allowed",
+			policy: state.ImagePolicy{RequireImagesInRegistry: true},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"zarf-agent": "patched"},
+					Annotations: map[string]string{"zarf.dev/original-image-hello": "ghcr.io/zarf-dev/images/hello-world:latest"},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "hello", Image: helloImage}}},
+			},
+			allowed: true,
+		},
+		{
+			name:   "pod with a patched label and a forged original image is denied on create",
+			policy: state.ImagePolicy{RequireImagesInRegistry: true, AllowedRegistries: []string{"ghcr.io/zarf-dev"}},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"zarf-agent": "patched"},
+					Annotations: map[string]string{"zarf.dev/original-image-miner": "ghcr.io/zarf-dev/images/hello-world:latest"},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "miner", Image: "evil.example.com/miner:latest"}}},
+			},
+			allowed:    false,
+			msgContain: `container "miner": the zarf-agent=patched label was not applied by the Zarf agent`,
+		},
+		{
+			name:      "pod with a forged original image is evaluated against its container image on update",
+			policy:    state.ImagePolicy{AllowedRegistries: []string{"ghcr.io/zarf-dev"}},
+			operation: v1.Update,
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"zarf-agent": "patched"},
+					Annotations: map[string]string{"zarf.dev/original-image-miner": "ghcr.io/zarf-dev/images/hello-world:latest"},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "miner", Image: "evil.example.com/miner:latest"}}},
+			},
+			allowed:    false,
+			msgContain: `container "miner": image evil.example.com/miner:latest does not come from an allowed registry (ghcr.io/zarf-dev)`,
+		},
+		{
+			name:   "pod with image missing from the registry is denied",
+			policy: state.ImagePolicy{RequireImagesInRegistry: true},
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{"zarf.dev/original-image-nginx": "nginx"},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: nginxImage}}},
+			},
+			allowed:    false,
+			msgContain: `container "nginx": image nginx has not been pushed to the Zarf registry`,
+		},
+		{
+			name:   "pod with image outside of the allowed registries is denied",
+			policy: state.ImagePolicy{AllowedRegistries: []string{"ghcr.io/zarf-dev"}},
+			pod: &corev1.Pod{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "hello", Image: "ghcr.io/zarf-dev/images/hello-world:latest"},
+					{Name: "nginx", Image: "nginx"},
+				}},
+			},
+			allowed:    false,
+			msgContain: `container "nginx": image nginx does not come from an allowed registry (ghcr.io/zarf-dev)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &state.State{
+				RegistryInfo: state.RegistryInfo{Address: registryURL},
+				AgentInfo:    state.AgentInfo{ImagePolicy: tt.policy},
+			}
+			c := createTestClientWithZarfState(ctx, t, s)
+			handler := admission.NewHandler(c).Serve(ctx, "pod-policy", NewPodValidationHook(ctx, c))
+
+			operation := tt.operation
+			if operation == "" {
+				operation = v1.Create
+			}
+			rr := sendAdmissionRequest(t, createPodAdmissionRequest(t, operation, tt.pod, ""), handler)
+			require.Equal(t, http.StatusOK, rr.Code)
+			resp := decodeAdmissionResponse(t, rr.Body.Bytes())
+			require.Equal(t, tt.allowed, resp.Allowed)
+			require.Empty(t, resp.Patch)
+			if tt.msgContain != "" {
+				require.Contains(t, resp.Result.Message, tt.msgContain)
+			}
+		})
+	}
+}
+
+func TestIsAllowedRegistry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		image    string
+		allowed  []string
+		expected bool
+	}{
+		{image: "nginx", allowed: nil, expected: true},
+		{image: "nginx", allowed: []string{"docker.io"}, expected: true},
+		{image: "nginx", allowed: []string{"docker.io/library"}, expected: true},
+		{image: "ghcr.io/zarf-dev/images/hello-world:latest", allowed: []string{"ghcr.io/zarf-dev/"}, expected: true},
+		{image: "ghcr.io/zarf-devious/hello-world:latest", allowed: []string{"ghcr.io/zarf-dev"}, expected: false},
+		{image: "quay.io/prometheus/prometheus", allowed: []string{"ghcr.io", "docker.io"}, expected: false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, isAllowedRegistry(tt.image, tt.allowed), tt.image)
+	}
+}
@@ -15,7 +15,7 @@ import (
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
-	"github.com/zarf-dev/zarf/src/pkg/transform"
+	"github.com/zarf-dev/zarf/src/pkg/state"
 	v1 "k8s.io/api/admission/v1"
 
 	corev1 "k8s.io/api/core/v1"
@@ -26,11 +26,11 @@ const annotationPrefix = "zarf.dev"
 // NewPodMutationHook creates a new instance of pods mutation hook.
 func NewPodMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
 	return operations.Hook{
-		Create: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutatePod(ctx, r, cluster)
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutatePod(ctx, r, s, cluster)
 		},
-		Update: func(r *v1.AdmissionRequest) (*operations.Result, error) {
-			return mutatePod(ctx, r, cluster)
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutatePod(ctx, r, s, cluster)
 		},
 	}
 }
@@ -59,7 +59,7 @@ func getImageAnnotationKey(ctx context.Context, containerName string) string {
 	return key
 }
 
-func mutatePod(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutatePod(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
 	pod, err := parsePod(r.Object.Raw)
 	if err != nil {
@@ -67,7 +67,7 @@ func mutatePod(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Clu
 	}
 
 	if r.SubResource != "" {
-		return mutatePodSubresource(ctx, r, cluster)
+		return mutatePodSubresource(ctx, r, s, cluster)
 	}
 
 	if pod.Labels != nil && pod.Labels["zarf-agent"] == "patched" {
@@ -78,14 +78,12 @@ func mutatePod(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Clu
 		}, nil
 	}
 
-	state, err := cluster.LoadState(ctx)
+	// Pods do not have a metadata.name at the time of admission if from a deployment so we don't log the name
+	l.Info("using the Zarf registry URL to mutate the Pod", "registry", s.RegistryInfo.Address)
+	rules, err := packageImageRules(ctx, cluster, pod.Labels)
 	if err != nil {
 		return nil, err
 	}
-	registryURL := state.RegistryInfo.Address
-
-	// Pods do not have a metadata.name at the time of admission if from a deployment so we don't log the name
-	l.Info("using the Zarf registry URL to mutate the Pod", "registry", registryURL)
 
 	var patches []operations.PatchOperation
 
@@ -101,10 +99,14 @@ func mutatePod(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Clu
 	// update the image host for each init container
 	for idx, container := range pod.Spec.InitContainers {
 		path := fmt.Sprintf("/spec/initContainers/%d/image", idx)
-		replacement, err := transform.ImageTransformHost(registryURL, container.Image)
+		replacement, err := transformContainerImage(ctx, s, container.Image, r.Namespace, rules)
 		if err != nil {
 			return nil, err
 		}
+		// Images pulled through the registry mirrors keep their original name
+		if replacement == container.Image {
+			continue
+		}
 		updatedAnnotations[getImageAnnotationKey(ctx, container.Name)] = container.Image
 		patches = append(patches, operations.ReplacePatchOperation(path, replacement))
 	}
@@ -112,10 +114,14 @@ func mutatePod(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Clu
 	// update the image host for each normal container
 	for idx, container := range pod.Spec.Containers {
 		path := fmt.Sprintf("/spec/containers/%d/image", idx)
-		replacement, err := transform.ImageTransformHost(registryURL, container.Image)
+		replacement, err := transformContainerImage(ctx, s, container.Image, r.Namespace, rules)
 		if err != nil {
 			return nil, err
 		}
+		// Images pulled through the registry mirrors keep their original name
+		if replacement == container.Image {
+			continue
+		}
 		updatedAnnotations[getImageAnnotationKey(ctx, container.Name)] = container.Image
 		patches = append(patches, operations.ReplacePatchOperation(path, replacement))
 	}
@@ -133,31 +139,29 @@ func mutatePod(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Clu
 }
 
 // mutatePodSubresource handles pod subresource mutation
-func mutatePodSubresource(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutatePodSubresource(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	switch res := r.SubResource; res {
 	case "ephemeralcontainers":
-		return mutateEphemeralContainers(ctx, r, cluster)
+		return mutateEphemeralContainers(ctx, r, s, cluster)
 	default:
 		// this likely won't be hit as the MutatingWebhookConfiguration would need to be modified - but this can help ensure they stay synchronized
 		return nil, fmt.Errorf("attempted mutation of unsupported subresource: %s", res)
 	}
 }
 
-func mutateEphemeralContainers(ctx context.Context, r *v1.AdmissionRequest, cluster *cluster.Cluster) (*operations.Result, error) {
+func mutateEphemeralContainers(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
 	l := logger.From(ctx)
 	pod, err := parsePod(r.Object.Raw)
 	if err != nil {
 		return nil, fmt.Errorf(lang.AgentErrParsePod, err)
 	}
 
-	state, err := cluster.LoadState(ctx)
+	// Pods do not have a metadata.name at the time of admission if from a deployment so we don't log the name
+	l.Info("using the Zarf registry URL to mutate the Pod", "registry", s.RegistryInfo.Address)
+	rules, err := packageImageRules(ctx, cluster, pod.Labels)
 	if err != nil {
 		return nil, err
 	}
-	registryURL := state.RegistryInfo.Address
-
-	// Pods do not have a metadata.name at the time of admission if from a deployment so we don't log the name
-	l.Info("using the Zarf registry URL to mutate the Pod", "registry", registryURL)
 
 	updatedAnnotations := pod.Annotations
 	if updatedAnnotations == nil {
@@ -169,10 +173,14 @@ func mutateEphemeralContainers(ctx context.Context, r *v1.AdmissionRequest, clus
 	// update the image host for each ephemeral container
 	for idx, container := range pod.Spec.EphemeralContainers {
 		path := fmt.Sprintf("/spec/ephemeralContainers/%d/image", idx)
-		replacement, err := transform.ImageTransformHost(registryURL, container.Image)
+		replacement, err := transformContainerImage(ctx, s, container.Image, r.Namespace, rules)
 		if err != nil {
 			return nil, err
 		}
+		// Images pulled through the registry mirrors keep their original name
+		if replacement == container.Image {
+			continue
+		}
 		updatedAnnotations[getImageAnnotationKey(ctx, container.Name)] = container.Image
 		patches = append(patches, operations.ReplacePatchOperation(path, replacement))
 	}
@@ -10,9 +10,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/config"
 	"github.com/zarf-dev/zarf/src/internal/agent/http/admission"
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/state"
 	v1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -40,7 +42,7 @@ func TestPodMutationWebhook(t *testing.T) {
 
 	s := &state.State{RegistryInfo: state.RegistryInfo{Address: "127.0.0.1:31999"}}
 	c := createTestClientWithZarfState(ctx, t, s)
-	handler := admission.NewHandler().Serve(ctx, NewPodMutationHook(ctx, c))
+	handler := admission.NewHandler(c).Serve(ctx, "pod", NewPodMutationHook(ctx, c))
 
 	tests := []admissionTest{
 		{
@@ -180,6 +182,205 @@ func TestPodMutationWebhook(t *testing.T) {
 		})
 	}
 }
+func TestPodMutationWebhookRouting(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	s := &state.State{
+		RegistryInfo: state.RegistryInfo{Address: "127.0.0.1:31999"},
+		Registries: []state.NamedRegistryInfo{
+			{
+				Name:         "tenant-a",
+				RegistryInfo: state.RegistryInfo{Address: "registry.tenant-a.example.com"},
+				Routes:       state.RoutingRules{Namespaces: []string{"tenant-a"}},
+			},
+			{
+				Name:         "tenant-b",
+				RegistryInfo: state.RegistryInfo{Address: "registry.tenant-b.example.com"},
+				Routes:       state.RoutingRules{SourcePrefixes: []string{"ghcr.io/tenant-b"}},
+			},
+		},
+	}
+	c := createTestClientWithZarfState(ctx, t, s)
+	handler := admission.NewHandler(c).Serve(ctx, "pod", NewPodMutationHook(ctx, c))
+
+	digest := "sha256:3e1c1a1d1ee5a4a9bde3e0e5ee2b1f7f2f0ef0dd4a1a50a1d1eeb7e4cd16d4dd"
+	req := createPodAdmissionRequest(t, v1.Create, &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nginx", Image: "nginx"},
+				{Name: "app", Image: "ghcr.io/tenant-b/app@" + digest},
+			},
+		},
+	}, "")
+	tenantReq := *req
+	tenantReq.Namespace = "tenant-a"
+
+	tests := []admissionTest{
+		{
+			name:         "images are routed by source prefix",
+			admissionReq: req,
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/imagePullSecrets",
+					[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/0/image",
+					"127.0.0.1:31999/library/nginx:latest-zarf-3793515731",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/1/image",
+					"registry.tenant-b.example.com/tenant-b/app@"+digest,
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{"zarf-agent": "patched"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/annotations",
+					map[string]string{
+						"zarf.dev/original-image-nginx": "nginx",
+						"zarf.dev/original-image-app":   "ghcr.io/tenant-b/app@" + digest,
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:         "images are routed by namespace",
+			admissionReq: &tenantReq,
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/imagePullSecrets",
+					[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/0/image",
+					"registry.tenant-a.example.com/library/nginx:latest-zarf-3793515731",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/1/image",
+					"registry.tenant-a.example.com/tenant-b/app@"+digest,
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{"zarf-agent": "patched"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/annotations",
+					map[string]string{
+						"zarf.dev/original-image-nginx": "nginx",
+						"zarf.dev/original-image-app":   "ghcr.io/tenant-b/app@" + digest,
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := sendAdmissionRequest(t, tt.admissionReq, handler)
+			verifyAdmission(t, rr, tt)
+		})
+	}
+}
+
+func TestPodMutationWebhookMirror(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	s := &state.State{
+		RegistryInfo: state.RegistryInfo{
+			Address:      "127.0.0.1:31999",
+			RegistryMode: state.RegistryModeMirror,
+			Mirrors:      []string{"docker.io", "ghcr.io"},
+		},
+		Registries: []state.NamedRegistryInfo{
+			{
+				Name:         "tenant-a",
+				RegistryInfo: state.RegistryInfo{Address: "registry.tenant-a.example.com", RegistryMode: state.RegistryModeExternal},
+				Routes:       state.RoutingRules{Namespaces: []string{"tenant-a"}},
+			},
+		},
+	}
+	c := createTestClientWithZarfState(ctx, t, s)
+	handler := admission.NewHandler(c).Serve(ctx, "pod", NewPodMutationHook(ctx, c))
+
+	req := createPodAdmissionRequest(t, v1.Create, &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox"}},
+			Containers:     []corev1.Container{{Name: "app", Image: "ghcr.io/stefanprodan/podinfo:6.4.0"}},
+		},
+	}, "")
+	tenantReq := *req
+	tenantReq.Namespace = "tenant-a"
+
+	tests := []admissionTest{
+		{
+			name:         "images pulled through the mirrors are not rewritten",
+			admissionReq: req,
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/imagePullSecrets",
+					[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{"zarf-agent": "patched"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/annotations",
+					map[string]string{},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:         "images routed to a named registry are rewritten",
+			admissionReq: &tenantReq,
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/imagePullSecrets",
+					[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/initContainers/0/image",
+					"registry.tenant-a.example.com/library/busybox:latest-zarf-2140033595",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/0/image",
+					"registry.tenant-a.example.com/stefanprodan/podinfo:6.4.0-zarf-2985051089",
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{"zarf-agent": "patched"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/annotations",
+					map[string]string{
+						"zarf.dev/original-image-init": "busybox",
+						"zarf.dev/original-image-app":  "ghcr.io/stefanprodan/podinfo:6.4.0",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := sendAdmissionRequest(t, tt.admissionReq, handler)
+			verifyAdmission(t, rr, tt)
+		})
+	}
+}
+
 func TestGetImageAnnotationKey(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -208,3 +409,118 @@ func TestGetImageAnnotationKey(t *testing.T) {
 		})
 	}
 }
+
+func TestPodMutationWebhookTransforms(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	s := &state.State{RegistryInfo: state.RegistryInfo{Address: "127.0.0.1:31999"}}
+	c := createTestClientWithZarfState(ctx, t, s)
+	deployedPackage := state.DeployedPackage{
+		Name: "podinfo",
+		Data: v1alpha1.ZarfPackage{
+			Transforms: v1alpha1.ZarfTransforms{
+				Images: []v1alpha1.ZarfImageTransform{
+					{Match: `^ghcr\.io/(.*)$`, Path: "platform/$1", Flatten: true, NoChecksum: true},
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(deployedPackage)
+	require.NoError(t, err)
+	_, err = c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployedPackage.GetSecretName(),
+			Namespace: state.ZarfNamespaceName,
+		},
+		Data: map[string][]byte{"data": b},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	handler := admission.NewHandler(c).Serve(ctx, "pod", NewPodMutationHook(ctx, c))
+
+	pod := func(pkgName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{cluster.PackageLabel: pkgName},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "podinfo", Image: "ghcr.io/stefanprodan/podinfo:6.4.0"},
+					{Name: "nginx", Image: "nginx"},
+				},
+			},
+		}
+	}
+
+	tests := []admissionTest{
+		{
+			name:         "images of the package are transformed by its rules",
+			admissionReq: createPodAdmissionRequest(t, v1.Create, pod("podinfo"), ""),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/imagePullSecrets",
+					[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/0/image",
+					"127.0.0.1:31999/platform/stefanprodan-podinfo:6.4.0",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/1/image",
+					"127.0.0.1:31999/library/nginx:latest-zarf-3793515731",
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{"zarf-agent": "patched", cluster.PackageLabel: "podinfo"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/annotations",
+					map[string]string{
+						"zarf.dev/original-image-podinfo": "ghcr.io/stefanprodan/podinfo:6.4.0",
+						"zarf.dev/original-image-nginx":   "nginx",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:         "packages that are not deployed use the default transform",
+			admissionReq: createPodAdmissionRequest(t, v1.Create, pod("removed"), ""),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/imagePullSecrets",
+					[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/0/image",
+					"127.0.0.1:31999/stefanprodan/podinfo:6.4.0-zarf-2985051089",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/containers/1/image",
+					"127.0.0.1:31999/library/nginx:latest-zarf-3793515731",
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/labels",
+					map[string]string{"zarf-agent": "patched", cluster.PackageLabel: "removed"},
+				),
+				operations.ReplacePatchOperation(
+					"/metadata/annotations",
+					map[string]string{
+						"zarf.dev/original-image-podinfo": "ghcr.io/stefanprodan/podinfo:6.4.0",
+						"zarf.dev/original-image-nginx":   "nginx",
+					},
+				),
+			},
+			code: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := sendAdmissionRequest(t, tt.admissionReq, handler)
+			verifyAdmission(t, rr, tt)
+		})
+	}
+}
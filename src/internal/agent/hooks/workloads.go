@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	v1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WorkloadsNamespaceLabel is the namespace label used to opt a namespace into workload mutation.
+const WorkloadsNamespaceLabel = "zarf.dev/agent-workloads"
+
+// NewWorkloadMutationHook creates a new instance of the workload mutation hook.
+// It rewrites the pod template images of Deployments, StatefulSets, DaemonSets, Jobs and CronJobs.
+func NewWorkloadMutationHook(ctx context.Context, cluster *cluster.Cluster) operations.Hook {
+	return operations.Hook{
+		Create: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateWorkload(ctx, r, s, cluster)
+		},
+		Update: func(r *v1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			return mutateWorkload(ctx, r, s, cluster)
+		},
+	}
+}
+
+// parsePodTemplate returns the pod template of a workload along with the JSON pointer to it.
+func parsePodTemplate(kind string, object []byte) (*corev1.PodTemplateSpec, string, error) {
+	switch kind {
+	case "Deployment":
+		var deploy appsv1.Deployment
+		if err := json.Unmarshal(object, &deploy); err != nil {
+			return nil, "", err
+		}
+		return &deploy.Spec.Template, "/spec/template", nil
+	case "StatefulSet":
+		var sts appsv1.StatefulSet
+		if err := json.Unmarshal(object, &sts); err != nil {
+			return nil, "", err
+		}
+		return &sts.Spec.Template, "/spec/template", nil
+	case "DaemonSet":
+		var ds appsv1.DaemonSet
+		if err := json.Unmarshal(object, &ds); err != nil {
+			return nil, "", err
+		}
+		return &ds.Spec.Template, "/spec/template", nil
+	case "Job":
+		var job batchv1.Job
+		if err := json.Unmarshal(object, &job); err != nil {
+			return nil, "", err
+		}
+		return &job.Spec.Template, "/spec/template", nil
+	case "CronJob":
+		var cronJob batchv1.CronJob
+		if err := json.Unmarshal(object, &cronJob); err != nil {
+			return nil, "", err
+		}
+		return &cronJob.Spec.JobTemplate.Spec.Template, "/spec/jobTemplate/spec/template", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported workload kind %q", kind)
+	}
+}
+
+func mutateWorkload(ctx context.Context, r *v1.AdmissionRequest, s *state.State, cluster *cluster.Cluster) (*operations.Result, error) {
+	l := logger.From(ctx)
+	template, templatePath, err := parsePodTemplate(r.Kind.Kind, r.Object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf(lang.ErrUnmarshal, err)
+	}
+
+	l.Info("using the Zarf registry URL to mutate the workload",
+		"kind", r.Kind.Kind,
+		"name", r.Name,
+		"namespace", r.Namespace,
+		"registry", s.RegistryInfo.Address)
+	rules, err := packageImageRules(ctx, cluster, template.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	var patches []operations.PatchOperation
+
+	// Add the zarf secret to the pod template
+	zarfSecret := []corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}}
+	patches = append(patches, operations.ReplacePatchOperation(templatePath+"/spec/imagePullSecrets", zarfSecret))
+
+	updatedAnnotations := template.Annotations
+	if updatedAnnotations == nil {
+		updatedAnnotations = make(map[string]string)
+	}
+
+	containerPatches := func(field string, containers []corev1.Container) error {
+		for idx, container := range containers {
+			path := fmt.Sprintf("%s/spec/%s/%d/image", templatePath, field, idx)
+			replacement, err := transformContainerImage(ctx, s, container.Image, r.Namespace, rules)
+			if err != nil {
+				return err
+			}
+			// Only record the original image when it is changed so updates of an already
+			// mutated workload keep pointing at the upstream reference.
+			if replacement != container.Image {
+				updatedAnnotations[getImageAnnotationKey(ctx, container.Name)] = container.Image
+			}
+			patches = append(patches, operations.ReplacePatchOperation(path, replacement))
+		}
+		return nil
+	}
+
+	// update the image host for each init container
+	if err := containerPatches("initContainers", template.Spec.InitContainers); err != nil {
+		return nil, err
+	}
+
+	// update the image host for each normal container
+	if err := containerPatches("containers", template.Spec.Containers); err != nil {
+		return nil, err
+	}
+
+	// Add the "zarf-agent"="patched" label so the pod hook skips pods created from this template
+	labelPatch := getLabelPatch(template.Labels)
+	labelPatch.Path = templatePath + labelPatch.Path
+	patches = append(patches, labelPatch)
+
+	// Add the annotations label patch
+	patches = append(patches, operations.ReplacePatchOperation(templatePath+"/metadata/annotations", updatedAnnotations))
+
+	return &operations.Result{
+		Allowed:  true,
+		PatchOps: patches,
+	}, nil
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/internal/agent/http/admission"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	v1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func createWorkloadAdmissionRequest(t *testing.T, op v1.Operation, kind string, obj any) *v1.AdmissionRequest {
+	t.Helper()
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+	return &v1.AdmissionRequest{
+		Operation: op,
+		Kind:      metav1.GroupVersionKind{Kind: kind},
+		Object: runtime.RawExtension{
+			Raw: raw,
+		},
+	}
+}
+
+func TestWorkloadMutationWebhook(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	s := &state.State{RegistryInfo: state.RegistryInfo{Address: "127.0.0.1:31999"}}
+	c := createTestClientWithZarfState(ctx, t, s)
+	handler := admission.NewHandler(c).Serve(ctx, "workload", NewWorkloadMutationHook(ctx, c))
+
+	podTemplate := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"app": "nginx"},
+		},
+		Spec: corev1.PodSpec{
+			Containers:     []corev1.Container{{Name: "nginx", Image: "nginx"}},
+			InitContainers: []corev1.Container{{Name: "different", Image: "busybox"}},
+		},
+	}
+	templatePatches := func(templatePath string) []operations.PatchOperation {
+		return []operations.PatchOperation{
+			operations.ReplacePatchOperation(
+				templatePath+"/spec/imagePullSecrets",
+				[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+			),
+			operations.ReplacePatchOperation(
+				templatePath+"/spec/initContainers/0/image",
+				"127.0.0.1:31999/library/busybox:latest-zarf-2140033595",
+			),
+			operations.ReplacePatchOperation(
+				templatePath+"/spec/containers/0/image",
+				"127.0.0.1:31999/library/nginx:latest-zarf-3793515731",
+			),
+			operations.ReplacePatchOperation(
+				templatePath+"/metadata/labels",
+				map[string]string{
+					"zarf-agent": "patched",
+					"app":        "nginx",
+				},
+			),
+			operations.ReplacePatchOperation(
+				templatePath+"/metadata/annotations",
+				map[string]string{
+					"zarf.dev/original-image-nginx":     "nginx",
+					"zarf.dev/original-image-different": "busybox",
+				},
+			),
+		}
+	}
+
+	tests := []admissionTest{
+		{
+			name: "deployment should be mutated",
+			admissionReq: createWorkloadAdmissionRequest(t, v1.Create, "Deployment", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec:       appsv1.DeploymentSpec{Template: podTemplate},
+			}),
+			patch: templatePatches("/spec/template"),
+			code:  http.StatusOK,
+		},
+		{
+			name: "statefulset should be mutated",
+			admissionReq: createWorkloadAdmissionRequest(t, v1.Create, "StatefulSet", &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec:       appsv1.StatefulSetSpec{Template: podTemplate},
+			}),
+			patch: templatePatches("/spec/template"),
+			code:  http.StatusOK,
+		},
+		{
+			name: "daemonset should be mutated",
+			admissionReq: createWorkloadAdmissionRequest(t, v1.Create, "DaemonSet", &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec:       appsv1.DaemonSetSpec{Template: podTemplate},
+			}),
+			patch: templatePatches("/spec/template"),
+			code:  http.StatusOK,
+		},
+		{
+			name: "job should be mutated",
+			admissionReq: createWorkloadAdmissionRequest(t, v1.Create, "Job", &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec:       batchv1.JobSpec{Template: podTemplate},
+			}),
+			patch: templatePatches("/spec/template"),
+			code:  http.StatusOK,
+		},
+		{
+			name: "cronjob should be mutated",
+			admissionReq: createWorkloadAdmissionRequest(t, v1.Create, "CronJob", &batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec: batchv1.CronJobSpec{
+					JobTemplate: batchv1.JobTemplateSpec{
+						Spec: batchv1.JobSpec{Template: podTemplate},
+					},
+				},
+			}),
+			patch: templatePatches("/spec/jobTemplate/spec/template"),
+			code:  http.StatusOK,
+		},
+		{
+			name: "update of a mutated deployment keeps the original image annotation",
+			admissionReq: createWorkloadAdmissionRequest(t, v1.Update, "Deployment", &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels:      map[string]string{"zarf-agent": "patched"},
+							Annotations: map[string]string{"zarf.dev/original-image-nginx": "nginx"},
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "nginx", Image: "127.0.0.1:31999/library/nginx:latest-zarf-3793515731"}},
+						},
+					},
+				},
+			}),
+			patch: []operations.PatchOperation{
+				operations.ReplacePatchOperation(
+					"/spec/template/spec/imagePullSecrets",
+					[]corev1.LocalObjectReference{{Name: config.ZarfImagePullSecretName}},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/template/spec/containers/0/image",
+					"127.0.0.1:31999/library/nginx:latest-zarf-3793515731",
+				),
+				operations.ReplacePatchOperation(
+					"/spec/template/metadata/labels",
+					map[string]string{"zarf-agent": "patched"},
+				),
+				operations.ReplacePatchOperation(
+					"/spec/template/metadata/annotations",
+					map[string]string{"zarf.dev/original-image-nginx": "nginx"},
+				),
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:         "unsupported kind should error",
+			admissionReq: createWorkloadAdmissionRequest(t, v1.Create, "ReplicaSet", &appsv1.ReplicaSet{}),
+			code:         http.StatusInternalServerError,
+			errContains:  "unsupported workload kind",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := sendAdmissionRequest(t, tt.admissionReq, handler)
+			verifyAdmission(t, rr, tt)
+		})
+	}
+}
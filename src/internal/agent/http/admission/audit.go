@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zarf-dev/zarf/src/internal/agent/metrics"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AuditEventReason is the reason of the events recorded for hooks in audit mode.
+	AuditEventReason = "ZarfAgentAudit"
+	// maxEventMessageLength keeps event messages within the size accepted by the API server.
+	maxEventMessageLength = 1024
+)
+
+// auditPatches drops the patch operations of the result if the hook runs in audit mode and records them instead.
+func (h *Handler) auditPatches(ctx context.Context, s *state.State, hookName string, r *admissionv1.AdmissionRequest, result *operations.Result) error {
+	if !s.AgentInfo.Audit.IsAudited(hookName) {
+		return nil
+	}
+
+	patch, err := json.Marshal(result.PatchOps)
+	if err != nil {
+		return fmt.Errorf("unable to marshal the audited patch: %w", err)
+	}
+	l := logger.From(ctx)
+	l.Info("hook is in audit mode, admitting the object unmodified", "hook", hookName, "kind", r.Kind.Kind, "namespace", r.Namespace, "name", r.Name, "patch", string(patch))
+	metrics.AuditedPatches.WithLabelValues(hookName, r.Kind.Kind).Inc()
+
+	// Failing to record the event should not block the admission of the object
+	if err := h.recordAuditEvent(ctx, hookName, r, string(patch)); err != nil {
+		l.Error("unable to record the audit event", "hook", hookName, "error", err.Error())
+	}
+
+	result.PatchOps = nil
+	return nil
+}
+
+// recordAuditEvent creates a Kubernetes Event on the admitted object describing the patch that would have been applied.
+func (h *Handler) recordAuditEvent(ctx context.Context, hookName string, r *admissionv1.AdmissionRequest, patch string) error {
+	name := r.Name
+	if name == "" {
+		// Objects such as pods created by controllers do not have a name yet during admission
+		var obj metav1.PartialObjectMetadata
+		if err := json.Unmarshal(r.Object.Raw, &obj); err == nil {
+			name = obj.GenerateName
+		}
+	}
+	namespace := r.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	msg := fmt.Sprintf("%s hook would have applied patch: %s", hookName, patch)
+	if len(msg) > maxEventMessageLength {
+		msg = msg[:maxEventMessageLength-3] + "..."
+	}
+
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "zarf-agent-audit-",
+			Namespace:    namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: metav1.GroupVersion{Group: r.Kind.Group, Version: r.Kind.Version}.String(),
+			Kind:       r.Kind.Kind,
+			Namespace:  r.Namespace,
+			Name:       name,
+		},
+		Reason:         AuditEventReason,
+		Message:        msg,
+		Type:           corev1.EventTypeNormal,
+		Source:         corev1.EventSource{Component: "zarf-agent"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := h.cluster.Clientset.CoreV1().Events(namespace).Create(ctx, event, metav1.CreateOptions{})
+	return err
+}
@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/internal/agent/metrics"
+	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func createTestCluster(ctx context.Context, t *testing.T, s *state.State) *cluster.Cluster {
+	t.Helper()
+	c := &cluster.Cluster{Clientset: fake.NewClientset()}
+	require.NoError(t, c.SaveState(ctx, s, state.AuditOperationInit))
+	return c
+}
+
+func serveTestRequest(t *testing.T, handler http.HandlerFunc, path string, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	t.Helper()
+	b, err := json.Marshal(&admissionv1.AdmissionReview{Request: req})
+	require.NoError(t, err)
+	httpReq := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httpReq)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var review admissionv1.AdmissionReview
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&review))
+	require.NotNil(t, review.Response)
+	return review.Response
+}
+
+func counterValue(t *testing.T, hook, kind string) float64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, metrics.AuditedPatches.WithLabelValues(hook, kind).Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+func TestAuditMode(t *testing.T) {
+	t.Parallel()
+
+	hook := operations.Hook{
+		Create: func(_ *admissionv1.AdmissionRequest, _ *state.State) (*operations.Result, error) {
+			return &operations.Result{
+				Allowed:  true,
+				PatchOps: []operations.PatchOperation{operations.ReplacePatchOperation("/metadata/labels", map[string]string{"zarf-agent": "patched"})},
+			}, nil
+		},
+	}
+	raw, err := json.Marshal(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "podinfo-"}})
+	require.NoError(t, err)
+	req := &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "podinfo",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+
+	tests := []struct {
+		name    string
+		audit   state.AgentAudit
+		path    string
+		audited bool
+	}{
+		{
+			name:    "hook is patched when audit mode is disabled",
+			audit:   state.AgentAudit{},
+			path:    "/mutate/pod-disabled",
+			audited: false,
+		},
+		{
+			name:    "hook is audited when listed",
+			audit:   state.AgentAudit{Hooks: []string{"pod-listed"}},
+			path:    "/mutate/pod-listed",
+			audited: true,
+		},
+		{
+			name:    "hook is not audited when another hook is listed",
+			audit:   state.AgentAudit{Hooks: []string{"flux-gitrepository"}},
+			path:    "/mutate/pod-unlisted",
+			audited: false,
+		},
+		{
+			name:    "hook is audited when audit mode is global",
+			audit:   state.AgentAudit{All: true},
+			path:    "/mutate/pod-global",
+			audited: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			c := createTestCluster(ctx, t, &state.State{AgentInfo: state.AgentInfo{Audit: tt.audit}})
+			hookName := tt.path[len("/mutate/"):]
+			handler := NewHandler(c).Serve(ctx, hookName, hook)
+
+			resp := serveTestRequest(t, handler, tt.path, req)
+			require.True(t, resp.Allowed)
+
+			events, err := c.Clientset.CoreV1().Events("podinfo").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			if !tt.audited {
+				require.NotEmpty(t, resp.Patch)
+				require.Empty(t, events.Items)
+				require.Zero(t, counterValue(t, hookName, "Pod"))
+				return
+			}
+			require.Empty(t, resp.Patch)
+			require.Len(t, events.Items, 1)
+			require.Equal(t, AuditEventReason, events.Items[0].Reason)
+			require.Equal(t, "podinfo-", events.Items[0].InvolvedObject.Name)
+			require.Contains(t, events.Items[0].Message, `"path":"/metadata/labels"`)
+			require.InDelta(t, 1, counterValue(t, hookName, "Pod"), 0)
+		})
+	}
+}
+
+func TestAdmissionMetrics(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := createTestCluster(ctx, t, &state.State{})
+	hook := operations.Hook{
+		Create: func(_ *admissionv1.AdmissionRequest, _ *state.State) (*operations.Result, error) {
+			return &operations.Result{Allowed: false, Msg: "denied"}, nil
+		},
+	}
+	// The metrics are labeled with the name the hook is registered with rather than the request path
+	handler := NewHandler(c).Serve(ctx, "pod-metrics-policy", hook)
+	req := &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Object:    runtime.RawExtension{Raw: []byte(`{}`)},
+	}
+	resp := serveTestRequest(t, handler, "/validate/pod-metrics", req)
+	require.False(t, resp.Allowed)
+
+	var m dto.Metric
+	require.NoError(t, metrics.AdmissionRequests.WithLabelValues("pod-metrics-policy", "CREATE", metrics.ResultDenied).Write(&m))
+	require.InDelta(t, 1, m.GetCounter().GetValue(), 0)
+
+	observer, err := metrics.AdmissionDuration.GetMetricWithLabelValues("pod-metrics-policy")
+	require.NoError(t, err)
+	m = dto.Metric{}
+	require.NoError(t, observer.(prometheus.Histogram).Write(&m))
+	require.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+}
+
+func TestHandlerLoadsStateOnce(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := createTestCluster(ctx, t, &state.State{AgentInfo: state.AgentInfo{Audit: state.AgentAudit{All: true}}})
+	var stateGets atomic.Int32
+	c.Clientset.(*fake.Clientset).PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.GetAction).GetName() == state.ZarfStateSecretName {
+			stateGets.Add(1)
+		}
+		return false, nil, nil
+	})
+	hook := operations.Hook{
+		Create: func(_ *admissionv1.AdmissionRequest, s *state.State) (*operations.Result, error) {
+			require.True(t, s.AgentInfo.Audit.All)
+			return &operations.Result{
+				Allowed:  true,
+				PatchOps: []operations.PatchOperation{operations.ReplacePatchOperation("/metadata/labels", map[string]string{"zarf-agent": "patched"})},
+			}, nil
+		},
+	}
+	handler := NewHandler(c).Serve(ctx, "pod-state", hook)
+	req := &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "podinfo",
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Object:    runtime.RawExtension{Raw: []byte(`{}`)},
+	}
+	resp := serveTestRequest(t, handler, "/mutate/pod-state", req)
+	require.True(t, resp.Allowed)
+	require.Empty(t, resp.Patch)
+	require.Equal(t, int32(1), stateGets.Load())
+}
+
+func TestAdmissionResult(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, metrics.ResultErrored, admissionResult(nil, errors.New("failed")))
+	require.Equal(t, metrics.ResultDenied, admissionResult(&operations.Result{Allowed: false}, nil))
+	require.Equal(t, metrics.ResultPatched, admissionResult(&operations.Result{Allowed: true, PatchOps: []operations.PatchOperation{{}}}, nil))
+	require.Equal(t, metrics.ResultAllowed, admissionResult(&operations.Result{Allowed: true}, nil))
+}
@@ -12,9 +12,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/internal/agent/metrics"
 	"github.com/zarf-dev/zarf/src/internal/agent/operations"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	corev1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,17 +28,21 @@ import (
 // Handler represents the HTTP handler for an admission webhook.
 type Handler struct {
 	decoder runtime.Decoder
+	cluster *cluster.Cluster
 }
 
 // NewHandler returns a new admission Handler.
-func NewHandler() *Handler {
+// The cluster is used to look up which hooks run in audit mode and to record audit events.
+func NewHandler(cluster *cluster.Cluster) *Handler {
 	return &Handler{
 		decoder: serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer(),
+		cluster: cluster,
 	}
 }
 
 // Serve returns an http.HandlerFunc for an admission webhook.
-func (h *Handler) Serve(ctx context.Context, hook operations.Hook) http.HandlerFunc {
+// The hook name identifies the hook in audit mode, metrics and logs, so it must be unique across the served hooks.
+func (h *Handler) Serve(ctx context.Context, hookName string, hook operations.Hook) http.HandlerFunc {
 	l := logger.From(ctx)
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -66,7 +73,10 @@ func (h *Handler) Serve(ctx context.Context, hook operations.Hook) http.HandlerF
 			return
 		}
 
-		result, err := hook.Execute(review.Request)
+		start := time.Now()
+		result, err := h.execute(ctx, hookName, hook, review.Request)
+		metrics.AdmissionDuration.WithLabelValues(hookName).Observe(time.Since(start).Seconds())
+		metrics.AdmissionRequests.WithLabelValues(hookName, string(review.Request.Operation), admissionResult(result, err)).Inc()
 		admissionMeta := metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
 			Kind:       "AdmissionReview",
@@ -119,9 +129,42 @@ func (h *Handler) Serve(ctx context.Context, hook operations.Hook) http.HandlerF
 			return
 		}
 
-		l.Info("webhook execution complete", "path", r.URL.Path, "operation", review.Request.Operation, "allowed", result.Allowed)
+		l.Info("webhook execution complete", "hook", hookName, "path", r.URL.Path, "operation", review.Request.Operation, "allowed", result.Allowed)
 		w.WriteHeader(http.StatusOK)
 		//nolint: errcheck // ignore
 		w.Write(jsonResponse)
 	}
 }
+
+// execute runs the hook with the Zarf state, which is loaded once per request for the hook and its audit mode.
+func (h *Handler) execute(ctx context.Context, hookName string, hook operations.Hook, r *corev1.AdmissionRequest) (*operations.Result, error) {
+	s, err := h.cluster.LoadState(ctx)
+	if err != nil {
+		metrics.StateLoadFailures.WithLabelValues(metrics.ComponentWebhook).Inc()
+		return nil, err
+	}
+	result, err := hook.Execute(r, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.PatchOps) > 0 {
+		if err := h.auditPatches(ctx, s, hookName, r, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// admissionResult returns the result label of the admission request metric.
+func admissionResult(result *operations.Result, err error) string {
+	switch {
+	case err != nil:
+		return metrics.ResultErrored
+	case !result.Allowed:
+		return metrics.ResultDenied
+	case len(result.PatchOps) > 0:
+		return metrics.ResultPatched
+	default:
+		return metrics.ResultAllowed
+	}
+}
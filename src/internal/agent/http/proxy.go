@@ -12,8 +12,11 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/zarf-dev/zarf/src/internal/agent/metrics"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/state"
@@ -23,9 +26,19 @@ import (
 // ProxyHandler constructs a new httputil.ReverseProxy and returns an http handler.
 func ProxyHandler(ctx context.Context, cluster *cluster.Cluster) http.HandlerFunc {
 	l := logger.From(ctx)
-	return func(w http.ResponseWriter, r *http.Request) {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		agent := userAgentType(r.UserAgent())
+		w := &metricsResponseWriter{ResponseWriter: rw, code: http.StatusOK}
+		start := time.Now()
+		defer func() {
+			metrics.ProxyDuration.WithLabelValues(agent).Observe(time.Since(start).Seconds())
+			metrics.ProxyRequests.WithLabelValues(agent, strconv.Itoa(w.code)).Inc()
+			metrics.ProxyResponseBytes.WithLabelValues(agent).Add(float64(w.bytes))
+		}()
+
 		s, err := cluster.LoadState(r.Context())
 		if err != nil {
+			metrics.StateLoadFailures.WithLabelValues(metrics.ComponentProxy).Inc()
 			l.Debug(err.Error())
 			w.WriteHeader(http.StatusInternalServerError)
 			//nolint: errcheck // ignore
@@ -45,6 +58,31 @@ func ProxyHandler(ctx context.Context, cluster *cluster.Cluster) http.HandlerFun
 	}
 }
 
+// metricsResponseWriter records the status code and body size of a proxied response.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	code  int
+	bytes int
+}
+
+func (w *metricsResponseWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush allows the reverse proxy to stream responses such as git packfiles.
+func (w *metricsResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func proxyRequestTransform(r *http.Request, s *state.State) error {
 	// We add this so that we can use it to rewrite urls in the response if needed
 	r.Header.Add("X-Forwarded-Host", r.Host)
@@ -52,10 +90,14 @@ func proxyRequestTransform(r *http.Request, s *state.State) error {
 	// We remove this so that go will encode and decode on our behalf (see https://pkg.go.dev/net/http#Transport DisableCompression)
 	r.Header.Del("Accept-Encoding")
 
+	// Git requests are routed by their source URL as the namespace of the client is not known
+	sourceURL := getTLSScheme(r.TLS) + r.Host + r.URL.String()
+	gitServer := s.GitServerFor(sourceURL, "")
+
 	// Setup authentication for each type of service based on User Agent
 	switch {
 	case isGitUserAgent(r.UserAgent()):
-		r.SetBasicAuth(s.GitServer.PushUsername, s.GitServer.PushPassword)
+		r.SetBasicAuth(gitServer.PushUsername, gitServer.PushPassword)
 	case isNpmUserAgent(r.UserAgent()):
 		r.Header.Set("Authorization", "Bearer "+s.ArtifactServer.PushToken)
 	default:
@@ -68,20 +110,20 @@ func proxyRequestTransform(r *http.Request, s *state.State) error {
 	if strings.HasPrefix(r.URL.Path, transform.NoTransform) {
 		switch {
 		case isGitUserAgent(r.UserAgent()):
-			targetURL, err = transform.NoTransformTarget(s.GitServer.Address, r.URL.Path)
+			targetURL, err = transform.NoTransformTarget(gitServer.Address, r.URL.Path)
 		default:
 			targetURL, err = transform.NoTransformTarget(s.ArtifactServer.Address, r.URL.Path)
 		}
 	} else {
 		switch {
 		case isGitUserAgent(r.UserAgent()):
-			targetURL, err = transform.GitURL(s.GitServer.Address, getTLSScheme(r.TLS)+r.Host+r.URL.String(), s.GitServer.PushUsername)
+			targetURL, err = transform.GitURL(gitServer.Address, sourceURL, gitServer.PushUsername)
 		case isPipUserAgent(r.UserAgent()):
-			targetURL, err = transform.PipTransformURL(s.ArtifactServer.Address, getTLSScheme(r.TLS)+r.Host+r.URL.String())
+			targetURL, err = transform.PipTransformURL(s.ArtifactServer.Address, sourceURL)
 		case isNpmUserAgent(r.UserAgent()):
-			targetURL, err = transform.NpmTransformURL(s.ArtifactServer.Address, getTLSScheme(r.TLS)+r.Host+r.URL.String())
+			targetURL, err = transform.NpmTransformURL(s.ArtifactServer.Address, sourceURL)
 		default:
-			targetURL, err = transform.GenTransformURL(s.ArtifactServer.Address, getTLSScheme(r.TLS)+r.Host+r.URL.String())
+			targetURL, err = transform.GenTransformURL(s.ArtifactServer.Address, sourceURL)
 		}
 	}
 	if err != nil {
@@ -160,3 +202,17 @@ func isPipUserAgent(userAgent string) bool {
 func isNpmUserAgent(userAgent string) bool {
 	return strings.HasPrefix(userAgent, "npm") || strings.HasPrefix(userAgent, "pnpm") || strings.HasPrefix(userAgent, "yarn") || strings.HasPrefix(userAgent, "bun")
 }
+
+// userAgentType returns the type of client used as the user agent label of the proxy metrics.
+func userAgentType(userAgent string) string {
+	switch {
+	case isGitUserAgent(userAgent):
+		return "git"
+	case isPipUserAgent(userAgent):
+		return "pip"
+	case isNpmUserAgent(userAgent):
+		return "npm"
+	default:
+		return "generic"
+	}
+}
@@ -190,3 +190,39 @@ func TestUserAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestUserAgentType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		userAgent string
+		expected  string
+	}{
+		{userAgent: "git/2.0.0", expected: "git"},
+		{userAgent: "pip/1.2.3", expected: "pip"},
+		{userAgent: "twine/1.2.3", expected: "pip"},
+		{userAgent: "yarn/1.0.0", expected: "npm"},
+		{userAgent: "curl/8.0.0", expected: "generic"},
+		{userAgent: "", expected: "generic"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, userAgentType(tt.userAgent), tt.userAgent)
+	}
+}
+
+func TestMetricsResponseWriter(t *testing.T) {
+	t.Parallel()
+
+	rr := httptest.NewRecorder()
+	w := &metricsResponseWriter{ResponseWriter: rr, code: http.StatusOK}
+	w.WriteHeader(http.StatusNotFound)
+	n, err := w.Write([]byte("not found"))
+	require.NoError(t, err)
+	w.Flush()
+
+	require.Equal(t, 9, n)
+	require.Equal(t, http.StatusNotFound, w.code)
+	require.Equal(t, 9, w.bytes)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.True(t, rr.Flushed)
+}
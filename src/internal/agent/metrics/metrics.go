@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+// Package metrics holds the Prometheus collectors exposed by the Zarf agent.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "zarf_agent"
+
+// Results of an admission request as recorded by AdmissionRequests.
+const (
+	ResultAllowed = "allowed"
+	ResultDenied  = "denied"
+	ResultPatched = "patched"
+	ResultErrored = "errored"
+)
+
+// Components that load the Zarf state as recorded by StateLoadFailures.
+const (
+	ComponentWebhook = "webhook"
+	ComponentProxy   = "proxy"
+)
+
+// AuditedPatches counts the admission requests that would have been patched by a hook running in audit mode.
+var AuditedPatches = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "audited_patches_total",
+	Help:      "Number of admission requests admitted unmodified that would have been patched by a hook in audit mode.",
+}, []string{"hook", "kind"})
+
+// AdmissionRequests counts the admission requests handled by each hook by operation and result.
+var AdmissionRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Subsystem: "admission",
+	Name:      "requests_total",
+	Help:      "Number of admission requests handled by a hook, by operation and result (allowed, denied, patched or errored).",
+}, []string{"hook", "operation", "result"})
+
+// AdmissionDuration observes how long each hook takes to respond to an admission request.
+var AdmissionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: "admission",
+	Name:      "duration_seconds",
+	Help:      "Time taken by a hook to respond to an admission request.",
+	Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+}, []string{"hook"})
+
+// StateLoadFailures counts the failures to load the Zarf state from the cluster.
+var StateLoadFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "state_load_failures_total",
+	Help:      "Number of failures to load the Zarf state from the cluster, by component.",
+}, []string{"component"})
+
+// ProxyRequests counts the requests forwarded by the HTTP proxy by user agent type and upstream status code.
+var ProxyRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Subsystem: "proxy",
+	Name:      "requests_total",
+	Help:      "Number of requests handled by the HTTP proxy, by user agent type (git, npm, pip or generic) and upstream status code.",
+}, []string{"agent", "code"})
+
+// ProxyDuration observes how long the HTTP proxy takes to serve a request.
+var ProxyDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Subsystem: "proxy",
+	Name:      "duration_seconds",
+	Help:      "Time taken by the HTTP proxy to serve a request, by user agent type.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"agent"})
+
+// ProxyResponseBytes counts the bytes of the response bodies written by the HTTP proxy.
+var ProxyResponseBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Subsystem: "proxy",
+	Name:      "response_bytes_total",
+	Help:      "Number of response body bytes written by the HTTP proxy, by user agent type.",
+}, []string{"agent"})
+
+func init() {
+	prometheus.MustRegister(
+		AuditedPatches,
+		AdmissionRequests,
+		AdmissionDuration,
+		StateLoadFailures,
+		ProxyRequests,
+		ProxyDuration,
+		ProxyResponseBytes,
+	)
+}
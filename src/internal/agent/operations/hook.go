@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/zarf-dev/zarf/src/config/lang"
+	"github.com/zarf-dev/zarf/src/pkg/state"
 	admission "k8s.io/api/admission/v1"
 )
 
@@ -18,8 +19,8 @@ type Result struct {
 	PatchOps []PatchOperation
 }
 
-// AdmitFunc defines how to process an admission request.
-type AdmitFunc func(request *admission.AdmissionRequest) (*Result, error)
+// AdmitFunc defines how to process an admission request with the Zarf state loaded for it.
+type AdmitFunc func(request *admission.AdmissionRequest, s *state.State) (*Result, error)
 
 // Hook represents the set of functions for each operation in an admission webhook.
 type Hook struct {
@@ -30,25 +31,25 @@ type Hook struct {
 }
 
 // Execute evaluates the request and try to execute the function for operation specified in the request.
-func (h *Hook) Execute(r *admission.AdmissionRequest) (*Result, error) {
+func (h *Hook) Execute(r *admission.AdmissionRequest, s *state.State) (*Result, error) {
 	switch r.Operation {
 	case admission.Create:
-		return wrapperExecution(h.Create, r)
+		return wrapperExecution(h.Create, r, s)
 	case admission.Update:
-		return wrapperExecution(h.Update, r)
+		return wrapperExecution(h.Update, r, s)
 	case admission.Delete:
-		return wrapperExecution(h.Delete, r)
+		return wrapperExecution(h.Delete, r, s)
 	case admission.Connect:
-		return wrapperExecution(h.Connect, r)
+		return wrapperExecution(h.Connect, r, s)
 	}
 
 	return &Result{Msg: fmt.Sprintf(lang.AgentErrInvalidOp, r.Operation)}, nil
 }
 
 // If the mutatingwebhook calls for an operation with no bound function--go tell on them.
-func wrapperExecution(fn AdmitFunc, r *admission.AdmissionRequest) (*Result, error) {
+func wrapperExecution(fn AdmitFunc, r *admission.AdmissionRequest, s *state.State) (*Result, error) {
 	if fn == nil {
 		return nil, fmt.Errorf(lang.AgentErrInvalidOp, r.Operation)
 	}
-	return fn(r)
+	return fn(r, s)
 }
@@ -1,7 +1,7 @@
 // SPDX-License-Identifier: Apache-2.0
 // SPDX-FileCopyrightText: 2021-Present The Zarf Authors
 
-// Package agent holds the mutating webhook server.
+// Package agent holds the mutating and validating webhook server.
 package agent
 
 import (
@@ -31,27 +31,35 @@ const (
 	tlsKey   = "/etc/certs/tls.key"
 )
 
-// StartWebhook launches the Zarf agent mutating webhook in the cluster.
+// StartWebhook launches the Zarf agent mutating and validating webhooks in the cluster.
 func StartWebhook(ctx context.Context, cluster *cluster.Cluster) error {
 	// Routers
-	admissionHandler := admission.NewHandler()
+	admissionHandler := admission.NewHandler(cluster)
 	podsMutation := hooks.NewPodMutationHook(ctx, cluster)
+	workloadsMutation := hooks.NewWorkloadMutationHook(ctx, cluster)
+	podsValidation := hooks.NewPodValidationHook(ctx, cluster)
 	fluxGitRepositoryMutation := hooks.NewGitRepositoryMutationHook(ctx, cluster)
 	argocdApplicationMutation := hooks.NewApplicationMutationHook(ctx, cluster)
 	argocdAppProjectMutation := hooks.NewAppProjectMutationHook(ctx, cluster)
 	argocdRepositoryMutation := hooks.NewRepositorySecretMutationHook(ctx, cluster)
 	fluxHelmRepositoryMutation := hooks.NewHelmRepositoryMutationHook(ctx, cluster)
 	fluxOCIRepositoryMutation := hooks.NewOCIRepositoryMutationHook(ctx, cluster)
+	fluxHelmReleaseMutation := hooks.NewHelmReleaseMutationHook(ctx, cluster)
+	argocdApplicationSetMutation := hooks.NewApplicationSetMutationHook(ctx, cluster)
 
 	// Routers
 	mux := http.NewServeMux()
-	mux.Handle("/mutate/pod", admissionHandler.Serve(ctx, podsMutation))
-	mux.Handle("/mutate/flux-gitrepository", admissionHandler.Serve(ctx, fluxGitRepositoryMutation))
-	mux.Handle("/mutate/flux-helmrepository", admissionHandler.Serve(ctx, fluxHelmRepositoryMutation))
-	mux.Handle("/mutate/flux-ocirepository", admissionHandler.Serve(ctx, fluxOCIRepositoryMutation))
-	mux.Handle("/mutate/argocd-application", admissionHandler.Serve(ctx, argocdApplicationMutation))
-	mux.Handle("/mutate/argocd-appproject", admissionHandler.Serve(ctx, argocdAppProjectMutation))
-	mux.Handle("/mutate/argocd-repository", admissionHandler.Serve(ctx, argocdRepositoryMutation))
+	mux.Handle("/mutate/pod", admissionHandler.Serve(ctx, "pod", podsMutation))
+	mux.Handle("/mutate/workload", admissionHandler.Serve(ctx, "workload", workloadsMutation))
+	mux.Handle("/mutate/flux-gitrepository", admissionHandler.Serve(ctx, "flux-gitrepository", fluxGitRepositoryMutation))
+	mux.Handle("/mutate/flux-helmrepository", admissionHandler.Serve(ctx, "flux-helmrepository", fluxHelmRepositoryMutation))
+	mux.Handle("/mutate/flux-ocirepository", admissionHandler.Serve(ctx, "flux-ocirepository", fluxOCIRepositoryMutation))
+	mux.Handle("/mutate/flux-helmrelease", admissionHandler.Serve(ctx, "flux-helmrelease", fluxHelmReleaseMutation))
+	mux.Handle("/mutate/argocd-application", admissionHandler.Serve(ctx, "argocd-application", argocdApplicationMutation))
+	mux.Handle("/mutate/argocd-applicationset", admissionHandler.Serve(ctx, "argocd-applicationset", argocdApplicationSetMutation))
+	mux.Handle("/mutate/argocd-appproject", admissionHandler.Serve(ctx, "argocd-appproject", argocdAppProjectMutation))
+	mux.Handle("/mutate/argocd-repository", admissionHandler.Serve(ctx, "argocd-repository", argocdRepositoryMutation))
+	mux.Handle("/validate/pod", admissionHandler.Serve(ctx, "pod-policy", podsValidation))
 
 	return startServer(ctx, httpPort, mux)
 }
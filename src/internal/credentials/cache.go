@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package credentials
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Cache keeps the credentials loaded from an external store for a TTL so that every state load does not call the backend.
+// Entries are keyed by the configuration of the store so that a changed store is never served from the cache.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	key     string
+	creds   map[string]string
+	expires time.Time
+}
+
+// NewCache creates a cache that keeps the credentials for ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// Load returns the cached credentials of the store with the given key, loading them from the store once they expire.
+func (c *Cache) Load(ctx context.Context, key string, store Store) (map[string]string, error) {
+	c.mu.Lock()
+	if c.key == key && c.now().Before(c.expires) {
+		creds := maps.Clone(c.creds)
+		c.mu.Unlock()
+		return creds, nil
+	}
+	c.mu.Unlock()
+
+	creds, err := store.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.set(key, creds)
+	return maps.Clone(creds), nil
+}
+
+// Save writes the credentials to the store and replaces the cached credentials with them.
+func (c *Cache) Save(ctx context.Context, key string, store Store, creds map[string]string) error {
+	if err := store.Save(ctx, creds); err != nil {
+		c.Invalidate()
+		return err
+	}
+	c.set(key, creds)
+	return nil
+}
+
+// Invalidate drops the cached credentials so that the next load reads them from the store.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key = ""
+	c.creds = nil
+	c.expires = time.Time{}
+}
+
+func (c *Cache) set(key string, creds map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key = key
+	c.creds = maps.Clone(creds)
+	c.expires = c.now().Add(c.ttl)
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package credentials
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingStore is an in-memory store that counts the loads.
+type countingStore struct {
+	creds   map[string]string
+	loads   int
+	saveErr error
+}
+
+func (s *countingStore) Load(_ context.Context) (map[string]string, error) {
+	s.loads++
+	return s.creds, nil
+}
+
+func (s *countingStore) Save(_ context.Context, creds map[string]string) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.creds = creds
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	store := &countingStore{creds: map[string]string{"registry.pushPassword": "push"}}
+
+	// The credentials are only loaded once within the TTL
+	creds, err := cache.Load(ctx, "vault", store)
+	require.NoError(t, err)
+	require.Equal(t, "push", creds["registry.pushPassword"])
+	creds["registry.pushPassword"] = "modified"
+	creds, err = cache.Load(ctx, "vault", store)
+	require.NoError(t, err)
+	require.Equal(t, "push", creds["registry.pushPassword"])
+	require.Equal(t, 1, store.loads)
+
+	// Another store configuration is never served from the cache
+	_, err = cache.Load(ctx, "envelope", store)
+	require.NoError(t, err)
+	require.Equal(t, 2, store.loads)
+
+	// Saved credentials replace the cached ones
+	require.NoError(t, cache.Save(ctx, "vault", store, map[string]string{"registry.pushPassword": "rotated"}))
+	creds, err = cache.Load(ctx, "vault", store)
+	require.NoError(t, err)
+	require.Equal(t, "rotated", creds["registry.pushPassword"])
+	require.Equal(t, 2, store.loads)
+
+	// The credentials are loaded again once they expire
+	now = now.Add(time.Minute)
+	_, err = cache.Load(ctx, "vault", store)
+	require.NoError(t, err)
+	require.Equal(t, 3, store.loads)
+
+	// A failed save drops the cached credentials
+	store.saveErr = errors.New("unavailable")
+	require.Error(t, cache.Save(ctx, "vault", store, map[string]string{"registry.pushPassword": "lost"}))
+	_, err = cache.Load(ctx, "vault", store)
+	require.NoError(t, err)
+	require.Equal(t, 4, store.loads)
+}
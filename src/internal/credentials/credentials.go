@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+// Package credentials contains the backends that store the sensitive fields of the Zarf state outside of the state secret.
+package credentials
+
+import (
+	"context"
+)
+
+// Store is a backend that keeps the credentials of the Zarf state keyed by their credential name.
+type Store interface {
+	// Load returns the stored credentials.
+	Load(ctx context.Context) (map[string]string, error)
+	// Save replaces the stored credentials.
+	Save(ctx context.Context, creds map[string]string) error
+}
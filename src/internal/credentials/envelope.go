@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package credentials
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	keySize = 32
+
+	envelopeCiphertextDataKey = "ciphertext"
+	envelopeDataKeyDataKey    = "dataKey"
+	fieldManagerName          = "zarf"
+)
+
+// KeyWrapper encrypts and decrypts the data keys of an envelope, in the style of a KMS.
+type KeyWrapper interface {
+	// Wrap encrypts a data key with the key encryption key.
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	// Unwrap decrypts a data key with the key encryption key.
+	Unwrap(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// EnvelopeStore keeps the credentials in a Kubernetes secret encrypted with a per-save data key.
+// The data key is stored alongside the ciphertext after being wrapped by the key encryption key.
+type EnvelopeStore struct {
+	clientset  kubernetes.Interface
+	namespace  string
+	secretName string
+	wrapper    KeyWrapper
+}
+
+// NewEnvelopeStore creates a credential store that writes the encrypted credentials to the named secret.
+func NewEnvelopeStore(clientset kubernetes.Interface, namespace, secretName string, wrapper KeyWrapper) *EnvelopeStore {
+	return &EnvelopeStore{
+		clientset:  clientset,
+		namespace:  namespace,
+		secretName: secretName,
+		wrapper:    wrapper,
+	}
+}
+
+// Load decrypts the credentials from the envelope secret.
+func (e *EnvelopeStore) Load(ctx context.Context) (map[string]string, error) {
+	secret, err := e.clientset.CoreV1().Secrets(e.namespace).Get(ctx, e.secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the encrypted credentials: %w", err)
+	}
+	dataKey, err := e.wrapper.Unwrap(ctx, secret.Data[envelopeDataKeyDataKey])
+	if err != nil {
+		return nil, fmt.Errorf("unable to unwrap the credentials data key: %w", err)
+	}
+	plaintext, err := decrypt(dataKey, secret.Data[envelopeCiphertextDataKey])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the credentials: %w", err)
+	}
+	creds := map[string]string{}
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// Save encrypts the credentials with a new data key and writes them to the envelope secret.
+func (e *EnvelopeStore) Save(ctx context.Context, creds map[string]string) error {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+	ciphertext, err := encrypt(dataKey, plaintext)
+	if err != nil {
+		return err
+	}
+	wrappedKey, err := e.wrapper.Wrap(ctx, dataKey)
+	if err != nil {
+		return fmt.Errorf("unable to wrap the credentials data key: %w", err)
+	}
+	secret := v1ac.Secret(e.secretName, e.namespace).
+		WithType(corev1.SecretTypeOpaque).
+		WithData(map[string][]byte{
+			envelopeCiphertextDataKey: ciphertext,
+			envelopeDataKeyDataKey:    wrappedKey,
+		})
+	_, err = e.clientset.CoreV1().Secrets(e.namespace).Apply(ctx, secret, metav1.ApplyOptions{Force: true, FieldManager: fieldManagerName})
+	if err != nil {
+		return fmt.Errorf("unable to apply the encrypted credentials secret: %w", err)
+	}
+	return nil
+}
+
+// VaultTransitKeyWrapper wraps data keys with a key in a Vault compatible transit secrets engine.
+// The key encryption key never leaves Vault, so the credentials can not be decrypted with access to the cluster alone.
+type VaultTransitKeyWrapper struct {
+	client *vaultClient
+	mount  string
+	key    string
+}
+
+// NewVaultTransitKeyWrapper creates a key wrapper using the named key of the transit engine mounted at mount.
+func NewVaultTransitKeyWrapper(address, mount, key, token string) (*VaultTransitKeyWrapper, error) {
+	client, err := newVaultClient(address, token)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return nil, errors.New("a Vault transit key is required")
+	}
+	if mount == "" {
+		mount = defaultVaultTransitMount
+	}
+	return &VaultTransitKeyWrapper{
+		client: client,
+		mount:  mount,
+		key:    key,
+	}, nil
+}
+
+type vaultTransitRequest struct {
+	Plaintext  string `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+}
+
+type vaultTransitResponse struct {
+	Data vaultTransitRequest `json:"data"`
+}
+
+// Wrap encrypts the data key with the transit key.
+func (v *VaultTransitKeyWrapper) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	resp, err := v.transit(ctx, "encrypt", vaultTransitRequest{Plaintext: base64.StdEncoding.EncodeToString(dataKey)})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Ciphertext == "" {
+		return nil, fmt.Errorf("the Vault transit key %s/%s did not return a ciphertext", v.mount, v.key)
+	}
+	return []byte(resp.Ciphertext), nil
+}
+
+// Unwrap decrypts the data key with the transit key.
+func (v *VaultTransitKeyWrapper) Unwrap(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	resp, err := v.transit(ctx, "decrypt", vaultTransitRequest{Ciphertext: string(wrappedKey)})
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(resp.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the data key decrypted by the Vault transit key %s/%s: %w", v.mount, v.key, err)
+	}
+	return dataKey, nil
+}
+
+func (v *VaultTransitKeyWrapper) transit(ctx context.Context, operation string, req vaultTransitRequest) (vaultTransitRequest, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return vaultTransitRequest{}, err
+	}
+	u := v.client.endpoint.JoinPath("v1", v.mount, operation, v.key)
+	b, statusCode, err := v.client.do(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return vaultTransitRequest{}, err
+	}
+	if statusCode != http.StatusOK {
+		return vaultTransitRequest{}, fmt.Errorf("unable to %s with the Vault transit key %s/%s, status code %d: %s", operation, v.mount, v.key, statusCode, string(b))
+	}
+	var resp vaultTransitResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return vaultTransitRequest{}, fmt.Errorf("unable to decode the Vault transit response: %w", err)
+	}
+	return resp.Data, nil
+}
+
+// encrypt seals the plaintext with AES-256-GCM, prefixing the ciphertext with the nonce.
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decrypt opens a ciphertext created by encrypt.
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
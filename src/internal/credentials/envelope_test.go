@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestTransitServer returns a stand-in for a Vault transit secrets engine with a single key.
+func newTestTransitServer(t *testing.T, token, keyName string) *httptest.Server {
+	t.Helper()
+
+	key := make([]byte, keySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var req vaultTransitRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var resp vaultTransitResponse
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/" + keyName:
+			plaintext, err := base64.StdEncoding.DecodeString(req.Plaintext)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			ciphertext, err := encrypt(key, plaintext)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			resp.Data.Ciphertext = "vault:v1:" + base64.StdEncoding.EncodeToString(ciphertext)
+		case "/v1/transit/decrypt/" + keyName:
+			ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(req.Ciphertext, "vault:v1:"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			plaintext, err := decrypt(key, ciphertext)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			resp.Data.Plaintext = base64.StdEncoding.EncodeToString(plaintext)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		//nolint:errcheck // ignore
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEnvelopeStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cs := fake.NewClientset()
+	srv := newTestTransitServer(t, "root", "zarf")
+	wrapper, err := NewVaultTransitKeyWrapper(srv.URL, "", "zarf", "root")
+	require.NoError(t, err)
+	store := NewEnvelopeStore(cs, "zarf", "creds", wrapper)
+
+	_, err = store.Load(ctx)
+	require.ErrorContains(t, err, "unable to get the encrypted credentials")
+
+	creds := map[string]string{"registry.pushPassword": "super-secret"}
+	require.NoError(t, store.Save(ctx, creds))
+	loaded, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Equal(t, creds, loaded)
+
+	// Neither the credentials nor the data key are stored in plain text
+	secret, err := cs.CoreV1().Secrets("zarf").Get(ctx, "creds", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(secret.Data[envelopeCiphertextDataKey], []byte("super-secret")))
+	require.True(t, bytes.HasPrefix(secret.Data[envelopeDataKeyDataKey], []byte("vault:v1:")))
+
+	// Every save uses a new data key
+	require.NoError(t, store.Save(ctx, creds))
+	resaved, err := cs.CoreV1().Secrets("zarf").Get(ctx, "creds", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEqual(t, secret.Data[envelopeDataKeyDataKey], resaved.Data[envelopeDataKeyDataKey])
+
+	// The credentials can not be decrypted without access to the transit key
+	denied, err := NewVaultTransitKeyWrapper(srv.URL, "", "zarf", "wrong")
+	require.NoError(t, err)
+	_, err = NewEnvelopeStore(cs, "zarf", "creds", denied).Load(ctx)
+	require.ErrorContains(t, err, "unable to unwrap the credentials data key")
+	other, err := NewVaultTransitKeyWrapper(srv.URL, "", "other", "root")
+	require.NoError(t, err)
+	_, err = NewEnvelopeStore(cs, "zarf", "creds", other).Load(ctx)
+	require.ErrorContains(t, err, "unable to decrypt with the Vault transit key transit/other, status code 404")
+}
+
+func TestNewVaultTransitKeyWrapper(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewVaultTransitKeyWrapper("https://vault.example.com", "", "", "root")
+	require.EqualError(t, err, "a Vault transit key is required")
+	_, err = NewVaultTransitKeyWrapper("https://vault.example.com", "", "zarf", "")
+	require.EqualError(t, err, "a Vault token is required")
+	_, err = NewVaultTransitKeyWrapper("vault.example.com", "", "zarf", "root")
+	require.ErrorContains(t, err, "must include a scheme and host")
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	key := bytes.Repeat([]byte{7}, keySize)
+	ciphertext, err := encrypt(key, []byte("plaintext"))
+	require.NoError(t, err)
+	plaintext, err := decrypt(key, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "plaintext", string(plaintext))
+
+	_, err = decrypt(key, []byte("short"))
+	require.EqualError(t, err, "ciphertext is too short")
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = decrypt(key, ciphertext)
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+const (
+	defaultVaultMount        = "secret"
+	defaultVaultPath         = "zarf/state"
+	defaultVaultTransitMount = "transit"
+)
+
+// vaultClient sends authenticated requests to the HTTP API of a Vault server.
+type vaultClient struct {
+	httpClient *http.Client
+	endpoint   *url.URL
+	token      string
+}
+
+func newVaultClient(address, token string) (*vaultClient, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Vault address %q, must include a scheme and host", address)
+	}
+	if token == "" {
+		return nil, errors.New("a Vault token is required")
+	}
+	return &vaultClient{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		endpoint:   u,
+		token:      token,
+	}, nil
+}
+
+// VaultStore keeps the credentials in a Vault compatible KV version 2 secrets engine.
+type VaultStore struct {
+	client *vaultClient
+	mount  string
+	path   string
+}
+
+// NewVaultStore creates a credential store for the secret at path in the KV version 2 engine mounted at mount.
+func NewVaultStore(address, mount, secretPath, token string) (*VaultStore, error) {
+	client, err := newVaultClient(address, token)
+	if err != nil {
+		return nil, err
+	}
+	if mount == "" {
+		mount = defaultVaultMount
+	}
+	if secretPath == "" {
+		secretPath = defaultVaultPath
+	}
+	return &VaultStore{
+		client: client,
+		mount:  mount,
+		path:   secretPath,
+	}, nil
+}
+
+type vaultKVRequest struct {
+	Data map[string]string `json:"data"`
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]string `json:"data"`
+	} `json:"data"`
+}
+
+// Load reads the latest version of the credentials secret.
+func (v *VaultStore) Load(ctx context.Context) (map[string]string, error) {
+	b, statusCode, err := v.client.do(ctx, http.MethodGet, v.secretURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("the Vault secret %s/%s does not exist", v.mount, v.path)
+	}
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to read the Vault secret %s/%s, status code %d: %s", v.mount, v.path, statusCode, string(b))
+	}
+	var resp vaultKVResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, fmt.Errorf("unable to decode the Vault secret %s/%s: %w", v.mount, v.path, err)
+	}
+	return resp.Data.Data, nil
+}
+
+// Save writes the credentials as a new version of the credentials secret.
+func (v *VaultStore) Save(ctx context.Context, creds map[string]string) error {
+	body, err := json.Marshal(vaultKVRequest{Data: creds})
+	if err != nil {
+		return err
+	}
+	b, statusCode, err := v.client.do(ctx, http.MethodPost, v.secretURL(), body)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK && statusCode != http.StatusNoContent {
+		return fmt.Errorf("unable to write the Vault secret %s/%s, status code %d: %s", v.mount, v.path, statusCode, string(b))
+	}
+	return nil
+}
+
+func (v *VaultStore) secretURL() *url.URL {
+	return v.client.endpoint.JoinPath("v1", v.mount, "data", path.Clean(v.path))
+}
+
+func (c *vaultClient) do(ctx context.Context, method string, u *url.URL, body []byte) (_ []byte, _ int, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewBuffer(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		errClose := resp.Body.Close()
+		err = errors.Join(err, errClose)
+	}()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return b, resp.StatusCode, nil
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestVaultServer returns a stand-in for a Vault KV version 2 secrets engine.
+func newTestVaultServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	secrets := map[string]map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := secrets[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			resp := vaultKVResponse{}
+			resp.Data.Data = data
+			//nolint:errcheck // ignore
+			json.NewEncoder(w).Encode(resp)
+		case http.MethodPost:
+			var req vaultKVRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			secrets[r.URL.Path] = req.Data
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVaultStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	srv := newTestVaultServer(t, "root")
+
+	store, err := NewVaultStore(srv.URL, "", "", "root")
+	require.NoError(t, err)
+	_, err = store.Load(ctx)
+	require.ErrorContains(t, err, "the Vault secret secret/zarf/state does not exist")
+
+	creds := map[string]string{"registry.pushPassword": "push", "git.pullPassword": "pull"}
+	require.NoError(t, store.Save(ctx, creds))
+	loaded, err := store.Load(ctx)
+	require.NoError(t, err)
+	require.Equal(t, creds, loaded)
+
+	// Secrets at other paths are kept separately
+	other, err := NewVaultStore(srv.URL, "kv", "clusters/edge", "root")
+	require.NoError(t, err)
+	_, err = other.Load(ctx)
+	require.Error(t, err)
+
+	denied, err := NewVaultStore(srv.URL, "", "", "wrong")
+	require.NoError(t, err)
+	_, err = denied.Load(ctx)
+	require.ErrorContains(t, err, "status code 403")
+	err = denied.Save(ctx, creds)
+	require.ErrorContains(t, err, "status code 403")
+}
+
+func TestNewVaultStore(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewVaultStore("vault.example.com", "", "", "root")
+	require.ErrorContains(t, err, "must include a scheme and host")
+	_, err = NewVaultStore("https://vault.example.com", "", "", "")
+	require.EqualError(t, err, "a Vault token is required")
+	store, err := NewVaultStore("https://vault.example.com:8200", "", "", "root")
+	require.NoError(t, err)
+	require.Equal(t, defaultVaultMount, store.mount)
+	require.Equal(t, defaultVaultPath, store.path)
+}
@@ -229,10 +229,10 @@ func init() {
 		},
 		{
 			Name:        RegistryProxy,
-			Description: "Enables the registry proxy feature during Zarf init",
-			Enabled:     false,
+			Description: "Enables the registry proxy mode during Zarf init, the proxy connects to the registry over mTLS and falls back to nodeport mode when it is unavailable",
+			Enabled:     true,
 			Since:       "v0.65.0",
-			Stage:       Alpha,
+			Stage:       Beta,
 		},
 		{
 			Name: Values,
@@ -0,0 +1,264 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/utils/exec"
+)
+
+const lfsMediaType = "application/vnd.git-lfs+json"
+
+// lfsBatchSize is the maximum number of objects sent in a single batch request.
+const lfsBatchSize = 100
+
+// lfsTimeout is the time limit of a single request to the LFS server, including the upload of an object.
+const lfsTimeout = 10 * time.Minute
+
+var lfsOIDRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+type lfsObject struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+type lfsBatchRequest struct {
+	Operation string      `json:"operation"`
+	Transfers []string    `json:"transfers"`
+	Objects   []lfsObject `json:"objects"`
+}
+
+type lfsBatchResponse struct {
+	Objects []struct {
+		lfsObject
+		Actions map[string]lfsAction `json:"actions,omitempty"`
+		Error   *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+	} `json:"objects"`
+}
+
+// usesLFS returns true if the attributes at the root of the worktree store files in Git LFS.
+func usesLFS(worktreePath string) (bool, error) {
+	b, err := os.ReadFile(filepath.Join(worktreePath, ".gitattributes"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(b), "filter=lfs"), nil
+}
+
+// fetchLFS fetches the Git LFS objects of every ref in the repository at the worktree path using the host 'git lfs'.
+func fetchLFS(ctx context.Context, worktreePath, remoteName string) error {
+	ok, err := usesLFS(worktreePath)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	logger.From(ctx).Debug("fetching Git LFS objects", "path", worktreePath)
+	execCfg := exec.Config{
+		Dir: worktreePath,
+	}
+	_, _, err = exec.CmdWithContext(ctx, execCfg, "git", "lfs", "fetch", "--all", remoteName)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the Git LFS objects, ensure git-lfs is installed: %w", err)
+	}
+	return nil
+}
+
+// lfsObjects returns the Git LFS objects stored in the git directory.
+func lfsObjects(gitDir string) ([]lfsObject, error) {
+	objects := []lfsObject{}
+	root := filepath.Join(gitDir, "lfs", "objects")
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !lfsOIDRegex.MatchString(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, lfsObject{OID: d.Name(), Size: info.Size()})
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func lfsObjectPath(gitDir, oid string) string {
+	return filepath.Join(gitDir, "lfs", "objects", oid[0:2], oid[2:4], oid)
+}
+
+// newLFSClient returns the client for the LFS API of the git server. It uses the default transport like the git push so that
+// certificates and proxies are handled the same way for both.
+func newLFSClient() *http.Client {
+	return &http.Client{
+		Transport: http.DefaultTransport,
+		Timeout:   lfsTimeout,
+	}
+}
+
+// pushLFS uploads the Git LFS objects stored in the git directory to the LFS server of the target repository.
+func pushLFS(ctx context.Context, client *http.Client, gitDir string, targetURL *url.URL, username, password string) error {
+	objects, err := lfsObjects(gitDir)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	logger.From(ctx).Debug("pushing Git LFS objects", "url", targetURL.String(), "count", len(objects))
+
+	repoPath := targetURL.Path
+	if !strings.HasSuffix(repoPath, ".git") {
+		repoPath += ".git"
+	}
+	batchURL := *targetURL
+	batchURL.Path = repoPath + "/info/lfs/objects/batch"
+	for i := 0; i < len(objects); i += lfsBatchSize {
+		batch := objects[i:min(i+lfsBatchSize, len(objects))]
+		resp, err := lfsBatch(ctx, client, batchURL.String(), batch, username, password)
+		if err != nil {
+			return err
+		}
+		for _, obj := range resp.Objects {
+			if obj.Error != nil {
+				return fmt.Errorf("unable to upload the Git LFS object %s: %s", obj.OID, obj.Error.Message)
+			}
+			// Objects without an upload action are already present on the server
+			upload, ok := obj.Actions["upload"]
+			if !ok {
+				continue
+			}
+			err := lfsUpload(ctx, client, localizeAction(upload, targetURL, repoPath), lfsObjectPath(gitDir, obj.OID), obj.Size, username, password)
+			if err != nil {
+				return fmt.Errorf("unable to upload the Git LFS object %s: %w", obj.OID, err)
+			}
+			verify, ok := obj.Actions["verify"]
+			if !ok {
+				continue
+			}
+			b, err := json.Marshal(obj.lfsObject)
+			if err != nil {
+				return err
+			}
+			_, err = lfsRequest(ctx, client, http.MethodPost, localizeAction(verify, targetURL, repoPath), bytes.NewReader(b), int64(len(b)), lfsMediaType, username, password)
+			if err != nil {
+				return fmt.Errorf("unable to verify the Git LFS object %s: %w", obj.OID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// localizeAction points actions on the target repository at the address the repository is pushed to,
+// servers like Gitea return hrefs using their external URL which is not reachable through a tunnel.
+func localizeAction(action lfsAction, targetURL *url.URL, repoPath string) lfsAction {
+	href, err := url.Parse(action.Href)
+	if err != nil || !strings.HasPrefix(href.Path, repoPath+"/") {
+		return action
+	}
+	href.Scheme = targetURL.Scheme
+	href.Host = targetURL.Host
+	action.Href = href.String()
+	return action
+}
+
+func lfsBatch(ctx context.Context, client *http.Client, batchURL string, objects []lfsObject, username, password string) (*lfsBatchResponse, error) {
+	b, err := json.Marshal(lfsBatchRequest{
+		Operation: "upload",
+		Transfers: []string{"basic"},
+		Objects:   objects,
+	})
+	if err != nil {
+		return nil, err
+	}
+	body, err := lfsRequest(ctx, client, http.MethodPost, lfsAction{Href: batchURL}, bytes.NewReader(b), int64(len(b)), lfsMediaType, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to request the Git LFS upload batch: %w", err)
+	}
+	resp := &lfsBatchResponse{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("unable to parse the Git LFS batch response: %w", err)
+	}
+	return resp, nil
+}
+
+func lfsUpload(ctx context.Context, client *http.Client, action lfsAction, path string, size int64, username, password string) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	// The file is closed here rather than by the client
+	_, err = lfsRequest(ctx, client, http.MethodPut, action, io.NopCloser(f), size, "application/octet-stream", username, password)
+	return err
+}
+
+func lfsRequest(ctx context.Context, client *http.Client, method string, action lfsAction, body io.Reader, size int64, contentType, username, password string) (_ []byte, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, action.Href, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+	// Actions may carry their own authorization
+	if req.Header.Get("Authorization") == "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, resp.Body.Close())
+	}()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s returned status code %d: %s", method, req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
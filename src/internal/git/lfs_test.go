@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zarf-dev/zarf/src/test/testutil"
+)
+
+func TestPushLFS(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.TestContext(t)
+
+	gitDir := t.TempDir()
+	content := []byte("Hello LFS")
+	sum := sha256.Sum256(content)
+	oid := hex.EncodeToString(sum[:])
+	objectPath := lfsObjectPath(gitDir, oid)
+	require.NoError(t, os.MkdirAll(filepath.Dir(objectPath), 0o755))
+	require.NoError(t, os.WriteFile(objectPath, content, 0o644))
+	existing := "0000000000000000000000000000000000000000000000000000000000000000"
+	existingPath := lfsObjectPath(gitDir, existing)
+	require.NoError(t, os.MkdirAll(filepath.Dir(existingPath), 0o755))
+	require.NoError(t, os.WriteFile(existingPath, []byte("present"), 0o644))
+
+	var mu sync.Mutex
+	uploaded := map[string][]byte{}
+	verified := []string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /zarf-git-user/repo.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "zarf-git-user" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		req := lfsBatchRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp := map[string]any{}
+		objects := []map[string]any{}
+		for _, obj := range req.Objects {
+			o := map[string]any{"oid": obj.OID, "size": obj.Size}
+			if obj.OID != existing {
+				// Hrefs use the external URL of the server
+				href := "http://gitea.example.com/zarf-git-user/repo.git/info/lfs/objects/" + obj.OID
+				o["actions"] = map[string]any{
+					"upload": map[string]any{"href": href, "header": map[string]string{"X-Upload": "true"}},
+					"verify": map[string]any{"href": href + "/verify"},
+				}
+			}
+			objects = append(objects, o)
+		}
+		resp["objects"] = objects
+		w.Header().Set("Content-Type", lfsMediaType)
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+	mux.HandleFunc("PUT /zarf-git-user/repo.git/info/lfs/objects/{oid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Upload") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		uploaded[r.PathValue("oid")] = b
+	})
+	mux.HandleFunc("POST /zarf-git-user/repo.git/info/lfs/objects/{oid}/verify", func(_ http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		verified = append(verified, r.PathValue("oid"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	targetURL, err := url.Parse(srv.URL + "/zarf-git-user/repo")
+	require.NoError(t, err)
+	err = pushLFS(ctx, srv.Client(), gitDir, targetURL, "zarf-git-user", "password")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{oid: content}, uploaded)
+	require.Equal(t, []string{oid}, verified)
+
+	// Repositories without LFS objects are skipped
+	err = pushLFS(ctx, srv.Client(), t.TempDir(), targetURL, "zarf-git-user", "password")
+	require.NoError(t, err)
+
+	err = pushLFS(ctx, srv.Client(), gitDir, targetURL, "zarf-git-user", "wrong")
+	require.ErrorContains(t, err, "returned status code 401")
+}
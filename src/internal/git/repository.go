@@ -124,6 +124,10 @@ func Clone(ctx context.Context, rootPath, address string, shallow bool) (*Reposi
 		if err != nil {
 			return nil, err
 		}
+		err = r.fetchSubmodulesAndLFS(ctx, nil, shallow)
+		if err != nil {
+			return nil, err
+		}
 		return r, nil
 	}
 
@@ -147,9 +151,41 @@ func Clone(ctx context.Context, rootPath, address string, shallow bool) (*Reposi
 		return nil, err
 	}
 
+	err = r.fetchSubmodulesAndLFS(ctx, cloneOpts.Auth, shallow)
+	if err != nil {
+		return nil, err
+	}
+
 	return r, nil
 }
 
+// fetchSubmodulesAndLFS fetches the submodules of the checked out commit and the Git LFS objects of the repository and its submodules.
+func (r *Repository) fetchSubmodulesAndLFS(ctx context.Context, auth transport.AuthMethod, shallow bool) error {
+	err := r.updateSubmodules(ctx, auth, shallow)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the submodules: %w", err)
+	}
+	err = fetchLFS(ctx, r.path, onlineRemoteName)
+	if err != nil {
+		return err
+	}
+	repo, err := git.PlainOpen(r.path)
+	if err != nil {
+		return fmt.Errorf("not a valid git repo or unable to open: %w", err)
+	}
+	subs, err := walkSubmodules(repo, r.path, filepath.Join(r.path, git.GitDirName))
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		err := fetchLFS(ctx, sub.path, git.DefaultRemoteName)
+		if err != nil {
+			return fmt.Errorf("unable to fetch the Git LFS objects of the submodule %s: %w", sub.url, err)
+		}
+	}
+	return nil
+}
+
 // Repository manages a local git repository.
 type Repository struct {
 	path string
@@ -160,23 +196,41 @@ func (r *Repository) Path() string {
 	return r.path
 }
 
-// Push pushes the repository to the remote git server.
-func (r *Repository) Push(ctx context.Context, address, username, password string) error {
-	l := logger.From(ctx)
+// Push pushes the repository, its submodules and their Git LFS objects to the remote git server under the names given by the repo transform rules.
+func (r *Repository) Push(ctx context.Context, address, username, password string, rules transform.RepoRules) error {
 	repo, err := git.PlainOpen(r.path)
 	if err != nil {
 		return fmt.Errorf("not a valid git repo or unable to open: %w", err)
 	}
 
+	// Push the submodules first so that they are available once the repository that references them is
+	gitDir := filepath.Join(r.path, git.GitDirName)
+	subs, err := walkSubmodules(repo, r.path, gitDir)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		err := pushRepository(ctx, sub.repo, sub.gitDir, git.DefaultRemoteName, address, username, password, rules)
+		if err != nil {
+			return fmt.Errorf("unable to push the submodule %s: %w", sub.url, err)
+		}
+	}
+
+	return pushRepository(ctx, repo, gitDir, onlineRemoteName, address, username, password, rules)
+}
+
+func pushRepository(ctx context.Context, repo *git.Repository, gitDir, remoteName, address, username, password string, rules transform.RepoRules) error {
+	l := logger.From(ctx)
+
 	// Configure new remote
-	remote, err := repo.Remote(onlineRemoteName)
+	remote, err := repo.Remote(remoteName)
 	if err != nil {
 		return fmt.Errorf("unable to find the git remote: %w", err)
 	}
 	if len(remote.Config().URLs) == 0 {
 		return fmt.Errorf("repository has zero remotes configured")
 	}
-	targetURL, err := transform.GitURL(address, remote.Config().URLs[0], username)
+	targetURL, err := transform.GitURLWithRules(address, remote.Config().URLs[0], username, rules)
 	if err != nil {
 		return fmt.Errorf("unable to transform the git url: %w", err)
 	}
@@ -210,7 +264,7 @@ func (r *Repository) Push(ctx context.Context, address, username, password strin
 		},
 	}
 	err = repo.FetchContext(ctx, fetchOptions)
-	if errors.Is(err, transport.ErrRepositoryNotFound) {
+	if errors.Is(err, transport.ErrRepositoryNotFound) || errors.Is(err, transport.ErrEmptyRemoteRepository) {
 		l.Debug("repo not yet available offline, skipping fetch")
 	} else if errors.Is(err, git.ErrForceNeeded) {
 		l.Debug("repo fetch requires force, skipping fetch")
@@ -220,6 +274,23 @@ func (r *Repository) Push(ctx context.Context, address, username, password strin
 		return fmt.Errorf("unable to fetch the git repo prior to push: %w", err)
 	}
 
+	// Track the offline branches so that only branches at a previous submodule rewrite are force pushed
+	trackOptions := &git.FetchOptions{
+		RemoteName: offlineRemoteName,
+		Auth:       &gitCred,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", offlineRemoteName)),
+		},
+	}
+	err = repo.FetchContext(ctx, trackOptions)
+	if err != nil && !errors.Is(err, transport.ErrRepositoryNotFound) && !errors.Is(err, transport.ErrEmptyRemoteRepository) && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("unable to fetch the branches of the git repo prior to push: %w", err)
+	}
+
+	refSpecs, err := pushRefSpecs(repo)
+	if err != nil {
+		return err
+	}
 	// Push all heads and tags to the offline remote
 	err = repo.PushContext(ctx, &git.PushOptions{
 		RemoteName: offlineRemoteName,
@@ -227,10 +298,7 @@ func (r *Repository) Push(ctx context.Context, address, username, password strin
 		// TODO: (@JEFFMCCOY) add the parsing for the `+` force prefix (see https://github.com/zarf-dev/zarf/issues/1410)
 		//Force: isForce,
 		// If a provided refspec doesn't push anything, it is just ignored
-		RefSpecs: []config.RefSpec{
-			"refs/heads/*:refs/heads/*",
-			"refs/tags/*:refs/tags/*",
-		},
+		RefSpecs: refSpecs,
 	})
 	if errors.Is(err, git.NoErrAlreadyUpToDate) {
 		l.Debug("repo already up-to-date")
@@ -240,8 +308,86 @@ func (r *Repository) Push(ctx context.Context, address, username, password strin
 		return fmt.Errorf("unable to push repo to the gitops service: %s", err.Error())
 	}
 
+	err = pushLFS(ctx, newLFSClient(), gitDir, targetURL, username, password)
+	if err != nil {
+		return fmt.Errorf("unable to push the Git LFS objects to the gitops service: %w", err)
+	}
+
 	return nil
 }
+
+// pushRefSpecs returns the refspecs that push all heads and tags. Branches that end with a submodule rewrite are force pushed
+// when the offline branch is at the rewrite of a previous push, as that rewrite commit is not an ancestor of the new one.
+// Any other divergence from the offline branch fails the push.
+func pushRefSpecs(repo *git.Repository) ([]config.RefSpec, error) {
+	refSpecs := []config.RefSpec{"refs/tags/*:refs/tags/*"}
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+	defer branches.Close()
+	err = branches.ForEach(func(ref *plumbing.Reference) error {
+		refSpec := config.RefSpec(fmt.Sprintf("%s:%s", ref.Name(), ref.Name()))
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return fmt.Errorf("unable to find the commit of the branch %s: %w", ref.Name().Short(), err)
+		}
+		if commit.Message != submoduleRewriteMessage {
+			refSpecs = append(refSpecs, refSpec)
+			return nil
+		}
+		offlineRef, err := repo.Reference(plumbing.NewRemoteReferenceName(offlineRemoteName, ref.Name().Short()), true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			refSpecs = append(refSpecs, refSpec)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		replace, err := replacesRewrite(repo, commit, offlineRef.Hash())
+		if err != nil {
+			return fmt.Errorf("unable to compare the branch %s with the git server: %w", ref.Name().Short(), err)
+		}
+		if replace {
+			refSpec = "+" + refSpec
+		}
+		refSpecs = append(refSpecs, refSpec)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refSpecs, nil
+}
+
+// replacesRewrite returns true if the offline commit is a submodule rewrite of an ancestor of the commit that rewrites the branch.
+func replacesRewrite(repo *git.Repository, rewrite *object.Commit, offlineHash plumbing.Hash) (bool, error) {
+	offline, err := repo.CommitObject(offlineHash)
+	if err != nil {
+		return false, err
+	}
+	if offline.Message != submoduleRewriteMessage || offline.NumParents() != 1 || rewrite.NumParents() != 1 {
+		return false, nil
+	}
+	offlineParent, err := offline.Parent(0)
+	if err != nil {
+		return false, err
+	}
+	parent, err := rewrite.Parent(0)
+	if err != nil {
+		return false, err
+	}
+	isAncestor, err := offlineParent.IsAncestor(parent)
+	// The history of shallow clones ends before the previous push, so it cannot be compared
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return isAncestor, nil
+}
+
 func (r *Repository) checkoutRefAsBranch(ref string, branch plumbing.ReferenceName) error {
 	repo, err := git.PlainOpen(r.path)
 	if err != nil {
@@ -4,7 +4,10 @@
 package git
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -16,12 +19,15 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/stretchr/testify/require"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
 	"github.com/zarf-dev/zarf/src/test/testutil"
 )
 
@@ -95,3 +101,215 @@ func TestRepository(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, filepath.Join(rootPath, expectedPath), repo.Path())
 }
+
+func TestRepositorySubmodules(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.TestContext(t)
+
+	newGitServer := func(t *testing.T) (string, string) {
+		t.Helper()
+		cfg := gitkit.Config{
+			Dir:        t.TempDir(),
+			AutoCreate: true,
+		}
+		gitSrv := gitkit.New(cfg)
+		err := gitSrv.Setup()
+		require.NoError(t, err)
+		srv := httptest.NewServer(http.HandlerFunc(gitSrv.ServeHTTP))
+		t.Cleanup(func() {
+			srv.Close()
+		})
+		return srv.URL, cfg.Dir
+	}
+	// pushRepo commits the files to a new repository and pushes it to the address.
+	pushRepo := func(t *testing.T, dir, address string, files map[string]string, gitlinks map[string]plumbing.Hash) plumbing.Hash {
+		t.Helper()
+		fs := memfs.New()
+		initRepo, err := git.InitWithOptions(memory.NewStorage(), fs, git.InitOptions{DefaultBranch: plumbing.Main})
+		require.NoError(t, err)
+		w, err := initRepo.Worktree()
+		require.NoError(t, err)
+		for name, content := range files {
+			f, err := fs.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(content))
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+			_, err = w.Add(name)
+			require.NoError(t, err)
+		}
+		idx, err := initRepo.Storer.Index()
+		require.NoError(t, err)
+		for path, hash := range gitlinks {
+			e := idx.Add(path)
+			e.Hash = hash
+			e.Mode = filemode.Submodule
+		}
+		require.NoError(t, initRepo.Storer.SetIndex(idx))
+		hash, err := w.Commit("Initial commit", &git.CommitOptions{
+			Author: &object.Signature{
+				Email: "example@example.com",
+			},
+		})
+		require.NoError(t, err)
+		_, err = initRepo.CreateTag("v1.0.0", hash, &git.CreateTagOptions{
+			Tagger: &object.Signature{
+				Email: "example@example.com",
+			},
+			Message: "Release v1.0.0",
+		})
+		require.NoError(t, err)
+		_, err = initRepo.CreateTag("v0.1.0", hash, nil)
+		require.NoError(t, err)
+		_, err = initRepo.CreateRemote(&config.RemoteConfig{
+			Name: "origin",
+			URLs: []string{address},
+		})
+		require.NoError(t, err)
+		require.NoError(t, initRepo.Push(&git.PushOptions{
+			RemoteName: "origin",
+			RefSpecs:   []config.RefSpec{"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*"},
+		}))
+		headFile := filepath.Join(dir, filepath.Base(address), "HEAD")
+		require.NoError(t, os.WriteFile(headFile, []byte("ref: refs/heads/main\n"), 0644))
+		return hash
+	}
+
+	// commitOnTop commits the file on top of the main branch of the repository at the address.
+	commitOnTop := func(t *testing.T, address, name, content string) plumbing.Hash {
+		t.Helper()
+		fs := memfs.New()
+		cloneRepo, err := git.Clone(memory.NewStorage(), fs, &git.CloneOptions{URL: address, ReferenceName: plumbing.Main})
+		require.NoError(t, err)
+		w, err := cloneRepo.Worktree()
+		require.NoError(t, err)
+		f, err := fs.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		_, err = w.Add(name)
+		require.NoError(t, err)
+		hash, err := w.Commit("Update "+name, &git.CommitOptions{
+			Author: &object.Signature{
+				Email: "example@example.com",
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, cloneRepo.Push(&git.PushOptions{}))
+		return hash
+	}
+
+	upstreamURL, upstreamDir := newGitServer(t)
+	subAddress := fmt.Sprintf("%s/sub.git", upstreamURL)
+	subHash := pushRepo(t, upstreamDir, subAddress, map[string]string{"sub.txt": "Hello Submodule"}, nil)
+	parentAddress := fmt.Sprintf("%s/parent.git", upstreamURL)
+	gitModules := "[submodule \"sub\"]\n\tpath = sub\n\turl = ../sub.git\n"
+	pushRepo(t, upstreamDir, parentAddress, map[string]string{".gitmodules": gitModules, "test.txt": "Hello World"}, map[string]plumbing.Hash{"sub": subHash})
+
+	// The submodule is fetched and checked out with the repository
+	repo, err := Clone(ctx, t.TempDir(), parentAddress, false)
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(repo.Path(), "sub", "sub.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "Hello Submodule", string(b))
+	subURLs, err := repo.SubmoduleURLs()
+	require.NoError(t, err)
+	require.Equal(t, []string{subAddress}, subURLs)
+
+	airgapURL, airgapDir := newGitServer(t)
+	username := "zarf-git-user"
+	require.NoError(t, repo.RewriteSubmoduleURLs(ctx, airgapURL, username, nil))
+	// Rewriting again does not change the branch
+	require.NoError(t, repo.RewriteSubmoduleURLs(ctx, airgapURL, username, nil))
+	require.NoError(t, repo.Push(ctx, airgapURL, username, "", nil))
+
+	subName, err := transform.GitURLtoRepoName(subAddress)
+	require.NoError(t, err)
+	airgapSub, err := git.PlainOpen(filepath.Join(airgapDir, username, subName+".git"))
+	require.NoError(t, err)
+	_, err = airgapSub.CommitObject(subHash)
+	require.NoError(t, err)
+
+	parentName, err := transform.GitURLtoRepoName(parentAddress)
+	require.NoError(t, err)
+	airgapParent, err := git.PlainOpen(filepath.Join(airgapDir, username, parentName+".git"))
+	require.NoError(t, err)
+	ref, err := airgapParent.Reference(plumbing.NewBranchReferenceName("main"), true)
+	require.NoError(t, err)
+	commit, err := airgapParent.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	require.Equal(t, submoduleRewriteMessage, commit.Message)
+	file, err := commit.File(".gitmodules")
+	require.NoError(t, err)
+	content, err := file.Contents()
+	require.NoError(t, err)
+	require.Contains(t, content, fmt.Sprintf("url = %s/%s/%s.git", airgapURL, username, subName))
+
+	// Annotated and lightweight tags point at the same rewritten commit as the branch
+	tagRef, err := airgapParent.Tag("v1.0.0")
+	require.NoError(t, err)
+	tag, err := airgapParent.TagObject(tagRef.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "Release v1.0.0\n", tag.Message)
+	require.Equal(t, commit.Hash, tag.Target)
+	tagRef, err = airgapParent.Tag("v0.1.0")
+	require.NoError(t, err)
+	require.Equal(t, commit.Hash, tagRef.Hash())
+
+	// Pushing again leaves the rewritten branch as is
+	require.NoError(t, repo.Push(ctx, airgapURL, username, "", nil))
+
+	// Pushing a new upstream commit replaces the previous rewrite
+	upstreamHash := commitOnTop(t, parentAddress, "update.txt", "Hello Update")
+	repo, err = Clone(ctx, t.TempDir(), parentAddress, false)
+	require.NoError(t, err)
+	require.NoError(t, repo.RewriteSubmoduleURLs(ctx, airgapURL, username, nil))
+	require.NoError(t, repo.Push(ctx, airgapURL, username, "", nil))
+	ref, err = airgapParent.Reference(plumbing.NewBranchReferenceName("main"), true)
+	require.NoError(t, err)
+	commit, err = airgapParent.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	require.Equal(t, submoduleRewriteMessage, commit.Message)
+	require.Equal(t, []plumbing.Hash{upstreamHash}, commit.ParentHashes)
+
+	// Branches that diverged on the git server are not overwritten
+	airgapHash := commitOnTop(t, fmt.Sprintf("%s/%s/%s.git", airgapURL, username, parentName), "airgap.txt", "Hello Airgap")
+	commitOnTop(t, parentAddress, "update.txt", "Hello Again")
+	repo, err = Clone(ctx, t.TempDir(), parentAddress, false)
+	require.NoError(t, err)
+	require.NoError(t, repo.RewriteSubmoduleURLs(ctx, airgapURL, username, nil))
+	err = repo.Push(ctx, airgapURL, username, "", nil)
+	require.ErrorContains(t, err, "non-fast-forward")
+	ref, err = airgapParent.Reference(plumbing.NewBranchReferenceName("main"), true)
+	require.NoError(t, err)
+	require.Equal(t, airgapHash, ref.Hash())
+}
+
+func TestRetargetTag(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	ctx := logger.WithContext(context.Background(), slog.New(slog.NewTextHandler(&buf, nil)))
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+	tag := &object.Tag{
+		Name:         "v1.0.0",
+		Tagger:       object.Signature{Name: "Zarf", Email: "zarf@example.com"},
+		Message:      "Release v1.0.0\n",
+		TargetType:   plumbing.CommitObject,
+		Target:       plumbing.NewHash("1111111111111111111111111111111111111111"),
+		PGPSignature: "-----BEGIN PGP SIGNATURE-----\n\n-----END PGP SIGNATURE-----\n",
+	}
+	commit := plumbing.NewHash("2222222222222222222222222222222222222222")
+
+	hash, err := retargetTag(ctx, repo, tag, commit)
+	require.NoError(t, err)
+	newTag, err := repo.TagObject(hash)
+	require.NoError(t, err)
+	require.Equal(t, tag.Name, newTag.Name)
+	require.Equal(t, tag.Message, newTag.Message)
+	require.Equal(t, commit, newTag.Target)
+	require.Empty(t, newTag.PGPSignature)
+	require.Contains(t, buf.String(), "tag=v1.0.0")
+}
@@ -0,0 +1,405 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	"github.com/zarf-dev/zarf/src/pkg/utils/exec"
+)
+
+const gitModulesFile = ".gitmodules"
+
+// submoduleRewriteMessage is the message of the commits that point the submodules of a branch at the Zarf git server.
+const submoduleRewriteMessage = "Rewrite submodule URLs for the Zarf git server"
+
+// submodule is an initialized submodule of a repository.
+type submodule struct {
+	repo *git.Repository
+	// url is the resolved URL of the submodule's upstream repository
+	url string
+	// path is the path of the submodule's worktree
+	path string
+	// gitDir is the path of the submodule's git directory
+	gitDir string
+}
+
+// walkSubmodules returns the initialized submodules of the repository, including nested submodules.
+func walkSubmodules(repo *git.Repository, worktreePath, gitDir string) ([]submodule, error) {
+	w, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the git repo: %w", err)
+	}
+	subs, err := w.Submodules()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the submodules: %w", err)
+	}
+	out := []submodule{}
+	for _, s := range subs {
+		// Only submodules that were fetched at create time are initialized
+		subRepo, err := s.Repository()
+		if errors.Is(err, git.ErrSubmoduleNotInitialized) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to open the submodule %s: %w", s.Config().Name, err)
+		}
+		remote, err := subRepo.Remote(git.DefaultRemoteName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the git remote of the submodule %s: %w", s.Config().Name, err)
+		}
+		if len(remote.Config().URLs) == 0 {
+			return nil, fmt.Errorf("submodule %s has zero remotes configured", s.Config().Name)
+		}
+		sub := submodule{
+			repo:   subRepo,
+			url:    remote.Config().URLs[0],
+			path:   filepath.Join(worktreePath, s.Config().Path),
+			gitDir: filepath.Join(gitDir, "modules", s.Config().Name),
+		}
+		nested, err := walkSubmodules(sub.repo, sub.path, sub.gitDir)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sub)
+		out = append(out, nested...)
+	}
+	return out, nil
+}
+
+// updateSubmodules fetches the submodules of the checked out commit, including nested submodules.
+func (r *Repository) updateSubmodules(ctx context.Context, auth transport.AuthMethod, shallow bool) error {
+	repo, err := git.PlainOpen(r.path)
+	if err != nil {
+		return fmt.Errorf("not a valid git repo or unable to open: %w", err)
+	}
+	updateOpts := &git.SubmoduleUpdateOptions{
+		Init:              true,
+		RecurseSubmodules: git.NoRecurseSubmodules,
+		Auth:              auth,
+	}
+	if shallow {
+		updateOpts.Depth = 1
+	}
+	err = updateSubmodules(ctx, repo, onlineRemoteName, updateOpts, git.DefaultSubmoduleRecursionDepth)
+	if err != nil {
+		logger.From(ctx).Info("falling back to host 'git', failed to update the submodules with Zarf", "path", r.path, "error", err)
+		err := r.gitSubmoduleUpdateFallback(ctx, shallow)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Submodules are checked out at a detached commit, create a branch for it so that it is pushed to the git server.
+	subs, err := walkSubmodules(repo, r.path, filepath.Join(r.path, git.GitDirName))
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		head, err := sub.repo.Head()
+		if err != nil {
+			return fmt.Errorf("unable to find the checked out commit of the submodule %s: %w", sub.url, err)
+		}
+		branch := plumbing.NewBranchReferenceName(fmt.Sprintf("zarf-ref-%s", head.Hash()))
+		err = sub.repo.Storer.SetReference(plumbing.NewHashReference(branch, head.Hash()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateSubmodules updates the submodules of the repository one level at a time as go-git resolves relative submodule URLs as local paths.
+func updateSubmodules(ctx context.Context, repo *git.Repository, remoteName string, updateOpts *git.SubmoduleUpdateOptions, depth git.SubmoduleRescursivity) error {
+	if depth == git.NoRecurseSubmodules {
+		return nil
+	}
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return fmt.Errorf("unable to find the git remote: %w", err)
+	}
+	if len(remote.Config().URLs) == 0 {
+		return fmt.Errorf("repository has zero remotes configured")
+	}
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("unable to load the git repo: %w", err)
+	}
+	subs, err := w.Submodules()
+	if err != nil {
+		return fmt.Errorf("unable to load the submodules: %w", err)
+	}
+	for _, s := range subs {
+		s.Config().URL, err = resolveSubmoduleURL(remote.Config().URLs[0], s.Config().URL)
+		if err != nil {
+			return err
+		}
+		err = s.UpdateContext(ctx, updateOpts)
+		if err != nil {
+			return fmt.Errorf("unable to update the submodule %s: %w", s.Config().Name, err)
+		}
+		subRepo, err := s.Repository()
+		if err != nil {
+			return err
+		}
+		err = updateSubmodules(ctx, subRepo, git.DefaultRemoteName, updateOpts, depth-1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gitSubmoduleUpdateFallback is a fallback if go-git fails to update the submodules of a repo.
+func (r *Repository) gitSubmoduleUpdateFallback(ctx context.Context, shallow bool) error {
+	updateArgs := []string{"submodule", "update", "--init", "--recursive"}
+	if shallow {
+		updateArgs = append(updateArgs, "--depth", "1")
+	}
+	updateExecConfig := exec.Config{
+		Dir: r.path,
+	}
+	_, _, err := exec.CmdWithContext(ctx, updateExecConfig, "git", updateArgs...)
+	return err
+}
+
+// SubmoduleURLs returns the upstream URLs of the submodules that were fetched with the repository.
+func (r *Repository) SubmoduleURLs() ([]string, error) {
+	repo, err := git.PlainOpen(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid git repo or unable to open: %w", err)
+	}
+	subs, err := walkSubmodules(repo, r.path, filepath.Join(r.path, git.GitDirName))
+	if err != nil {
+		return nil, err
+	}
+	urls := []string{}
+	for _, sub := range subs {
+		urls = append(urls, sub.url)
+	}
+	return urls, nil
+}
+
+// RewriteSubmoduleURLs points the submodules of every branch and tag at their repositories on the git server at the given address.
+// Refs whose .gitmodules changes get a new commit on top so that GitOps tools can fetch the submodules in the air gap,
+// the commit is deterministic so that pushing the same repository again does not change the ref.
+func (r *Repository) RewriteSubmoduleURLs(ctx context.Context, address, username string, rules transform.RepoRules) error {
+	repo, err := git.PlainOpen(r.path)
+	if err != nil {
+		return fmt.Errorf("not a valid git repo or unable to open: %w", err)
+	}
+	remote, err := repo.Remote(onlineRemoteName)
+	if err != nil {
+		return fmt.Errorf("unable to find the git remote: %w", err)
+	}
+	if len(remote.Config().URLs) == 0 {
+		return fmt.Errorf("repository has zero remotes configured")
+	}
+	sourceURL := remote.Config().URLs[0]
+
+	refs, err := repo.References()
+	if err != nil {
+		return err
+	}
+	defer refs.Close()
+	return refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || (!ref.Name().IsBranch() && !ref.Name().IsTag()) {
+			return nil
+		}
+		err := rewriteRefSubmodules(ctx, repo, ref, sourceURL, address, username, rules)
+		if err != nil {
+			return fmt.Errorf("unable to rewrite the submodules of %s: %w", ref.Name().Short(), err)
+		}
+		return nil
+	})
+}
+
+// rewriteRefSubmodules commits the rewritten .gitmodules on top of the commit of a branch or tag and moves the ref to it.
+func rewriteRefSubmodules(ctx context.Context, repo *git.Repository, ref *plumbing.Reference, sourceURL, address, username string, rules transform.RepoRules) error {
+	// Annotated tags point at a tag object rather than a commit
+	tag, err := repo.TagObject(ref.Hash())
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	var commit *object.Commit
+	if tag != nil {
+		commit, err = tag.Commit()
+		if errors.Is(err, object.ErrUnsupportedObject) {
+			return nil
+		}
+	} else {
+		commit, err = repo.CommitObject(ref.Hash())
+	}
+	if err != nil {
+		return fmt.Errorf("unable to find the commit: %w", err)
+	}
+	file, err := commit.File(gitModulesFile)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return err
+	}
+	rewritten, changed, err := rewriteGitModules([]byte(content), sourceURL, address, username, rules)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	hash, err := commitGitModules(repo, commit, rewritten)
+	if err != nil {
+		return err
+	}
+	if tag != nil {
+		hash, err = retargetTag(ctx, repo, tag, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(ref.Name(), hash))
+}
+
+// retargetTag creates a copy of the annotated tag that points at the commit, signatures are dropped as they no longer match.
+func retargetTag(ctx context.Context, repo *git.Repository, tag *object.Tag, commit plumbing.Hash) (plumbing.Hash, error) {
+	if tag.PGPSignature != "" {
+		logger.From(ctx).Warn("the signature of the tag is dropped as the tag is moved to the commit that rewrites the submodule URLs", "tag", tag.Name)
+	}
+	newTag := &object.Tag{
+		Name:       tag.Name,
+		Tagger:     tag.Tagger,
+		Message:    tag.Message,
+		TargetType: plumbing.CommitObject,
+		Target:     commit,
+	}
+	tagObj := repo.Storer.NewEncodedObject()
+	if err := newTag.Encode(tagObj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(tagObj)
+}
+
+// rewriteGitModules replaces the URLs in the .gitmodules content with their Zarf-compatible URLs.
+func rewriteGitModules(content []byte, sourceURL, address, username string, rules transform.RepoRules) ([]byte, bool, error) {
+	modules := config.NewModules()
+	if err := modules.Unmarshal(content); err != nil {
+		return nil, false, err
+	}
+	changed := false
+	for _, m := range modules.Submodules {
+		// Skip submodules that already point at the git server
+		if strings.HasPrefix(m.URL, address) {
+			continue
+		}
+		subURL, err := resolveSubmoduleURL(sourceURL, m.URL)
+		if err != nil {
+			return nil, false, err
+		}
+		targetURL, err := transform.GitURLWithRules(address, subURL, username, rules)
+		if err != nil {
+			return nil, false, err
+		}
+		m.URL = targetURL.String()
+		changed = true
+	}
+	if !changed {
+		return content, false, nil
+	}
+	b, err := modules.Marshal()
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+// resolveSubmoduleURL resolves a submodule URL relative to the URL of its superproject.
+func resolveSubmoduleURL(parentURL, subURL string) (string, error) {
+	if !strings.HasPrefix(subURL, "./") && !strings.HasPrefix(subURL, "../") {
+		return subURL, nil
+	}
+	u, err := url.Parse(parentURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the submodule url %s: %w", subURL, err)
+	}
+	u.Path = path.Join(u.Path, subURL)
+	return u.String(), nil
+}
+
+// commitGitModules creates a commit on top of the parent that replaces its .gitmodules.
+func commitGitModules(repo *git.Repository, parent *object.Commit, content []byte) (plumbing.Hash, error) {
+	blob := repo.Storer.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+	w, err := blob.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(content); err != nil {
+		return plumbing.ZeroHash, errors.Join(err, w.Close())
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	blobHash, err := repo.Storer.SetEncodedObject(blob)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	tree := &object.Tree{}
+	for _, entry := range parentTree.Entries {
+		if entry.Name == gitModulesFile {
+			entry = object.TreeEntry{Name: gitModulesFile, Mode: filemode.Regular, Hash: blobHash}
+		}
+		tree.Entries = append(tree.Entries, entry)
+	}
+	treeObj := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(treeObj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// Reuse the parent's timestamp so that the same branch is always rewritten to the same commit
+	signature := object.Signature{
+		Name:  "Zarf",
+		Email: "zarf@localhost",
+		When:  parent.Committer.When,
+	}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      submoduleRewriteMessage,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{parent.Hash},
+	}
+	commitObj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(commitObj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(commitObj)
+}
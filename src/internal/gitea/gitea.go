@@ -80,6 +80,19 @@ func (g *Client) DoRequest(ctx context.Context, method string, path string, body
 	return b, resp.StatusCode, nil
 }
 
+// CheckAuth verifies that the client credentials are accepted by the Gitea API.
+// Gitea accepts access tokens in place of a password, so this also verifies tokens.
+func (g *Client) CheckAuth(ctx context.Context) error {
+	_, statusCode, err := g.DoRequest(ctx, http.MethodGet, "/api/v1/user", nil)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("the credentials for %s were not accepted, status code %d", g.username, statusCode)
+	}
+	return nil
+}
+
 // CreateReadOnlyUser creates a non-admin Zarf user.
 func (g *Client) CreateReadOnlyUser(ctx context.Context, username, password string) error {
 	// Create the read only user
@@ -4,6 +4,9 @@
 package gitea
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -18,3 +21,26 @@ func TestNewClient(t *testing.T) {
 	require.Equal(t, "foo", c.username)
 	require.Equal(t, "bar", c.password)
 }
+
+func TestCheckAuth(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if r.URL.Path != "/api/v1/user" || !ok || username != "zarf-git-user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, "zarf-git-user", "secret")
+	require.NoError(t, err)
+	require.NoError(t, c.CheckAuth(context.Background()))
+
+	c, err = NewClient(srv.URL, "zarf-git-user", "wrong")
+	require.NoError(t, err)
+	err = c.CheckAuth(context.Background())
+	require.EqualError(t, err, "the credentials for zarf-git-user were not accepted, status code 401")
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -21,9 +23,15 @@ import (
 )
 
 // Run waits for a list of Zarf healthchecks to reach a ready state.
+// Checks on a single resource are evaluated together with kstatus, while checks with a selector, condition, JSONPath or timeout are evaluated on their own.
 func Run(ctx context.Context, watcher watcher.StatusWatcher, healthChecks []v1alpha1.NamespacedObjectKindReference) error {
 	objs := []object.ObjMetadata{}
+	separate := []v1alpha1.NamespacedObjectKindReference{}
 	for _, hc := range healthChecks {
+		if hc.IsStructured() || hc.TimeoutSeconds > 0 {
+			separate = append(separate, hc)
+			continue
+		}
 		gv, err := schema.ParseGroupVersion(hc.APIVersion)
 		if err != nil {
 			return err
@@ -38,11 +46,48 @@ func Run(ctx context.Context, watcher watcher.StatusWatcher, healthChecks []v1al
 		}
 		objs = append(objs, obj)
 	}
-	err := WaitForReady(ctx, watcher, objs)
+
+	// Every check runs to completion so that all failures are reported together
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := []error{}
+	if len(objs) > 0 {
+		wg.Go(func() {
+			if err := WaitForReady(ctx, watcher, objs); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	for _, hc := range separate {
+		wg.Go(func() {
+			if err := runSeparate(ctx, watcher, hc); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// runSeparate runs a single health check with its own timeout.
+func runSeparate(ctx context.Context, sw watcher.StatusWatcher, hc v1alpha1.NamespacedObjectKindReference) error {
+	if hc.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(hc.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+	if !hc.IsStructured() {
+		return Run(ctx, sw, []v1alpha1.NamespacedObjectKindReference{{APIVersion: hc.APIVersion, Kind: hc.Kind, Namespace: hc.Namespace, Name: hc.Name}})
+	}
+	checker, err := newStructuredChecker(sw)
 	if err != nil {
 		return err
 	}
-	return nil
+	return checker.run(ctx, hc)
 }
 
 // WaitForReadyRuntime waits for all of the objects to reach a ready state.
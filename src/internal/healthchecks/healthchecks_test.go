@@ -18,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
@@ -98,3 +99,99 @@ func TestRunHealthChecks(t *testing.T) {
 		})
 	}
 }
+
+var podLabeledYaml = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: labeled-pod
+  namespace: ns
+  labels:
+    app: web
+status:
+  conditions:
+  - type: Ready
+    status: "True"
+  phase: Running
+`
+
+func TestRunStructuredHealthChecks(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		healthCheck v1alpha1.NamespacedObjectKindReference
+		expectErrs  []error
+	}{
+		{
+			name:        "Condition is met",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{Name: "good-pod", Condition: &v1alpha1.HealthCheckCondition{Type: "Ready"}},
+		},
+		{
+			name:        "JSONPath matches the value",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{Name: "good-pod", JSONPath: ".status.phase", Value: "Running"},
+		},
+		{
+			name:        "Selector with condition",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{Selector: "app=web", Condition: &v1alpha1.HealthCheckCondition{Type: "ready", Status: "true"}},
+		},
+		{
+			name:        "Selector with kstatus",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{Selector: "app=web"},
+		},
+		{
+			name:        "Condition is never met",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{Name: "in-progress-pod", Condition: &v1alpha1.HealthCheckCondition{Type: "Ready"}},
+			expectErrs:  []error{errors.New("in-progress-pod: Pod not ready: condition Ready not found"), context.DeadlineExceeded},
+		},
+		{
+			name:        "JSONPath does not match the value",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{Name: "good-pod", JSONPath: "{.status.phase}", Value: "Succeeded"},
+			expectErrs:  []error{errors.New(`good-pod: Pod not ready: jsonPath {.status.phase} is "Running", expected "Succeeded"`), context.DeadlineExceeded},
+		},
+		{
+			name:        "Selector matches nothing",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{Selector: "app=missing", JSONPath: ".status.phase"},
+			expectErrs:  []error{errors.New(`no Pod matched the selector "app=missing" in ns`), context.DeadlineExceeded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fakeClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+			fakeMapper := testutil.NewFakeRESTMapper(
+				v1.SchemeGroupVersion.WithKind("Pod"),
+			)
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			statusWatcher := watcher.NewDefaultStatusWatcher(fakeClient, fakeMapper)
+			for _, podYaml := range []string{podCurrentYaml, podYaml, podLabeledYaml} {
+				m := make(map[string]interface{})
+				err := yaml.Unmarshal([]byte(podYaml), &m)
+				require.NoError(t, err)
+				pod := &unstructured.Unstructured{Object: m}
+				podGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+				err = fakeClient.Tracker().Create(podGVR, pod, pod.GetNamespace())
+				require.NoError(t, err)
+			}
+			hc := tt.healthCheck
+			hc.APIVersion = "v1"
+			hc.Kind = "Pod"
+			hc.Namespace = "ns"
+
+			err := Run(ctx, statusWatcher, []v1alpha1.NamespacedObjectKindReference{hc})
+			if tt.expectErrs != nil {
+				require.EqualError(t, err, errors.Join(tt.expectErrs...).Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRunStructuredHealthCheckRequiresStatusWatcher(t *testing.T) {
+	t.Parallel()
+	hc := v1alpha1.NamespacedObjectKindReference{APIVersion: "v1", Kind: "Pod", Namespace: "ns", Selector: "app=web"}
+	err := Run(context.Background(), NewImmediateWatcher(status.CurrentStatus), []v1alpha1.NamespacedObjectKindReference{hc})
+	require.ErrorContains(t, err, "require a cluster status watcher")
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package healthchecks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// structuredChecker evaluates health checks that use a selector, condition or JSONPath.
+type structuredChecker struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+	sw     watcher.StatusWatcher
+}
+
+// newStructuredChecker reuses the dynamic client and REST mapper of the status watcher.
+func newStructuredChecker(sw watcher.StatusWatcher) (*structuredChecker, error) {
+	dsw, ok := sw.(*watcher.DefaultStatusWatcher)
+	if !ok {
+		return nil, errors.New("health checks with a selector, condition or jsonPath require a cluster status watcher")
+	}
+	return &structuredChecker{
+		client: dsw.DynamicClient,
+		mapper: dsw.Mapper,
+		sw:     sw,
+	}, nil
+}
+
+// run waits for the resources of the health check to become healthy.
+func (c *structuredChecker) run(ctx context.Context, hc v1alpha1.NamespacedObjectKindReference) error {
+	gv, err := schema.ParseGroupVersion(hc.APIVersion)
+	if err != nil {
+		return err
+	}
+	gvk := gv.WithKind(hc.Kind)
+
+	// Without a condition or JSONPath the resources matching the selector are checked with kstatus.
+	if hc.Condition == nil && hc.JSONPath == "" {
+		var objs []unstructured.Unstructured
+		err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+			objs, err = c.list(ctx, gvk, hc)
+			if err != nil {
+				return false, err
+			}
+			return len(objs) > 0, nil
+		})
+		if err != nil {
+			return errors.Join(fmt.Errorf("no %s matched the selector %q in %s", hc.Kind, hc.Selector, hc.Namespace), err)
+		}
+		ids := []object.ObjMetadata{}
+		for _, obj := range objs {
+			ids = append(ids, object.UnstructuredToObjMetadata(&obj))
+		}
+		return WaitForReady(ctx, c.sw, ids)
+	}
+
+	var failures []error
+	err = wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		objs, err := c.list(ctx, gvk, hc)
+		if err != nil {
+			return false, err
+		}
+		failures = nil
+		if len(objs) == 0 {
+			failures = append(failures, notFoundError(hc))
+		}
+		for _, obj := range objs {
+			if err := evaluate(obj, hc); err != nil {
+				failures = append(failures, fmt.Errorf("%s: %s not ready: %w", obj.GetName(), hc.Kind, err))
+			}
+		}
+		return len(failures) == 0, nil
+	})
+	if err != nil {
+		failures = append(failures, err)
+		return errors.Join(failures...)
+	}
+	return nil
+}
+
+// list returns the resources selected by the health check, a kind that does not exist yet is treated as no resources.
+func (c *structuredChecker) list(ctx context.Context, gvk schema.GroupVersionKind, hc v1alpha1.NamespacedObjectKindReference) ([]unstructured.Unstructured, error) {
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resource dynamic.ResourceInterface = c.client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		resource = c.client.Resource(mapping.Resource).Namespace(hc.Namespace)
+	}
+
+	if hc.Name != "" {
+		obj, err := resource.Get(ctx, hc.Name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []unstructured.Unstructured{*obj}, nil
+	}
+	list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: hc.Selector})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func notFoundError(hc v1alpha1.NamespacedObjectKindReference) error {
+	if hc.Name != "" {
+		return fmt.Errorf("%s: %s not found", hc.Name, hc.Kind)
+	}
+	return fmt.Errorf("no %s matched the selector %q in %s", hc.Kind, hc.Selector, hc.Namespace)
+}
+
+// evaluate returns an error describing why the resource does not satisfy the condition or JSONPath of the health check.
+func evaluate(obj unstructured.Unstructured, hc v1alpha1.NamespacedObjectKindReference) error {
+	if hc.Condition != nil {
+		return evaluateCondition(obj, *hc.Condition)
+	}
+	return evaluateJSONPath(obj, hc.JSONPath, hc.Value)
+}
+
+func evaluateCondition(obj unstructured.Unstructured, condition v1alpha1.HealthCheckCondition) error {
+	expected := condition.Status
+	if expected == "" {
+		expected = string(metav1.ConditionTrue)
+	}
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return err
+	}
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _, _ := unstructured.NestedString(cond, "type")
+		if !strings.EqualFold(condType, condition.Type) {
+			continue
+		}
+		status, _, _ := unstructured.NestedString(cond, "status")
+		if strings.EqualFold(status, expected) {
+			return nil
+		}
+		msg := fmt.Sprintf("condition %s is %s, expected %s", condition.Type, status, expected)
+		if message, _, _ := unstructured.NestedString(cond, "message"); message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, message)
+		}
+		return errors.New(msg)
+	}
+	return fmt.Errorf("condition %s not found", condition.Type)
+}
+
+func evaluateJSONPath(obj unstructured.Unstructured, expression, expected string) error {
+	if !strings.HasPrefix(expression, "{") {
+		expression = fmt.Sprintf("{%s}", expression)
+	}
+	jp := jsonpath.New("healthcheck").AllowMissingKeys(true)
+	if err := jp.Parse(expression); err != nil {
+		return fmt.Errorf("invalid jsonPath %s: %w", expression, err)
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, obj.Object); err != nil {
+		return fmt.Errorf("unable to evaluate jsonPath %s: %w", expression, err)
+	}
+	actual := buf.String()
+	if expected == "" {
+		if actual == "" {
+			return fmt.Errorf("jsonPath %s returned no result", expression)
+		}
+		return nil
+	}
+	if actual != expected {
+		return fmt.Errorf("jsonPath %s is %q, expected %q", expression, actual, expected)
+	}
+	return nil
+}
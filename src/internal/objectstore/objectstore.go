@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+// Package objectstore checks the object storage used by the Zarf registry.
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/zarf-dev/zarf/src/pkg/state"
+)
+
+// checkObjectName is written and removed below the root directory to verify write access to the bucket.
+const checkObjectName = "zarf-storage-check"
+
+// CheckS3 verifies the bucket exists and the credentials can write and delete objects below the root directory.
+func CheckS3(ctx context.Context, storage state.RegistryS3Storage) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	client := newS3Client(storage)
+	_, err := client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(storage.Bucket)})
+	if err != nil {
+		return fmt.Errorf("unable to reach the S3 bucket %s: %w", storage.Bucket, err)
+	}
+	key := path.Join(strings.Trim(storage.RootDirectory, "/"), checkObjectName)
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(storage.Bucket),
+		Key:    aws.String(key),
+		Body:   strings.NewReader("zarf"),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to write to the S3 bucket %s: %w", storage.Bucket, err)
+	}
+	_, err = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(storage.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to delete from the S3 bucket %s: %w", storage.Bucket, err)
+	}
+	return nil
+}
+
+// Endpoint returns the endpoint URL with the scheme the registry connects with.
+func Endpoint(storage state.RegistryS3Storage) string {
+	if storage.Endpoint == "" || strings.Contains(storage.Endpoint, "://") {
+		return storage.Endpoint
+	}
+	if storage.Insecure {
+		return "http://" + storage.Endpoint
+	}
+	return "https://" + storage.Endpoint
+}
+
+// IsInCluster returns true if the endpoint is a Kubernetes service, which is only reachable from inside the cluster.
+func IsInCluster(storage state.RegistryS3Storage) bool {
+	if storage.Endpoint == "" {
+		return false
+	}
+	u, err := url.Parse(Endpoint(storage))
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	return strings.HasSuffix(host, ".svc") || strings.HasSuffix(host, ".svc.cluster.local")
+}
+
+func newS3Client(storage state.RegistryS3Storage) *s3.Client {
+	return s3.New(s3.Options{
+		Region:       storage.Region,
+		Credentials:  credentials.NewStaticCredentialsProvider(storage.AccessKey, storage.SecretKey, ""),
+		UsePathStyle: storage.ForcePathStyle,
+		BaseEndpoint: stringOrNil(Endpoint(storage)),
+		// S3-compatible services do not all support the checksums newer SDKs send by default
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+}
+
+func stringOrNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package objectstore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+)
+
+// newFakeS3 returns a minimal path style S3-compatible server with a single bucket.
+func newFakeS3(t *testing.T, bucket string, readOnly bool) (*httptest.Server, map[string]string) {
+	t.Helper()
+	var mu sync.Mutex
+	objects := map[string]string{}
+	writes := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if name != bucket {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential=access/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodHead && key == "":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPut && !readOnly:
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			objects[key] = string(b)
+			writes[key] = string(b)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete && !readOnly:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() {
+		require.Empty(t, objects)
+	})
+	return srv, writes
+}
+
+func TestCheckS3(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.TestContext(t)
+
+	srv, writes := newFakeS3(t, "zarf", false)
+	storage := state.RegistryS3Storage{
+		Bucket:         "zarf",
+		Region:         "us-east-1",
+		Endpoint:       strings.TrimPrefix(srv.URL, "http://"),
+		RootDirectory:  "/registry/",
+		AccessKey:      "access",
+		SecretKey:      "secret",
+		Insecure:       true,
+		ForcePathStyle: true,
+	}
+	require.NoError(t, CheckS3(ctx, storage))
+	require.Equal(t, map[string]string{"registry/zarf-storage-check": "zarf"}, writes)
+
+	missing := storage
+	missing.Bucket = "missing"
+	require.ErrorContains(t, CheckS3(ctx, missing), "unable to reach the S3 bucket missing")
+
+	readOnlySrv, _ := newFakeS3(t, "zarf", true)
+	readOnly := storage
+	readOnly.Endpoint = readOnlySrv.URL
+	require.ErrorContains(t, CheckS3(ctx, readOnly), "unable to write to the S3 bucket zarf")
+}
+
+func TestEndpoint(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, Endpoint(state.RegistryS3Storage{}))
+	require.Equal(t, "https://minio:9000", Endpoint(state.RegistryS3Storage{Endpoint: "minio:9000"}))
+	require.Equal(t, "http://minio:9000", Endpoint(state.RegistryS3Storage{Endpoint: "minio:9000", Insecure: true}))
+	require.Equal(t, "https://s3.example.com", Endpoint(state.RegistryS3Storage{Endpoint: "https://s3.example.com", Insecure: true}))
+}
+
+func TestIsInCluster(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, IsInCluster(state.RegistryS3Storage{}))
+	require.False(t, IsInCluster(state.RegistryS3Storage{Endpoint: "https://minio.example.com:9000"}))
+	require.True(t, IsInCluster(state.RegistryS3Storage{Endpoint: "minio.minio.svc:9000", Insecure: true}))
+	require.True(t, IsInCluster(state.RegistryS3Storage{Endpoint: "https://minio.minio.svc.cluster.local"}))
+}
@@ -24,6 +24,7 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/storage/driver"
@@ -57,6 +58,8 @@ type InstallUpgradeOptions struct {
 	NamespaceOverride string
 	// IsInteractive decides if Zarf can interactively prompt users through the CLI
 	IsInteractive bool
+	// Overlay is a kustomize component applied to the rendered manifests, keyed by the path of each file in the component
+	Overlay map[string][]byte
 }
 
 // InstallOrUpgradeChart performs a helm install of the given chart.
@@ -87,6 +90,10 @@ func InstallOrUpgradeChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, ch
 	if err != nil {
 		return nil, zarfChart.ReleaseName, fmt.Errorf("unable to create helm renderer: %w", err)
 	}
+	chainedRender, err := newChainedRenderer(zarfChart.PostRenderers, opts.Overlay, postRender)
+	if err != nil {
+		return nil, zarfChart.ReleaseName, err
+	}
 
 	histClient := action.NewHistory(actionConfig)
 	var release *release.Release
@@ -102,14 +109,14 @@ func InstallOrUpgradeChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, ch
 		// No prior release, try to install it.
 		l.Info("performing Helm install", "chart", zarfChart.Name)
 
-		release, err = installChart(helmCtx, zarfChart, chart, values, opts.Timeout, actionConfig, postRender)
+		release, err = installChart(helmCtx, zarfChart, chart, values, opts.Timeout, actionConfig, chainedRender)
 	} else if histErr == nil && len(releases) > 0 {
 		// Otherwise, there is a prior release so upgrade it.
 		l.Info("performing Helm upgrade", "chart", zarfChart.Name)
 
 		lastRelease := releases[len(releases)-1]
 
-		release, err = upgradeChart(helmCtx, zarfChart, chart, values, opts.Timeout, actionConfig, postRender, opts.Cluster, lastRelease)
+		release, err = upgradeChart(helmCtx, zarfChart, chart, values, opts.Timeout, actionConfig, chainedRender, opts.Cluster, lastRelease)
 	} else {
 		return nil, zarfChart.ReleaseName, fmt.Errorf("unable to verify the chart installation status: %w", histErr)
 	}
@@ -130,14 +137,14 @@ func InstallOrUpgradeChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, ch
 			}
 		}
 
-		// No prior releases means this was an initial install.
-		if previouslyDeployedVersion == 0 {
+		// No prior releases means this was an initial install, Helm already rolled back atomic releases.
+		if previouslyDeployedVersion == 0 || zarfChart.Atomic {
 			return nil, zarfChart.ReleaseName, installErr
 		}
 
 		// Attempt to rollback on a failed upgrade.
 		l.Info("performing Helm rollback", "chart", zarfChart.Name)
-		err = rollbackChart(zarfChart.ReleaseName, previouslyDeployedVersion, actionConfig, opts.Timeout)
+		err = rollbackChart(zarfChart, previouslyDeployedVersion, actionConfig, opts.Timeout)
 		if err != nil {
 			return nil, zarfChart.ReleaseName, fmt.Errorf("%w: unable to rollback: %w", installErr, err)
 		}
@@ -237,7 +244,7 @@ func UpdateReleaseValues(ctx context.Context, chart v1alpha1.ZarfChart, updatedV
 }
 
 func installChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, chart *chart.Chart, chartValues chartutil.Values,
-	timeout time.Duration, actionConfig *action.Configuration, postRender *renderer) (*release.Release, error) {
+	timeout time.Duration, actionConfig *action.Configuration, postRender postrender.PostRenderer) (*release.Release, error) {
 	// Bind the helm action.
 	client := action.NewInstall(actionConfig)
 
@@ -247,8 +254,14 @@ func installChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, chart *char
 	// Default helm behavior for Zarf is to wait for the resources to deploy, NoWait overrides that for special cases (such as data-injection).
 	client.Wait = !zarfChart.NoWait
 
-	// We need to include CRDs or operator installations will fail spectacularly.
-	client.SkipCRDs = false
+	// We need to include CRDs or operator installations will fail spectacularly, unless the chart opts out.
+	client.SkipCRDs = zarfChart.SkipCRDs
+
+	client.Atomic = zarfChart.Atomic
+
+	client.DisableHooks = zarfChart.DisableHooks
+
+	client.Force = zarfChart.Force
 
 	// Must be unique per-namespace and < 53 characters. @todo: restrict helm loadedChart name to this.
 	client.ReleaseName = zarfChart.ReleaseName
@@ -266,7 +279,7 @@ func installChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, chart *char
 }
 
 func upgradeChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, chart *chart.Chart, chartValues chartutil.Values,
-	timeout time.Duration, actionConfig *action.Configuration, postRender *renderer, c *cluster.Cluster, lastRelease *release.Release) (*release.Release, error) {
+	timeout time.Duration, actionConfig *action.Configuration, postRender postrender.PostRenderer, c *cluster.Cluster, lastRelease *release.Release) (*release.Release, error) {
 	// Migrate any deprecated APIs (if applicable)
 	err := migrateDeprecatedAPIs(ctx, c, actionConfig, lastRelease)
 	if err != nil {
@@ -286,27 +299,42 @@ func upgradeChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, chart *char
 
 	client.SkipSchemaValidation = !zarfChart.ShouldRunSchemaValidation()
 
+	client.Atomic = zarfChart.Atomic
+
+	client.DisableHooks = zarfChart.DisableHooks
+
+	client.Force = zarfChart.Force
+
 	// Namespace must be specified.
 	client.Namespace = zarfChart.Namespace
 
 	// Post-processing our manifests to apply vars and run zarf helm logic in cluster
 	client.PostRenderer = postRender
 
-	client.MaxHistory = maxHelmHistory
+	client.MaxHistory = chartMaxHistory(zarfChart)
 
 	// Perform the loadedChart upgrade.
 	return client.RunWithContext(ctx, zarfChart.ReleaseName, chart, chartValues)
 }
 
-func rollbackChart(name string, version int, actionConfig *action.Configuration, timeout time.Duration) error {
+func rollbackChart(zarfChart v1alpha1.ZarfChart, version int, actionConfig *action.Configuration, timeout time.Duration) error {
 	client := action.NewRollback(actionConfig)
 	client.CleanupOnFail = true
 	client.Force = true
 	client.Wait = true
 	client.Timeout = timeout
 	client.Version = version
-	client.MaxHistory = maxHelmHistory
-	return client.Run(name)
+	client.DisableHooks = zarfChart.DisableHooks
+	client.MaxHistory = chartMaxHistory(zarfChart)
+	return client.Run(zarfChart.ReleaseName)
+}
+
+// chartMaxHistory returns the number of release revisions to keep for the chart.
+func chartMaxHistory(zarfChart v1alpha1.ZarfChart) int {
+	if zarfChart.MaxHistory > 0 {
+		return zarfChart.MaxHistory
+	}
+	return maxHelmHistory
 }
 
 func uninstallChart(name string, actionConfig *action.Configuration, timeout time.Duration) (*release.UninstallReleaseResponse, error) {
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// ChartDependencies verifies the Chart.lock of a packaged chart and returns the dependency tree that was packed into it.
+func ChartDependencies(ctx context.Context, zarfChart v1alpha1.ZarfChart, chartPath string) (v1alpha1.ZarfBuildChart, error) {
+	buildChart := v1alpha1.ZarfBuildChart{
+		Name: zarfChart.Name,
+	}
+	c, err := loader.Load(StandardName(chartPath, zarfChart) + ".tgz")
+	if err != nil {
+		return buildChart, fmt.Errorf("unable to load the chart %s: %w", zarfChart.Name, err)
+	}
+	if err := verifyChartLock(c); err != nil {
+		return buildChart, err
+	}
+	if c.Lock != nil {
+		buildChart.LockDigest = c.Lock.Digest
+	} else if len(c.Dependencies()) > 0 {
+		logger.From(ctx).Warn("chart dependencies are not locked, add a Chart.lock to make the dependencies reproducible", "chart", zarfChart.Name)
+	}
+	buildChart.Dependencies = dependencyTree(c)
+	return buildChart, nil
+}
+
+// verifyChartLock ensures the Chart.lock of the chart and its dependencies matches the Chart.yaml and the packed dependencies.
+func verifyChartLock(c *chart.Chart) error {
+	if c.Lock != nil {
+		if c.Metadata.APIVersion == chart.APIVersionV2 && !usesRepoAlias(c.Metadata.Dependencies) {
+			digest, err := hashDependencies(c.Metadata.Dependencies, c.Lock.Dependencies)
+			if err != nil {
+				return err
+			}
+			if digest != c.Lock.Digest {
+				return fmt.Errorf("the Chart.lock digest %s of the chart %s does not match the Chart.yaml dependencies digest %s", c.Lock.Digest, c.Name(), digest)
+			}
+		}
+		for _, locked := range c.Lock.Dependencies {
+			packed := slices.ContainsFunc(c.Dependencies(), func(dep *chart.Chart) bool {
+				return dep.Name() == locked.Name && dep.Metadata.Version == locked.Version
+			})
+			if !packed {
+				return fmt.Errorf("the dependency %s %s in the Chart.lock of the chart %s was not packed into the chart", locked.Name, locked.Version, c.Name())
+			}
+		}
+	}
+	for _, dep := range c.Dependencies() {
+		if err := verifyChartLock(dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashDependencies computes the Chart.lock digest the same way Helm does when it writes the lock.
+func hashDependencies(req, lock []*chart.Dependency) (string, error) {
+	b, err := json.Marshal([2][]*chart.Dependency{req, lock})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b)), nil
+}
+
+// usesRepoAlias returns if any dependency refers to a repository by its local alias, which Helm resolves before hashing.
+func usesRepoAlias(deps []*chart.Dependency) bool {
+	return slices.ContainsFunc(deps, func(dep *chart.Dependency) bool {
+		return strings.HasPrefix(dep.Repository, "@") || strings.HasPrefix(dep.Repository, "alias:")
+	})
+}
+
+func dependencyTree(c *chart.Chart) []v1alpha1.ZarfBuildChartDependency {
+	deps := []v1alpha1.ZarfBuildChartDependency{}
+	for _, sub := range c.Dependencies() {
+		dep := v1alpha1.ZarfBuildChartDependency{
+			Name:         sub.Name(),
+			Version:      sub.Metadata.Version,
+			Digest:       chartDigest(sub),
+			Dependencies: dependencyTree(sub),
+		}
+		for _, md := range c.Metadata.Dependencies {
+			if md.Name == sub.Name() {
+				dep.Repository = md.Repository
+				break
+			}
+		}
+		deps = append(deps, dep)
+	}
+	slices.SortFunc(deps, func(a, b v1alpha1.ZarfBuildChartDependency) int {
+		return strings.Compare(a.Name+a.Version, b.Name+b.Version)
+	})
+	if len(deps) == 0 {
+		return nil
+	}
+	return deps
+}
+
+// chartDigest hashes the files of the chart in name order, so the digest does not depend on how the chart was archived.
+func chartDigest(c *chart.Chart) string {
+	files := slices.Clone(c.Raw)
+	slices.SortFunc(files, func(a, b *chart.File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	h := sha256.New()
+	for _, f := range files {
+		fmt.Fprintf(h, "%s\x00%x\x00", f.Name, sha256.Sum256(f.Data))
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+)
+
+func TestChartDependencies(t *testing.T) {
+	t.Parallel()
+
+	deps := []*chart.Dependency{{Name: "child", Version: "~1.0.0", Repository: "https://example.com/charts"}}
+	lockedDeps := []*chart.Dependency{{Name: "child", Version: "1.0.0", Repository: "https://example.com/charts"}}
+	digest, err := hashDependencies(deps, lockedDeps)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		lock        *chart.Lock
+		expectedErr string
+	}{
+		{
+			name: "locked dependencies",
+			lock: &chart.Lock{Digest: digest, Dependencies: lockedDeps},
+		},
+		{
+			name: "unlocked dependencies",
+		},
+		{
+			name:        "lock out of sync with Chart.yaml",
+			lock:        &chart.Lock{Digest: "sha256:0000", Dependencies: lockedDeps},
+			expectedErr: "the Chart.lock digest sha256:0000 of the chart parent does not match the Chart.yaml dependencies digest",
+		},
+		{
+			name:        "locked version not packed",
+			lock:        &chart.Lock{Digest: mustHashDependencies(t, deps, []*chart.Dependency{{Name: "child", Version: "1.0.1", Repository: "https://example.com/charts"}}), Dependencies: []*chart.Dependency{{Name: "child", Version: "1.0.1", Repository: "https://example.com/charts"}}},
+			expectedErr: "the dependency child 1.0.1 in the Chart.lock of the chart parent was not packed into the chart",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+
+			parentDir := filepath.Join(tmpDir, "parent")
+			require.NoError(t, os.MkdirAll(filepath.Join(parentDir, "charts"), 0o700))
+			child := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "child", Version: "1.0.0"}}
+			childArchive, err := chartutil.Save(child, filepath.Join(parentDir, "charts"))
+			require.NoError(t, err)
+			parent := &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "parent", Version: "1.0.0", Dependencies: deps}
+			b, err := yaml.Marshal(parent)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(parentDir, "Chart.yaml"), b, 0o600))
+			if tt.lock != nil {
+				b, err := yaml.Marshal(tt.lock)
+				require.NoError(t, err)
+				require.NoError(t, os.WriteFile(filepath.Join(parentDir, "Chart.lock"), b, 0o600))
+			}
+
+			chartPath := filepath.Join(tmpDir, "out")
+			client := action.NewPackage()
+			client.Destination = chartPath
+			_, err = client.Run(parentDir, nil)
+			require.NoError(t, err)
+
+			buildChart, err := ChartDependencies(testutil.TestContext(t), v1alpha1.ZarfChart{Name: "parent", Version: "1.0.0"}, chartPath)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// The digest does not depend on the dependency being archived or a directory.
+			childChart, err := loader.Load(childArchive)
+			require.NoError(t, err)
+			expected := v1alpha1.ZarfBuildChart{
+				Name: "parent",
+				Dependencies: []v1alpha1.ZarfBuildChartDependency{
+					{
+						Name:       "child",
+						Version:    "1.0.0",
+						Repository: "https://example.com/charts",
+						Digest:     chartDigest(childChart),
+					},
+				},
+			}
+			if tt.lock != nil {
+				expected.LockDigest = tt.lock.Digest
+			}
+			require.Equal(t, expected, buildChart)
+		})
+	}
+}
+
+func mustHashDependencies(t *testing.T, req, lock []*chart.Dependency) string {
+	t.Helper()
+	digest, err := hashDependencies(req, lock)
+	require.NoError(t, err)
+	return digest
+}
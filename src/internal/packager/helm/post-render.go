@@ -14,10 +14,12 @@ import (
 
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/internal/packager/kustomize"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/variables"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/restmapper"
@@ -30,6 +32,56 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// chainedRenderer runs the post-renderers of the chart and the overlay in order before the Zarf post-renderer.
+type chainedRenderer struct {
+	renderers []postrender.PostRenderer
+}
+
+func newChainedRenderer(postRenderers []v1alpha1.ZarfChartPostRenderer, overlay map[string][]byte, zarfRenderer postrender.PostRenderer) (postrender.PostRenderer, error) {
+	if len(postRenderers) == 0 && len(overlay) == 0 {
+		return zarfRenderer, nil
+	}
+	chain := &chainedRenderer{}
+	for _, pr := range postRenderers {
+		r, err := postrender.NewExec(pr.Command, pr.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the post-renderer %s: %w", pr.Command, err)
+		}
+		chain.renderers = append(chain.renderers, r)
+	}
+	if len(overlay) > 0 {
+		chain.renderers = append(chain.renderers, overlayRenderer{component: overlay})
+	}
+	chain.renderers = append(chain.renderers, zarfRenderer)
+	return chain, nil
+}
+
+// Run satisfies the Helm post-renderer interface, passing the output of each post-renderer to the next.
+func (c *chainedRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	var err error
+	for _, r := range c.renderers {
+		renderedManifests, err = r.Run(renderedManifests)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return renderedManifests, nil
+}
+
+// overlayRenderer applies a kustomize component supplied at deploy time to the rendered manifests.
+type overlayRenderer struct {
+	component map[string][]byte
+}
+
+// Run satisfies the Helm post-renderer interface.
+func (r overlayRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	out, err := kustomize.Overlay(renderedManifests.Bytes(), r.component)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(out), nil
+}
+
 type renderer struct {
 	chart v1alpha1.ZarfChart
 
@@ -148,7 +200,7 @@ func (r *renderer) adoptAndUpdateNamespaces(ctx context.Context) error {
 		}
 
 		// Create the secret
-		validRegistrySecret, err := c.GenerateRegistryPullCreds(ctx, name, config.ZarfImagePullSecretName, r.state.RegistryInfo)
+		validRegistrySecret, err := c.GenerateRegistryPullCreds(ctx, name, config.ZarfImagePullSecretName, r.state.RegistriesForNamespace(name)...)
 		if err != nil {
 			return err
 		}
@@ -156,9 +208,7 @@ func (r *renderer) adoptAndUpdateNamespaces(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("problem applying registry secret for the %s namespace: %w", name, err)
 		}
-		gitServerSecret := c.GenerateGitPullCreds(name, config.ZarfGitServerSecretName, r.state.GitServer)
-		_, err = c.Clientset.CoreV1().Secrets(*gitServerSecret.Namespace).Apply(ctx, gitServerSecret, metav1.ApplyOptions{Force: true, FieldManager: cluster.FieldManagerName})
-		if err != nil {
+		if err := c.ApplyGitPullCreds(ctx, name, r.state); err != nil {
 			return fmt.Errorf("problem applying git server secret for the %s namespace: %w", name, err)
 		}
 	}
@@ -191,8 +241,7 @@ func (r *renderer) editHelmResources(ctx context.Context, resources []releaseuti
 				labels = map[string]string{}
 			}
 			rawData.SetLabels(r.setPackageLabels(labels))
-			// Add the package label to pod templates (for Deployments, StatefulSets, etc.)
-			if err := r.addLabelsToNestedPath(rawData, []string{"spec", "template", "metadata", "labels"}); err != nil {
+			if err := r.addPodTemplateLabels(rawData); err != nil {
 				return fmt.Errorf("failed to add labels to pod template: %w", err)
 			}
 			newContent, err := yaml.Marshal(rawData)
@@ -295,6 +344,16 @@ func (r *renderer) editHelmResources(ctx context.Context, resources []releaseuti
 	return nil
 }
 
+// addPodTemplateLabels adds the package labels to the pod templates of a resource so the agent applies the transform rules of the package to its pods
+func (r *renderer) addPodTemplateLabels(obj *unstructured.Unstructured) error {
+	// Pod templates of Deployments, StatefulSets, Jobs, etc.
+	if err := r.addLabelsToNestedPath(obj, []string{"spec", "template", "metadata", "labels"}); err != nil {
+		return err
+	}
+	// Pod templates of the Jobs created by CronJobs
+	return r.addLabelsToNestedPath(obj, []string{"spec", "jobTemplate", "spec", "template", "metadata", "labels"})
+}
+
 // addLabelsToNestedPath adds package labels to a nested path in an unstructured object
 func (r *renderer) addLabelsToNestedPath(obj *unstructured.Unstructured, path []string) error {
 	// Check if the nested path exists and get the labels
@@ -302,8 +361,11 @@ func (r *renderer) addLabelsToNestedPath(obj *unstructured.Unstructured, path []
 	if err != nil {
 		return err
 	} else if !found {
-		// Path doesn't exist, nothing to do
-		return nil
+		// Templates without labels are labelled as well, nothing to do when there is no template
+		_, found, err := unstructured.NestedMap(obj.Object, path[:len(path)-2]...)
+		if err != nil || !found {
+			return err
+		}
 	}
 	if templateLabels == nil {
 		templateLabels = map[string]string{}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+)
+
+type suffixRenderer struct {
+	suffix string
+}
+
+func (r suffixRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	return bytes.NewBufferString(renderedManifests.String() + r.suffix), nil
+}
+
+func TestChainedRenderer(t *testing.T) {
+	t.Parallel()
+
+	zarfRenderer := suffixRenderer{suffix: "zarf: true\n"}
+
+	r, err := newChainedRenderer(nil, nil, zarfRenderer)
+	require.NoError(t, err)
+	require.Equal(t, zarfRenderer, r)
+
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("sed is required to test exec post-renderers")
+	}
+	r, err = newChainedRenderer([]v1alpha1.ZarfChartPostRenderer{
+		{Command: "sed", Args: []string{"s/podinfo/renamed/"}},
+		{Command: "sed", Args: []string{"s/renamed/chained/"}},
+	}, nil, zarfRenderer)
+	require.NoError(t, err)
+	out, err := r.Run(bytes.NewBufferString("name: podinfo\n"))
+	require.NoError(t, err)
+	require.Equal(t, "name: chained\nzarf: true\n", out.String())
+
+	_, err = newChainedRenderer([]v1alpha1.ZarfChartPostRenderer{{Command: "zarf-missing-post-renderer"}}, nil, zarfRenderer)
+	require.ErrorContains(t, err, "unable to create the post-renderer zarf-missing-post-renderer")
+}
+
+func TestChainedRendererOverlay(t *testing.T) {
+	t.Parallel()
+
+	zarfRenderer := suffixRenderer{suffix: "---\nzarf: true\n"}
+	overlay := map[string][]byte{
+		"kustomization.yaml": []byte("apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: Component\nnamespace: edge\n"),
+	}
+	r, err := newChainedRenderer(nil, overlay, zarfRenderer)
+	require.NoError(t, err)
+	out, err := r.Run(bytes.NewBufferString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: podinfo\n"))
+	require.NoError(t, err)
+	require.Equal(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: podinfo\n  namespace: edge\n---\nzarf: true\n", out.String())
+}
+
+func TestChartMaxHistory(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, maxHelmHistory, chartMaxHistory(v1alpha1.ZarfChart{}))
+	require.Equal(t, 3, chartMaxHistory(v1alpha1.ZarfChart{MaxHistory: 3}))
+}
+
+func TestAddPodTemplateLabels(t *testing.T) {
+	t.Parallel()
+
+	r := &renderer{pkgName: "podinfo"}
+	cronJob := &unstructured.Unstructured{}
+	err := yaml.Unmarshal([]byte(`apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: podinfo
+spec:
+  schedule: "*/5 * * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: podinfo
+              image: ghcr.io/stefanprodan/podinfo:6.4.0
+`), cronJob)
+	require.NoError(t, err)
+	require.NoError(t, r.addPodTemplateLabels(cronJob))
+	labels, found, err := unstructured.NestedStringMap(cronJob.Object, "spec", "jobTemplate", "spec", "template", "metadata", "labels")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, map[string]string{cluster.PackageLabel: "podinfo"}, labels)
+	_, found, err = unstructured.NestedMap(cronJob.Object, "spec", "template")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	deployment := &unstructured.Unstructured{}
+	err = yaml.Unmarshal([]byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    metadata:
+      labels:
+        app: podinfo
+`), deployment)
+	require.NoError(t, err)
+	require.NoError(t, r.addPodTemplateLabels(deployment))
+	labels, found, err = unstructured.NestedStringMap(deployment.Object, "spec", "template", "metadata", "labels")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, map[string]string{"app": "podinfo", cluster.PackageLabel: "podinfo"}, labels)
+}
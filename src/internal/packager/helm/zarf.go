@@ -6,6 +6,8 @@ package helm
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/state"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/object"
 
@@ -79,6 +82,127 @@ func UpdateZarfRegistryValues(ctx context.Context, opts InstallUpgradeOptions) e
 	return nil
 }
 
+// UpdateZarfRegistryMode moves the Zarf registry to the registry mode of the state from the previous registry.
+// Nodes pull the registry images through the previous address while the registry is moved.
+func UpdateZarfRegistryMode(ctx context.Context, previous state.RegistryInfo, opts InstallUpgradeOptions) error {
+	pkgs, err := opts.Cluster.GetDeployedZarfPackages(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting init package: %w", err)
+	}
+	initPkgName := findInitPackageWithComponent(pkgs, "zarf-registry")
+	if initPkgName == "" {
+		return fmt.Errorf("error finding init package with zarf-registry component")
+	}
+	opts.PkgName = initPkgName
+
+	actionConfig, err := createActionConfig(ctx, state.ZarfNamespaceName)
+	if err != nil {
+		return fmt.Errorf("unable to initialize the K8s client: %w", err)
+	}
+	getValues := action.NewGetValues(actionConfig)
+	getValues.AllValues = true
+	currentValues, err := getValues.Run("zarf-docker-registry")
+	if err != nil {
+		return fmt.Errorf("unable to get the Zarf registry values: %w", err)
+	}
+	registryValues, err := registryModeValues(currentValues, previous, opts.State)
+	if err != nil {
+		return err
+	}
+	chart := v1alpha1.ZarfChart{
+		Namespace:   state.ZarfNamespaceName,
+		ReleaseName: "zarf-docker-registry",
+	}
+	err = UpdateReleaseValues(ctx, chart, registryValues, opts)
+	if err != nil {
+		return fmt.Errorf("error updating the release values: %w", err)
+	}
+	if opts.State.RegistryInfo.RegistryMode == state.RegistryModeProxy {
+		waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer waitCancel()
+		return opts.Cluster.WaitForRegistryProxy(waitCtx)
+	}
+	return nil
+}
+
+// registryModeValues returns the registry chart values that move the registry to the registry mode of the state.
+func registryModeValues(currentValues map[string]interface{}, previous state.RegistryInfo, s *state.State) (map[string]interface{}, error) {
+	vals := chartutil.Values(currentValues)
+	imageRepo, err := vals.PathValue("image.repository")
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the registry image in the Zarf registry values: %w", err)
+	}
+	proxyImageRepo, err := vals.PathValue("proxy.image.repository")
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the proxy image in the Zarf registry values: %w", err)
+	}
+
+	registryInfo := s.RegistryInfo
+	switch registryInfo.RegistryMode {
+	case state.RegistryModeProxy:
+		if s.RegistryProxyTLS == nil {
+			return nil, errors.New("the registry proxy certificates are missing from the state")
+		}
+		proxyTLS := s.RegistryProxyTLS
+		return map[string]interface{}{
+			"service": map[string]interface{}{
+				"nodePort": registryInfo.NodePort,
+			},
+			"proxy": map[string]interface{}{
+				"enabled": true,
+				// There is no injector outside of init so new nodes pull the registry images through the previous node port
+				"fallbackNodePort": previous.NodePort,
+				"injector": map[string]interface{}{
+					"enabled": false,
+				},
+				"image": map[string]interface{}{
+					"repository": replaceRegistryHost(fmt.Sprint(proxyImageRepo), previous.Address),
+				},
+				"registry": map[string]interface{}{
+					"image": map[string]interface{}{
+						"repository": replaceRegistryHost(fmt.Sprint(imageRepo), previous.Address),
+					},
+				},
+				"tls": map[string]interface{}{
+					"enabled":    true,
+					"ca":         base64.StdEncoding.EncodeToString(proxyTLS.Server.CA),
+					"serverCert": base64.StdEncoding.EncodeToString(proxyTLS.Server.Cert),
+					"serverKey":  base64.StdEncoding.EncodeToString(proxyTLS.Server.Key),
+					"clientCert": base64.StdEncoding.EncodeToString(proxyTLS.Client.Cert),
+					"clientKey":  base64.StdEncoding.EncodeToString(proxyTLS.Client.Key),
+				},
+			},
+		}, nil
+	case state.RegistryModeNodePort:
+		return map[string]interface{}{
+			"service": map[string]interface{}{
+				"nodePort": registryInfo.NodePort,
+			},
+			"image": map[string]interface{}{
+				"repository": replaceRegistryHost(fmt.Sprint(imageRepo), registryInfo.Address),
+			},
+			"proxy": map[string]interface{}{
+				"enabled":          false,
+				"fallbackNodePort": 0,
+				"tls": map[string]interface{}{
+					"enabled": false,
+				},
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("the Zarf registry can not be moved to the %s registry mode", registryInfo.RegistryMode)
+	}
+}
+
+// replaceRegistryHost replaces the registry host of the image repository.
+func replaceRegistryHost(repository, host string) string {
+	_, path, found := strings.Cut(repository, "/")
+	if !found {
+		return fmt.Sprintf("%s/%s", host, repository)
+	}
+	return fmt.Sprintf("%s/%s", host, path)
+}
+
 // UpdateZarfAgentValues updates the Zarf agent deployment with the new state values
 func UpdateZarfAgentValues(ctx context.Context, opts InstallUpgradeOptions) error {
 	l := logger.From(ctx)
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package helm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/zarf-dev/zarf/src/pkg/pki"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+)
+
+func TestRegistryModeValues(t *testing.T) {
+	t.Parallel()
+
+	currentValues := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "127.0.0.1:31999/library/registry",
+		},
+		"proxy": map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "127.0.0.1:31997/alpine/socat",
+			},
+		},
+	}
+	nodePort := state.RegistryInfo{Address: "127.0.0.1:31999", NodePort: 31999, RegistryMode: state.RegistryModeNodePort}
+	proxy := state.RegistryInfo{Address: "127.0.0.1:5000", NodePort: 5000, RegistryMode: state.RegistryModeProxy}
+
+	t.Run("nodeport to proxy", func(t *testing.T) {
+		t.Parallel()
+		s := &state.State{
+			RegistryInfo: proxy,
+			RegistryProxyTLS: &state.RegistryProxyTLS{
+				Server: pki.GeneratedPKI{CA: []byte("ca"), Cert: []byte("server-cert"), Key: []byte("server-key")},
+				Client: pki.GeneratedPKI{CA: []byte("ca"), Cert: []byte("client-cert"), Key: []byte("client-key")},
+			},
+		}
+		vals, err := registryModeValues(currentValues, nodePort, s)
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"service.nodePort":                5000,
+			"proxy.enabled":                   true,
+			"proxy.fallbackNodePort":          31999,
+			"proxy.injector.enabled":          false,
+			"proxy.image.repository":          "127.0.0.1:31999/alpine/socat",
+			"proxy.registry.image.repository": "127.0.0.1:31999/library/registry",
+			"proxy.tls.enabled":               true,
+			"proxy.tls.clientKey":             "Y2xpZW50LWtleQ==",
+		}
+		for path, value := range expected {
+			actual, err := chartutil.Values(vals).PathValue(path)
+			require.NoError(t, err)
+			require.Equal(t, value, actual, path)
+		}
+
+		s.RegistryProxyTLS = nil
+		_, err = registryModeValues(currentValues, nodePort, s)
+		require.ErrorContains(t, err, "the registry proxy certificates are missing")
+	})
+
+	t.Run("proxy to nodeport", func(t *testing.T) {
+		t.Parallel()
+		s := &state.State{RegistryInfo: nodePort}
+		vals, err := registryModeValues(currentValues, proxy, s)
+		require.NoError(t, err)
+		expected := map[string]interface{}{
+			"service.nodePort":       31999,
+			"image.repository":       "127.0.0.1:31999/library/registry",
+			"proxy.enabled":          false,
+			"proxy.fallbackNodePort": 0,
+			"proxy.tls.enabled":      false,
+		}
+		for path, value := range expected {
+			actual, err := chartutil.Values(vals).PathValue(path)
+			require.NoError(t, err)
+			require.Equal(t, value, actual, path)
+		}
+	})
+
+	t.Run("missing images", func(t *testing.T) {
+		t.Parallel()
+		s := &state.State{RegistryInfo: nodePort}
+		_, err := registryModeValues(map[string]interface{}{}, proxy, s)
+		require.ErrorContains(t, err, "unable to find the registry image")
+	})
+}
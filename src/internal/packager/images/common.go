@@ -54,6 +54,8 @@ type PushConfig struct {
 	InsecureSkipTLSVerify bool
 	Cluster               *cluster.Cluster
 	ResponseHeaderTimeout time.Duration
+	// Transforms are the image transform rules of the package, they do not apply in mirror mode
+	Transforms transform.ImageRules
 }
 
 // RegistryOverride describes an override for a specific registry.
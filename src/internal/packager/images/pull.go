@@ -29,6 +29,7 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"golang.org/x/sync/errgroup"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/oci"
 	"oras.land/oras-go/v2/registry"
 
@@ -49,6 +50,9 @@ type imagePullInfo struct {
 	ref                 string
 	manifestDesc        ocispec.Descriptor
 	byteSize            int64
+	// index is the index a tagged image resolved to, recorded in the image cache to resolve the tag offline
+	index      ocispec.Descriptor
+	indexBytes []byte
 }
 
 type imageWithOverride struct {
@@ -143,7 +147,7 @@ func Pull(ctx context.Context, cfg PullConfig) (map[transform.Image]ocispec.Mani
 
 	// This loop pulls the metadata from images with three goals
 	// - Get all the manifests from images that will be pulled so they can be returned to the function
-	// - discover if any images are sha'd to an index, if so error and inform user on the different available platforms
+	// - discover if any images are sha'd to an index without the architecture, if so error and inform user on the different available platforms
 	// - Mark any images that don't resolve so we can attempt to pull them from the daemon
 	eg, ectx := errgroup.WithContext(ctx)
 	eg.SetLimit(10)
@@ -184,7 +188,7 @@ func Pull(ctx context.Context, cfg PullConfig) (map[transform.Image]ocispec.Mani
 				return nil
 			}
 
-			// If the image sha points to an index then error
+			// If the image sha points to an index the whole index is pulled so that the image keeps its digest
 			if image.original.Digest != "" && isIndex(desc.MediaType) {
 				// Both index types can be marshalled into an ocispec.Index
 				// https://github.com/oras-project/oras-go/blob/853e0125ccad32ff691e4ed70e156c7619021bfd/internal/manifestutil/parser.go#L55
@@ -192,11 +196,39 @@ func Pull(ctx context.Context, cfg PullConfig) (map[transform.Image]ocispec.Mani
 				if err := json.Unmarshal(b, &idx); err != nil {
 					return fmt.Errorf("unable to unmarshal index.json: %w", err)
 				}
-				return constructIndexError(idx, image.overridden)
+				fetchOpts.FetchOptions.TargetPlatform = platform
+				_, mb, err := oras.FetchBytes(ectx, repo, image.overridden.Reference, fetchOpts)
+				if err != nil {
+					return constructIndexError(idx, image.overridden, platform.Architecture)
+				}
+				var manifest ocispec.Manifest
+				if err := json.Unmarshal(mb, &manifest); err != nil {
+					return err
+				}
+				size, err := getSizeOfIndex(ectx, repo, desc, idx)
+				if err != nil {
+					return fmt.Errorf("failed to fetch the images of the index %s: %w", image.overridden.Reference, err)
+				}
+				imageListLock.Lock()
+				defer imageListLock.Unlock()
+				imagesInfo = append(imagesInfo, imagePullInfo{
+					registryOverrideRef: image.overridden.Reference,
+					ref:                 image.original.Reference,
+					byteSize:            size,
+					manifestDesc:        desc,
+				})
+				imagesWithManifests[image.original] = manifest
+				l.Debug("pulled index for image", "name", image.overridden.Reference)
+				return nil
 			}
 			// If a manifest was returned from FetchBytes, either it's a tag with only one image or it's a non container image
 			// If it's not a manifest then we received an index and need to pull the manifest by platform
+			var index ocispec.Descriptor
+			var indexBytes []byte
 			if !isManifest(desc.MediaType) {
+				if isIndex(desc.MediaType) {
+					index, indexBytes = desc, b
+				}
 				fetchOpts.FetchOptions.TargetPlatform = platform
 				desc, b, err = oras.FetchBytes(ectx, repo, image.overridden.Reference, fetchOpts)
 				if err != nil {
@@ -222,6 +254,8 @@ func Pull(ctx context.Context, cfg PullConfig) (map[transform.Image]ocispec.Mani
 				ref:                 image.original.Reference,
 				byteSize:            size,
 				manifestDesc:        desc,
+				index:               index,
+				indexBytes:          indexBytes,
 			})
 			imagesWithManifests[image.original] = manifest
 			l.Debug("pulled manifest for image", "name", image.overridden.Reference)
@@ -260,7 +294,7 @@ func Pull(ctx context.Context, cfg PullConfig) (map[transform.Image]ocispec.Mani
 	return imagesWithManifests, nil
 }
 
-func constructIndexError(idx ocispec.Index, image transform.Image) error {
+func constructIndexError(idx ocispec.Index, image transform.Image, arch string) error {
 	lines := []string{"The following images are available in the index:"}
 	name := image.Name
 	if image.Tag != "" {
@@ -270,7 +304,28 @@ func constructIndexError(idx ocispec.Index, image transform.Image) error {
 		lines = append(lines, fmt.Sprintf("image - %s@%s with platform %s", name, desc.Digest, desc.Platform))
 	}
 	imageOptions := strings.Join(lines, "\n")
-	return fmt.Errorf("%s resolved to an OCI image index which is not supported by Zarf, select a specific platform to use: %s", image.Reference, imageOptions)
+	return fmt.Errorf("%s resolved to an OCI image index without an image for the architecture %s, select a specific platform to use: %s", image.Reference, arch, imageOptions)
+}
+
+// getSizeOfIndex returns the size of an index and of every image it references.
+func getSizeOfIndex(ctx context.Context, repo *orasRemote.Repository, desc ocispec.Descriptor, idx ocispec.Index) (int64, error) {
+	totalSize := desc.Size
+	for _, manifestDesc := range idx.Manifests {
+		if !isManifest(manifestDesc.MediaType) {
+			totalSize += manifestDesc.Size
+			continue
+		}
+		b, err := content.FetchAll(ctx, repo, manifestDesc)
+		if err != nil {
+			return 0, err
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(b, &manifest); err != nil {
+			return 0, err
+		}
+		totalSize += getSizeOfImage(manifestDesc, manifest)
+	}
+	return totalSize, nil
 }
 
 func getDockerEndpointHost() (string, error) {
@@ -459,9 +514,19 @@ func orasSave(ctx context.Context, imageInfo imagePullInfo, cfg PullConfig, dst
 	}
 	desc.Annotations[ocispec.AnnotationRefName] = imageInfo.ref
 	desc.Annotations[ocispec.AnnotationBaseImageName] = imageInfo.ref
+	// The platform of an index records which of its images is deployed
+	if isIndex(desc.MediaType) {
+		desc.Platform = &ocispec.Platform{Architecture: cfg.Arch, OS: "linux"}
+	}
 	err = dst.Tag(ctx, desc, imageInfo.ref)
 	if err != nil {
 		return fmt.Errorf("failed to tag image: %w", err)
 	}
+	// The cache is only tagged to resolve digests offline, so a failure does not fail the pull.
+	if !strings.Contains(imageInfo.ref, "@") {
+		if err := tagCache(ctx, cfg.CacheDirectory, imageInfo, desc, cfg.Arch); err != nil {
+			l.Debug("unable to tag the image cache", "image", imageInfo.ref, "error", err)
+		}
+	}
 	return nil
 }
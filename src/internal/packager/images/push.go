@@ -24,6 +24,7 @@ import (
 	"github.com/zarf-dev/zarf/src/internal/dns"
 	"github.com/zarf-dev/zarf/src/pkg/cluster"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
 	"github.com/zarf-dev/zarf/src/pkg/transform"
 )
 
@@ -136,49 +137,66 @@ func Push(ctx context.Context, cfg PushConfig) error {
 				delete(toPush, refInfo)
 			}
 		}()
+		pushWithRetry := func(img, dstName string) error {
+			return retry.Do(
+				func() error { return pushImage(img, dstName) },
+				retry.OnRetry(func(_ uint, err error) {
+					ociConcurrency = 1
+					l.Debug("retrying image push", "error", err, "concurrency", ociConcurrency)
+				}),
+				retry.Context(ctx),
+				retry.Attempts(2),
+				retry.Delay(500*time.Millisecond),
+			)
+		}
 		for img := range toPush {
 			l.Info("pushing image", "name", img)
+			dstNames := []string{}
 			// If this is not a no checksum image push it for use with the Zarf agent
 			if !cfg.NoChecksum {
-				offlineNameCRC, err := transform.ImageTransformHost(registryRef.String(), img)
-				if err != nil {
-					return err
+				transformHost := func(targetHost, srcReference string) (string, error) {
+					return transform.ImageTransformHostWithRules(targetHost, srcReference, cfg.Transforms)
 				}
-
-				err = retry.Do(
-					func() error { return pushImage(img, offlineNameCRC) },
-					retry.OnRetry(func(_ uint, err error) {
-						ociConcurrency = 1
-						l.Debug("retrying image push", "error", err, "concurrency", ociConcurrency)
-					}),
-					retry.Context(ctx),
-					retry.Attempts(2),
-					retry.Delay(500*time.Millisecond),
-				)
+				// In mirror mode images are pulled by their original name through the node mirrors instead
+				if cfg.RegistryInfo.RegistryMode == state.RegistryModeMirror {
+					transformHost = transform.ImageTransformMirror
+				}
+				offlineNameCRC, err := transformHost(registryRef.String(), img)
 				if err != nil {
 					return err
 				}
+				dstNames = append(dstNames, offlineNameCRC)
 			}
 
 			// To allow for other non-zarf workloads to easily see the images upload a non-checksum version
 			// (this may result in collisions but this is acceptable for this use case)
-			offlineName, err := transform.ImageTransformHostWithoutChecksum(registryRef.String(), img)
+			offlineName, err := transform.ImageTransformHostWithRulesWithoutChecksum(registryRef.String(), img, cfg.Transforms)
 			if err != nil {
 				return err
 			}
+			dstNames = append(dstNames, offlineName)
 
-			err = retry.Do(
-				func() error { return pushImage(img, offlineName) },
-				retry.OnRetry(func(_ uint, err error) {
-					ociConcurrency = 1
-					l.Debug("retrying image push", "error", err, "concurrency", ociConcurrency)
-				}),
-				retry.Context(ctx),
-				retry.Attempts(2),
-				retry.Delay(500*time.Millisecond),
-			)
-			if err != nil {
-				return err
+			// The agent only applies the transform rules to resources labelled with their package, so workloads such as
+			// CronJobs or the resources synced by Flux and Argo CD are mutated to the default names which are pushed as well
+			if len(cfg.Transforms) > 0 {
+				if !cfg.NoChecksum && cfg.RegistryInfo.RegistryMode != state.RegistryModeMirror {
+					defaultNameCRC, err := transform.ImageTransformHost(registryRef.String(), img)
+					if err != nil {
+						return err
+					}
+					dstNames = append(dstNames, defaultNameCRC)
+				}
+				defaultName, err := transform.ImageTransformHostWithoutChecksum(registryRef.String(), img)
+				if err != nil {
+					return err
+				}
+				dstNames = append(dstNames, defaultName)
+			}
+
+			for _, dstName := range helpers.Unique(dstNames) {
+				if err := pushWithRetry(img, dstName); err != nil {
+					return err
+				}
 			}
 
 			pushed = append(pushed, img)
@@ -206,6 +224,11 @@ func addRefNameAnnotationToImages(ociLayoutDirectory string) error {
 	// which ORAS uses to find images. We do this to be backwards compatible with packages built with Crane
 	var correctedManifests []ocispec.Descriptor
 	for _, manifest := range idx.Manifests {
+		// The images of an index are listed without annotations
+		if manifest.Annotations == nil {
+			correctedManifests = append(correctedManifests, manifest)
+			continue
+		}
 		if manifest.Annotations[ocispec.AnnotationRefName] == "" {
 			manifest.Annotations[ocispec.AnnotationRefName] = manifest.Annotations[ocispec.AnnotationBaseImageName]
 		}
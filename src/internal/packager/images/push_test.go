@@ -11,6 +11,7 @@ import (
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 	"github.com/zarf-dev/zarf/src/pkg/state"
 	"github.com/zarf-dev/zarf/src/pkg/transform"
 	"github.com/zarf-dev/zarf/src/test/testutil"
@@ -27,6 +28,7 @@ func TestPush(t *testing.T) {
 		imageNames      []string
 		expectErr       bool
 		namespace       string
+		transforms      []v1alpha1.ZarfImageTransform
 	}{
 		{
 			name: "push local images oras",
@@ -62,6 +64,14 @@ func TestPush(t *testing.T) {
 			},
 			namespace: "my-namespace",
 		},
+		{
+			name:            "push image with transform rules",
+			SourceDirectory: "testdata/oras-oci-layout/images",
+			imageNames: []string{
+				"local-test:1.0.0",
+			},
+			transforms: []v1alpha1.ZarfImageTransform{{Match: `^docker\.io/library/(.*)$`, Path: "mirror/$1"}},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -92,6 +102,9 @@ func TestPush(t *testing.T) {
 				imageList = append(imageList, ref)
 			}
 
+			rules, err := transform.NewImageRules(tc.transforms)
+			require.NoError(t, err)
+
 			// push images to registry
 			cfg := PushConfig{
 				SourceDirectory: tc.SourceDirectory,
@@ -99,6 +112,7 @@ func TestPush(t *testing.T) {
 				PlainHTTP:       true,
 				Arch:            "amd64",
 				ImageList:       imageList,
+				Transforms:      rules,
 			}
 			err = Push(ctx, cfg)
 
@@ -108,7 +122,19 @@ func TestPush(t *testing.T) {
 			}
 			require.NoError(t, err)
 
-			// Verify all images are in the repo
+			// Verify all images are in the repo, the default names are pushed as well for the workloads
+			// the agent does not apply the transform rules to, such as the pods of CronJobs
+			for _, image := range tc.imageNames {
+				checksumRef, err := transform.ImageTransformHostWithRules(address, image, rules)
+				require.NoError(t, err)
+				if len(tc.transforms) > 0 {
+					require.Contains(t, checksumRef, "/mirror/")
+				}
+				verifyImageExists(ctx, t, checksumRef)
+				ref, err := transform.ImageTransformHostWithRulesWithoutChecksum(address, image, rules)
+				require.NoError(t, err)
+				verifyImageExists(ctx, t, ref)
+			}
 			for _, image := range tc.imageNames {
 				checksumRef, err := transform.ImageTransformHost(address, image)
 				require.NoError(t, err)
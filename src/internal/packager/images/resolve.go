@@ -0,0 +1,181 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package images
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/zarf-dev/zarf/src/internal/dns"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+	orasRemote "oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// ResolveConfig is the configuration for resolving image digests.
+type ResolveConfig struct {
+	// Arch is the architecture of the image manifest to resolve
+	Arch string
+	// CacheDirectory is the image cache used when the registry cannot be reached
+	CacheDirectory        string
+	PlainHTTP             bool
+	InsecureSkipTLSVerify bool
+}
+
+// cacheTagLock serializes tagging the image cache as the index of the OCI layout is rewritten on every tag.
+var cacheTagLock sync.Mutex
+
+// Resolve returns the digest of a tagged image, which is the digest of the index for images with several platforms.
+// An index must include an image for the architecture. The digest is resolved against the source registry and falls back to the image cache when the registry cannot be reached.
+func Resolve(ctx context.Context, ref string, cfg ResolveConfig) (string, error) {
+	l := logger.From(ctx)
+	desc, err := resolveRemote(ctx, ref, cfg)
+	if err == nil {
+		return desc.Digest.String(), nil
+	}
+	if cfg.CacheDirectory == "" {
+		return "", err
+	}
+	l.Debug("unable to resolve image from the registry, falling back to the image cache", "image", ref, "error", err)
+	cached, cacheErr := resolveCache(ctx, ref, cfg)
+	if cacheErr != nil {
+		return "", errors.Join(err, cacheErr)
+	}
+	return cached.Digest.String(), nil
+}
+
+func resolveRemote(ctx context.Context, ref string, cfg ResolveConfig) (ocispec.Descriptor, error) {
+	// Short names such as nginx:1.27 are expanded to their registry and repository
+	img, err := transform.ParseImageRef(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	ref = img.Reference
+	credStore, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	transport, err := orasTransport(cfg.InsecureSkipTLSVerify, 0)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	client := &auth.Client{
+		Client:     &http.Client{Transport: transport},
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(credStore),
+	}
+
+	repo := &orasRemote.Repository{Client: client, PlainHTTP: cfg.PlainHTTP}
+	repo.Reference, err = registry.ParseReference(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if dns.IsLocalhost(repo.Reference.Host()) && !cfg.PlainHTTP {
+		repo.PlainHTTP, err = ShouldUsePlainHTTP(ctx, repo.Reference.Host(), client)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("unable to connect to the registry %s: %w", repo.Reference.Host(), err)
+		}
+	}
+
+	fetchOpts := oras.DefaultFetchBytesOptions
+	desc, _, err := oras.FetchBytes(ctx, repo, ref, fetchOpts)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to resolve image %s: %w", ref, err)
+	}
+	// An index keeps every platform of the image, so it is pinned once it is known to include the architecture.
+	if isIndex(desc.MediaType) {
+		fetchOpts.FetchOptions.TargetPlatform = &ocispec.Platform{Architecture: cfg.Arch, OS: "linux"}
+		if _, _, err := oras.FetchBytes(ctx, repo, ref, fetchOpts); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to resolve image %s with architecture %s: %w", ref, cfg.Arch, err)
+		}
+		return desc, nil
+	}
+	if !isManifest(desc.MediaType) {
+		return ocispec.Descriptor{}, fmt.Errorf("image %s resolved to the unexpected mediatype %s", ref, desc.MediaType)
+	}
+	return desc, nil
+}
+
+func resolveCache(ctx context.Context, ref string, cfg ResolveConfig) (ocispec.Descriptor, error) {
+	// The cache is tagged with the normalized reference of the image.
+	img, err := transform.ParseImageRef(ref)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	ref = img.Reference
+	if _, err := os.Stat(filepath.Join(cfg.CacheDirectory, ocispec.ImageIndexFile)); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("image %s is not in the image cache", ref)
+	}
+	store, err := oci.NewWithContext(ctx, cfg.CacheDirectory)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to open the image cache: %w", err)
+	}
+	desc, err := store.Resolve(ctx, ref)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("image %s is not in the image cache: %w", ref, err)
+	}
+	if isIndex(desc.MediaType) {
+		b, err := content.FetchAll(ctx, store, desc)
+		if err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("failed to read the index of image %s from the image cache: %w", ref, err)
+		}
+		var idx ocispec.Index
+		if err := json.Unmarshal(b, &idx); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("unable to unmarshal index.json: %w", err)
+		}
+		if slices.ContainsFunc(idx.Manifests, func(m ocispec.Descriptor) bool {
+			return m.Platform != nil && m.Platform.Architecture == cfg.Arch
+		}) {
+			return desc, nil
+		}
+		return ocispec.Descriptor{}, fmt.Errorf("image %s is not in the image cache for the architecture %s", ref, cfg.Arch)
+	}
+	if desc.Platform == nil || desc.Platform.Architecture != cfg.Arch {
+		return ocispec.Descriptor{}, fmt.Errorf("image %s is not in the image cache for the architecture %s", ref, cfg.Arch)
+	}
+	return desc, nil
+}
+
+// tagCache records what an image tag resolved to in the image cache so it can be resolved offline.
+// A tag that resolved to an index is recorded with the index, which is stored in the cache as only the image of the architecture is pulled.
+func tagCache(ctx context.Context, cacheDirectory string, imageInfo imagePullInfo, desc ocispec.Descriptor, arch string) error {
+	cacheTagLock.Lock()
+	defer cacheTagLock.Unlock()
+	store, err := oci.NewWithContext(ctx, cacheDirectory)
+	if err != nil {
+		return err
+	}
+	if imageInfo.indexBytes != nil {
+		exists, err := store.Exists(ctx, imageInfo.index)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if err := store.Push(ctx, imageInfo.index, bytes.NewReader(imageInfo.indexBytes)); err != nil {
+				return err
+			}
+		}
+		index := imageInfo.index
+		index.Annotations = nil
+		return store.Tag(ctx, index, imageInfo.ref)
+	}
+	desc.Platform = &ocispec.Platform{Architecture: arch, OS: "linux"}
+	desc.Annotations = nil
+	return store.Tag(ctx, desc, imageInfo.ref)
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package images
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	"github.com/zarf-dev/zarf/src/pkg/utils"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
+	orasRemote "oras.land/oras-go/v2/registry/remote"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.TestContext(t)
+
+	port, err := helpers.GetAvailablePort()
+	require.NoError(t, err)
+	address := testutil.SetupInMemoryRegistry(ctx, t, port)
+	ref := fmt.Sprintf("%s/local-test:1.0.0", address)
+	expectedDigest := "sha256:6a88822e787d1df28b83ebd72eb348569fc44351b84bba25c0bc566f6083965b"
+
+	src, err := oci.NewFromFS(ctx, os.DirFS("testdata/oras-oci-layout/images"))
+	require.NoError(t, err)
+	repo := &orasRemote.Repository{PlainHTTP: true}
+	repo.Reference, err = registry.ParseReference(ref)
+	require.NoError(t, err)
+	_, err = oras.Copy(ctx, src, "docker.io/library/local-test:1.0.0", repo, ref, oras.DefaultCopyOptions)
+	require.NoError(t, err)
+
+	cacheDir := t.TempDir()
+	cfg := ResolveConfig{
+		Arch:           "amd64",
+		CacheDirectory: cacheDir,
+		PlainHTTP:      true,
+	}
+	digest, err := Resolve(ctx, ref, cfg)
+	require.NoError(t, err)
+	require.Equal(t, expectedDigest, digest)
+
+	// References are normalized before they are resolved
+	_, err = oras.Copy(ctx, src, "docker.io/library/local-test:1.0.0", repo, "latest", oras.DefaultCopyOptions)
+	require.NoError(t, err)
+	digest, err = Resolve(ctx, fmt.Sprintf("%s/local-test", address), ResolveConfig{Arch: "amd64", PlainHTTP: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedDigest, digest)
+
+	// The image is not in the cache until it has been pulled.
+	_, err = resolveCache(ctx, ref, cfg)
+	require.ErrorContains(t, err, "is not in the image cache")
+
+	img, err := transform.ParseImageRef(ref)
+	require.NoError(t, err)
+	_, err = Pull(ctx, PullConfig{
+		DestinationDirectory: filepath.Join(t.TempDir(), "images"),
+		CacheDirectory:       cacheDir,
+		ImageList:            []transform.Image{img},
+		Arch:                 "amd64",
+		PlainHTTP:            true,
+	})
+	require.NoError(t, err)
+
+	desc, err := resolveCache(ctx, ref, cfg)
+	require.NoError(t, err)
+	require.Equal(t, expectedDigest, desc.Digest.String())
+
+	cfg.Arch = "arm64"
+	_, err = resolveCache(ctx, ref, cfg)
+	require.ErrorContains(t, err, "is not in the image cache for the architecture arm64")
+}
+
+func TestResolveIndex(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.TestContext(t)
+
+	port, err := helpers.GetAvailablePort()
+	require.NoError(t, err)
+	address := testutil.SetupInMemoryRegistry(ctx, t, port)
+	ref := fmt.Sprintf("%s/local-test:1.0.0", address)
+	manifestDigest := "sha256:6a88822e787d1df28b83ebd72eb348569fc44351b84bba25c0bc566f6083965b"
+
+	src, err := oci.NewFromFS(ctx, os.DirFS("testdata/oras-oci-layout/images"))
+	require.NoError(t, err)
+	repo := &orasRemote.Repository{PlainHTTP: true}
+	repo.Reference, err = registry.ParseReference(ref)
+	require.NoError(t, err)
+	manifestDesc, err := oras.Copy(ctx, src, "docker.io/library/local-test:1.0.0", repo, "amd64", oras.DefaultCopyOptions)
+	require.NoError(t, err)
+	manifestDesc.Platform = &ocispec.Platform{Architecture: "amd64", OS: "linux"}
+	manifestDesc.Annotations = nil
+	b, err := json.Marshal(ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{manifestDesc},
+	})
+	require.NoError(t, err)
+	indexDesc, err := oras.TagBytes(ctx, repo, ocispec.MediaTypeImageIndex, b, "1.0.0")
+	require.NoError(t, err)
+
+	// The index is pinned instead of the image of the architecture
+	cacheDir := t.TempDir()
+	cfg := ResolveConfig{
+		Arch:           "amd64",
+		CacheDirectory: cacheDir,
+		PlainHTTP:      true,
+	}
+	digest, err := Resolve(ctx, ref, cfg)
+	require.NoError(t, err)
+	require.Equal(t, indexDesc.Digest.String(), digest)
+	_, err = Resolve(ctx, ref, ResolveConfig{Arch: "arm64", PlainHTTP: true})
+	require.ErrorContains(t, err, "with architecture arm64")
+
+	// Pulling the tag records the index in the cache
+	img, err := transform.ParseImageRef(ref)
+	require.NoError(t, err)
+	_, err = Pull(ctx, PullConfig{
+		DestinationDirectory: filepath.Join(t.TempDir(), "images"),
+		CacheDirectory:       cacheDir,
+		ImageList:            []transform.Image{img},
+		Arch:                 "amd64",
+		PlainHTTP:            true,
+	})
+	require.NoError(t, err)
+	desc, err := resolveCache(ctx, ref, cfg)
+	require.NoError(t, err)
+	require.Equal(t, indexDesc.Digest, desc.Digest)
+	cfg.Arch = "arm64"
+	_, err = resolveCache(ctx, ref, cfg)
+	require.ErrorContains(t, err, "is not in the image cache for the architecture arm64")
+
+	// Pulling the pinned index keeps the digest and loads the image of the architecture
+	pinned, err := transform.ParseImageRef(fmt.Sprintf("%s@%s", ref, digest))
+	require.NoError(t, err)
+	imagesDir := filepath.Join(t.TempDir(), "images")
+	manifests, err := Pull(ctx, PullConfig{
+		DestinationDirectory: imagesDir,
+		CacheDirectory:       t.TempDir(),
+		ImageList:            []transform.Image{pinned},
+		Arch:                 "amd64",
+		PlainHTTP:            true,
+	})
+	require.NoError(t, err)
+	require.Contains(t, manifests, pinned)
+	idx, err := getIndexFromOCILayout(imagesDir)
+	require.NoError(t, err)
+	require.Equal(t, indexDesc.Digest, idx.Manifests[0].Digest)
+	require.Equal(t, pinned.Reference, idx.Manifests[0].Annotations[ocispec.AnnotationRefName])
+	require.Equal(t, "amd64", idx.Manifests[0].Platform.Architecture)
+	require.NoError(t, addRefNameAnnotationToImages(imagesDir))
+	loaded, err := utils.LoadOCIImage(imagesDir, pinned)
+	require.NoError(t, err)
+	loadedDigest, err := loaded.Digest()
+	require.NoError(t, err)
+	require.Equal(t, manifestDigest, loadedDigest.String())
+
+	_, err = Pull(ctx, PullConfig{
+		DestinationDirectory: filepath.Join(t.TempDir(), "images"),
+		CacheDirectory:       t.TempDir(),
+		ImageList:            []transform.Image{pinned},
+		Arch:                 "arm64",
+		PlainHTTP:            true,
+	})
+	require.ErrorContains(t, err, "without an image for the architecture arm64")
+}
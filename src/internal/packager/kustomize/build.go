@@ -5,28 +5,66 @@
 package kustomize
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/pkg/utils"
+	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/krusty"
 	krustytypes "sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
 )
 
+// Options are the optional parameters to Build and Vendor.
+type Options struct {
+	// AllowAnyDirectory allows kustomizations to load files outside of their directory
+	AllowAnyDirectory bool
+	// EnablePlugins enables kustomize plugins during the build
+	EnablePlugins bool
+	// Components are local paths or remote URLs of kustomize components applied to the kustomization
+	Components []string
+	// Patches are inline strategic merge or JSON 6902 patches applied to the kustomization
+	Patches []krustytypes.Patch
+	// Scope is the directory that local files must be within to be vendored, defaults to the kustomization directory
+	Scope string
+	// CachePath is the directory remote kustomizations are cached in when vendored
+	CachePath string
+}
+
 // Build reads a kustomization and builds it into a single yaml file.
-func Build(path string, destination string, kustomizeAllowAnyDirectory bool, enableKustomizePlugins bool) error {
+func Build(path string, destination string, opts Options) (err error) {
+	if len(opts.Components) > 0 || len(opts.Patches) > 0 {
+		// Components and patches are applied by a kustomization that includes the original as a resource.
+		var tmpDir string
+		tmpDir, err = utils.MakeTempDir(config.CommonOptions.TempDirectory)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = errors.Join(err, os.RemoveAll(tmpDir))
+		}()
+		if err := writeWrapper(tmpDir, path, opts); err != nil {
+			return err
+		}
+		path = tmpDir
+	}
+
 	// Kustomize has to write to the filesystem on-disk
 	fSys := filesys.MakeFsOnDisk()
 
 	// flux2 build options for consistency, load restrictions none applies only to local files
 	buildOptions := krusty.MakeDefaultOptions()
 
-	if kustomizeAllowAnyDirectory {
+	if opts.AllowAnyDirectory {
 		buildOptions.LoadRestrictions = krustytypes.LoadRestrictionsNone
 	}
 
-	if enableKustomizePlugins {
+	if opts.EnablePlugins {
 		buildOptions.PluginConfig = krustytypes.MakePluginConfig(krustytypes.PluginRestrictionsNone, krustytypes.BploUseStaticallyLinked)
 	}
 
@@ -46,3 +84,52 @@ func Build(path string, destination string, kustomizeAllowAnyDirectory bool, ena
 
 	return os.WriteFile(destination, yaml, helpers.ReadWriteUser)
 }
+
+// writeWrapper writes a kustomization to dir that applies the components and patches of opts to the kustomization at path.
+func writeWrapper(dir string, path string, opts Options) error {
+	resource, err := relativeIfLocal(dir, path)
+	if err != nil {
+		return err
+	}
+	components := []string{}
+	for _, component := range opts.Components {
+		component, err := relativeIfLocal(dir, component)
+		if err != nil {
+			return err
+		}
+		components = append(components, component)
+	}
+	return writeKustomization(dir, krustytypes.Kustomization{
+		Resources:  []string{resource},
+		Components: components,
+		Patches:    opts.Patches,
+	})
+}
+
+// relativeIfLocal returns the path of a local kustomization relative to dir, as kustomize does not allow absolute paths, and leaves remote URLs as they are.
+func relativeIfLocal(dir string, path string) (string, error) {
+	if helpers.InvalidPath(path) {
+		return path, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+func writeKustomization(dir string, kustomization krustytypes.Kustomization) error {
+	if kustomization.Kind == "" {
+		kustomization.Kind = krustytypes.KustomizationKind
+	}
+	kustomization.APIVersion = krustytypes.KustomizationVersion
+	b, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, konfig.DefaultKustomizationFileName()), b, helpers.ReadWriteUser)
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	krustytypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+func TestBuildAndVendor(t *testing.T) {
+	t.Parallel()
+
+	expected := `apiVersion: v1
+data:
+  replicas: "3"
+kind: ConfigMap
+metadata:
+  labels:
+    component: enabled
+  name: app
+`
+	opts := Options{
+		Components: []string{filepath.Join("testdata", "component")},
+		Patches: []krustytypes.Patch{
+			{Patch: "- op: replace\n  path: /data/replicas\n  value: \"3\"\n", Target: &krustytypes.Selector{ResId: resid.ResId{Gvk: resid.Gvk{Kind: "ConfigMap"}, Name: "app"}}},
+		},
+	}
+
+	built := filepath.Join(t.TempDir(), "built.yaml")
+	err := Build(filepath.Join("testdata", "base"), built, opts)
+	require.NoError(t, err)
+	b, err := os.ReadFile(built)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+
+	vendorDir := filepath.Join(t.TempDir(), "vendor")
+	err = Vendor(filepath.Join("testdata", "base"), vendorDir, opts)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(vendorDir, "resources", "configmap.yaml"))
+	require.FileExists(t, filepath.Join(vendorDir, "components", "0", "kustomization.yaml"))
+
+	// The vendored kustomization builds without the original sources.
+	rebuilt := filepath.Join(t.TempDir(), "rebuilt.yaml")
+	err = Build(vendorDir, rebuilt, Options{})
+	require.NoError(t, err)
+	b, err = os.ReadFile(rebuilt)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+}
+
+func TestOverlay(t *testing.T) {
+	t.Parallel()
+
+	manifests := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      containers:
+        - name: podinfo
+          image: ghcr.io/stefanprodan/podinfo:6.4.0
+`)
+	component := map[string][]byte{
+		"kustomization.yaml": []byte(`apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+patches:
+  - path: patches/node-selector.yaml
+`),
+		"patches/node-selector.yaml": []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      nodeSelector:
+        site: edge
+`),
+	}
+	out, err := Overlay(manifests, component)
+	require.NoError(t, err)
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      containers:
+      - image: ghcr.io/stefanprodan/podinfo:6.4.0
+        name: podinfo
+      nodeSelector:
+        site: edge
+`
+	require.Equal(t, expected, string(out))
+
+	_, err = Overlay(manifests, map[string][]byte{"kustomization.yaml": []byte("kind: Kustomization\n")})
+	require.ErrorContains(t, err, "unable to apply the overlay")
+}
+
+func TestIsPinnedRemote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		remote   string
+		expected bool
+	}{
+		{remote: "https://github.com/stefanprodan/podinfo//kustomize?ref=6.4.0", expected: false},
+		{remote: "https://github.com/stefanprodan/podinfo//kustomize?ref=master", expected: false},
+		{remote: "https://github.com/stefanprodan/podinfo//kustomize", expected: false},
+		{remote: "https://github.com/stefanprodan/podinfo//kustomize?ref=0b0c0fbd6b0e2c4f0a8e5c3b0d2a9a0f1e3c4b5a", expected: true},
+		{remote: "https://github.com/stefanprodan/podinfo//kustomize?timeout=2m&version=0b0c0fbd6b0e2c4f0a8e5c3b0d2a9a0f1e3c4b5a", expected: true},
+		{remote: "https://github.com/stefanprodan/podinfo//kustomize?ref=0B0C0FBD6B0E2C4F0A8E5C3B0D2A9A0F1E3C4B5A", expected: false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, IsPinnedRemote(tt.remote), tt.remote)
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package kustomize
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	krustytypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// Overlay applies a kustomize component to rendered manifests and returns the resulting manifests.
+// The files of the component are keyed by their path relative to the component directory.
+func Overlay(manifests []byte, component map[string][]byte) ([]byte, error) {
+	// The overlay is built in memory so the rendered manifests are never written to disk.
+	fSys := filesys.MakeFsInMemory()
+	for name, content := range component {
+		p := path.Join("/overlay", filepath.ToSlash(name))
+		if err := fSys.MkdirAll(path.Dir(p)); err != nil {
+			return nil, err
+		}
+		if err := fSys.WriteFile(p, content); err != nil {
+			return nil, err
+		}
+	}
+	if err := fSys.WriteFile("/manifests.yaml", manifests); err != nil {
+		return nil, err
+	}
+	kustomization := krustytypes.Kustomization{
+		TypeMeta:   krustytypes.TypeMeta{APIVersion: krustytypes.KustomizationVersion, Kind: krustytypes.KustomizationKind},
+		Resources:  []string{"manifests.yaml"},
+		Components: []string{"overlay"},
+	}
+	b, err := yaml.Marshal(kustomization)
+	if err != nil {
+		return nil, err
+	}
+	if err := fSys.WriteFile(path.Join("/", konfig.DefaultKustomizationFileName()), b); err != nil {
+		return nil, err
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, "/")
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply the overlay: %w", err)
+	}
+	return resources.AsYaml()
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package kustomize
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/pkg/utils"
+	"sigs.k8s.io/kustomize/api/krusty/localizer"
+	krustytypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Vendor writes a kustomization to destination that applies the components and patches of opts to the kustomization at path.
+// The kustomization and components are localized into destination with the files and remote bases they reference so it can be built offline.
+func Vendor(path string, destination string, opts Options) error {
+	if err := os.MkdirAll(filepath.Join(destination, "components"), helpers.ReadWriteExecuteUser); err != nil {
+		return err
+	}
+	resource, err := localize(path, filepath.Join(destination, "resources"), krustytypes.KustomizationKind, opts)
+	if err != nil {
+		return fmt.Errorf("unable to vendor kustomization %s: %w", path, err)
+	}
+	kustomization := krustytypes.Kustomization{Patches: opts.Patches}
+	rel, err := filepath.Rel(destination, resource)
+	if err != nil {
+		return err
+	}
+	kustomization.Resources = []string{filepath.ToSlash(rel)}
+	for i, component := range opts.Components {
+		localized, err := localize(component, filepath.Join(destination, "components", strconv.Itoa(i)), krustytypes.ComponentKind, opts)
+		if err != nil {
+			return fmt.Errorf("unable to vendor kustomize component %s: %w", component, err)
+		}
+		rel, err := filepath.Rel(destination, localized)
+		if err != nil {
+			return err
+		}
+		kustomization.Components = append(kustomization.Components, filepath.ToSlash(rel))
+	}
+	return writeKustomization(destination, kustomization)
+}
+
+// localize copies the kustomization at path and everything it references into newDir and returns the directory of the localized kustomization.
+func localize(path string, newDir string, kind string, opts Options) (string, error) {
+	fSys := filesys.MakeFsOnDisk()
+	if helpers.InvalidPath(path) {
+		return localizeRemote(path, newDir, kind, opts.CachePath)
+	}
+
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	scope := ""
+	dst := newDir
+	if opts.Scope != "" {
+		absScope, err := filepath.Abs(opts.Scope)
+		if err != nil {
+			return "", err
+		}
+		// The localized kustomization mirrors its location within the scope.
+		rel, err := filepath.Rel(absScope, target)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			scope = absScope
+			dst = filepath.Join(newDir, rel)
+		}
+	}
+	if _, err := localizer.Run(fSys, target, scope, newDir); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// localizeRemote localizes a remote kustomization through a kustomization that references it.
+// Remote kustomizations pinned to a commit are kept in the cache so later builds do not need to fetch them.
+func localizeRemote(url string, newDir string, kind string, cachePath string) (_ string, err error) {
+	cacheDir := ""
+	if cachePath != "" && IsPinnedRemote(url) {
+		cacheDir = filepath.Join(cachePath, fmt.Sprintf("%x", sha256.Sum256([]byte(kind+url))))
+		if !helpers.InvalidPath(cacheDir) {
+			return newDir, helpers.CreatePathAndCopy(cacheDir, newDir)
+		}
+	}
+
+	tmpDir, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		err = errors.Join(err, os.RemoveAll(tmpDir))
+	}()
+	wrapper := krustytypes.Kustomization{TypeMeta: krustytypes.TypeMeta{Kind: kind}}
+	if kind == krustytypes.ComponentKind {
+		wrapper.Components = []string{url}
+	} else {
+		wrapper.Resources = []string{url}
+	}
+	if err := writeKustomization(tmpDir, wrapper); err != nil {
+		return "", err
+	}
+	if _, err := localizer.Run(filesys.MakeFsOnDisk(), tmpDir, "", newDir); err != nil {
+		return "", err
+	}
+
+	if cacheDir != "" {
+		if err := helpers.CreatePathAndCopy(newDir, cacheDir); err != nil {
+			return "", err
+		}
+	}
+	return newDir, nil
+}
+
+var commitRegex = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// IsPinnedRemote returns true if the remote kustomization references a commit, branches and tags can move
+// so the kustomizations they reference are fetched again on every build.
+func IsPinnedRemote(remote string) bool {
+	_, query, ok := strings.Cut(remote, "?")
+	if !ok {
+		return false
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return false
+	}
+	for _, key := range []string{"ref", "version"} {
+		if commitRegex.MatchString(values.Get(key)) {
+			return true
+		}
+	}
+	return false
+}
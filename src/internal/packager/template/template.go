@@ -15,6 +15,7 @@ import (
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
 
 	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/internal/objectstore"
 	"github.com/zarf-dev/zarf/src/pkg/interactive"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/utils"
@@ -49,6 +50,7 @@ func GetZarfTemplates(ctx context.Context, componentName string, s *state.State)
 			"STORAGE_CLASS":               s.StorageClass,
 			"IPV6_ONLY":                   fmt.Sprintf("%t", s.IPFamily == state.IPFamilyIPv6),
 			"REGISTRY_PROXY":              fmt.Sprintf("%t", s.RegistryInfo.RegistryMode == state.RegistryModeProxy),
+			"REGISTRY_MIRROR":             fmt.Sprintf("%t", s.RegistryInfo.RegistryMode == state.RegistryModeMirror),
 			"INJECTOR_IMAGE":              s.InjectorInfo.Image,
 			"INJECTOR_HOSTPORT":           fmt.Sprintf("%d", s.InjectorInfo.Port),
 			"INJECTOR_PAYLOAD_CONFIGMAPS": fmt.Sprintf("%d", s.InjectorInfo.PayLoadConfigMapAmount),
@@ -85,6 +87,31 @@ func GetZarfTemplates(ctx context.Context, componentName string, s *state.State)
 			}
 			builtinMap["HTPASSWD"] = htpasswd
 			builtinMap["REGISTRY_SECRET"] = regInfo.Secret
+
+			// The registry proxy connects to the registry over mTLS when its certificates exist
+			proxyTLS := s.RegistryProxyTLS
+			builtinMap["REGISTRY_PROXY_TLS"] = fmt.Sprintf("%t", proxyTLS != nil)
+			if proxyTLS == nil {
+				proxyTLS = &state.RegistryProxyTLS{}
+			}
+			builtinMap["REGISTRY_PROXY_CA"] = base64.StdEncoding.EncodeToString(proxyTLS.Server.CA)
+			builtinMap["REGISTRY_PROXY_SERVER_CRT"] = base64.StdEncoding.EncodeToString(proxyTLS.Server.Cert)
+			builtinMap["REGISTRY_PROXY_SERVER_KEY"] = base64.StdEncoding.EncodeToString(proxyTLS.Server.Key)
+			builtinMap["REGISTRY_PROXY_CLIENT_CRT"] = base64.StdEncoding.EncodeToString(proxyTLS.Client.Cert)
+			builtinMap["REGISTRY_PROXY_CLIENT_KEY"] = base64.StdEncoding.EncodeToString(proxyTLS.Client.Key)
+
+			// The registry stores blobs in S3-compatible object storage instead of a PVC when it is configured
+			s3 := regInfo.S3
+			builtinMap["REGISTRY_S3"] = fmt.Sprintf("%t", s3 != nil)
+			if s3 == nil {
+				s3 = &state.RegistryS3Storage{}
+			}
+			builtinMap["REGISTRY_S3_BUCKET"] = s3.Bucket
+			builtinMap["REGISTRY_S3_REGION"] = s3.Region
+			builtinMap["REGISTRY_S3_ENDPOINT"] = objectstore.Endpoint(*s3)
+			builtinMap["REGISTRY_S3_ROOT_DIRECTORY"] = s3.RootDirectory
+			builtinMap["REGISTRY_S3_SECURE"] = fmt.Sprintf("%t", !s3.Insecure)
+			builtinMap["REGISTRY_S3_FORCE_PATH_STYLE"] = fmt.Sprintf("%t", s3.ForcePathStyle)
 		}
 
 		// Iterate over any custom variables and add them to the mappings for templating
@@ -96,7 +123,8 @@ func GetZarfTemplates(ctx context.Context, componentName string, s *state.State)
 
 			if key == "REGISTRY_SECRET" || key == "HTPASSWD" ||
 				key == "AGENT_CA" || key == "AGENT_KEY" || key == "AGENT_CRT" || key == "GIT_AUTH_PULL" ||
-				key == "GIT_AUTH_PUSH" || key == "REGISTRY_AUTH_PULL" || key == "REGISTRY_AUTH_PUSH" {
+				key == "GIT_AUTH_PUSH" || key == "REGISTRY_AUTH_PULL" || key == "REGISTRY_AUTH_PUSH" ||
+				key == "REGISTRY_PROXY_SERVER_KEY" || key == "REGISTRY_PROXY_CLIENT_KEY" {
 				// Sanitize any builtin templates that are sensitive
 				templateMap[strings.ToUpper(fmt.Sprintf("###ZARF_%s###", key))].Sensitive = true
 			}
@@ -6,9 +6,11 @@ package template
 
 import (
 	"context"
+	"encoding/base64"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/pki"
 	"github.com/zarf-dev/zarf/src/pkg/state"
 	"github.com/zarf-dev/zarf/src/pkg/variables"
 )
@@ -123,3 +125,69 @@ func TestGetZarfTemplatesForIPv6SeedRegistry(t *testing.T) {
 		})
 	}
 }
+
+func TestGetZarfTemplatesForRegistryProxyTLS(t *testing.T) {
+	s := state.State{
+		RegistryInfo: state.RegistryInfo{
+			Address:      "127.0.0.1:5000",
+			NodePort:     5000,
+			RegistryMode: state.RegistryModeProxy,
+		},
+		InjectorInfo: state.InjectorInfo{
+			Port: 31997,
+		},
+	}
+	templateMap, err := GetZarfTemplates(context.Background(), "zarf-registry", &s)
+	require.NoError(t, err)
+	require.Equal(t, "true", templateMap["###ZARF_REGISTRY_PROXY###"].Value)
+	require.Equal(t, "false", templateMap["###ZARF_REGISTRY_PROXY_TLS###"].Value)
+	require.Empty(t, templateMap["###ZARF_REGISTRY_PROXY_CLIENT_KEY###"].Value)
+
+	s.RegistryProxyTLS = &state.RegistryProxyTLS{
+		Server: pki.GeneratedPKI{CA: []byte("ca"), Cert: []byte("server-cert"), Key: []byte("server-key")},
+		Client: pki.GeneratedPKI{CA: []byte("ca"), Cert: []byte("client-cert"), Key: []byte("client-key")},
+	}
+	templateMap, err = GetZarfTemplates(context.Background(), "zarf-registry", &s)
+	require.NoError(t, err)
+	require.Equal(t, "true", templateMap["###ZARF_REGISTRY_PROXY_TLS###"].Value)
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte("ca")), templateMap["###ZARF_REGISTRY_PROXY_CA###"].Value)
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte("client-key")), templateMap["###ZARF_REGISTRY_PROXY_CLIENT_KEY###"].Value)
+	require.True(t, templateMap["###ZARF_REGISTRY_PROXY_SERVER_KEY###"].Sensitive)
+	require.True(t, templateMap["###ZARF_REGISTRY_PROXY_CLIENT_KEY###"].Sensitive)
+}
+
+func TestGetZarfTemplatesForRegistryS3(t *testing.T) {
+	s := state.State{
+		RegistryInfo: state.RegistryInfo{
+			Address:      "127.0.0.1:31999",
+			NodePort:     31999,
+			RegistryMode: state.RegistryModeNodePort,
+		},
+	}
+	templateMap, err := GetZarfTemplates(context.Background(), "zarf-registry", &s)
+	require.NoError(t, err)
+	require.Equal(t, "false", templateMap["###ZARF_REGISTRY_S3###"].Value)
+	require.Equal(t, "true", templateMap["###ZARF_REGISTRY_S3_SECURE###"].Value)
+
+	s.RegistryInfo.S3 = &state.RegistryS3Storage{
+		Bucket:         "zarf",
+		Region:         "us-east-1",
+		Endpoint:       "minio.minio.svc:9000",
+		AccessKey:      "s3-access-key",
+		SecretKey:      "s3-secret-key",
+		Insecure:       true,
+		ForcePathStyle: true,
+	}
+	templateMap, err = GetZarfTemplates(context.Background(), "zarf-seed-registry", &s)
+	require.NoError(t, err)
+	require.Equal(t, "true", templateMap["###ZARF_REGISTRY_S3###"].Value)
+	require.Equal(t, "zarf", templateMap["###ZARF_REGISTRY_S3_BUCKET###"].Value)
+	require.Equal(t, "http://minio.minio.svc:9000", templateMap["###ZARF_REGISTRY_S3_ENDPOINT###"].Value)
+	require.Equal(t, "false", templateMap["###ZARF_REGISTRY_S3_SECURE###"].Value)
+	require.Equal(t, "true", templateMap["###ZARF_REGISTRY_S3_FORCE_PATH_STYLE###"].Value)
+	// The keys are read by the registry from a secret instead of the Helm values
+	for _, tmpl := range templateMap {
+		require.NotContains(t, tmpl.Value, "s3-access-key")
+		require.NotContains(t, tmpl.Value, "s3-secret-key")
+	}
+}
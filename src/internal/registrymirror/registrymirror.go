@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+// Package registrymirror writes the containerd registry mirrors of the Zarf registry to a node.
+package registrymirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+)
+
+const (
+	// HostsFile lists each hosts.toml file of the mirrors directory with the host it configures.
+	HostsFile = "hosts"
+	// markerFile marks the host directories written by Zarf so that only those are pruned.
+	markerFile = ".zarf-registry-mirror"
+)
+
+// criRegistryPlugins are the plugins containerd reads the registry config_path from, in config versions 3, 2 and 1.
+var criRegistryPlugins = []string{"io.containerd.cri.v1.images", "io.containerd.grpc.v1.cri", "cri"}
+
+// CheckConfigPath returns an error unless the containerd config reads the registry host configuration from the directory.
+// Containerd ignores the hosts.toml files otherwise and images would keep being pulled from their source.
+func CheckConfigPath(containerdConfig, configPath string) error {
+	var cfg struct {
+		Imports []string                  `toml:"imports"`
+		Plugins map[string]map[string]any `toml:"plugins"`
+	}
+	if _, err := toml.DecodeFile(containerdConfig, &cfg); err != nil {
+		return fmt.Errorf("unable to read the containerd config %s, set REGISTRY_MIRROR_CONTAINERD_CONFIG to the containerd config of the node: %w", containerdConfig, err)
+	}
+	configPath = filepath.Clean(configPath)
+	for _, name := range criRegistryPlugins {
+		registry, ok := cfg.Plugins[name]["registry"].(map[string]any)
+		if !ok {
+			continue
+		}
+		paths, ok := registry["config_path"].(string)
+		if !ok || paths == "" {
+			continue
+		}
+		for _, p := range filepath.SplitList(paths) {
+			if filepath.Clean(p) == configPath {
+				return nil
+			}
+		}
+		return fmt.Errorf("containerd reads registry hosts from %s instead of %s, set REGISTRY_MIRROR_CERTS_DIR to it", paths, configPath)
+	}
+	msg := fmt.Sprintf("containerd does not read registry hosts from a directory, set config_path = %q in the registry section of the CRI plugin in %s and restart containerd", configPath, containerdConfig)
+	if len(cfg.Imports) > 0 {
+		msg += fmt.Sprintf(", config_path is not read from the imported files %s", strings.Join(cfg.Imports, ", "))
+	}
+	return errors.New(msg)
+}
+
+// Sync copies the hosts.toml of every mirrored host into the containerd certs directory and removes the hosts that are no longer mirrored.
+// A missing hosts file is treated as no host being mirrored.
+func Sync(ctx context.Context, mirrorsDir, certsDir string) error {
+	l := logger.From(ctx)
+	hosts, err := readHosts(filepath.Join(mirrorsDir, HostsFile))
+	if err != nil {
+		return err
+	}
+	for host, key := range hosts {
+		b, err := os.ReadFile(filepath.Join(mirrorsDir, key))
+		if err != nil {
+			return err
+		}
+		dir := filepath.Join(certsDir, host)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "hosts.toml"), b, 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, markerFile), nil, 0o644); err != nil {
+			return err
+		}
+		l.Info("wrote the registry mirror", "host", host)
+	}
+
+	// Only directories written by Zarf are pruned
+	markers, err := filepath.Glob(filepath.Join(certsDir, "*", markerFile))
+	if err != nil {
+		return err
+	}
+	for _, marker := range markers {
+		dir := filepath.Dir(marker)
+		if _, ok := hosts[filepath.Base(dir)]; ok {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		l.Info("removed the registry mirror", "host", filepath.Base(dir))
+	}
+	return nil
+}
+
+// readHosts returns the hosts.toml file of each host listed in the hosts file.
+func readHosts(path string) (map[string]string, error) {
+	hosts := map[string]string{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return hosts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		key, host := fields[0], fields[1]
+		// The host is used as a directory name and the key as a file name
+		if !isFileName(key) || !isFileName(host) {
+			return nil, fmt.Errorf("invalid registry mirror %q for the host %q", key, host)
+		}
+		hosts[host] = key
+	}
+	return hosts, nil
+}
+
+func isFileName(name string) bool {
+	return name != "" && !slices.Contains([]string{".", ".."}, name) && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package registrymirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConfigPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name: "config version 2",
+			config: `version = 2
+[plugins."io.containerd.grpc.v1.cri".registry]
+  config_path = "/etc/containerd/certs.d"
+`,
+		},
+		{
+			name: "config version 3 with several directories",
+			config: `version = 3
+[plugins."io.containerd.cri.v1.images".registry]
+  config_path = "/etc/docker/certs.d:/etc/containerd/certs.d/"
+`,
+		},
+		{
+			name: "config_path not set",
+			config: `version = 2
+imports = ["/etc/containerd/conf.d/*.toml"]
+[plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+  endpoint = ["https://registry-1.docker.io"]
+`,
+			expectedErr: `containerd does not read registry hosts from a directory, set config_path = "/etc/containerd/certs.d"`,
+		},
+		{
+			name: "config_path set to another directory",
+			config: `version = 2
+[plugins."io.containerd.grpc.v1.cri".registry]
+  config_path = "/var/lib/rancher/k3s/agent/etc/containerd/certs.d"
+`,
+			expectedErr: "containerd reads registry hosts from /var/lib/rancher/k3s/agent/etc/containerd/certs.d instead of /etc/containerd/certs.d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "config.toml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.config), 0o644))
+			err := CheckConfigPath(path, "/etc/containerd/certs.d")
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	err := CheckConfigPath(filepath.Join(t.TempDir(), "config.toml"), "/etc/containerd/certs.d")
+	require.ErrorContains(t, err, "set REGISTRY_MIRROR_CONTAINERD_CONFIG")
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mirrorsDir := t.TempDir()
+	certsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorsDir, HostsFile), []byte("ghcr.io.toml ghcr.io\nlocalhost_5000.toml localhost:5000\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorsDir, "ghcr.io.toml"), []byte("ghcr"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorsDir, "localhost_5000.toml"), []byte("localhost"), 0o644))
+
+	// Hosts configured by others are kept while hosts Zarf no longer mirrors are removed
+	require.NoError(t, os.MkdirAll(filepath.Join(certsDir, "quay.io"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(certsDir, "docker.io"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(certsDir, "docker.io", markerFile), nil, 0o644))
+
+	require.NoError(t, Sync(ctx, mirrorsDir, certsDir))
+	b, err := os.ReadFile(filepath.Join(certsDir, "ghcr.io", "hosts.toml"))
+	require.NoError(t, err)
+	require.Equal(t, "ghcr", string(b))
+	b, err = os.ReadFile(filepath.Join(certsDir, "localhost:5000", "hosts.toml"))
+	require.NoError(t, err)
+	require.Equal(t, "localhost", string(b))
+	require.DirExists(t, filepath.Join(certsDir, "quay.io"))
+	require.NoDirExists(t, filepath.Join(certsDir, "docker.io"))
+
+	// Every mirror is removed once the config map no longer exists
+	require.NoError(t, os.Remove(filepath.Join(mirrorsDir, HostsFile)))
+	require.NoError(t, Sync(ctx, mirrorsDir, certsDir))
+	require.NoDirExists(t, filepath.Join(certsDir, "ghcr.io"))
+	require.DirExists(t, filepath.Join(certsDir, "quay.io"))
+
+	// Hosts can not escape the certs directory
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorsDir, HostsFile), []byte("ghcr.io.toml ..\n"), 0o644))
+	require.ErrorContains(t, Sync(ctx, mirrorsDir, certsDir), `invalid registry mirror "ghcr.io.toml" for the host ".."`)
+}
@@ -9,9 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/mholt/archives"
 	"github.com/zarf-dev/zarf/src/config/lang"
@@ -104,11 +107,14 @@ func findArchiver(name string) (archives.Archiver, error) {
 	return nil, fmt.Errorf("unsupported archive extension for %q", name)
 }
 
-// CompressOpts holds future optional parameters for Compress.
-type CompressOpts struct{}
+// CompressOpts holds optional parameters for Compress.
+type CompressOpts struct {
+	// ModTime, when set, replaces the modification time of every file and omits file ownership so the archive is reproducible.
+	ModTime *time.Time
+}
 
 // Compress archives the given sources into dest, selecting the format by dest's extension.
-func Compress(ctx context.Context, sources []string, dest string, _ CompressOpts) (err error) {
+func Compress(ctx context.Context, sources []string, dest string, opts CompressOpts) (err error) {
 	if len(sources) == 0 {
 		return fmt.Errorf("sources cannot be empty")
 	}
@@ -137,6 +143,15 @@ func Compress(ctx context.Context, sources []string, dest string, _ CompressOpts
 	if err != nil {
 		return fmt.Errorf("failed to stat sources: %w", err)
 	}
+	// The sources are mapped in random order, sort them so the archive entries are written in the same order every time.
+	slices.SortFunc(files, func(a, b archives.FileInfo) int {
+		return strings.Compare(a.NameInArchive, b.NameInArchive)
+	})
+	if opts.ModTime != nil {
+		for i := range files {
+			files[i].FileInfo = reproducibleFileInfo{FileInfo: files[i].FileInfo, modTime: *opts.ModTime}
+		}
+	}
 
 	archiver, err := findArchiver(dest)
 	if err != nil {
@@ -148,6 +163,22 @@ func Compress(ctx context.Context, sources []string, dest string, _ CompressOpts
 	return nil
 }
 
+// reproducibleFileInfo overrides the modification time of a file and hides its ownership from archivers.
+type reproducibleFileInfo struct {
+	fs.FileInfo
+	modTime time.Time
+}
+
+// ModTime returns the overridden modification time.
+func (fi reproducibleFileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+// Sys returns nil so no user or group information is written.
+func (fi reproducibleFileInfo) Sys() any {
+	return nil
+}
+
 // DecompressOpts defines optional behavior for Decompress.
 type DecompressOpts struct {
 	// UnarchiveAll enables recursive unpacking of nested .tar files.
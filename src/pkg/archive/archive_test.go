@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -183,6 +184,33 @@ func TestCompressAndDecompress_MultipleFormats(t *testing.T) {
 	}
 }
 
+func TestCompressReproducible(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modTime := time.Unix(1700000000, 0)
+
+	compress := func(mtime time.Time) []byte {
+		t.Helper()
+		root := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "dir"), testDirPerm))
+		writeTestFile(t, filepath.Join(root, "dir", "file.txt"), "test-data")
+		writeTestFile(t, filepath.Join(root, "zarf.yaml"), "kind: ZarfPackageConfig")
+		for _, path := range []string{filepath.Join(root, "dir", "file.txt"), filepath.Join(root, "dir"), filepath.Join(root, "zarf.yaml")} {
+			require.NoError(t, os.Chtimes(path, mtime, mtime))
+		}
+		dest := filepath.Join(t.TempDir(), "package.tar.zst")
+		err := Compress(ctx, []string{filepath.Join(root, "dir"), filepath.Join(root, "zarf.yaml")}, dest, CompressOpts{ModTime: &modTime})
+		require.NoError(t, err)
+		b, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		return b
+	}
+
+	first := compress(time.Now())
+	second := compress(time.Now().Add(time.Hour))
+	require.Equal(t, first, second)
+}
+
 func TestCompressUnsupportedExtension(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+// Package cluster contains Zarf-specific cluster management functions.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/pki"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	v1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// AgentCertificateName is the name of the cert-manager Certificate issuing the agent TLS certificate.
+	AgentCertificateName = "zarf-agent"
+	// AgentCertManagerSecretName is the name of the secret cert-manager writes the agent TLS certificate to.
+	AgentCertManagerSecretName = "agent-hook-cert-manager-tls"
+
+	// The validity and renewal window of cert-manager issued certificates match the ones Zarf generates,
+	// 375 days with a renewal once less than 20% of the validity remains.
+	agentCertificateDuration    = "9000h"
+	agentCertificateRenewBefore = "1800h"
+)
+
+var certificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// RenewAgentTLS issues a new agent certificate as configured in the state.
+// Certificates issued by cert-manager are synced from the secret cert-manager manages instead.
+func (c *Cluster) RenewAgentTLS(ctx context.Context, s *state.State) error {
+	if issuer := s.AgentInfo.TLS.CertManagerIssuer; issuer != nil {
+		agentTLS, err := c.syncCertManagerAgentTLS(ctx, *issuer, s.AgentInfo.TLS.KeyAlgorithm)
+		if err != nil {
+			return err
+		}
+		s.AgentTLS = agentTLS
+		return nil
+	}
+	agentTLS, err := s.AgentInfo.TLS.GenerateAgentTLS()
+	if err != nil {
+		return err
+	}
+	s.AgentTLS = agentTLS
+	return nil
+}
+
+// saveAgentCA stores the agent CA in its own secret so that its private key is never part of the state.
+// The secret is removed once the agent certificate is no longer signed by a provided CA.
+func (c *Cluster) saveAgentCA(ctx context.Context, tlsConfig state.AgentTLSConfig) error {
+	if len(tlsConfig.CACert) == 0 || tlsConfig.CertManagerIssuer != nil {
+		err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Delete(ctx, state.ZarfAgentCASecretName, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete the Zarf agent CA secret: %w", err)
+		}
+		return nil
+	}
+	// The key is only set once it has been read from the flags or the existing secret
+	if len(tlsConfig.CAKey) == 0 {
+		return nil
+	}
+	secret := v1ac.Secret(state.ZarfAgentCASecretName, state.ZarfNamespaceName).
+		WithLabels(map[string]string{
+			state.ZarfManagedByLabel: "zarf",
+		}).
+		WithType(corev1.SecretTypeTLS).
+		WithData(map[string][]byte{
+			corev1.TLSCertKey:       tlsConfig.CACert,
+			corev1.TLSPrivateKeyKey: tlsConfig.CAKey,
+		})
+	_, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Apply(ctx, secret, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
+	if err != nil {
+		return fmt.Errorf("unable to apply the Zarf agent CA secret: %w", err)
+	}
+	return nil
+}
+
+// LoadAgentCA reads the private key of the agent CA into the state when the agent certificate is signed by a provided CA.
+// The key is not part of the loaded state and must be read before the state is used to sign a certificate.
+func (c *Cluster) LoadAgentCA(ctx context.Context, s *state.State) error {
+	if len(s.AgentInfo.TLS.CACert) == 0 || s.AgentInfo.TLS.CertManagerIssuer != nil {
+		return nil
+	}
+	secret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.ZarfAgentCASecretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get the Zarf agent CA secret: %w", err)
+	}
+	s.AgentInfo.TLS.CAKey = secret.Data[corev1.TLSPrivateKeyKey]
+	return nil
+}
+
+// syncCertManagerAgentTLS ensures a cert-manager Certificate exists for the agent and returns the certificate it issued.
+func (c *Cluster) syncCertManagerAgentTLS(ctx context.Context, issuer state.CertManagerIssuerRef, algorithm pki.KeyAlgorithm) (pki.GeneratedPKI, error) {
+	if c.RestConfig == nil {
+		return pki.GeneratedPKI{}, errors.New("unable to manage the cert-manager Certificate without a Kubernetes client configuration")
+	}
+	dc, err := dynamic.NewForConfig(c.RestConfig)
+	if err != nil {
+		return pki.GeneratedPKI{}, err
+	}
+	certificate := newAgentCertificate(issuer, algorithm)
+	_, err = dc.Resource(certificateGVR).Namespace(state.ZarfNamespaceName).Apply(ctx, AgentCertificateName, certificate, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
+	if err != nil {
+		return pki.GeneratedPKI{}, fmt.Errorf("unable to apply the cert-manager Certificate for the Zarf agent, ensure cert-manager is installed: %w", err)
+	}
+
+	var agentTLS pki.GeneratedPKI
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	err = wait.PollUntilContextCancel(waitCtx, time.Second, true, func(ctx context.Context) (bool, error) {
+		secret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, AgentCertManagerSecretName, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		agentTLS, err = agentTLSFromSecret(secret)
+		if err != nil {
+			logger.From(ctx).Debug("waiting for cert-manager to issue the Zarf agent certificate", "error", err.Error())
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return pki.GeneratedPKI{}, fmt.Errorf("cert-manager did not issue the Zarf agent certificate to the secret %s: %w", AgentCertManagerSecretName, err)
+	}
+	return agentTLS, nil
+}
+
+// newAgentCertificate returns the cert-manager Certificate for the agent service.
+func newAgentCertificate(issuer state.CertManagerIssuerRef, algorithm pki.KeyAlgorithm) *unstructured.Unstructured {
+	kind := issuer.Kind
+	if kind == "" {
+		kind = "Issuer"
+	}
+	keyAlgorithm := "RSA"
+	if algorithm == pki.KeyAlgorithmECDSA {
+		keyAlgorithm = "ECDSA"
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata": map[string]any{
+				"name":      AgentCertificateName,
+				"namespace": state.ZarfNamespaceName,
+				"labels": map[string]any{
+					state.ZarfManagedByLabel: "zarf",
+				},
+			},
+			"spec": map[string]any{
+				"secretName":  AgentCertManagerSecretName,
+				"commonName":  state.ZarfAgentHost,
+				"dnsNames":    []any{state.ZarfAgentHost},
+				"duration":    agentCertificateDuration,
+				"renewBefore": agentCertificateRenewBefore,
+				"privateKey": map[string]any{
+					"algorithm":      keyAlgorithm,
+					"rotationPolicy": "Always",
+				},
+				"issuerRef": map[string]any{
+					"name":  issuer.Name,
+					"kind":  kind,
+					"group": "cert-manager.io",
+				},
+			},
+		},
+	}
+}
+
+// agentTLSFromSecret reads the agent certificate from a cert-manager managed TLS secret.
+func agentTLSFromSecret(secret *corev1.Secret) (pki.GeneratedPKI, error) {
+	agentTLS := pki.GeneratedPKI{
+		CA:   secret.Data["ca.crt"],
+		Cert: secret.Data[corev1.TLSCertKey],
+		Key:  secret.Data[corev1.TLSPrivateKeyKey],
+	}
+	if len(agentTLS.Cert) == 0 || len(agentTLS.Key) == 0 {
+		return pki.GeneratedPKI{}, errors.New("the secret does not contain a certificate and key yet")
+	}
+	// The CA is required for the webhook caBundle, some issuers such as ACME do not provide one
+	if len(agentTLS.CA) == 0 {
+		return pki.GeneratedPKI{}, errors.New("the issuer did not provide a ca.crt for the certificate")
+	}
+	return agentTLS, nil
+}
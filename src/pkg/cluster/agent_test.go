@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/pki"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAgentCASecret(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &Cluster{Clientset: fake.NewClientset()}
+
+	s := &state.State{}
+	s.AgentInfo.TLS = state.AgentTLSConfig{CACert: []byte("ca-cert"), CAKey: []byte("ca-key")}
+	require.NoError(t, c.SaveState(ctx, s, state.AuditOperationInit))
+
+	// The CA key is kept out of the state secret
+	stateSecret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.ZarfStateSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Contains(t, string(stateSecret.Data[state.ZarfStateDataKey]), base64.StdEncoding.EncodeToString([]byte("ca-cert")))
+	require.NotContains(t, string(stateSecret.Data[state.ZarfStateDataKey]), base64.StdEncoding.EncodeToString([]byte("ca-key")))
+
+	// The CA key is only read when it is needed to sign a certificate
+	loaded, err := c.LoadState(ctx)
+	require.NoError(t, err)
+	require.Empty(t, loaded.AgentInfo.TLS.CAKey)
+	require.NoError(t, c.LoadAgentCA(ctx, loaded))
+	require.Equal(t, []byte("ca-key"), loaded.AgentInfo.TLS.CAKey)
+
+	// Switching to a cert-manager issuer removes the CA secret
+	loaded.AgentInfo.TLS = state.AgentTLSConfig{CertManagerIssuer: &state.CertManagerIssuerRef{Name: "zarf-ca"}}
+	require.NoError(t, c.SaveState(ctx, loaded, state.AuditOperationInit))
+	_, err = c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.ZarfAgentCASecretName, metav1.GetOptions{})
+	require.True(t, kerrors.IsNotFound(err))
+}
+
+func TestAgentTLSFromSecret(t *testing.T) {
+	t.Parallel()
+
+	_, err := agentTLSFromSecret(&corev1.Secret{})
+	require.ErrorContains(t, err, "does not contain a certificate and key yet")
+
+	_, err = agentTLSFromSecret(&corev1.Secret{Data: map[string][]byte{
+		corev1.TLSCertKey:       []byte("cert"),
+		corev1.TLSPrivateKeyKey: []byte("key"),
+	}})
+	require.ErrorContains(t, err, "did not provide a ca.crt")
+
+	agentTLS, err := agentTLSFromSecret(&corev1.Secret{Data: map[string][]byte{
+		"ca.crt":                []byte("ca"),
+		corev1.TLSCertKey:       []byte("cert"),
+		corev1.TLSPrivateKeyKey: []byte("key"),
+	}})
+	require.NoError(t, err)
+	require.Equal(t, pki.GeneratedPKI{CA: []byte("ca"), Cert: []byte("cert"), Key: []byte("key")}, agentTLS)
+}
+
+func TestNewAgentCertificate(t *testing.T) {
+	t.Parallel()
+
+	certificate := newAgentCertificate(state.CertManagerIssuerRef{Name: "zarf-ca"}, pki.KeyAlgorithmECDSA)
+	require.Equal(t, AgentCertificateName, certificate.GetName())
+	require.Equal(t, state.ZarfNamespaceName, certificate.GetNamespace())
+
+	spec, ok := certificate.Object["spec"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, AgentCertManagerSecretName, spec["secretName"])
+	require.Equal(t, []any{state.ZarfAgentHost}, spec["dnsNames"])
+	require.Equal(t, map[string]any{"name": "zarf-ca", "kind": "Issuer", "group": "cert-manager.io"}, spec["issuerRef"])
+	require.Equal(t, "ECDSA", spec["privateKey"].(map[string]any)["algorithm"])
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// AuditLogPrefix is the name prefix of the config maps that hold the audit log.
+	AuditLogPrefix = "zarf-audit-"
+	// auditLogSegmentSize is the number of entries kept in each audit log config map before a new one is started.
+	auditLogSegmentSize = 250
+)
+
+// recordAudit appends an entry to the audit log. Failures are logged rather than returned
+// so that the audit log can not block changes to the cluster.
+func (c *Cluster) recordAudit(ctx context.Context, entry state.AuditEntry) {
+	entry = stampAuditEntry(entry)
+	if err := c.appendAuditEntry(ctx, entry); err != nil {
+		logger.From(ctx).Warn("unable to record the change in the Zarf audit log", "operation", entry.Operation, "error", err.Error())
+	}
+}
+
+// RecordDestroy logs the audit entry for removing Zarf from the cluster.
+// The entry is not written to the audit log because the audit log is deleted with the Zarf namespace,
+// so the log of the destroy is the only record of it.
+func (c *Cluster) RecordDestroy(ctx context.Context) {
+	l := logger.From(ctx)
+	entry := stampAuditEntry(state.AuditEntry{Operation: state.AuditOperationDestroy})
+	entries, err := c.GetAuditEntries(ctx, state.AuditFilter{})
+	if err != nil {
+		l.Warn("unable to read the Zarf audit log", "error", err.Error())
+	}
+	l.Info("removing Zarf and its audit log from the cluster", "operation", entry.Operation, "timestamp", entry.Timestamp,
+		"user", entry.User, "cliVersion", entry.CLIVersion, "auditEntries", len(entries))
+}
+
+// stampAuditEntry sets when and by whom the change was made.
+func stampAuditEntry(entry state.AuditEntry) state.AuditEntry {
+	entry.Timestamp = time.Now().UTC()
+	entry.CLIVersion = config.CLIVersion
+	entry.User = auditUser()
+	return entry
+}
+
+// appendAuditEntry adds the entry to the latest audit log config map, starting a new one once it is full.
+// Existing entries are never modified.
+func (c *Cluster) appendAuditEntry(ctx context.Context, entry state.AuditEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		segments, err := c.listAuditLogSegments(ctx)
+		if err != nil {
+			return err
+		}
+		if len(segments) > 0 {
+			latest := segments[len(segments)-1]
+			if len(latest.Data) < auditLogSegmentSize {
+				if latest.Data == nil {
+					latest.Data = map[string]string{}
+				}
+				latest.Data[auditEntryKey(len(latest.Data))] = string(b)
+				_, err := c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).Update(ctx, &latest, metav1.UpdateOptions{FieldManager: FieldManagerName})
+				return err
+			}
+		}
+
+		index := 0
+		if len(segments) > 0 {
+			index = auditLogSegmentIndex(segments[len(segments)-1].Name) + 1
+		}
+		segment := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s%06d", AuditLogPrefix, index),
+				Namespace: state.ZarfNamespaceName,
+				Labels: map[string]string{
+					state.ZarfManagedByLabel: "zarf",
+					state.ZarfAuditLogLabel:  "true",
+				},
+			},
+			Data: map[string]string{
+				auditEntryKey(0): string(b),
+			},
+		}
+		_, err = c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).Create(ctx, segment, metav1.CreateOptions{FieldManager: FieldManagerName})
+		// Another writer started the segment first, retry against it
+		if kerrors.IsAlreadyExists(err) {
+			return kerrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, segment.Name, err)
+		}
+		return err
+	})
+}
+
+// GetAuditEntries returns the entries in the audit log selected by the filter, oldest first.
+func (c *Cluster) GetAuditEntries(ctx context.Context, filter state.AuditFilter) ([]state.AuditEntry, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	segments, err := c.listAuditLogSegments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := []state.AuditEntry{}
+	for _, segment := range segments {
+		keys := make([]string, 0, len(segment.Data))
+		for k := range segment.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var entry state.AuditEntry
+			if err := json.Unmarshal([]byte(segment.Data[k]), &entry); err != nil {
+				return nil, fmt.Errorf("unable to read entry %s of the audit log %s: %w", k, segment.Name, err)
+			}
+			if filter.Matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// listAuditLogSegments returns the audit log config maps in the order they were started.
+func (c *Cluster) listAuditLogSegments(ctx context.Context) ([]corev1.ConfigMap, error) {
+	list, err := c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).List(ctx, metav1.ListOptions{
+		LabelSelector: state.ZarfAuditLogLabel + "=true",
+	})
+	if err != nil {
+		return nil, err
+	}
+	segments := list.Items
+	sort.Slice(segments, func(i, j int) bool {
+		return auditLogSegmentIndex(segments[i].Name) < auditLogSegmentIndex(segments[j].Name)
+	})
+	return segments, nil
+}
+
+func auditLogSegmentIndex(name string) int {
+	index, err := strconv.Atoi(strings.TrimPrefix(name, AuditLogPrefix))
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+func auditEntryKey(i int) string {
+	return fmt.Sprintf("%06d", i)
+}
+
+// auditUser returns the OS user running Zarf.
+func auditUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return "unknown"
+}
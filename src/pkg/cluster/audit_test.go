@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &Cluster{Clientset: fake.NewClientset()}
+
+	s := &state.State{Distro: "k3s", RegistryInfo: state.RegistryInfo{Address: "127.0.0.1:31999", PushPassword: "first"}}
+	require.NoError(t, c.SaveState(ctx, s, state.AuditOperationInit))
+	s.RegistryInfo.PushPassword = "second"
+	require.NoError(t, c.SaveState(ctx, s, state.AuditOperationUpdateCreds))
+
+	pkg := v1alpha1.ZarfPackage{Metadata: v1alpha1.ZarfMetadata{Name: "podinfo", Version: "1.0.0"}}
+	components := []state.DeployedComponent{{Name: "podinfo", Status: state.ComponentStatusSucceeded}}
+	depPkg, err := c.RecordPackageDeployment(ctx, pkg, components, 1)
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteDeployedPackage(ctx, *depPkg))
+
+	entries, err := c.GetAuditEntries(ctx, state.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	for _, entry := range entries {
+		require.NotEmpty(t, entry.User)
+		require.False(t, entry.Timestamp.IsZero())
+	}
+
+	require.Equal(t, state.AuditOperationInit, entries[0].Operation)
+	require.Contains(t, entries[0].Changes, state.AuditChange{Path: "registryInfo.address", New: "127.0.0.1:31999"})
+	require.Equal(t, state.AuditOperationUpdateCreds, entries[1].Operation)
+	require.Equal(t, []state.AuditChange{{Path: "registryInfo.pushPassword", Old: "**sanitized**", New: "**sanitized**"}}, entries[1].Changes)
+
+	require.Equal(t, state.AuditOperationRecordPackageDeployment, entries[2].Operation)
+	require.Equal(t, "podinfo", entries[2].Package)
+	require.Equal(t, 1, entries[2].Generation)
+	require.Contains(t, entries[2].Changes, state.AuditChange{Path: "components.podinfo", New: string(state.ComponentStatusSucceeded)})
+
+	require.Equal(t, state.AuditOperationDeleteDeployedPackage, entries[3].Operation)
+
+	entries, err = c.GetAuditEntries(ctx, state.AuditFilter{Package: "podinfo", Operation: state.AuditOperationDeleteDeployedPackage})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// The destroy is logged rather than written to the audit log it removes
+	var buf bytes.Buffer
+	l, err := logger.New(logger.Config{Level: logger.Info, Format: logger.FormatJSON, Destination: &buf})
+	require.NoError(t, err)
+	c.RecordDestroy(logger.WithContext(ctx, l))
+	require.Contains(t, buf.String(), `"operation":"destroy"`)
+	require.Contains(t, buf.String(), `"auditEntries":4`)
+	entries, err = c.GetAuditEntries(ctx, state.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	_, err = c.GetAuditEntries(ctx, state.AuditFilter{Operation: "deploy"})
+	require.Error(t, err)
+}
+
+func TestAuditLogSegments(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &Cluster{Clientset: fake.NewClientset()}
+	for i := range auditLogSegmentSize + 1 {
+		require.NoError(t, c.appendAuditEntry(ctx, state.AuditEntry{Operation: state.AuditOperationInit, Generation: i}))
+	}
+
+	segments, err := c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, segments.Items, 2)
+	first, err := c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).Get(ctx, "zarf-audit-000000", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, first.Data, auditLogSegmentSize)
+	second, err := c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).Get(ctx, "zarf-audit-000001", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, second.Data, 1)
+
+	// Entries are returned in the order they were appended across segments
+	entries, err := c.GetAuditEntries(ctx, state.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, auditLogSegmentSize+1)
+	for i, entry := range entries {
+		require.Equal(t, i, entry.Generation)
+	}
+}
@@ -14,9 +14,9 @@ import (
 
 	"github.com/avast/retry-go/v4"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/internal/credentials"
 	"github.com/zarf-dev/zarf/src/internal/healthchecks"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
-	"github.com/zarf-dev/zarf/src/pkg/pki"
 	"github.com/zarf-dev/zarf/src/pkg/state"
 
 	corev1 "k8s.io/api/core/v1"
@@ -52,6 +52,9 @@ type Cluster struct {
 	RestConfig *rest.Config
 	// Watcher implements kstatus StatusWatcher
 	Watcher watcher.StatusWatcher
+
+	// credentialsCache keeps the credentials loaded from an external credential store, nil disables caching
+	credentialsCache *credentials.Cache
 }
 
 // NewWithWait creates a new Cluster instance and waits for the given timeout for the cluster to be ready.
@@ -104,9 +107,10 @@ func New(_ context.Context) (*Cluster, error) {
 		return nil, errors.Join(clusterErr, err)
 	}
 	c := &Cluster{
-		Clientset:  clientset,
-		RestConfig: cfg,
-		Watcher:    w,
+		Clientset:        clientset,
+		RestConfig:       cfg,
+		Watcher:          w,
+		credentialsCache: credentials.NewCache(credentialsCacheTTL),
 	}
 	// Dogsled the version output. We just want to ensure no errors were returned to validate cluster connection.
 	_, err = c.Clientset.Discovery().ServerVersion()
@@ -159,6 +163,18 @@ type InitStateOptions struct {
 	RegistryInfo state.RegistryInfo
 	// Information about the artifact registry Zarf is going to be using
 	ArtifactServer state.ArtifactServerInfo
+	// Source of the Zarf agent TLS certificate
+	AgentTLS state.AgentTLSConfig
+	// Policy for the images the Zarf agent admits, nil keeps the existing policy
+	AgentImagePolicy *state.ImagePolicy
+	// Zarf agent hooks that run in audit mode, nil keeps the existing hooks
+	AgentAudit *state.AgentAudit
+	// Backend that stores the sensitive fields of the state
+	CredentialStore state.CredentialStore
+	// Token used to authenticate with the Vault credential store
+	VaultToken string
+	// Named registries and git servers that replace the ones in the state, nil keeps the existing ones
+	Routing *state.RoutingConfig
 	// StorageClass of the k8s cluster Zarf is initializing
 	StorageClass string
 	// InjectorPort is the port that the injector will be exposed through
@@ -207,13 +223,6 @@ func (c *Cluster) InitState(ctx context.Context, opts InitStateOptions) (*state.
 			l.Debug("Detected K8s distro", "name", s.Distro)
 		}
 
-		// Setup zarf agent PKI
-		agentTLS, err := pki.GeneratePKI(state.ZarfAgentHost)
-		if err != nil {
-			return nil, err
-		}
-		s.AgentTLS = agentTLS
-
 		namespaceList, err := c.Clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return nil, fmt.Errorf("unable to get the Kubernetes namespaces: %w", err)
@@ -246,6 +255,12 @@ func (c *Cluster) InitState(ctx context.Context, opts InitStateOptions) (*state.
 			return nil, fmt.Errorf("unable to apply the Zarf namespace: %w", err)
 		}
 
+		// Setup zarf agent PKI, this happens after the namespace exists as cert-manager issues the certificate into it
+		s.AgentInfo.TLS = opts.AgentTLS
+		if err := c.RenewAgentTLS(ctx, s); err != nil {
+			return nil, fmt.Errorf("unable to setup the Zarf agent PKI: %w", err)
+		}
+
 		ipFamily, err := c.GetIPFamily(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get the Kubernetes IP family: %w", err)
@@ -278,6 +293,13 @@ func (c *Cluster) InitState(ctx context.Context, opts InitStateOptions) (*state.
 			return nil, err
 		}
 		s.RegistryInfo = opts.RegistryInfo
+		// The registry proxy on each node connects to the registry over mTLS
+		if s.RegistryInfo.RegistryMode == state.RegistryModeProxy {
+			s.RegistryProxyTLS, err = s.AgentInfo.TLS.GenerateRegistryProxyTLS()
+			if err != nil {
+				return nil, fmt.Errorf("unable to setup the registry proxy PKI: %w", err)
+			}
+		}
 		opts.ArtifactServer.FillInEmptyValues()
 		s.ArtifactServer = opts.ArtifactServer
 	}
@@ -301,8 +323,38 @@ func (c *Cluster) InitState(ctx context.Context, opts InitStateOptions) (*state.
 		s.InjectorInfo.Port = opts.InjectorPort
 	}
 
+	if opts.AgentImagePolicy != nil {
+		s.AgentInfo.ImagePolicy = *opts.AgentImagePolicy
+	}
+
+	if opts.AgentAudit != nil {
+		s.AgentInfo.Audit = *opts.AgentAudit
+	}
+
+	if opts.CredentialStore.Backend != "" {
+		if err := opts.CredentialStore.Validate(); err != nil {
+			return nil, err
+		}
+		if opts.VaultToken != "" {
+			if err := c.SaveVaultToken(ctx, opts.VaultToken); err != nil {
+				return nil, err
+			}
+		}
+		s.CredentialStore = opts.CredentialStore
+	}
+
+	if opts.Routing != nil {
+		if err := opts.Routing.Validate(); err != nil {
+			return nil, err
+		}
+		if err := opts.Routing.FillInEmptyValues(); err != nil {
+			return nil, err
+		}
+		s.SetRouting(*opts.Routing)
+	}
+
 	// Save the state back to K8s
-	if err := c.SaveState(ctx, s); err != nil {
+	if err := c.SaveState(ctx, s, state.AuditOperationInit); err != nil {
 		return nil, fmt.Errorf("unable to save the Zarf state: %w", err)
 	}
 
@@ -331,18 +383,47 @@ func (c *Cluster) LoadState(ctx context.Context) (*state.State, error) {
 			s.RegistryInfo.RegistryMode = state.RegistryModeExternal
 		}
 	}
+	if s.CredentialStore.IsExternal() {
+		if err := c.loadCredentials(ctx, s); err != nil {
+			return nil, err
+		}
+	}
 	state.DebugPrint(ctx, s)
 	return s, nil
 }
 
 // SaveState takes a given state.State and persists it to k8s Cluster secrets.
-func (c *Cluster) SaveState(ctx context.Context, s *state.State) error {
+// The changes are recorded in the audit log under the given operation.
+func (c *Cluster) SaveState(ctx context.Context, s *state.State, operation state.AuditOperation) error {
 	state.DebugPrint(ctx, s)
 
+	if err := c.saveAgentCA(ctx, s.AgentInfo.TLS); err != nil {
+		return err
+	}
+
+	// Only the reference to the credential store is kept in the state secret for external backends
+	if s.CredentialStore.IsExternal() {
+		var err error
+		s, err = c.saveCredentials(ctx, s)
+		if err != nil {
+			return err
+		}
+	}
+
 	data, err := json.Marshal(&s)
 	if err != nil {
 		return err
 	}
+	// The previous state is only read to record the changes in the audit log
+	var previousState *state.State
+	previousSecret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.ZarfStateSecretName, metav1.GetOptions{})
+	if err == nil {
+		previousState = &state.State{}
+		if err := json.Unmarshal(previousSecret.Data[state.ZarfStateDataKey], previousState); err != nil {
+			previousState = nil
+		}
+	}
+
 	secret := v1ac.Secret(state.ZarfStateSecretName, state.ZarfNamespaceName).
 		WithLabels(map[string]string{
 			state.ZarfManagedByLabel: "zarf",
@@ -356,6 +437,12 @@ func (c *Cluster) SaveState(ctx context.Context, s *state.State) error {
 	if err != nil {
 		return fmt.Errorf("unable to apply the zarf state secret: %w", err)
 	}
+
+	changes, err := state.AuditChanges(previousState, s)
+	if err != nil {
+		return err
+	}
+	c.recordAudit(ctx, state.AuditEntry{Operation: operation, Changes: changes})
 	return nil
 }
 
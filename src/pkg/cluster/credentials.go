@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zarf-dev/zarf/src/internal/credentials"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1ac "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+// credentialsCacheTTL is how long the credentials loaded from an external store are reused before they are loaded again.
+const credentialsCacheTTL = time.Minute
+
+// SaveVaultToken stores the token used to authenticate with the Vault credential store.
+// The token is kept in its own secret so that it is never written to the state.
+func (c *Cluster) SaveVaultToken(ctx context.Context, token string) error {
+	secret := v1ac.Secret(state.CredentialStoreSecretName, state.ZarfNamespaceName).
+		WithLabels(map[string]string{
+			state.ZarfManagedByLabel: "zarf",
+		}).
+		WithType(corev1.SecretTypeOpaque).
+		WithData(map[string][]byte{
+			state.CredentialStoreTokenKey: []byte(token),
+		})
+	_, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Apply(ctx, secret, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
+	if err != nil {
+		return fmt.Errorf("unable to apply the credential store secret: %w", err)
+	}
+	if c.credentialsCache != nil {
+		c.credentialsCache.Invalidate()
+	}
+	return nil
+}
+
+// vaultToken reads the token used to authenticate with Vault.
+func (c *Cluster) vaultToken(ctx context.Context) (string, error) {
+	secret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.CredentialStoreSecretName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to get the Vault token: %w", err)
+	}
+	return string(secret.Data[state.CredentialStoreTokenKey]), nil
+}
+
+// credentialStore returns the backend that stores the credentials of the state.
+func (c *Cluster) credentialStore(ctx context.Context, cs state.CredentialStore) (credentials.Store, error) {
+	if err := cs.Validate(); err != nil {
+		return nil, err
+	}
+	switch cs.Backend {
+	case state.CredentialStoreVault:
+		token, err := c.vaultToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return credentials.NewVaultStore(cs.Vault.Address, cs.Vault.Mount, cs.Vault.Path, token)
+	case state.CredentialStoreEnvelope:
+		token, err := c.vaultToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		wrapper, err := credentials.NewVaultTransitKeyWrapper(cs.Envelope.Address, cs.Envelope.Mount, cs.Envelope.Key, token)
+		if err != nil {
+			return nil, err
+		}
+		return credentials.NewEnvelopeStore(c.Clientset, state.ZarfNamespaceName, state.EnvelopeCredentialsSecretName, wrapper), nil
+	default:
+		return nil, fmt.Errorf("the %q credential store is not an external backend", cs.Backend)
+	}
+}
+
+// credentialsCacheKey identifies the credentials of a credential store in the cache.
+func credentialsCacheKey(cs state.CredentialStore) (string, error) {
+	b, err := json.Marshal(cs)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// loadCredentials fills in the sensitive fields of the state from its external credential store.
+func (c *Cluster) loadCredentials(ctx context.Context, s *state.State) error {
+	store, err := c.credentialStore(ctx, s.CredentialStore)
+	if err != nil {
+		return err
+	}
+	var creds map[string]string
+	if c.credentialsCache != nil {
+		key, err := credentialsCacheKey(s.CredentialStore)
+		if err != nil {
+			return err
+		}
+		creds, err = c.credentialsCache.Load(ctx, key, store)
+	} else {
+		creds, err = store.Load(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to load the Zarf credentials from the %s credential store: %w", s.CredentialStore.Backend, err)
+	}
+	s.SetCredentials(creds)
+	return nil
+}
+
+// saveCredentials writes the sensitive fields of the state to its external credential store
+// and returns a copy of the state without them.
+func (c *Cluster) saveCredentials(ctx context.Context, s *state.State) (*state.State, error) {
+	store, err := c.credentialStore(ctx, s.CredentialStore)
+	if err != nil {
+		return nil, err
+	}
+	if c.credentialsCache != nil {
+		key, err := credentialsCacheKey(s.CredentialStore)
+		if err != nil {
+			return nil, err
+		}
+		err = c.credentialsCache.Save(ctx, key, store, s.Credentials())
+		if err != nil {
+			return nil, fmt.Errorf("unable to save the Zarf credentials to the %s credential store: %w", s.CredentialStore.Backend, err)
+		}
+	} else if err := store.Save(ctx, s.Credentials()); err != nil {
+		return nil, fmt.Errorf("unable to save the Zarf credentials to the %s credential store: %w", s.CredentialStore.Backend, err)
+	}
+	referenced := *s
+	referenced.SetCredentials(map[string]string{})
+	return &referenced, nil
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/internal/credentials"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestVaultServer returns a stand-in for Vault with a KV version 2 secrets engine that only knows the default path
+// and a transit secrets engine with the zarf key, it also returns the number of reads of the KV secret.
+func newTestVaultServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var vaultData []byte
+	var reads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/v1/transit/encrypt/zarf":
+			var req struct{ Plaintext string }
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			//nolint:errcheck // ignore
+			w.Write([]byte(`{"data":{"ciphertext":"vault:v1:` + req.Plaintext + `"}}`))
+		case r.URL.Path == "/v1/transit/decrypt/zarf":
+			var req struct{ Ciphertext string }
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			//nolint:errcheck // ignore
+			w.Write([]byte(`{"data":{"plaintext":"` + strings.TrimPrefix(req.Ciphertext, "vault:v1:") + `"}}`))
+		case r.URL.Path != "/v1/secret/data/zarf/state":
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodPost:
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			vaultData = b
+		case vaultData == nil:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			reads.Add(1)
+			//nolint:errcheck // ignore
+			w.Write([]byte(`{"data":` + string(vaultData) + `}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reads
+}
+
+func TestExternalCredentialStore(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := newTestVaultServer(t)
+
+	tests := []struct {
+		name  string
+		store state.CredentialStore
+	}{
+		{
+			name:  "envelope",
+			store: state.CredentialStore{Backend: state.CredentialStoreEnvelope, Envelope: &state.EnvelopeCredentialStore{Address: srv.URL, Key: "zarf"}},
+		},
+		{
+			name:  "vault",
+			store: state.CredentialStore{Backend: state.CredentialStoreVault, Vault: &state.VaultCredentialStore{Address: srv.URL}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := &Cluster{Clientset: fake.NewClientset()}
+			require.NoError(t, c.SaveVaultToken(ctx, "root"))
+
+			// Start from a state with inline credentials as an existing cluster would
+			s := &state.State{
+				Distro:       "test",
+				RegistryInfo: state.RegistryInfo{Address: "127.0.0.1:31999", PushPassword: "registry-push", PullPassword: "registry-pull"},
+				GitServer:    state.GitServerInfo{Address: "http://gitea", PushPassword: "git-push"},
+			}
+			require.NoError(t, c.SaveState(ctx, s, state.AuditOperationInit))
+			secret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.ZarfStateSecretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Contains(t, string(secret.Data[state.ZarfStateDataKey]), "registry-push")
+
+			// Moving to an external backend leaves only the reference in the state secret
+			s.CredentialStore = tt.store
+			require.NoError(t, c.SaveState(ctx, s, state.AuditOperationInit))
+			require.Equal(t, "registry-push", s.RegistryInfo.PushPassword)
+			secret, err = c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.ZarfStateSecretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			saved := &state.State{}
+			require.NoError(t, json.Unmarshal(secret.Data[state.ZarfStateDataKey], saved))
+			require.Equal(t, tt.store, saved.CredentialStore)
+			require.Empty(t, saved.RegistryInfo.PushPassword)
+			require.Empty(t, saved.RegistryInfo.PullPassword)
+			require.Empty(t, saved.GitServer.PushPassword)
+			require.Equal(t, "127.0.0.1:31999", saved.RegistryInfo.Address)
+
+			loaded, err := c.LoadState(ctx)
+			require.NoError(t, err)
+			require.Equal(t, "registry-push", loaded.RegistryInfo.PushPassword)
+			require.Equal(t, "registry-pull", loaded.RegistryInfo.PullPassword)
+			require.Equal(t, "git-push", loaded.GitServer.PushPassword)
+		})
+	}
+}
+
+func TestCredentialStoreMissingVaultToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &Cluster{Clientset: fake.NewClientset()}
+	s := &state.State{CredentialStore: state.CredentialStore{Backend: state.CredentialStoreVault, Vault: &state.VaultCredentialStore{Address: "https://vault.example.com"}}}
+	err := c.SaveState(ctx, s, state.AuditOperationInit)
+	require.ErrorContains(t, err, "unable to get the Vault token")
+}
+
+func TestCredentialsCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	srv, reads := newTestVaultServer(t)
+	c := &Cluster{Clientset: fake.NewClientset(), credentialsCache: credentials.NewCache(time.Hour)}
+	require.NoError(t, c.SaveVaultToken(ctx, "root"))
+	s := &state.State{
+		RegistryInfo:    state.RegistryInfo{PushPassword: "registry-push"},
+		CredentialStore: state.CredentialStore{Backend: state.CredentialStoreVault, Vault: &state.VaultCredentialStore{Address: srv.URL}},
+	}
+	require.NoError(t, c.SaveState(ctx, s, state.AuditOperationInit))
+
+	// The saved credentials are loaded from the cache
+	for range 3 {
+		loaded, err := c.LoadState(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "registry-push", loaded.RegistryInfo.PushPassword)
+	}
+	require.Zero(t, reads.Load())
+
+	// A new Vault token drops the cached credentials
+	require.NoError(t, c.SaveVaultToken(ctx, "root"))
+	_, err := c.LoadState(ctx)
+	require.NoError(t, err)
+	_, err = c.LoadState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), reads.Load())
+}
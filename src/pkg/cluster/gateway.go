@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// TunnelGateway is a local HTTP reverse proxy in front of a set of tunnels.
+// Requests are routed by the first label of the host name (e.g. http://podinfo.localhost:8080/)
+// or by the first segment of the path (e.g. http://127.0.0.1:8080/podinfo/), which is removed before forwarding.
+type TunnelGateway struct {
+	names   []string
+	proxies map[string]*httputil.ReverseProxy
+}
+
+// NewTunnelGateway returns a gateway that routes to the base URLs keyed by their connect name.
+func NewTunnelGateway(targets map[string]string) (*TunnelGateway, error) {
+	g := &TunnelGateway{
+		proxies: map[string]*httputil.ReverseProxy{},
+	}
+	for name, target := range targets {
+		targetURL, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway target %s for %s: %w", target, name, err)
+		}
+		key := strings.ToLower(name)
+		if _, ok := g.proxies[key]; ok {
+			return nil, fmt.Errorf("the connect name %s is routed more than once", name)
+		}
+		g.proxies[key] = &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.SetURL(targetURL)
+				pr.SetXForwarded()
+			},
+		}
+		g.names = append(g.names, name)
+	}
+	slices.Sort(g.names)
+	return g, nil
+}
+
+// TunnelGatewayTargets returns the HTTP base URL of each tunnel keyed by its connect name.
+func TunnelGatewayTargets(tunnels map[string]*Tunnel) map[string]string {
+	targets := map[string]string{}
+	for name, tunnel := range tunnels {
+		endpoints := tunnel.HTTPEndpoints()
+		if len(endpoints) == 0 {
+			continue
+		}
+		targets[name] = endpoints[0]
+	}
+	return targets
+}
+
+// ServeHTTP routes the request to the tunnel matching its host name or path.
+func (g *TunnelGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if label, _, found := strings.Cut(host, "."); found {
+		if proxy, ok := g.proxies[strings.ToLower(label)]; ok {
+			proxy.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	segment, rest, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	proxy, ok := g.proxies[strings.ToLower(segment)]
+	if !ok {
+		g.serveIndex(w, r)
+		return
+	}
+	// Relative links of the service only resolve below the prefix when it ends with a slash
+	if !found {
+		http.Redirect(w, r, "/"+segment+"/", http.StatusMovedPermanently)
+		return
+	}
+	routed := r.Clone(r.Context())
+	routed.URL.Path = "/" + rest
+	routed.URL.RawPath = ""
+	routed.Header.Set("X-Forwarded-Prefix", "/"+segment)
+	proxy.ServeHTTP(w, routed)
+}
+
+// serveIndex lists the routes of the gateway.
+func (g *TunnelGateway) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, "<html><body><ul>")
+	for _, name := range g.names {
+		escaped := html.EscapeString(name)
+		fmt.Fprintf(w, "<li><a href=\"/%s/\">%s</a></li>\n", url.PathEscape(name), escaped)
+	}
+	fmt.Fprintln(w, "</ul></body></html>")
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/zarf-dev/zarf/src/test/testutil"
+)
+
+func TestTunnelGateway(t *testing.T) {
+	t.Parallel()
+
+	newBackend := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "%s %s %s", name, r.URL.Path, r.Header.Get("X-Forwarded-Prefix"))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	podinfo := newBackend("podinfo")
+	registry := newBackend("registry")
+
+	g, err := NewTunnelGateway(map[string]string{
+		"podinfo":  podinfo.URL,
+		"REGISTRY": registry.URL,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		host         string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "route by path",
+			host:         "127.0.0.1:8080",
+			path:         "/podinfo/api/info",
+			expectedCode: http.StatusOK,
+			expectedBody: "podinfo /api/info /podinfo",
+		},
+		{
+			name:         "route by path ignores case",
+			host:         "127.0.0.1:8080",
+			path:         "/registry/v2/_catalog",
+			expectedCode: http.StatusOK,
+			expectedBody: "registry /v2/_catalog /registry",
+		},
+		{
+			name:         "route by host",
+			host:         "podinfo.localhost:8080",
+			path:         "/api/info",
+			expectedCode: http.StatusOK,
+			expectedBody: "podinfo /api/info ",
+		},
+		{
+			name:         "redirect to the prefix with a trailing slash",
+			host:         "127.0.0.1:8080",
+			path:         "/podinfo",
+			expectedCode: http.StatusMovedPermanently,
+		},
+		{
+			name:         "unknown route",
+			host:         "127.0.0.1:8080",
+			path:         "/unknown/",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "index",
+			host:         "127.0.0.1:8080",
+			path:         "/",
+			expectedCode: http.StatusOK,
+			expectedBody: "<html><body><ul>\n<li><a href=\"/REGISTRY/\">REGISTRY</a></li>\n<li><a href=\"/podinfo/\">podinfo</a></li>\n</ul></body></html>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			g.ServeHTTP(rec, req)
+			require.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedBody != "" {
+				b, err := io.ReadAll(rec.Body)
+				require.NoError(t, err)
+				require.Equal(t, tt.expectedBody, string(b))
+			}
+		})
+	}
+
+	_, err = NewTunnelGateway(map[string]string{"podinfo": podinfo.URL, "PODINFO": podinfo.URL})
+	require.ErrorContains(t, err, "is routed more than once")
+}
+
+func TestConnectAllWithoutConnections(t *testing.T) {
+	t.Parallel()
+	c := &Cluster{Clientset: fake.NewClientset()}
+	_, err := c.ConnectAll(testutil.TestContext(t), nil, []string{"127.0.0.1"})
+	require.ErrorContains(t, err, "no services with the zarf.dev/connect-name label were found")
+}
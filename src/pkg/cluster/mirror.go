@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	v1ac "k8s.io/client-go/applyconfigurations/core/v1"
+
+	"github.com/zarf-dev/zarf/src/internal/registrymirror"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+)
+
+const (
+	// RegistryMirrorConfigMapName is the name of the config map holding the containerd hosts.toml files
+	// that the registry mirror DaemonSet writes to each node.
+	RegistryMirrorConfigMapName = "zarf-registry-mirrors"
+	// RegistryMirrorDaemonSetName is the name of the DaemonSet that writes the registry mirrors to each node.
+	RegistryMirrorDaemonSetName = "zarf-registry-mirror"
+
+	// registryMirrorChecksumAnnotation restarts the DaemonSet pods when the registry mirrors change.
+	registryMirrorChecksumAnnotation = "zarf.dev/registry-mirrors-checksum"
+)
+
+// UpdateRegistryMirrors mirrors the source registries of the images to the Zarf registry on every node.
+// The mirrored hosts are saved to the state and the containerd hosts.toml files are written to the registry mirror config map.
+func (c *Cluster) UpdateRegistryMirrors(ctx context.Context, s *state.State, images []transform.Image) error {
+	if s.RegistryInfo.RegistryMode != state.RegistryModeMirror {
+		return nil
+	}
+	hosts := []string{}
+	for _, img := range images {
+		hosts = append(hosts, img.Host)
+	}
+	if s.RegistryInfo.AddMirrors(hosts...) {
+		logger.From(ctx).Info("adding registry mirrors", "mirrors", s.RegistryInfo.Mirrors)
+		if err := c.SaveState(ctx, s, state.AuditOperationUpdateRegistryMirrors); err != nil {
+			return err
+		}
+	}
+	cm := registryMirrorConfigMap(ctx, s.RegistryInfo)
+	_, err := c.Clientset.CoreV1().ConfigMaps(*cm.Namespace).Apply(ctx, cm, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
+	if err != nil {
+		return fmt.Errorf("unable to update the registry mirror config map: %w", err)
+	}
+
+	// The DaemonSet only writes the mirrors when its pods start, so they are restarted when the mirrors change
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`, registryMirrorChecksumAnnotation, registryMirrorChecksum(cm.Data))
+	_, err = c.Clientset.AppsV1().DaemonSets(state.ZarfNamespaceName).Patch(ctx, RegistryMirrorDaemonSetName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{FieldManager: FieldManagerName})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return fmt.Errorf("unable to restart the registry mirror DaemonSet: %w", err)
+	}
+	return nil
+}
+
+// registryMirrorChecksum returns a checksum of the registry mirror config map data.
+func registryMirrorChecksum(data map[string]string) string {
+	h := sha256.New()
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", k, data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func registryMirrorConfigMap(ctx context.Context, registryInfo state.RegistryInfo) *v1ac.ConfigMapApplyConfiguration {
+	data := map[string]string{}
+	hosts := []string{}
+	for _, host := range registryInfo.Mirrors {
+		key := registryMirrorConfigKey(host)
+		if msgs := validation.IsConfigMapKey(key); len(msgs) > 0 {
+			logger.From(ctx).Warn("unable to mirror the registry host", "host", host, "reason", strings.Join(msgs, ", "))
+			continue
+		}
+		if _, ok := data[key]; ok {
+			logger.From(ctx).Warn("unable to mirror the registry host", "host", host, "reason", "another host uses the same config map key "+key)
+			continue
+		}
+		data[key] = registryMirrorHostsTOML(registryInfo.Address, host)
+		hosts = append(hosts, key+" "+host+"\n")
+	}
+	// The DaemonSet reads the host of each key from the hosts list as a config map key can not hold every valid host
+	data[registrymirror.HostsFile] = strings.Join(hosts, "")
+	return v1ac.ConfigMap(RegistryMirrorConfigMapName, state.ZarfNamespaceName).
+		WithLabels(map[string]string{
+			state.ZarfManagedByLabel: "zarf",
+		}).
+		WithData(data)
+}
+
+// registryMirrorConfigKey returns the config map key for the hosts.toml of the host.
+func registryMirrorConfigKey(host string) string {
+	return strings.ReplaceAll(host, ":", "_") + ".toml"
+}
+
+// registryMirrorHostsTOML returns the containerd hosts.toml that pulls images of the host from the Zarf registry.
+// Images are pushed to the registry under their source host so the path is overridden to include it.
+func registryMirrorHostsTOML(registryAddress, host string) string {
+	server := host
+	// Docker Hub is served from a different host than the one used in image references
+	if host == "docker.io" {
+		server = "registry-1.docker.io"
+	}
+	return fmt.Sprintf(`server = "https://%s"
+
+[host."http://%s/v2/%s"]
+  capabilities = ["pull", "resolve"]
+  override_path = true
+`, server, registryAddress, host)
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+)
+
+func TestUpdateRegistryMirrors(t *testing.T) {
+	ctx := testutil.TestContext(t)
+	c := &Cluster{
+		Clientset: fake.NewClientset(),
+	}
+	_, err := c.Clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: state.ZarfNamespaceName}}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: RegistryMirrorDaemonSetName, Namespace: state.ZarfNamespaceName}}
+	_, err = c.Clientset.AppsV1().DaemonSets(state.ZarfNamespaceName).Create(ctx, ds, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	s := &state.State{
+		RegistryInfo: state.RegistryInfo{
+			Address:      "127.0.0.1:31999",
+			PullUsername: "pull-user",
+			PullPassword: "pull-password",
+			RegistryMode: state.RegistryModeMirror,
+		},
+	}
+	var imgs []transform.Image
+	for _, ref := range []string{"nginx:1.27", "ghcr.io/stefanprodan/podinfo:6.4.0", "registry.example.com:5000/app:v1"} {
+		img, err := transform.ParseImageRef(ref)
+		require.NoError(t, err)
+		imgs = append(imgs, img)
+	}
+
+	require.NoError(t, c.UpdateRegistryMirrors(ctx, s, imgs))
+	require.Equal(t, []string{"docker.io", "ghcr.io", "registry.example.com:5000"}, s.RegistryInfo.Mirrors)
+
+	saved, err := c.LoadState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, s.RegistryInfo.Mirrors, saved.RegistryInfo.Mirrors)
+
+	cm, err := c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).Get(ctx, RegistryMirrorConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, cm.Data, 4)
+	require.Equal(t, "docker.io.toml docker.io\nghcr.io.toml ghcr.io\nregistry.example.com_5000.toml registry.example.com:5000\n", cm.Data["hosts"])
+	expected := `server = "https://registry-1.docker.io"
+
+[host."http://127.0.0.1:31999/v2/docker.io"]
+  capabilities = ["pull", "resolve"]
+  override_path = true
+`
+	require.Equal(t, expected, cm.Data["docker.io.toml"])
+	require.Contains(t, cm.Data["registry.example.com_5000.toml"], `[host."http://127.0.0.1:31999/v2/registry.example.com:5000"]`)
+
+	// The DaemonSet pods are restarted when the mirrors change
+	ds, err = c.Clientset.AppsV1().DaemonSets(state.ZarfNamespaceName).Get(ctx, RegistryMirrorDaemonSetName, metav1.GetOptions{})
+	require.NoError(t, err)
+	checksum := ds.Spec.Template.Annotations["zarf.dev/registry-mirrors-checksum"]
+	require.Equal(t, registryMirrorChecksum(cm.Data), checksum)
+
+	secret, err := c.GenerateRegistryPullCreds(ctx, "podinfo", config.ZarfImagePullSecretName, s.RegistryInfo)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"auths":{
+		"127.0.0.1:31999":{"auth":"cHVsbC11c2VyOnB1bGwtcGFzc3dvcmQ="},
+		"docker.io":{"auth":"cHVsbC11c2VyOnB1bGwtcGFzc3dvcmQ="},
+		"ghcr.io":{"auth":"cHVsbC11c2VyOnB1bGwtcGFzc3dvcmQ="},
+		"registry.example.com:5000":{"auth":"cHVsbC11c2VyOnB1bGwtcGFzc3dvcmQ="}
+	}}`, string(secret.Data[".dockerconfigjson"]))
+
+	img, err := transform.ParseImageRef("quay.io/argoproj/argocd:v2.9.0")
+	require.NoError(t, err)
+	require.NoError(t, c.UpdateRegistryMirrors(ctx, s, []transform.Image{img}))
+	ds, err = c.Clientset.AppsV1().DaemonSets(state.ZarfNamespaceName).Get(ctx, RegistryMirrorDaemonSetName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotEqual(t, checksum, ds.Spec.Template.Annotations["zarf.dev/registry-mirrors-checksum"])
+}
+
+func TestUpdateRegistryMirrorsSkipsOtherModes(t *testing.T) {
+	ctx := testutil.TestContext(t)
+	c := &Cluster{
+		Clientset: fake.NewClientset(),
+	}
+	s := &state.State{
+		RegistryInfo: state.RegistryInfo{
+			Address:      "127.0.0.1:31999",
+			RegistryMode: state.RegistryModeNodePort,
+		},
+	}
+	img, err := transform.ParseImageRef("nginx:1.27")
+	require.NoError(t, err)
+
+	require.NoError(t, c.UpdateRegistryMirrors(ctx, s, []transform.Image{img}))
+	require.Empty(t, s.RegistryInfo.Mirrors)
+	_, err = c.Clientset.CoreV1().ConfigMaps(state.ZarfNamespaceName).Get(ctx, RegistryMirrorConfigMapName, metav1.GetOptions{})
+	require.Error(t, err)
+}
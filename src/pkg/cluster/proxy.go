@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+)
+
+// RegistryProxyDaemonSetName is the name of the DaemonSet that runs the registry proxy on each node.
+const RegistryProxyDaemonSetName = "zarf-registry-proxy"
+
+// RegistryProxyHealthy returns true when the registry proxy is ready on every node it is scheduled to.
+// A proxy pod is only ready once it is able to reach the registry.
+func (c *Cluster) RegistryProxyHealthy(ctx context.Context) (bool, error) {
+	ds, err := c.Clientset.AppsV1().DaemonSets(state.ZarfNamespaceName).Get(ctx, RegistryProxyDaemonSetName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if ds.Generation != ds.Status.ObservedGeneration {
+		return false, nil
+	}
+	desired := ds.Status.DesiredNumberScheduled
+	return desired > 0 && ds.Status.NumberReady == desired && ds.Status.UpdatedNumberScheduled == desired, nil
+}
+
+// WaitForRegistryProxy waits until the registry proxy is ready on every node or the context is done.
+func (c *Cluster) WaitForRegistryProxy(ctx context.Context) error {
+	l := logger.From(ctx)
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		healthy, err := c.RegistryProxyHealthy(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !healthy {
+			l.Debug("waiting for the registry proxy to be ready on every node")
+		}
+		return healthy, nil
+	})
+	if err != nil {
+		return fmt.Errorf("the registry proxy is not ready: %w", err)
+	}
+	return nil
+}
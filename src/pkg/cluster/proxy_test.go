@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+)
+
+func TestRegistryProxyHealthy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		status   *appsv1.DaemonSetStatus
+		expected bool
+	}{
+		{
+			name:     "missing",
+			expected: false,
+		},
+		{
+			name:     "no nodes scheduled",
+			status:   &appsv1.DaemonSetStatus{},
+			expected: false,
+		},
+		{
+			name:     "not ready on every node",
+			status:   &appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2, UpdatedNumberScheduled: 3},
+			expected: false,
+		},
+		{
+			name:     "rollout in progress",
+			status:   &appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3, UpdatedNumberScheduled: 1},
+			expected: false,
+		},
+		{
+			name:     "ready",
+			status:   &appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3, UpdatedNumberScheduled: 3},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testutil.TestContext(t)
+			c := &Cluster{Clientset: fake.NewClientset()}
+			if tt.status != nil {
+				ds := &appsv1.DaemonSet{
+					ObjectMeta: metav1.ObjectMeta{Name: RegistryProxyDaemonSetName, Namespace: state.ZarfNamespaceName},
+					Status:     *tt.status,
+				}
+				_, err := c.Clientset.AppsV1().DaemonSets(state.ZarfNamespaceName).Create(ctx, ds, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			healthy, err := c.RegistryProxyHealthy(ctx)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, healthy)
+		})
+	}
+}
+
+func TestWaitForRegistryProxyTimeout(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(testutil.TestContext(t), 2*time.Second)
+	defer cancel()
+	c := &Cluster{Clientset: fake.NewClientset()}
+	err := c.WaitForRegistryProxy(ctx)
+	require.ErrorContains(t, err, "the registry proxy is not ready")
+}
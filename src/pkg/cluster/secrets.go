@@ -33,36 +33,46 @@ type DockerConfigEntryWithAuth struct {
 	Auth string `json:"auth"`
 }
 
-// GenerateRegistryPullCreds generates a secret containing the registry credentials.
-func (c *Cluster) GenerateRegistryPullCreds(ctx context.Context, namespace, name string, registryInfo state.RegistryInfo) (*v1ac.SecretApplyConfiguration, error) {
-	// Auth field must be username:password and base64 encoded
-	fieldValue := registryInfo.PullUsername + ":" + registryInfo.PullPassword
-	authEncodedValue := base64.StdEncoding.EncodeToString([]byte(fieldValue))
-
+// GenerateRegistryPullCreds generates a secret containing the credentials of one or more registries.
+func (c *Cluster) GenerateRegistryPullCreds(ctx context.Context, namespace, name string, registryInfos ...state.RegistryInfo) (*v1ac.SecretApplyConfiguration, error) {
 	dockerConfigJSON := DockerConfig{
-		Auths: DockerConfigEntry{
-			// nodePort for zarf-docker-registry - ie 127.0.0.1:31999
-			registryInfo.Address: DockerConfigEntryWithAuth{
-				Auth: authEncodedValue,
-			},
-		},
+		Auths: DockerConfigEntry{},
 	}
 
 	serviceList, err := c.Clientset.CoreV1().Services("").List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
-	// Build zarf-docker-registry service address and internal dns string
-	svc, port, err := serviceInfoFromNodePortURL(serviceList.Items, registryInfo.Address)
-	if err == nil {
-		kubeDNSRegistryURL := fmt.Sprintf("%s:%d", svc.Spec.ClusterIP, port)
-		dockerConfigJSON.Auths[kubeDNSRegistryURL] = DockerConfigEntryWithAuth{
+
+	for _, registryInfo := range registryInfos {
+		// Auth field must be username:password and base64 encoded
+		fieldValue := registryInfo.PullUsername + ":" + registryInfo.PullPassword
+		authEncodedValue := base64.StdEncoding.EncodeToString([]byte(fieldValue))
+
+		// nodePort for zarf-docker-registry - ie 127.0.0.1:31999
+		dockerConfigJSON.Auths[registryInfo.Address] = DockerConfigEntryWithAuth{
 			Auth: authEncodedValue,
 		}
 
-		kubeDNSRegistryHostname := fmt.Sprintf("%s.%s.svc.cluster.local:%d", svc.Name, svc.Namespace, port)
-		dockerConfigJSON.Auths[kubeDNSRegistryHostname] = DockerConfigEntryWithAuth{
-			Auth: authEncodedValue,
+		// In mirror mode images keep their source host so the credentials are also needed for every mirrored host
+		for _, host := range registryInfo.Mirrors {
+			dockerConfigJSON.Auths[host] = DockerConfigEntryWithAuth{
+				Auth: authEncodedValue,
+			}
+		}
+
+		// Build zarf-docker-registry service address and internal dns string
+		svc, port, err := serviceInfoFromNodePortURL(serviceList.Items, registryInfo.Address)
+		if err == nil {
+			kubeDNSRegistryURL := fmt.Sprintf("%s:%d", svc.Spec.ClusterIP, port)
+			dockerConfigJSON.Auths[kubeDNSRegistryURL] = DockerConfigEntryWithAuth{
+				Auth: authEncodedValue,
+			}
+
+			kubeDNSRegistryHostname := fmt.Sprintf("%s.%s.svc.cluster.local:%d", svc.Name, svc.Namespace, port)
+			dockerConfigJSON.Auths[kubeDNSRegistryHostname] = DockerConfigEntryWithAuth{
+				Auth: authEncodedValue,
+			}
 		}
 	}
 
@@ -96,6 +106,37 @@ func (c *Cluster) GenerateGitPullCreds(namespace, name string, gitServerInfo sta
 		})
 }
 
+// ApplyRegistryS3Secret applies the secret holding the access and secret keys the registry authenticates with its S3 bucket.
+// The keys are read from the secret by the registry chart so they are never part of the Helm values.
+func (c *Cluster) ApplyRegistryS3Secret(ctx context.Context, storage state.RegistryS3Storage) error {
+	secret := v1ac.Secret(state.ZarfRegistryS3SecretName, state.ZarfNamespaceName).
+		WithLabels(map[string]string{
+			state.ZarfManagedByLabel: "zarf",
+		}).WithType(corev1.SecretTypeOpaque).
+		WithStringData(map[string]string{
+			"accessKey": storage.AccessKey,
+			"secretKey": storage.SecretKey,
+		})
+	_, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Apply(ctx, secret, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
+	if err != nil {
+		return fmt.Errorf("unable to apply the registry S3 secret: %w", err)
+	}
+	return nil
+}
+
+// ApplyGitPullCreds applies a secret for each git server that workloads in the namespace pull repositories from.
+// The default git server uses the Zarf git server secret and named git servers use the secret name suffixed with their name.
+func (c *Cluster) ApplyGitPullCreds(ctx context.Context, namespace string, s *state.State) error {
+	for _, gs := range s.GitServersForNamespace(namespace) {
+		gitServerSecret := c.GenerateGitPullCreds(namespace, gs.SecretName(config.ZarfGitServerSecretName), gs.GitServerInfo)
+		_, err := c.Clientset.CoreV1().Secrets(namespace).Apply(ctx, gitServerSecret, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // UpdateZarfManagedImageSecrets updates all Zarf-managed image secrets in all namespaces based on state
 func (c *Cluster) UpdateZarfManagedImageSecrets(ctx context.Context, s *state.State) error {
 	l := logger.From(ctx)
@@ -117,7 +158,7 @@ func (c *Cluster) UpdateZarfManagedImageSecrets(ctx context.Context, s *state.St
 		if currentRegistrySecret.Labels[state.ZarfManagedByLabel] != "zarf" && (namespace.Labels[AgentLabel] == "skip" || namespace.Labels[AgentLabel] == "ignore") {
 			continue
 		}
-		newRegistrySecret, err := c.GenerateRegistryPullCreds(ctx, namespace.Name, config.ZarfImagePullSecretName, s.RegistryInfo)
+		newRegistrySecret, err := c.GenerateRegistryPullCreds(ctx, namespace.Name, config.ZarfImagePullSecretName, s.RegistriesForNamespace(namespace.Name)...)
 		if err != nil {
 			return err
 		}
@@ -151,10 +192,8 @@ func (c *Cluster) UpdateZarfManagedGitSecrets(ctx context.Context, s *state.Stat
 		if currentGitSecret.Labels[state.ZarfManagedByLabel] != "zarf" && (namespace.Labels[AgentLabel] == "skip" || namespace.Labels[AgentLabel] == "ignore") {
 			continue
 		}
-		newGitSecret := c.GenerateGitPullCreds(namespace.Name, config.ZarfGitServerSecretName, s.GitServer)
 		l.Info("applying Zarf managed git secret for namespace", "name", namespace.Name)
-		_, err = c.Clientset.CoreV1().Secrets(*newGitSecret.Namespace).Apply(ctx, newGitSecret, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
-		if err != nil {
+		if err := c.ApplyGitPullCreds(ctx, namespace.Name, s); err != nil {
 			return err
 		}
 	}
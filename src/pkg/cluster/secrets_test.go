@@ -204,3 +204,68 @@ func TestUpdateZarfManagedSecrets(t *testing.T) {
 		})
 	}
 }
+
+func TestPullCredsWithNamedTargets(t *testing.T) {
+	ctx := testutil.TestContext(t)
+	c := &Cluster{
+		Clientset: fake.NewClientset(),
+	}
+
+	s := &state.State{
+		GitServer: state.GitServerInfo{
+			PullUsername: "pull-user",
+			PullPassword: "pull-password",
+		},
+		RegistryInfo: state.RegistryInfo{
+			PullUsername: "pull-user",
+			PullPassword: "pull-password",
+			Address:      "127.0.0.1:31999",
+		},
+		Registries: []state.NamedRegistryInfo{
+			{
+				Name:         "tenant-a",
+				RegistryInfo: state.RegistryInfo{Address: "registry.tenant-a.example.com", PullUsername: "tenant-a", PullPassword: "tenant-a-password"},
+				Routes:       state.RoutingRules{Namespaces: []string{"tenant-a"}},
+			},
+		},
+		GitServers: []state.NamedGitServerInfo{
+			{
+				Name:          "tenant-a",
+				GitServerInfo: state.GitServerInfo{Address: "https://git.tenant-a.example.com", PullUsername: "tenant-a", PullPassword: "tenant-a-password"},
+				Routes:        state.RoutingRules{Namespaces: []string{"tenant-a"}},
+			},
+		},
+	}
+
+	secret, err := c.GenerateRegistryPullCreds(ctx, "tenant-a", config.ZarfImagePullSecretName, s.RegistriesForNamespace("tenant-a")...)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"auths":{"127.0.0.1:31999":{"auth":"cHVsbC11c2VyOnB1bGwtcGFzc3dvcmQ="},"registry.tenant-a.example.com":{"auth":"dGVuYW50LWE6dGVuYW50LWEtcGFzc3dvcmQ="}}}`, string(secret.Data[".dockerconfigjson"]))
+
+	secret, err = c.GenerateRegistryPullCreds(ctx, "other", config.ZarfImagePullSecretName, s.RegistriesForNamespace("other")...)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"auths":{"127.0.0.1:31999":{"auth":"cHVsbC11c2VyOnB1bGwtcGFzc3dvcmQ="}}}`, string(secret.Data[".dockerconfigjson"]))
+
+	require.NoError(t, c.ApplyGitPullCreds(ctx, "tenant-a", s))
+	gitSecret, err := c.Clientset.CoreV1().Secrets("tenant-a").Get(ctx, config.ZarfGitServerSecretName+"-tenant-a", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "tenant-a", gitSecret.StringData["username"])
+	_, err = c.Clientset.CoreV1().Secrets("tenant-a").Get(ctx, config.ZarfGitServerSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, c.ApplyGitPullCreds(ctx, "other", s))
+	secrets, err := c.Clientset.CoreV1().Secrets("other").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, secrets.Items, 1)
+}
+
+func TestApplyRegistryS3Secret(t *testing.T) {
+	ctx := testutil.TestContext(t)
+	c := &Cluster{
+		Clientset: fake.NewClientset(),
+	}
+
+	require.NoError(t, c.ApplyRegistryS3Secret(ctx, state.RegistryS3Storage{Bucket: "zarf", AccessKey: "access", SecretKey: "secret"}))
+	secret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, state.ZarfRegistryS3SecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"accessKey": "access", "secretKey": "secret"}, secret.StringData)
+}
@@ -10,9 +10,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -27,6 +29,7 @@ import (
 	"github.com/zarf-dev/zarf/src/internal/dns"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/state"
+	"golang.org/x/sync/errgroup"
 )
 
 // Zarf specific connect strings
@@ -146,6 +149,53 @@ func (c *Cluster) ConnectTunnelInfo(ctx context.Context, zt TunnelInfo) (*Tunnel
 	return tunnel, nil
 }
 
+// ConnectAll establishes tunnels to the connect names concurrently, or to every service with a connect name when none are given.
+// The tunnels are keyed by their connect name and bind to an available local port.
+func (c *Cluster) ConnectAll(ctx context.Context, names []string, listenAddresses []string) (map[string]*Tunnel, error) {
+	if len(names) == 0 {
+		connections, err := c.ListConnections(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(connections) == 0 {
+			return nil, fmt.Errorf("no services with the %s label were found", ZarfConnectLabelName)
+		}
+		for name := range connections {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+	}
+
+	var mu sync.Mutex
+	tunnels := map[string]*Tunnel{}
+	g, gCtx := errgroup.WithContext(ctx)
+	for _, name := range helpers.Unique(names) {
+		g.Go(func() error {
+			zt, err := c.NewTargetTunnelInfo(gCtx, name)
+			if err != nil {
+				return fmt.Errorf("unable to create the tunnel to %s: %w", name, err)
+			}
+			zt.LocalPort = 0
+			zt.ListenAddresses = listenAddresses
+			tunnel, err := c.ConnectTunnelInfo(gCtx, zt)
+			if err != nil {
+				return fmt.Errorf("unable to connect to %s: %w", name, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			tunnels[name] = tunnel
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		for _, tunnel := range tunnels {
+			tunnel.Close()
+		}
+		return nil, err
+	}
+	return tunnels, nil
+}
+
 // ConnectToZarfRegistryEndpoint determines if a registry endpoint is in cluster, and if so opens a tunnel to connect to it
 func (c *Cluster) ConnectToZarfRegistryEndpoint(ctx context.Context, registryInfo state.RegistryInfo) (string, *Tunnel, error) {
 	registryEndpoint := registryInfo.Address
@@ -404,6 +454,37 @@ func (tunnel *Tunnel) Connect(ctx context.Context) ([]string, error) {
 	return urls, nil
 }
 
+// KeepAlive blocks until the context is done or the tunnel is closed. Whenever the connection is lost, for example when
+// the pod behind the tunnel restarts, the tunnel is re-established on the same local port to a running pod of the resource.
+func (tunnel *Tunnel) KeepAlive(ctx context.Context) error {
+	l := logger.From(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tunnel.stopChan:
+			return nil
+		case err := <-tunnel.ErrChan():
+			// The port forward stops without an error once the tunnel is closed
+			if err == nil {
+				return nil
+			}
+			l.Warn("lost connection to the tunnel, reconnecting", "resource", tunnel.resourceName, "namespace", tunnel.namespace, "error", err.Error())
+			err = retry.Do(func() error {
+				_, err := tunnel.establish(ctx)
+				return err
+			}, retry.Context(ctx), retry.Attempts(0), retry.Delay(time.Second), retry.MaxDelay(10*time.Second), retry.LastErrorOnly(true))
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("unable to reconnect the tunnel to %s: %w", tunnel.resourceName, err)
+			}
+			l.Info("reconnected the tunnel", "resource", tunnel.resourceName, "namespace", tunnel.namespace, "urls", strings.Join(tunnel.FullURLs(), ", "))
+		}
+	}
+}
+
 // Endpoints returns all tunnel endpoints
 func (tunnel *Tunnel) Endpoints() []string {
 	endpoints := make([]string, len(tunnel.listenAddress))
@@ -510,6 +591,8 @@ func (tunnel *Tunnel) establish(ctx context.Context) ([]string, error) {
 	}
 
 	// Construct a new PortForwarder struct that manages the instructed port forward tunnel.
+	// The port forwarder closes the ready channel so a new one is needed each time the tunnel is established.
+	tunnel.readyChan = make(chan struct{}, 1)
 	ports := []string{fmt.Sprintf("%d:%d", localPort, tunnel.remotePort)}
 	portforwarder, err := portforward.NewOnAddresses(dialer, tunnel.listenAddress, ports, tunnel.stopChan, tunnel.readyChan, io.Discard, io.Discard)
 	if err != nil {
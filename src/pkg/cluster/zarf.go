@@ -96,11 +96,12 @@ func (c *Cluster) UpdateDeployedPackage(ctx context.Context, depPkg state.Deploy
 		}).WithData(map[string][]byte{
 		"data": packageSecretData,
 	}).WithType(corev1.SecretTypeOpaque)
+	previousPackage := c.getDeployedPackageForAudit(ctx, depPkg.GetSecretName())
 	_, err = c.Clientset.CoreV1().Secrets(*packageSecret.Namespace).Apply(ctx, packageSecret, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
 	if err != nil {
 		return fmt.Errorf("unable to apply the deployed package secret: %w", err)
 	}
-	return nil
+	return c.recordPackageAudit(ctx, state.AuditOperationUpdateDeployedPackage, previousPackage, &depPkg)
 }
 
 // DeleteDeployedPackage removes the metadata for the deployed package.
@@ -109,6 +110,44 @@ func (c *Cluster) DeleteDeployedPackage(ctx context.Context, depPkg state.Deploy
 	if err != nil {
 		return err
 	}
+	c.recordAudit(ctx, state.AuditEntry{
+		Operation:  state.AuditOperationDeleteDeployedPackage,
+		Package:    depPkg.Name,
+		Generation: depPkg.Generation,
+	})
+	return nil
+}
+
+// getDeployedPackageForAudit returns the deployed package stored in the secret, or nil if it can not be read.
+// The previous package is only used to record the changes in the audit log.
+func (c *Cluster) getDeployedPackageForAudit(ctx context.Context, secretName string) *state.DeployedPackage {
+	secret, err := c.Clientset.CoreV1().Secrets(state.ZarfNamespaceName).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	depPkg := &state.DeployedPackage{}
+	if err := json.Unmarshal(secret.Data["data"], depPkg); err != nil {
+		return nil
+	}
+	return depPkg
+}
+
+// recordPackageAudit records the changes to a deployed package in the audit log.
+func (c *Cluster) recordPackageAudit(ctx context.Context, operation state.AuditOperation, previous, current *state.DeployedPackage) error {
+	var previousView any
+	if previous != nil {
+		previousView = previous.AuditView()
+	}
+	changes, err := state.AuditChanges(previousView, current.AuditView())
+	if err != nil {
+		return err
+	}
+	c.recordAudit(ctx, state.AuditEntry{
+		Operation:  operation,
+		Package:    current.Name,
+		Generation: current.Generation,
+		Changes:    changes,
+	})
 	return nil
 }
 
@@ -193,6 +232,7 @@ func (c *Cluster) RecordPackageDeployment(ctx context.Context, pkg v1alpha1.Zarf
 		WithData(map[string][]byte{
 			"data": packageData,
 		})
+	previousPackage := c.getDeployedPackageForAudit(ctx, deployedPackage.GetSecretName())
 	updatedSecret, err := c.Clientset.CoreV1().Secrets(*deployedPackageSecret.Namespace).Apply(ctx, deployedPackageSecret, metav1.ApplyOptions{Force: true, FieldManager: FieldManagerName})
 	if err != nil {
 		return nil, fmt.Errorf("failed to record package deployment in secret '%s': %w", *deployedPackageSecret.Name, err)
@@ -200,6 +240,9 @@ func (c *Cluster) RecordPackageDeployment(ctx context.Context, pkg v1alpha1.Zarf
 	if err := json.Unmarshal(updatedSecret.Data["data"], &deployedPackage); err != nil {
 		return nil, err
 	}
+	if err := c.recordPackageAudit(ctx, state.AuditOperationRecordPackageDeployment, previousPackage, deployedPackage); err != nil {
+		return nil, err
+	}
 	return deployedPackage, nil
 }
 
@@ -8,16 +8,21 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 var (
 	// IsLowercaseNumberHyphenNoStartHyphen is a regex for lowercase, numbers and hyphens that cannot start with a hyphen.
 	// https://regex101.com/r/FLdG9G/2
 	IsLowercaseNumberHyphenNoStartHyphen = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]*$`).MatchString
+	// isTagSuffix is a regex for the characters allowed in image tags.
+	isTagSuffix = regexp.MustCompile(`^[\w.-]*$`).MatchString
 	// Define allowed OS, an empty string means it is allowed on all operating systems
 	// same as enums on ZarfComponentOnlyTarget
 	supportedOS = []string{"linux", "darwin", "windows", ""}
@@ -62,9 +67,30 @@ const (
 	PkgValidateErrChartNamespaceMissing   = "chart %q must include a namespace"
 	PkgValidateErrChartURLOrPath          = "chart %q must have either a url or localPath"
 	PkgValidateErrChartVersion            = "chart %q must include a chart version"
+	PkgValidateErrChartMaxHistory         = "chart %q must have a maxHistory of 0 or greater"
+	PkgValidateErrChartPostRenderer       = "chart %q has a post-renderer without a command"
 	PkgValidateErrManifestFileOrKustomize = "manifest %q must have at least one file or kustomization"
 	PkgValidateErrManifestNameLength      = "manifest %q exceed the maximum length of %d characters"
+	PkgValidateErrManifestKustomizeOnly   = "manifest %q must have a kustomization to use kustomize components or patches"
+	PkgValidateErrManifestKustomizePatch  = "manifest %q has a kustomize patch without a patch"
 	PkgValidateErrVariable                = "invalid package variable: %w"
+	PkgValidateErrHealthCheck             = "invalid health check: %w"
+	PkgValidateErrHealthCheckNameSelector = "health check for %s must have either a name or selector"
+	PkgValidateErrHealthCheckSelector     = "health check for %s has an invalid selector: %w"
+	PkgValidateErrHealthCheckCondJSONPath = "health check for %s cannot have both a condition and jsonPath"
+	PkgValidateErrHealthCheckCondition    = "health check for %s must include a condition type"
+	PkgValidateErrHealthCheckValue        = "health check for %s cannot have a value without a jsonPath"
+	PkgValidateErrHealthCheckJSONPath     = "health check for %s has an invalid jsonPath: %w"
+	PkgValidateErrHealthCheckTimeout      = "health check for %s must have a timeoutSeconds of 0 or greater"
+	PkgValidateErrImageExtractor          = "invalid image extractor: %w"
+	PkgValidateErrImageExtractorKind      = "image extractor must include an apiVersion and kind"
+	PkgValidateErrImageExtractorPaths     = "image extractor for %s must include at least one path or reference"
+	PkgValidateErrImageExtractorRepo      = "image extractor reference for %s must include a repository"
+	PkgValidateErrImageExtractorJSONPath  = "image extractor for %s has an invalid jsonPath: %w"
+	PkgValidateErrTransform               = "invalid transform rule: %w"
+	PkgValidateErrTransformMatch          = "transform rule must include a match"
+	PkgValidateErrTransformRegex          = "transform match %q is not a valid regular expression: %w"
+	PkgValidateErrTransformTagSuffix      = "image transform tag suffix %q may only contain letters, digits, underscores, periods and dashes"
 	PkgValidateErrNoComponents            = "package does not contain any compatible components"
 	PkgValidateErrActionTemplateOnCreate  = "templating is not supported in onCreate actions"
 )
@@ -83,6 +109,14 @@ func ValidatePackage(pkg v1alpha1.ZarfPackage) error {
 			err = errors.Join(err, fmt.Errorf(PkgValidateErrConstant, varErr))
 		}
 	}
+	for _, extractor := range pkg.ImageExtractors {
+		if extractorErr := validateImageExtractor(extractor); extractorErr != nil {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrImageExtractor, extractorErr))
+		}
+	}
+	if transformErr := validateTransforms(pkg.Transforms); transformErr != nil {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrTransform, transformErr))
+	}
 	uniqueComponentNames := make(map[string]bool)
 	groupDefault := make(map[string]string)
 	groupedComponents := make(map[string][]string)
@@ -141,6 +175,11 @@ func ValidatePackage(pkg v1alpha1.ZarfPackage) error {
 		if actionsErr := validateActions(component.Actions); actionsErr != nil {
 			err = errors.Join(err, fmt.Errorf("%q: %w", component.Name, actionsErr))
 		}
+		for _, hc := range component.HealthChecks {
+			if hcErr := validateHealthCheck(hc); hcErr != nil {
+				err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheck, hcErr))
+			}
+		}
 		// ensure groups don't have multiple defaults or only one component
 		if component.DeprecatedGroup != "" {
 			if component.Default {
@@ -317,6 +356,119 @@ func validateChart(chart v1alpha1.ZarfChart) error {
 		err = errors.Join(err, nameErr)
 	}
 
+	if chart.MaxHistory < 0 {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrChartMaxHistory, chart.Name))
+	}
+
+	for _, postRenderer := range chart.PostRenderers {
+		if postRenderer.Command == "" {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrChartPostRenderer, chart.Name))
+		}
+	}
+
+	return err
+}
+
+// validateHealthCheck runs all validation checks on a health check.
+func validateHealthCheck(hc v1alpha1.NamespacedObjectKindReference) error {
+	var err error
+	kind := hc.Kind
+	if hc.Name != "" {
+		kind = fmt.Sprintf("%s/%s", hc.Kind, hc.Name)
+	}
+
+	if (hc.Name == "") == (hc.Selector == "") {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheckNameSelector, kind))
+	}
+
+	if hc.Selector != "" {
+		if _, selErr := labels.Parse(hc.Selector); selErr != nil {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheckSelector, kind, selErr))
+		}
+	}
+
+	if hc.Condition != nil && hc.JSONPath != "" {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheckCondJSONPath, kind))
+	}
+
+	if hc.Condition != nil && hc.Condition.Type == "" {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheckCondition, kind))
+	}
+
+	if hc.Value != "" && hc.JSONPath == "" {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheckValue, kind))
+	}
+
+	if hc.JSONPath != "" {
+		expression := hc.JSONPath
+		if !strings.HasPrefix(expression, "{") {
+			expression = fmt.Sprintf("{%s}", expression)
+		}
+		if jpErr := jsonpath.New("healthcheck").Parse(expression); jpErr != nil {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheckJSONPath, kind, jpErr))
+		}
+	}
+
+	if hc.TimeoutSeconds < 0 {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrHealthCheckTimeout, kind))
+	}
+
+	return err
+}
+
+// validateImageExtractor runs all validation checks on an image extractor.
+func validateImageExtractor(extractor v1alpha1.ImageExtractor) error {
+	if extractor.APIVersion == "" || extractor.Kind == "" {
+		return errors.New(PkgValidateErrImageExtractorKind)
+	}
+	var err error
+	gvk := fmt.Sprintf("%s %s", extractor.APIVersion, extractor.Kind)
+	if len(extractor.Paths) == 0 && len(extractor.References) == 0 {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrImageExtractorPaths, gvk))
+	}
+	expressions := slices.Clone(extractor.Paths)
+	for _, ref := range extractor.References {
+		if ref.Repository == "" {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrImageExtractorRepo, gvk))
+		}
+		expressions = append(expressions, ref.Repository, ref.Tag, ref.Digest)
+	}
+	for _, expression := range expressions {
+		if expression == "" {
+			continue
+		}
+		if !strings.HasPrefix(expression, "{") {
+			expression = fmt.Sprintf("{%s}", expression)
+		}
+		if jpErr := jsonpath.New("image-extractor").Parse(expression); jpErr != nil {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrImageExtractorJSONPath, gvk, jpErr))
+		}
+	}
+	return err
+}
+
+// validateTransforms runs all validation checks on the image and repo transform rules of a package.
+func validateTransforms(transforms v1alpha1.ZarfTransforms) error {
+	var err error
+	matches := []string{}
+	for _, t := range transforms.Images {
+		matches = append(matches, t.Match)
+		if !isTagSuffix(t.TagSuffix) {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrTransformTagSuffix, t.TagSuffix))
+		}
+	}
+	for _, t := range transforms.Repos {
+		matches = append(matches, t.Match)
+	}
+	for _, match := range matches {
+		if match == "" {
+			err = errors.Join(err, errors.New(PkgValidateErrTransformMatch))
+			continue
+		}
+		if _, reErr := regexp.Compile(match); reErr != nil {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrTransformRegex, match, reErr))
+		}
+	}
 	return err
 }
 
@@ -332,5 +484,16 @@ func validateManifest(manifest v1alpha1.ZarfManifest) error {
 		err = errors.Join(err, fmt.Errorf(PkgValidateErrManifestFileOrKustomize, manifest.Name))
 	}
 
+	if len(manifest.Kustomizations) < 1 && (len(manifest.KustomizeComponents) > 0 || len(manifest.KustomizePatches) > 0) {
+		err = errors.Join(err, fmt.Errorf(PkgValidateErrManifestKustomizeOnly, manifest.Name))
+	}
+
+	for _, patch := range manifest.KustomizePatches {
+		if patch.Patch == "" {
+			err = errors.Join(err, fmt.Errorf(PkgValidateErrManifestKustomizePatch, manifest.Name))
+			break
+		}
+	}
+
 	return err
 }
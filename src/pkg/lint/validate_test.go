@@ -5,6 +5,7 @@
 package lint
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -178,6 +179,28 @@ func TestValidateManifest(t *testing.T) {
 			manifest:     v1alpha1.ZarfManifest{Name: "nothing-there"},
 			expectedErrs: []string{fmt.Sprintf(PkgValidateErrManifestFileOrKustomize, "nothing-there")},
 		},
+		{
+			name: "kustomize components and patches",
+			manifest: v1alpha1.ZarfManifest{
+				Name:                "kustomize",
+				Kustomizations:      []string{"a-kustomization"},
+				KustomizeComponents: []string{"a-component"},
+				KustomizePatches:    []v1alpha1.ZarfKustomizePatch{{Patch: "a-patch"}},
+			},
+			expectedErrs: nil,
+		},
+		{
+			name: "kustomize patches without a kustomization",
+			manifest: v1alpha1.ZarfManifest{
+				Name:             "files",
+				Files:            []string{"a-file"},
+				KustomizePatches: []v1alpha1.ZarfKustomizePatch{{}},
+			},
+			expectedErrs: []string{
+				fmt.Sprintf(PkgValidateErrManifestKustomizeOnly, "files"),
+				fmt.Sprintf(PkgValidateErrManifestKustomizePatch, "files"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -194,6 +217,164 @@ func TestValidateManifest(t *testing.T) {
 	}
 }
 
+func TestValidateHealthCheck(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		healthCheck  v1alpha1.NamespacedObjectKindReference
+		expectedErrs []string
+		name         string
+	}{
+		{
+			name:         "valid name",
+			healthCheck:  v1alpha1.NamespacedObjectKindReference{APIVersion: "v1", Kind: "Pod", Namespace: "ns", Name: "pod"},
+			expectedErrs: nil,
+		},
+		{
+			name: "valid selector and condition",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{APIVersion: "cert-manager.io/v1", Kind: "Certificate", Namespace: "ns", Selector: "app=web",
+				Condition: &v1alpha1.HealthCheckCondition{Type: "Ready"}, TimeoutSeconds: 60},
+			expectedErrs: nil,
+		},
+		{
+			name:         "valid jsonPath",
+			healthCheck:  v1alpha1.NamespacedObjectKindReference{APIVersion: "v1", Kind: "Pod", Namespace: "ns", Name: "pod", JSONPath: ".status.phase", Value: "Running"},
+			expectedErrs: nil,
+		},
+		{
+			name:         "name and selector",
+			healthCheck:  v1alpha1.NamespacedObjectKindReference{APIVersion: "v1", Kind: "Pod", Namespace: "ns", Name: "pod", Selector: "app=web"},
+			expectedErrs: []string{fmt.Sprintf(PkgValidateErrHealthCheckNameSelector, "Pod/pod")},
+		},
+		{
+			name: "invalid selector, condition and value",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{APIVersion: "v1", Kind: "Pod", Namespace: "ns", Selector: "app=(web",
+				Condition: &v1alpha1.HealthCheckCondition{}, Value: "Running", TimeoutSeconds: -1},
+			expectedErrs: []string{
+				fmt.Errorf(PkgValidateErrHealthCheckSelector, "Pod", errors.New("unable to parse requirement: found '(', expected: identifier")).Error(),
+				fmt.Sprintf(PkgValidateErrHealthCheckCondition, "Pod"),
+				fmt.Sprintf(PkgValidateErrHealthCheckValue, "Pod"),
+				fmt.Sprintf(PkgValidateErrHealthCheckTimeout, "Pod"),
+			},
+		},
+		{
+			name: "condition and invalid jsonPath",
+			healthCheck: v1alpha1.NamespacedObjectKindReference{APIVersion: "v1", Kind: "Pod", Namespace: "ns", Name: "pod",
+				Condition: &v1alpha1.HealthCheckCondition{Type: "Ready"}, JSONPath: "{.status[}"},
+			expectedErrs: []string{
+				fmt.Sprintf(PkgValidateErrHealthCheckCondJSONPath, "Pod/pod"),
+				fmt.Errorf(PkgValidateErrHealthCheckJSONPath, "Pod/pod", errors.New("unterminated array")).Error(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateHealthCheck(tt.healthCheck)
+			if tt.expectedErrs == nil {
+				require.NoError(t, err)
+				return
+			}
+			errs := strings.Split(err.Error(), "\n")
+			require.ElementsMatch(t, errs, tt.expectedErrs)
+		})
+	}
+}
+
+func TestValidateImageExtractor(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		extractor    v1alpha1.ImageExtractor
+		expectedErrs []string
+		name         string
+	}{
+		{
+			name:         "valid paths",
+			extractor:    v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "App", Paths: []string{".spec.image"}},
+			expectedErrs: nil,
+		},
+		{
+			name: "valid reference",
+			extractor: v1alpha1.ImageExtractor{APIVersion: "v1", Kind: "ConfigMap", Name: "operator-config",
+				References: []v1alpha1.ImageExtractorReference{{Repository: "{.data.repository}", Tag: "{.data.tag}"}}},
+			expectedErrs: nil,
+		},
+		{
+			name:         "missing kind",
+			extractor:    v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Paths: []string{".spec.image"}},
+			expectedErrs: []string{PkgValidateErrImageExtractorKind},
+		},
+		{
+			name: "no paths, missing repository and invalid jsonPath",
+			extractor: v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "App",
+				References: []v1alpha1.ImageExtractorReference{{Tag: "{.spec[}"}}},
+			expectedErrs: []string{
+				fmt.Sprintf(PkgValidateErrImageExtractorRepo, "example.com/v1 App"),
+				fmt.Errorf(PkgValidateErrImageExtractorJSONPath, "example.com/v1 App", errors.New("unterminated array")).Error(),
+			},
+		},
+		{
+			name:         "no paths or references",
+			extractor:    v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "App"},
+			expectedErrs: []string{fmt.Sprintf(PkgValidateErrImageExtractorPaths, "example.com/v1 App")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateImageExtractor(tt.extractor)
+			if tt.expectedErrs == nil {
+				require.NoError(t, err)
+				return
+			}
+			errs := strings.Split(err.Error(), "\n")
+			require.ElementsMatch(t, errs, tt.expectedErrs)
+		})
+	}
+}
+
+func TestValidateTransforms(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		transforms   v1alpha1.ZarfTransforms
+		expectedErrs []string
+		name         string
+	}{
+		{
+			name: "valid",
+			transforms: v1alpha1.ZarfTransforms{
+				Images: []v1alpha1.ZarfImageTransform{{Match: `^docker\.io/(.*)$`, Path: "platform/$1", TagSuffix: "-airgap.1"}},
+				Repos:  []v1alpha1.ZarfRepoTransform{{Match: `^github\.com/`, NoChecksum: true}},
+			},
+			expectedErrs: nil,
+		},
+		{
+			name: "missing match, invalid regex and tag suffix",
+			transforms: v1alpha1.ZarfTransforms{
+				Images: []v1alpha1.ZarfImageTransform{{Match: "(", TagSuffix: "/airgap"}},
+				Repos:  []v1alpha1.ZarfRepoTransform{{Name: "repo"}},
+			},
+			expectedErrs: []string{
+				fmt.Sprintf(PkgValidateErrTransformTagSuffix, "/airgap"),
+				fmt.Errorf(PkgValidateErrTransformRegex, "(", errors.New("error parsing regexp: missing closing ): `(`")).Error(),
+				PkgValidateErrTransformMatch,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateTransforms(tt.transforms)
+			if tt.expectedErrs == nil {
+				require.NoError(t, err)
+				return
+			}
+			errs := strings.Split(err.Error(), "\n")
+			require.ElementsMatch(t, errs, tt.expectedErrs)
+		})
+	}
+}
+
 func TestValidateReleaseName(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -311,6 +492,22 @@ func TestValidateChart(t *testing.T) {
 			chart:        v1alpha1.ZarfChart{Namespace: "namespace", URL: "http://whatever", Version: "v1.0.0"},
 			expectedErrs: []string{errChartReleaseNameEmpty},
 		},
+		{
+			name: "valid install options",
+			chart: v1alpha1.ZarfChart{Name: "chart4", Namespace: "namespace", URL: "http://whatever", Version: "v1.0.0",
+				Atomic: true, DisableHooks: true, SkipCRDs: true, MaxHistory: 3, Force: true,
+				PostRenderers: []v1alpha1.ZarfChartPostRenderer{{Command: "kustomize", Args: []string{"build"}}}},
+			expectedErrs: nil,
+		},
+		{
+			name: "invalid install options",
+			chart: v1alpha1.ZarfChart{Name: "invalid", Namespace: "namespace", URL: "http://whatever", Version: "v1.0.0",
+				MaxHistory: -1, PostRenderers: []v1alpha1.ZarfChartPostRenderer{{Args: []string{"build"}}}},
+			expectedErrs: []string{
+				fmt.Sprintf(PkgValidateErrChartMaxHistory, "invalid"),
+				fmt.Sprintf(PkgValidateErrChartPostRenderer, "invalid"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -7,7 +7,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/defenseunicorns/pkg/oci"
@@ -19,6 +22,9 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/zoci"
 )
 
+// sourceDateEpochEnv is the environment variable used to set the build timestamp of a package.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
 // CreateOptions are the optional parameters to create
 type CreateOptions struct {
 	Flavor                  string
@@ -33,6 +39,10 @@ type CreateOptions struct {
 	OCIConcurrency          int
 	CachePath               string
 	WithBuildMachineInfo    bool
+	// Reproducible creates a package that is identical when rebuilt from the same sources
+	Reproducible bool
+	// SourceDateEpoch overrides the build timestamp, defaults to the SOURCE_DATE_EPOCH environment variable
+	SourceDateEpoch *time.Time
 	// applicable when output is an OCI registry
 	RemoteOptions
 	// IsInteractive decides if Zarf can interactively prompt users through the CLI
@@ -46,6 +56,12 @@ func Create(ctx context.Context, packagePath string, output string, opts CreateO
 	if opts.SkipSBOM && opts.SBOMOut != "" {
 		return "", fmt.Errorf("cannot skip SBOM creation and specify an SBOM output directory")
 	}
+	if opts.SourceDateEpoch == nil {
+		opts.SourceDateEpoch, err = sourceDateEpoch()
+		if err != nil {
+			return "", err
+		}
+	}
 
 	loadOpts := load.DefinitionOptions{
 		Flavor:           opts.Flavor,
@@ -88,6 +104,8 @@ func Create(ctx context.Context, packagePath string, output string, opts CreateO
 		SigningKeyPassword:   opts.SigningKeyPassword,
 		CachePath:            opts.CachePath,
 		WithBuildMachineInfo: opts.WithBuildMachineInfo,
+		Reproducible:         opts.Reproducible,
+		SourceDateEpoch:      opts.SourceDateEpoch,
 	}
 	pkgLayout, err := layout.AssemblePackage(ctx, pkg, packagePath, assembleOpt)
 	if err != nil {
@@ -139,3 +157,18 @@ func Create(ctx context.Context, packagePath string, output string, opts CreateO
 	}
 	return packageLocation, nil
 }
+
+// sourceDateEpoch returns the build timestamp set through the SOURCE_DATE_EPOCH environment variable.
+// https://reproducible-builds.org/specs/source-date-epoch/
+func sourceDateEpoch() (*time.Time, error) {
+	v, ok := os.LookupEnv(sourceDateEpochEnv)
+	if !ok || v == "" {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", sourceDateEpochEnv, v, err)
+	}
+	t := time.Unix(seconds, 0)
+	return &t, nil
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -21,8 +22,10 @@ import (
 	"github.com/zarf-dev/zarf/src/config/lang"
 	"github.com/zarf-dev/zarf/src/internal/feature"
 	"github.com/zarf-dev/zarf/src/internal/healthchecks"
+	"github.com/zarf-dev/zarf/src/internal/objectstore"
 	"github.com/zarf-dev/zarf/src/internal/packager/helm"
 	"github.com/zarf-dev/zarf/src/internal/packager/images"
+	"github.com/zarf-dev/zarf/src/internal/packager/kustomize"
 	"github.com/zarf-dev/zarf/src/internal/packager/requirements"
 	ptmpl "github.com/zarf-dev/zarf/src/internal/packager/template"
 	"github.com/zarf-dev/zarf/src/internal/template"
@@ -66,11 +69,25 @@ type DeployOptions struct {
 	GitServer      state.GitServerInfo
 	RegistryInfo   state.RegistryInfo
 	ArtifactServer state.ArtifactServerInfo
-	StorageClass   string
-	InjectorPort   int
+	AgentTLS       state.AgentTLSConfig
+	// Agent image policy and audit hooks, nil keeps the existing configuration
+	AgentImagePolicy *state.ImagePolicy
+	AgentAudit       *state.AgentAudit
+	// Backend that stores the sensitive fields of the state and the Vault token if it is used
+	CredentialStore state.CredentialStore
+	VaultToken      string
+	// Named registries and git servers that images and repositories are routed to
+	Routing      *state.RoutingConfig
+	StorageClass string
+	InjectorPort int
+	// Skip checking that the S3 storage of the registry is reachable from this machine before the registry is deployed
+	SkipRegistryS3Check bool
 
 	// [Library Only] A map of component names to chart names containing Helm Chart values to override values on deploy
 	ValuesOverridesMap ValuesOverrides
+	// Overlays are kustomize components applied to the rendered manifests of charts, see LoadOverlays.
+	// When nil the overlays recorded by the previous deployment of the package are applied again.
+	Overlays []state.DeployedOverlay
 	// IsInteractive decides if Zarf can interactively prompt users through the CLI
 	IsInteractive bool
 	// SkipVersionCheck skips version requirement validation
@@ -84,6 +101,7 @@ type deployer struct {
 	c           *cluster.Cluster
 	vc          *variables.VariableConfig
 	vals        value.Values
+	overlays    []state.DeployedOverlay
 	hpaModified bool
 }
 
@@ -119,6 +137,10 @@ func Deploy(ctx context.Context, pkgLayout *layout.PackageLayout, opts DeployOpt
 		}
 	}
 
+	if err := validateOverlays(pkgLayout.Pkg, opts.Overlays); err != nil {
+		return DeployResult{}, fmt.Errorf("invalid overlays: %w", err)
+	}
+
 	if opts.Retries == 0 {
 		opts.Retries = config.ZarfDefaultRetries
 	}
@@ -157,8 +179,9 @@ func Deploy(ctx context.Context, pkgLayout *layout.PackageLayout, opts DeployOpt
 	l.Debug("package values", "values", vals)
 
 	d := deployer{
-		vc:   variableConfig,
-		vals: vals,
+		vc:       variableConfig,
+		vals:     vals,
+		overlays: opts.Overlays,
 	}
 
 	// During deploy we disable
@@ -228,6 +251,14 @@ func (d *deployer) deployComponents(ctx context.Context, pkgLayout *layout.Packa
 			//nolint: errcheck // this may be the first time deploying the package therefore it will not exist
 			if existingDeployedPackage, _ := d.c.GetDeployedPackage(ctx, pkgLayout.Pkg.Metadata.Name, state.WithPackageNamespaceOverride(opts.NamespaceOverride)); existingDeployedPackage != nil {
 				packageGeneration = existingDeployedPackage.Generation + 1
+				// Upgrades apply the overlays of the previous deployment unless new overlays are given.
+				if opts.Overlays == nil && d.overlays == nil && len(existingDeployedPackage.Overlays) > 0 {
+					if err := validateOverlays(pkgLayout.Pkg, existingDeployedPackage.Overlays); err != nil {
+						return nil, fmt.Errorf("invalid overlays of the previous deployment: %w", err)
+					}
+					l.Info("applying the overlays of the previous deployment", "package", pkgLayout.Pkg.Metadata.Name, "count", len(existingDeployedPackage.Overlays))
+					d.overlays = existingDeployedPackage.Overlays
+				}
 			}
 		}
 
@@ -249,7 +280,7 @@ func (d *deployer) deployComponents(ctx context.Context, pkgLayout *layout.Packa
 		deployedComponents = append(deployedComponents, deployedComponent)
 		idx := len(deployedComponents) - 1
 		if d.isConnectedToCluster() {
-			if _, err := d.c.RecordPackageDeployment(ctx, pkgLayout.Pkg, deployedComponents, packageGeneration, state.WithPackageNamespaceOverride(opts.NamespaceOverride)); err != nil {
+			if _, err := d.c.RecordPackageDeployment(ctx, pkgLayout.Pkg, deployedComponents, packageGeneration, state.WithPackageNamespaceOverride(opts.NamespaceOverride), state.WithPackageOverlays(d.overlays)); err != nil {
 				l.Debug("unable to record package deployment", "component", component.Name, "error", err.Error())
 			}
 		}
@@ -276,7 +307,7 @@ func (d *deployer) deployComponents(ctx context.Context, pkgLayout *layout.Packa
 				deployedComponents[idx].Status = state.ComponentStatusFailed
 				deployedComponents[idx].InstalledCharts = state.MergeInstalledChartsForComponent(deployedComponents[idx].InstalledCharts, charts, true)
 				if d.isConnectedToCluster() {
-					if _, err := d.c.RecordPackageDeployment(ctx, pkgLayout.Pkg, deployedComponents, packageGeneration, state.WithPackageNamespaceOverride(opts.NamespaceOverride)); err != nil {
+					if _, err := d.c.RecordPackageDeployment(ctx, pkgLayout.Pkg, deployedComponents, packageGeneration, state.WithPackageNamespaceOverride(opts.NamespaceOverride), state.WithPackageOverlays(d.overlays)); err != nil {
 						l.Debug("unable to record package deployment", "component", component.Name, "error", err.Error())
 					}
 				}
@@ -296,7 +327,7 @@ func (d *deployer) deployComponents(ctx context.Context, pkgLayout *layout.Packa
 		deployedComponents[idx].InstalledCharts = state.MergeInstalledChartsForComponent(deployedComponents[idx].InstalledCharts, charts, false)
 		deployedComponents[idx].Status = state.ComponentStatusSucceeded
 		if d.isConnectedToCluster() {
-			if _, err := d.c.RecordPackageDeployment(ctx, pkgLayout.Pkg, deployedComponents, packageGeneration, state.WithPackageNamespaceOverride(opts.NamespaceOverride)); err != nil {
+			if _, err := d.c.RecordPackageDeployment(ctx, pkgLayout.Pkg, deployedComponents, packageGeneration, state.WithPackageNamespaceOverride(opts.NamespaceOverride), state.WithPackageOverlays(d.overlays)); err != nil {
 				l.Debug("unable to record package deployment", "component", component.Name, "error", err.Error())
 			}
 		}
@@ -327,12 +358,18 @@ func (d *deployer) deployInitComponent(ctx context.Context, pkgLayout *layout.Pa
 		}
 		var err error
 		d.s, err = d.c.InitState(ctx, cluster.InitStateOptions{
-			GitServer:      opts.GitServer,
-			RegistryInfo:   opts.RegistryInfo,
-			ArtifactServer: opts.ArtifactServer,
-			ApplianceMode:  applianceMode,
-			StorageClass:   opts.StorageClass,
-			InjectorPort:   opts.InjectorPort,
+			GitServer:        opts.GitServer,
+			RegistryInfo:     opts.RegistryInfo,
+			ArtifactServer:   opts.ArtifactServer,
+			AgentTLS:         opts.AgentTLS,
+			AgentImagePolicy: opts.AgentImagePolicy,
+			AgentAudit:       opts.AgentAudit,
+			CredentialStore:  opts.CredentialStore,
+			VaultToken:       opts.VaultToken,
+			Routing:          opts.Routing,
+			ApplianceMode:    applianceMode,
+			StorageClass:     opts.StorageClass,
+			InjectorPort:     opts.InjectorPort,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("unable to initialize Zarf state: %w", err)
@@ -346,6 +383,25 @@ func (d *deployer) deployInitComponent(ctx context.Context, pkgLayout *layout.Pa
 		}
 	}
 
+	if (isSeedRegistry || isRegistry) && d.s.RegistryInfo.S3 != nil {
+		storage := *d.s.RegistryInfo.S3
+		// Verify the registry can store images in its object storage before it is deployed.
+		// The check runs from this machine, which can not reach endpoints that are only served inside the cluster.
+		switch {
+		case opts.SkipRegistryS3Check:
+			l.Info("skipping the registry S3 storage check")
+		case objectstore.IsInCluster(storage):
+			l.Info("skipping the registry S3 storage check as the endpoint is only reachable from the cluster", "endpoint", storage.Endpoint)
+		default:
+			if err := objectstore.CheckS3(ctx, storage); err != nil {
+				return nil, fmt.Errorf("the registry S3 storage is not available, use --registry-s3-skip-check if it is only reachable from the cluster: %w", err)
+			}
+		}
+		if err := d.c.ApplyRegistryS3Secret(ctx, storage); err != nil {
+			return nil, err
+		}
+	}
+
 	if isRegistry {
 		// If we are deploying the registry then mark the HPA as "modified" to set it to Min later
 		d.hpaModified = true
@@ -366,7 +422,7 @@ func (d *deployer) deployInitComponent(ctx context.Context, pkgLayout *layout.Pa
 			}
 			d.s.InjectorInfo.PayLoadConfigMapAmount = len(payloadCMs)
 			d.s.InjectorInfo.PayLoadShaSum = shasum
-		case state.RegistryModeNodePort:
+		case state.RegistryModeNodePort, state.RegistryModeMirror:
 			seedPort, err := d.c.StartInjection(ctx, pkgLayout.DirPath(), pkgLayout.GetImageDirPath(), component.Images, d.s.InjectorInfo.Port, d.s.RegistryInfo.NodePort, pkgLayout.Pkg.Metadata.Name)
 			if err != nil {
 				return nil, err
@@ -374,7 +430,7 @@ func (d *deployer) deployInitComponent(ctx context.Context, pkgLayout *layout.Pa
 			d.s.InjectorInfo.Port = seedPort
 		}
 		// Save the injector updates to state
-		if err := d.c.SaveState(ctx, d.s); err != nil {
+		if err := d.c.SaveState(ctx, d.s, state.AuditOperationInit); err != nil {
 			return nil, err
 		}
 	}
@@ -382,12 +438,15 @@ func (d *deployer) deployInitComponent(ctx context.Context, pkgLayout *layout.Pa
 	// Skip image checksum if component is agent.
 	// Skip image push if component is seed registry.
 	charts, err := d.deployComponent(ctx, pkgLayout, component, isAgent, isSeedRegistry, opts)
+	if err != nil && isSeedRegistry && d.s.RegistryInfo.RegistryMode == state.RegistryModeProxy {
+		charts, err = d.fallbackToNodePort(ctx, pkgLayout, component, err, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// Do cleanup for when we inject the seed registry during initialization
-	if isSeedRegistry && d.s.RegistryInfo.RegistryMode == state.RegistryModeNodePort {
+	if isSeedRegistry && (d.s.RegistryInfo.RegistryMode == state.RegistryModeNodePort || d.s.RegistryInfo.RegistryMode == state.RegistryModeMirror) {
 		if err := d.c.StopInjection(ctx); err != nil {
 			return nil, fmt.Errorf("failed to delete injector resources: %w", err)
 		}
@@ -396,6 +455,39 @@ func (d *deployer) deployInitComponent(ctx context.Context, pkgLayout *layout.Pa
 	return charts, nil
 }
 
+// fallbackToNodePort redeploys the seed registry in nodeport mode when the registry proxy is unavailable on the nodes.
+func (d *deployer) fallbackToNodePort(ctx context.Context, pkgLayout *layout.PackageLayout, component v1alpha1.ZarfComponent, deployErr error, opts DeployOptions) ([]state.InstalledChart, error) {
+	l := logger.From(ctx)
+	healthy, err := d.c.RegistryProxyHealthy(ctx)
+	if err != nil {
+		return nil, errors.Join(deployErr, err)
+	}
+	if healthy {
+		return nil, deployErr
+	}
+	l.Warn("the registry proxy is unavailable, falling back to the nodeport registry mode", "error", deployErr.Error())
+
+	for _, chart := range component.Charts {
+		if err := helm.RemoveChart(ctx, chart.Namespace, chart.ReleaseName, opts.Timeout); err != nil {
+			l.Debug("unable to remove the failed seed registry chart", "chart", chart.ReleaseName, "error", err.Error())
+		}
+	}
+	if err := d.s.RegistryInfo.SwitchMode(state.RegistryModeNodePort, d.s.IPFamily); err != nil {
+		return nil, err
+	}
+	d.s.RegistryProxyTLS = nil
+	d.s.InjectorInfo = state.InjectorInfo{}
+	seedPort, err := d.c.StartInjection(ctx, pkgLayout.DirPath(), pkgLayout.GetImageDirPath(), component.Images, 0, d.s.RegistryInfo.NodePort, pkgLayout.Pkg.Metadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	d.s.InjectorInfo.Port = seedPort
+	if err := d.c.SaveState(ctx, d.s, state.AuditOperationInit); err != nil {
+		return nil, err
+	}
+	return d.deployComponent(ctx, pkgLayout, component, false, true, opts)
+}
+
 func (d *deployer) deployComponent(ctx context.Context, pkgLayout *layout.PackageLayout, component v1alpha1.ZarfComponent, noImgChecksum bool, noImgPush bool, opts DeployOptions) (_ []state.InstalledChart, err error) {
 	l := logger.From(ctx)
 	start := time.Now()
@@ -452,34 +544,42 @@ func (d *deployer) deployComponent(ctx context.Context, pkgLayout *layout.Packag
 	}
 
 	if hasImages {
-		refs := []transform.Image{}
-		for _, img := range component.Images {
-			ref, err := transform.ParseImageRef(img)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create ref for image %s: %w", img, err)
-			}
-			refs = append(refs, ref)
-		}
-		pushConfig := images.PushConfig{
-			OCIConcurrency:        opts.OCIConcurrency,
-			SourceDirectory:       pkgLayout.GetImageDirPath(),
-			RegistryInfo:          d.s.RegistryInfo,
-			ImageList:             refs,
-			PlainHTTP:             opts.PlainHTTP,
-			NoChecksum:            noImgChecksum,
-			Arch:                  pkgLayout.Pkg.Build.Architecture,
-			Retries:               opts.Retries,
-			InsecureSkipTLSVerify: opts.InsecureSkipTLSVerify,
-			Cluster:               d.c,
-		}
-		err := images.Push(ctx, pushConfig)
+		pushes, err := routeImages([]v1alpha1.ZarfComponent{component}, d.s.RegistryInfo, d.s.Registries)
 		if err != nil {
-			return nil, fmt.Errorf("unable to push images to the registry: %w", err)
+			return nil, err
+		}
+		rules, err := transform.NewImageRules(pkgLayout.Pkg.Transforms.Images)
+		if err != nil {
+			return nil, err
+		}
+		for _, push := range pushes {
+			pushConfig := images.PushConfig{
+				OCIConcurrency:        opts.OCIConcurrency,
+				SourceDirectory:       pkgLayout.GetImageDirPath(),
+				RegistryInfo:          push.registry.RegistryInfo,
+				ImageList:             push.images,
+				PlainHTTP:             opts.PlainHTTP,
+				NoChecksum:            noImgChecksum,
+				Arch:                  pkgLayout.Pkg.Build.Architecture,
+				Retries:               opts.Retries,
+				InsecureSkipTLSVerify: opts.InsecureSkipTLSVerify,
+				Cluster:               d.c,
+				Transforms:            rules,
+			}
+			if err := images.Push(ctx, pushConfig); err != nil {
+				return nil, fmt.Errorf("unable to push images to the registry %s: %w", push.registry.Address, err)
+			}
+			// Named registries are always external, only the default registry can be mirrored to the nodes
+			if push.registry.Name == "" {
+				if err := d.c.UpdateRegistryMirrors(ctx, d.s, push.images); err != nil {
+					return nil, err
+				}
+			}
 		}
 	}
 
 	if hasRepos {
-		if err := pushComponentReposToRegistry(ctx, component, pkgLayout, d.s.GitServer, d.c, opts.Retries); err != nil {
+		if err := pushComponentReposToRegistry(ctx, component, pkgLayout, d.s.GitServer, d.s.GitServers, d.c, opts.Retries); err != nil {
 			return nil, fmt.Errorf("unable to push the repos to the repository: %w", err)
 		}
 	}
@@ -595,6 +695,7 @@ func (d *deployer) installCharts(ctx context.Context, pkgLayout *layout.PackageL
 			PkgName:                pkgLayout.Pkg.Metadata.Name,
 			NamespaceOverride:      opts.NamespaceOverride,
 			IsInteractive:          opts.IsInteractive,
+			Overlay:                overlayFor(d.overlays, component.Name, chart.Name),
 		}
 		helmChart, values, err := helm.LoadChartData(chart, chartDir, valuesDir, valuesOverrides)
 		if err != nil {
@@ -618,6 +719,41 @@ func (d *deployer) installCharts(ctx context.Context, pkgLayout *layout.PackageL
 	return installedCharts, nil
 }
 
+func (d *deployer) templateObjects(pkgLayout *layout.PackageLayout) template.Objects {
+	return template.NewObjects(d.vals).
+		WithPackage(pkgLayout.Pkg).
+		WithBuild(pkgLayout.Pkg.Build).
+		WithVariables(d.vc.GetSetVariableMap()).
+		WithConstants(d.vc.GetConstants())
+}
+
+// buildTemplatedKustomization templates the files of a vendored kustomization and builds it to destination.
+func (d *deployer) buildTemplatedKustomization(ctx context.Context, pkgLayout *layout.PackageLayout, manifest v1alpha1.ZarfManifest, vendorDir string, destination string) error {
+	l := logger.From(ctx)
+	l.Debug("start kustomization template", "manifest", manifest.Name, "path", vendorDir)
+	objs := d.templateObjects(pkgLayout)
+	err := filepath.WalkDir(vendorDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		return template.ApplyToFile(ctx, path, path, objs)
+	})
+	if err != nil {
+		return err
+	}
+	opts := kustomize.Options{
+		AllowAnyDirectory: manifest.KustomizeAllowAnyDirectory,
+		EnablePlugins:     manifest.EnableKustomizePlugins,
+	}
+	if err := kustomize.Build(vendorDir, destination, opts); err != nil {
+		return fmt.Errorf("unable to build kustomization for manifest %s: %w", manifest.Name, err)
+	}
+	return nil
+}
+
 func (d *deployer) installManifests(ctx context.Context, pkgLayout *layout.PackageLayout, component v1alpha1.ZarfComponent, opts DeployOptions) (_ []state.InstalledChart, err error) {
 	l := logger.From(ctx)
 	tmpDir, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
@@ -646,12 +782,7 @@ func (d *deployer) installManifests(ctx context.Context, pkgLayout *layout.Packa
 				path := filepath.Join(manifestDir, manifest.Files[idx])
 				l.Debug("start manifest template", "manifest", manifest.Name, "path", path)
 
-				objs := template.NewObjects(d.vals).
-					WithPackage(pkgLayout.Pkg).
-					WithBuild(pkgLayout.Pkg.Build).
-					WithVariables(d.vc.GetSetVariableMap()).
-					WithConstants(d.vc.GetConstants())
-				err := template.ApplyToFile(ctx, path, path, objs)
+				err := template.ApplyToFile(ctx, path, path, d.templateObjects(pkgLayout))
 				if err != nil {
 					return nil, err
 				}
@@ -660,6 +791,13 @@ func (d *deployer) installManifests(ctx context.Context, pkgLayout *layout.Packa
 		// Move kustomizations to files now
 		for idx := range manifest.Kustomizations {
 			kustomization := fmt.Sprintf("kustomization-%s-%d.yaml", manifest.Name, idx)
+			vendorDir := filepath.Join(manifestDir, fmt.Sprintf("kustomize-%s-%d", manifest.Name, idx))
+			// Templated kustomizations are built again from the sources vendored into the package.
+			if manifest.IsTemplate() && !helpers.InvalidPath(vendorDir) {
+				if err := d.buildTemplatedKustomization(ctx, pkgLayout, manifest, vendorDir, filepath.Join(manifestDir, kustomization)); err != nil {
+					return installedCharts, err
+				}
+			}
 			manifest.Files = append(manifest.Files, kustomization)
 		}
 
@@ -683,6 +821,7 @@ func (d *deployer) installManifests(ctx context.Context, pkgLayout *layout.Packa
 			PkgName:                pkgLayout.Pkg.Metadata.Name,
 			NamespaceOverride:      opts.NamespaceOverride,
 			IsInteractive:          opts.IsInteractive,
+			Overlay:                overlayFor(d.overlays, component.Name, manifest.Name),
 		}
 
 		// Install the chart.
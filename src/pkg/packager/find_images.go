@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -34,6 +35,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var (
@@ -63,6 +65,10 @@ type FindImagesOptions struct {
 	CachePath string
 	// IsInteractive decides if Zarf can interactively prompt users through the CLI
 	IsInteractive bool
+	// ImageExtractors adds JSONPath image extractors for custom resources to the built-in and package image extractors
+	ImageExtractors []v1alpha1.ImageExtractor
+	// CustomImageExtractors adds image extractors keyed by the GVK of the resources they extract images from
+	CustomImageExtractors map[schema.GroupVersionKind][]ImageExtractor
 }
 
 // ComponentImageScan contains the results of FindImages for a component
@@ -95,6 +101,14 @@ func FindImages(ctx context.Context, packagePath string, opts FindImagesOptions)
 		return nil, err
 	}
 
+	extractors, err := newImageExtractors(slices.Concat(defaultImageExtractors, pkg.ImageExtractors, opts.ImageExtractors))
+	if err != nil {
+		return nil, err
+	}
+	for gvk, custom := range opts.CustomImageExtractors {
+		extractors[gvk] = append(extractors[gvk], custom...)
+	}
+
 	s, err := state.Default()
 	if err != nil {
 		return nil, err
@@ -199,7 +213,7 @@ func FindImages(ctx context.Context, packagePath string, opts FindImagesOptions)
 			}
 		}
 		for _, manifest := range component.Manifests {
-			manifestResources, err := getTemplatedManifests(ctx, manifest, packagePath, compBuildPath, variableConfig)
+			manifestResources, err := getTemplatedManifests(ctx, manifest, packagePath, compBuildPath, opts.CachePath, variableConfig)
 			if err != nil {
 				return nil, err
 			}
@@ -229,7 +243,7 @@ func FindImages(ctx context.Context, packagePath string, opts FindImagesOptions)
 		l.Info("looking for images in component", "name", component.Name, "resourcesCount", len(resources))
 
 		for _, resource := range resources {
-			if matchedImages, maybeImages, err = processUnstructuredImages(ctx, resource, matchedImages, maybeImages); err != nil {
+			if matchedImages, maybeImages, err = processUnstructuredImages(ctx, resource, extractors, matchedImages, maybeImages); err != nil {
 				return nil, fmt.Errorf("could not process the Kubernetes resource %s: %w", resource.GetName(), err)
 			}
 		}
@@ -304,7 +318,7 @@ func FindImages(ctx context.Context, packagePath string, opts FindImagesOptions)
 }
 
 // processUnstructuredImages processes a Kubernetes resource and extracts container images
-func processUnstructuredImages(ctx context.Context, resource *unstructured.Unstructured, matchedImages, maybeImages map[string]bool) (map[string]bool, map[string]bool, error) {
+func processUnstructuredImages(ctx context.Context, resource *unstructured.Unstructured, extractors map[schema.GroupVersionKind][]ImageExtractor, matchedImages, maybeImages map[string]bool) (map[string]bool, map[string]bool, error) {
 	l := logger.From(ctx)
 	contents := resource.UnstructuredContent()
 	b, err := resource.MarshalJSON()
@@ -312,6 +326,18 @@ func processUnstructuredImages(ctx context.Context, resource *unstructured.Unstr
 		return nil, nil, err
 	}
 
+	gvkExtractors := extractors[resource.GroupVersionKind()]
+	for _, extractor := range gvkExtractors {
+		extracted, err := extractor(ctx, resource)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, image := range extracted {
+			l.Debug("found extracted image", "kind", resource.GetKind(), "value", image)
+			matchedImages[image] = true
+		}
+	}
+
 	switch resource.GetKind() {
 	case "Pod":
 		var pod corev1.Pod
@@ -376,6 +402,10 @@ func processUnstructuredImages(ctx context.Context, resource *unstructured.Unstr
 		matchedImages = appendToImageMapOCIRepo(ctx, matchedImages, ociRepo)
 
 	default:
+		// Resources with an image extractor do not need the regex fallback
+		if len(gvkExtractors) > 0 {
+			break
+		}
 		// Capture any custom images
 		matches := imageCheck.FindAllStringSubmatch(string(b), -1)
 		for _, group := range matches {
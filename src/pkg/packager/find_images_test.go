@@ -155,6 +155,24 @@ func TestFindImages(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "custom resources",
+			packagePath: "./testdata/find-images/custom-resources",
+			opts: FindImagesOptions{
+				SkipCosign: true,
+			},
+			expectedImages: []ComponentImageScan{
+				{
+					ComponentName: "baseline",
+					Matches: []string{
+						"busybox:1.36",
+						"docker.io/library/postgres:16.2",
+						"ghcr.io/stefanprodan/podinfo:6.4.0",
+						"quay.io/prometheus/prometheus:v2.45.0",
+					},
+				},
+			},
+		},
 		{
 			name:        "fuzzy",
 			packagePath: "./testdata/find-images/fuzzy",
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ImageExtractor returns the container images referenced by a Kubernetes resource.
+type ImageExtractor func(ctx context.Context, resource *unstructured.Unstructured) ([]string, error)
+
+// defaultImageExtractors are the built-in extractors for custom resources of common operators.
+var defaultImageExtractors = []v1alpha1.ImageExtractor{
+	// Argo Rollouts
+	{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Paths: podSpecPaths(".spec.template.spec")},
+	// Argo Workflows
+	{APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow", Paths: workflowPaths(".spec")},
+	{APIVersion: "argoproj.io/v1alpha1", Kind: "WorkflowTemplate", Paths: workflowPaths(".spec")},
+	{APIVersion: "argoproj.io/v1alpha1", Kind: "ClusterWorkflowTemplate", Paths: workflowPaths(".spec")},
+	{APIVersion: "argoproj.io/v1alpha1", Kind: "CronWorkflow", Paths: workflowPaths(".spec.workflowSpec")},
+	// Knative Serving
+	{APIVersion: "serving.knative.dev/v1", Kind: "Service", Paths: podSpecPaths(".spec.template.spec")},
+	{APIVersion: "serving.knative.dev/v1", Kind: "Configuration", Paths: podSpecPaths(".spec.template.spec")},
+	{APIVersion: "serving.knative.dev/v1", Kind: "Revision", Paths: podSpecPaths(".spec")},
+	// KEDA
+	{APIVersion: "keda.sh/v1alpha1", Kind: "ScaledJob", Paths: podSpecPaths(".spec.jobTargetRef.template.spec")},
+	// Prometheus Operator
+	prometheusOperatorExtractor("Prometheus"),
+	prometheusOperatorExtractor("Alertmanager"),
+	prometheusOperatorExtractor("ThanosRuler"),
+	// OpenShift
+	{APIVersion: "apps.openshift.io/v1", Kind: "DeploymentConfig", Paths: podSpecPaths(".spec.template.spec")},
+}
+
+func podSpecPaths(prefix string) []string {
+	return []string{
+		fmt.Sprintf("{%s.initContainers[*].image}", prefix),
+		fmt.Sprintf("{%s.containers[*].image}", prefix),
+		fmt.Sprintf("{%s.ephemeralContainers[*].image}", prefix),
+	}
+}
+
+func workflowPaths(prefix string) []string {
+	return []string{
+		fmt.Sprintf("{%s.templates[*].container.image}", prefix),
+		fmt.Sprintf("{%s.templates[*].script.image}", prefix),
+		fmt.Sprintf("{%s.templates[*].containerSet.containers[*].image}", prefix),
+		fmt.Sprintf("{%s.templates[*].initContainers[*].image}", prefix),
+		fmt.Sprintf("{%s.templates[*].sidecars[*].image}", prefix),
+	}
+}
+
+func prometheusOperatorExtractor(kind string) v1alpha1.ImageExtractor {
+	return v1alpha1.ImageExtractor{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       kind,
+		Paths:      append([]string{"{.spec.image}"}, podSpecPaths(".spec")[:2]...),
+		References: []v1alpha1.ImageExtractorReference{
+			{Repository: "{.spec.baseImage}", Tag: "{.spec.version}"},
+		},
+	}
+}
+
+// DefaultImageExtractors returns the built-in image extractors keyed by the GVK of the resources they extract images from.
+func DefaultImageExtractors() (map[schema.GroupVersionKind][]ImageExtractor, error) {
+	return newImageExtractors(defaultImageExtractors)
+}
+
+// newImageExtractors creates image extractors from declarative image extractor definitions.
+func newImageExtractors(defs []v1alpha1.ImageExtractor) (map[schema.GroupVersionKind][]ImageExtractor, error) {
+	extractors := map[schema.GroupVersionKind][]ImageExtractor{}
+	for _, def := range defs {
+		extractor, err := NewJSONPathImageExtractor(def)
+		if err != nil {
+			return nil, err
+		}
+		gvk := schema.FromAPIVersionAndKind(def.APIVersion, def.Kind)
+		extractors[gvk] = append(extractors[gvk], extractor)
+	}
+	return extractors, nil
+}
+
+// NewJSONPathImageExtractor creates an image extractor that evaluates the JSONPath expressions of an image extractor definition.
+func NewJSONPathImageExtractor(def v1alpha1.ImageExtractor) (ImageExtractor, error) {
+	if def.APIVersion == "" || def.Kind == "" {
+		return nil, fmt.Errorf("image extractor must include an apiVersion and kind")
+	}
+	if len(def.Paths) == 0 && len(def.References) == 0 {
+		return nil, fmt.Errorf("image extractor for %s %s must include at least one path or reference", def.APIVersion, def.Kind)
+	}
+	paths := []*jsonpath.JSONPath{}
+	for _, path := range def.Paths {
+		jp, err := parseImageJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, jp)
+	}
+	type referencePaths struct {
+		repository *jsonpath.JSONPath
+		tag        *jsonpath.JSONPath
+		digest     *jsonpath.JSONPath
+	}
+	references := []referencePaths{}
+	for _, ref := range def.References {
+		if ref.Repository == "" {
+			return nil, fmt.Errorf("image extractor reference for %s %s must include a repository", def.APIVersion, def.Kind)
+		}
+		rp := referencePaths{}
+		var err error
+		if rp.repository, err = parseImageJSONPath(ref.Repository); err != nil {
+			return nil, err
+		}
+		if ref.Tag != "" {
+			if rp.tag, err = parseImageJSONPath(ref.Tag); err != nil {
+				return nil, err
+			}
+		}
+		if ref.Digest != "" {
+			if rp.digest, err = parseImageJSONPath(ref.Digest); err != nil {
+				return nil, err
+			}
+		}
+		references = append(references, rp)
+	}
+
+	return func(ctx context.Context, resource *unstructured.Unstructured) ([]string, error) {
+		if def.Name != "" && resource.GetName() != def.Name {
+			return nil, nil
+		}
+		images := []string{}
+		for _, jp := range paths {
+			values, err := findImageJSONPath(jp, resource)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, values...)
+		}
+		for _, rp := range references {
+			repositories, err := findImageJSONPath(rp.repository, resource)
+			if err != nil {
+				return nil, err
+			}
+			tags, err := findImageJSONPath(rp.tag, resource)
+			if err != nil {
+				return nil, err
+			}
+			digests, err := findImageJSONPath(rp.digest, resource)
+			if err != nil {
+				return nil, err
+			}
+			for i, repository := range repositories {
+				image := repository
+				if tag := nthOrOnly(tags, i); tag != "" {
+					image = fmt.Sprintf("%s:%s", image, tag)
+				}
+				if digest := nthOrOnly(digests, i); digest != "" {
+					image = fmt.Sprintf("%s@%s", image, digest)
+				}
+				images = append(images, image)
+			}
+		}
+
+		valid := []string{}
+		for _, image := range images {
+			if !reference.ReferenceRegexp.MatchString(image) {
+				logger.From(ctx).Debug("extracted value is not an image reference", "kind", resource.GetKind(), "name", resource.GetName(), "value", image)
+				continue
+			}
+			valid = append(valid, image)
+		}
+		return valid, nil
+	}, nil
+}
+
+func parseImageJSONPath(expression string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(expression, "{") {
+		expression = fmt.Sprintf("{%s}", expression)
+	}
+	jp := jsonpath.New("image-extractor").AllowMissingKeys(true)
+	if err := jp.Parse(expression); err != nil {
+		return nil, fmt.Errorf("invalid image extractor jsonPath %s: %w", expression, err)
+	}
+	return jp, nil
+}
+
+// findImageJSONPath returns every non-empty string the JSONPath expression evaluates to.
+func findImageJSONPath(jp *jsonpath.JSONPath, resource *unstructured.Unstructured) ([]string, error) {
+	if jp == nil {
+		return nil, nil
+	}
+	results, err := jp.FindResults(resource.Object)
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate image extractor jsonPath for %s %s: %w", resource.GetKind(), resource.GetName(), err)
+	}
+	values := []string{}
+	for _, result := range results {
+		for _, v := range result {
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
+			if v.Kind() != reflect.String || v.String() == "" {
+				continue
+			}
+			values = append(values, v.String())
+		}
+	}
+	return values, nil
+}
+
+// nthOrOnly returns the value matching the position of a repository, or the only value when one applies to every repository.
+func nthOrOnly(values []string, i int) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	if i < len(values) {
+		return values[i]
+	}
+	return ""
+}
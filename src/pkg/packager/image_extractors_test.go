@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+)
+
+func TestDefaultImageExtractors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		resource string
+		expected []string
+	}{
+		{
+			name: "argo workflow",
+			resource: `
+apiVersion: argoproj.io/v1alpha1
+kind: WorkflowTemplate
+metadata:
+  name: build
+spec:
+  templates:
+    - name: build
+      container:
+        image: golang:1.24
+    - name: notify
+      script:
+        image: alpine:3.20
+      sidecars:
+        - name: proxy
+          image: envoyproxy/envoy:v1.30.0
+`,
+			expected: []string{"golang:1.24", "alpine:3.20", "envoyproxy/envoy:v1.30.0"},
+		},
+		{
+			name: "knative service",
+			resource: `
+apiVersion: serving.knative.dev/v1
+kind: Service
+metadata:
+  name: hello
+spec:
+  template:
+    spec:
+      containers:
+        - image: ghcr.io/knative/helloworld-go:latest
+`,
+			expected: []string{"ghcr.io/knative/helloworld-go:latest"},
+		},
+		{
+			name: "prometheus with image",
+			resource: `
+apiVersion: monitoring.coreos.com/v1
+kind: Prometheus
+metadata:
+  name: prometheus
+spec:
+  image: quay.io/prometheus/prometheus:v2.45.0
+  containers:
+    - name: config-reloader
+      image: quay.io/prometheus-operator/prometheus-config-reloader:v0.68.0
+`,
+			expected: []string{"quay.io/prometheus/prometheus:v2.45.0", "quay.io/prometheus-operator/prometheus-config-reloader:v0.68.0"},
+		},
+		{
+			name: "rollout with a workload reference",
+			resource: `
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: podinfo
+spec:
+  workloadRef:
+    kind: Deployment
+    name: podinfo
+`,
+			expected: []string{},
+		},
+	}
+	extractors, err := DefaultImageExtractors()
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resource := &unstructured.Unstructured{}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.resource), &resource.Object))
+			gvkExtractors := extractors[resource.GroupVersionKind()]
+			require.NotEmpty(t, gvkExtractors)
+			images := []string{}
+			for _, extractor := range gvkExtractors {
+				extracted, err := extractor(testutil.TestContext(t), resource)
+				require.NoError(t, err)
+				images = append(images, extracted...)
+			}
+			require.ElementsMatch(t, tt.expected, images)
+		})
+	}
+}
+
+func TestJSONPathImageExtractor(t *testing.T) {
+	t.Parallel()
+
+	resource := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Database",
+		"metadata":   map[string]any{"name": "db"},
+		"spec": map[string]any{
+			"image": "not a reference",
+			"replicas": []any{
+				map[string]any{"repository": "docker.io/library/postgres", "digest": "sha256:4d3a9a8a8d6d4e2f6ab6dc8b6a0f8e4cb8e0d1b8c6b7a1f3c1e0a5b3f3d9e2c1"},
+				map[string]any{"repository": "docker.io/library/postgres", "digest": "sha256:0e3d8a1b1c2f9f6e1e5b7a2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f5a6"},
+			},
+			"backup": map[string]any{"repository": "docker.io/library/busybox", "tag": "1.36"},
+		},
+	}}
+
+	tests := []struct {
+		name        string
+		extractor   v1alpha1.ImageExtractor
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name: "paired references",
+			extractor: v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "Database",
+				References: []v1alpha1.ImageExtractorReference{{Repository: "{.spec.replicas[*].repository}", Digest: "{.spec.replicas[*].digest}"}}},
+			expected: []string{
+				"docker.io/library/postgres@sha256:4d3a9a8a8d6d4e2f6ab6dc8b6a0f8e4cb8e0d1b8c6b7a1f3c1e0a5b3f3d9e2c1",
+				"docker.io/library/postgres@sha256:0e3d8a1b1c2f9f6e1e5b7a2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f5a6",
+			},
+		},
+		{
+			name: "paths without braces skip values that are not references",
+			extractor: v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "Database", Paths: []string{".spec.image"},
+				References: []v1alpha1.ImageExtractorReference{{Repository: ".spec.backup.repository", Tag: ".spec.backup.tag"}}},
+			expected: []string{"docker.io/library/busybox:1.36"},
+		},
+		{
+			name:      "other resource name",
+			extractor: v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "Database", Name: "other", Paths: []string{".spec.backup.repository"}},
+			expected:  []string{},
+		},
+		{
+			name:        "invalid jsonPath",
+			extractor:   v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "Database", Paths: []string{"{.spec[}"}},
+			expectedErr: "invalid image extractor jsonPath {.spec[}",
+		},
+		{
+			name:        "no paths",
+			extractor:   v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "Database"},
+			expectedErr: "image extractor for example.com/v1 Database must include at least one path or reference",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			extractor, err := NewJSONPathImageExtractor(tt.extractor)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			images, err := extractor(testutil.TestContext(t), resource)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.expected, images)
+		})
+	}
+}
+
+func TestProcessUnstructuredImagesWithExtractor(t *testing.T) {
+	t.Parallel()
+
+	resource := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "App",
+		"metadata":   map[string]any{"name": "app"},
+		"spec": map[string]any{
+			"image":   "example/app:1.0.0",
+			"sidecar": map[string]any{"image": "example/sidecar:1.0.0"},
+		},
+	}}
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "App"}
+	extractor, err := NewJSONPathImageExtractor(v1alpha1.ImageExtractor{APIVersion: "example.com/v1", Kind: "App", Paths: []string{".spec.image"}})
+	require.NoError(t, err)
+
+	// Without an extractor the regex fallback matches every image field.
+	matched, _, err := processUnstructuredImages(testutil.TestContext(t), resource, nil, map[string]bool{}, map[string]bool{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"example/app:1.0.0": true, "example/sidecar:1.0.0": true}, matched)
+
+	// With an extractor only the images it returns are matched.
+	extractors := map[schema.GroupVersionKind][]ImageExtractor{gvk: {extractor}}
+	matched, _, err = processUnstructuredImages(testutil.TestContext(t), resource, extractors, map[string]bool{}, map[string]bool{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"example/app:1.0.0": true}, matched)
+}
@@ -178,7 +178,7 @@ type InspectDefinitionResourcesOptions struct {
 	DeploySetVariables map[string]string
 	Flavor             string
 	KubeVersion        string
-	// CachePath is used to cache layers from skeleton package pulls
+	// CachePath is used to cache layers from skeleton package pulls and remote kustomizations
 	CachePath string
 	// IsInteractive decides if Zarf can interactively prompt users through the CLI
 	IsInteractive bool
@@ -254,7 +254,7 @@ func InspectDefinitionResources(ctx context.Context, packagePath string, opts In
 			}
 		}
 		for _, manifest := range component.Manifests {
-			manifestResources, err := getTemplatedManifests(ctx, manifest, packagePath, compBuildPath, variableConfig)
+			manifestResources, err := getTemplatedManifests(ctx, manifest, packagePath, compBuildPath, opts.CachePath, variableConfig)
 			if err != nil {
 				return nil, err
 			}
@@ -265,8 +265,8 @@ func InspectDefinitionResources(ctx context.Context, packagePath string, opts In
 	return resources, nil
 }
 
-func getTemplatedManifests(ctx context.Context, manifest v1alpha1.ZarfManifest, packagePath string, baseComponentDir string, variableConfig *variables.VariableConfig) ([]Resource, error) {
-	if err := layout.PackageManifest(ctx, manifest, baseComponentDir, packagePath); err != nil {
+func getTemplatedManifests(ctx context.Context, manifest v1alpha1.ZarfManifest, packagePath string, baseComponentDir string, cachePath string, variableConfig *variables.VariableConfig) ([]Resource, error) {
+	if err := layout.PackageManifest(ctx, manifest, baseComponentDir, packagePath, cachePath); err != nil {
 		return nil, err
 	}
 
@@ -278,6 +278,10 @@ func getTemplatedManifests(ctx context.Context, manifest v1alpha1.ZarfManifest,
 			return err
 		}
 		if info.IsDir() {
+			// Vendored kustomizations are only included through the manifests built from them.
+			if manifest != manifestPath {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		if err := variableConfig.ReplaceTextTemplate(manifest); err != nil {
@@ -305,7 +309,7 @@ func getTemplatedManifests(ctx context.Context, manifest v1alpha1.ZarfManifest,
 func getTemplatedChart(ctx context.Context, zarfChart v1alpha1.ZarfChart, packagePath string, baseComponentDir string, variableConfig *variables.VariableConfig, kubeVersion string, isInteractive bool) (Resource, chartutil.Values, error) {
 	chartPath := filepath.Join(baseComponentDir, string(layout.ChartsComponentDir))
 	valuesFilePath := filepath.Join(baseComponentDir, string(layout.ValuesComponentDir))
-	if err := layout.PackageChart(ctx, zarfChart, packagePath, chartPath, valuesFilePath); err != nil {
+	if _, err := layout.PackageChart(ctx, zarfChart, packagePath, chartPath, valuesFilePath); err != nil {
 		return Resource{}, chartutil.Values{}, err
 	}
 
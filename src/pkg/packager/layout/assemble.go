@@ -35,6 +35,8 @@ import (
 	"github.com/zarf-dev/zarf/src/pkg/packager/filters"
 	"github.com/zarf-dev/zarf/src/pkg/transform"
 	"github.com/zarf-dev/zarf/src/pkg/utils"
+	krustytypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
 // AssembleOptions are the options for creating a package from a package object
@@ -53,6 +55,10 @@ type AssembleOptions struct {
 	CachePath string
 	// WithBuildMachineInfo includes build machine information (hostname and username) in the package metadata
 	WithBuildMachineInfo bool
+	// Reproducible records a fixed build timestamp and normalizes SBOM output so rebuilds of the package are identical
+	Reproducible bool
+	// SourceDateEpoch overrides the build timestamp, defaults to the Unix epoch for reproducible packages and the current time otherwise
+	SourceDateEpoch *time.Time
 }
 
 // AssemblePackage takes a package definition and returns a package layout with all the resources collected
@@ -60,6 +66,16 @@ func AssemblePackage(ctx context.Context, pkg v1alpha1.ZarfPackage, packagePath
 	l := logger.From(ctx)
 	l.Info("assembling package", "path", packagePath)
 
+	if opts.Reproducible && opts.WithBuildMachineInfo {
+		return nil, errors.New("build machine information cannot be included in a reproducible package")
+	}
+	buildTime := time.Now()
+	if opts.SourceDateEpoch != nil {
+		buildTime = opts.SourceDateEpoch.UTC()
+	} else if opts.Reproducible {
+		buildTime = time.Unix(0, 0).UTC()
+	}
+
 	if opts.DifferentialPackage.Metadata.Name != "" {
 		l.Debug("creating differential package", "differential", opts.DifferentialPackage)
 		allIncludedImagesMap := map[string]bool{}
@@ -97,10 +113,11 @@ func AssemblePackage(ctx context.Context, pkg v1alpha1.ZarfPackage, packagePath
 		return nil, err
 	}
 	for _, component := range pkg.Components {
-		err := assemblePackageComponent(ctx, component, packagePath, buildPath)
+		buildCharts, err := assemblePackageComponent(ctx, component, packagePath, buildPath, opts.CachePath)
 		if err != nil {
 			return nil, err
 		}
+		pkg.Build.Charts = append(pkg.Build.Charts, buildCharts...)
 	}
 
 	componentImages := []transform.Image{}
@@ -150,7 +167,7 @@ func AssemblePackage(ctx context.Context, pkg v1alpha1.ZarfPackage, packagePath
 
 	if !opts.SkipSBOM && pkg.IsSBOMAble() {
 		l.Info("generating SBOM")
-		err := generateSBOM(ctx, pkg, buildPath, sbomImageList, opts.CachePath)
+		err := generateSBOM(ctx, pkg, buildPath, sbomImageList, opts.CachePath, opts.Reproducible)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate SBOM: %w", err)
 		}
@@ -174,7 +191,8 @@ func AssemblePackage(ctx context.Context, pkg v1alpha1.ZarfPackage, packagePath
 	}
 	pkg.Metadata.AggregateChecksum = checksumSha
 
-	pkg = recordPackageMetadata(pkg, opts.Flavor, opts.RegistryOverrides, opts.WithBuildMachineInfo)
+	pkg = recordPackageMetadata(pkg, opts.Flavor, opts.RegistryOverrides, opts.WithBuildMachineInfo, buildTime)
+	pkg.Build.Reproducible = opts.Reproducible
 
 	b, err := goyaml.Marshal(pkg)
 	if err != nil {
@@ -243,7 +261,7 @@ func AssembleSkeleton(ctx context.Context, pkg v1alpha1.ZarfPackage, packagePath
 	}
 	pkg.Metadata.AggregateChecksum = checksumSha
 
-	pkg = recordPackageMetadata(pkg, opts.Flavor, nil, opts.WithBuildMachineInfo)
+	pkg = recordPackageMetadata(pkg, opts.Flavor, nil, opts.WithBuildMachineInfo, time.Now())
 
 	b, err := goyaml.Marshal(pkg)
 	if err != nil {
@@ -276,10 +294,10 @@ func AssembleSkeleton(ctx context.Context, pkg v1alpha1.ZarfPackage, packagePath
 	return pkgLayout, nil
 }
 
-func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfComponent, packagePath, buildPath string) (err error) {
+func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfComponent, packagePath, buildPath, cachePath string) (buildCharts []v1alpha1.ZarfBuildChart, err error) {
 	tmpBuildPath, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		err = errors.Join(err, os.RemoveAll(tmpBuildPath))
@@ -287,21 +305,26 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 	compBuildPath := filepath.Join(tmpBuildPath, component.Name)
 	err = os.MkdirAll(compBuildPath, 0o700)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	onCreate := component.Actions.OnCreate
 	if err := actions.Run(ctx, packagePath, onCreate.Defaults, onCreate.Before, nil, nil); err != nil {
-		return fmt.Errorf("unable to run component before action: %w", err)
+		return nil, fmt.Errorf("unable to run component before action: %w", err)
 	}
 
 	// If any helm charts are defined, process them.
 	for _, chart := range component.Charts {
 		chartPath := filepath.Join(compBuildPath, string(ChartsComponentDir))
 		valuesFilePath := filepath.Join(compBuildPath, string(ValuesComponentDir))
-		err := PackageChart(ctx, chart, packagePath, chartPath, valuesFilePath)
+		buildChart, err := PackageChart(ctx, chart, packagePath, chartPath, valuesFilePath)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		// Only charts with dependencies are recorded in the build data.
+		if buildChart.LockDigest != "" || len(buildChart.Dependencies) > 0 {
+			buildChart.Component = component.Name
+			buildCharts = append(buildCharts, buildChart)
 		}
 	}
 
@@ -315,11 +338,11 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 				// get the compressedFileName from the source
 				compressedFileName, err := helpers.ExtractBasePathFromURL(file.Source)
 				if err != nil {
-					return fmt.Errorf(lang.ErrFileNameExtract, file.Source, err.Error())
+					return nil, fmt.Errorf(lang.ErrFileNameExtract, file.Source, err.Error())
 				}
 				tmpDir, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				defer func() {
 					err = errors.Join(err, os.RemoveAll(tmpDir))
@@ -328,18 +351,18 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 
 				// If the file is an archive, download it to the componentPath.Temp
 				if err := utils.DownloadToFile(ctx, file.Source, compressedFile); err != nil {
-					return fmt.Errorf(lang.ErrDownloading, file.Source, err.Error())
+					return nil, fmt.Errorf(lang.ErrDownloading, file.Source, err.Error())
 				}
 				decompressOpts := archive.DecompressOpts{
 					Files: []string{file.ExtractPath},
 				}
 				err = archive.Decompress(ctx, compressedFile, destinationDir, decompressOpts)
 				if err != nil {
-					return fmt.Errorf(lang.ErrFileExtract, file.ExtractPath, compressedFileName, err.Error())
+					return nil, fmt.Errorf(lang.ErrFileExtract, file.ExtractPath, compressedFileName, err.Error())
 				}
 			} else {
 				if err := utils.DownloadToFile(ctx, file.Source, dst); err != nil {
-					return fmt.Errorf(lang.ErrDownloading, file.Source, err.Error())
+					return nil, fmt.Errorf(lang.ErrDownloading, file.Source, err.Error())
 				}
 			}
 		} else {
@@ -353,11 +376,11 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 				}
 				err = archive.Decompress(ctx, src, destinationDir, decompressOpts)
 				if err != nil {
-					return fmt.Errorf(lang.ErrFileExtract, file.ExtractPath, src, err.Error())
+					return nil, fmt.Errorf(lang.ErrFileExtract, file.ExtractPath, src, err.Error())
 				}
 			} else {
 				if err := helpers.CreatePathAndCopy(src, dst); err != nil {
-					return fmt.Errorf("unable to copy file %s: %w", src, err)
+					return nil, fmt.Errorf("unable to copy file %s: %w", src, err)
 				}
 			}
 		}
@@ -367,7 +390,7 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 			updatedExtractedFileOrDir := filepath.Join(destinationDir, file.ExtractPath)
 			if updatedExtractedFileOrDir != dst {
 				if err := os.Rename(updatedExtractedFileOrDir, dst); err != nil {
-					return fmt.Errorf(lang.ErrWritingFile, dst, err)
+					return nil, fmt.Errorf(lang.ErrWritingFile, dst, err)
 				}
 			}
 		}
@@ -375,19 +398,19 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 		// Abort packaging on invalid shasum (if one is specified).
 		if file.Shasum != "" {
 			if err := helpers.SHAsMatch(dst, file.Shasum); err != nil {
-				return err
+				return nil, err
 			}
 		}
 
 		if file.Executable || helpers.IsDir(dst) {
 			err := os.Chmod(dst, helpers.ReadWriteExecuteUser)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		} else {
 			err := os.Chmod(dst, helpers.ReadWriteUser)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
@@ -398,7 +421,7 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 
 		if helpers.IsURL(data.Source) {
 			if err := utils.DownloadToFile(ctx, data.Source, dst); err != nil {
-				return fmt.Errorf(lang.ErrDownloading, data.Source, err.Error())
+				return nil, fmt.Errorf(lang.ErrDownloading, data.Source, err.Error())
 			}
 		} else {
 			src := data.Source
@@ -406,7 +429,7 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 				src = filepath.Join(packagePath, data.Source)
 			}
 			if err := helpers.CreatePathAndCopy(src, dst); err != nil {
-				return fmt.Errorf("unable to copy data injection %s: %s", data.Source, err.Error())
+				return nil, fmt.Errorf("unable to copy data injection %s: %s", data.Source, err.Error())
 			}
 		}
 	}
@@ -415,13 +438,13 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 	if len(component.Manifests) > 0 {
 		err := os.MkdirAll(filepath.Join(compBuildPath, string(ManifestsComponentDir)), 0o700)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	for _, manifest := range component.Manifests {
-		err := PackageManifest(ctx, manifest, compBuildPath, packagePath)
+		err := PackageManifest(ctx, manifest, compBuildPath, packagePath, cachePath)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -430,36 +453,36 @@ func assemblePackageComponent(ctx context.Context, component v1alpha1.ZarfCompon
 		// Pull all the references if there is no `@` in the string.
 		_, err := git.Clone(ctx, filepath.Join(compBuildPath, string(RepoComponentDir)), url, false)
 		if err != nil {
-			return fmt.Errorf("unable to pull git repo %s: %w", url, err)
+			return nil, fmt.Errorf("unable to pull git repo %s: %w", url, err)
 		}
 	}
 
 	if err := actions.Run(ctx, packagePath, onCreate.Defaults, onCreate.After, nil, nil); err != nil {
-		return fmt.Errorf("unable to run component after action: %w", err)
+		return nil, fmt.Errorf("unable to run component after action: %w", err)
 	}
 
 	// Write the tar component.
 	entries, err := os.ReadDir(compBuildPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(entries) == 0 {
-		return nil
+		return buildCharts, nil
 	}
 	tarPath := filepath.Join(buildPath, "components", fmt.Sprintf("%s.tar", component.Name))
 	err = os.MkdirAll(filepath.Join(buildPath, "components"), 0o700)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	err = createReproducibleTarballFromDir(compBuildPath, component.Name, tarPath, false)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return buildCharts, nil
 }
 
 // PackageManifest takes a Zarf manifest definition and packs it into a package layout
-func PackageManifest(ctx context.Context, manifest v1alpha1.ZarfManifest, compBuildPath string, packagePath string) error {
+func PackageManifest(ctx context.Context, manifest v1alpha1.ZarfManifest, compBuildPath string, packagePath string, cachePath string) error {
 	for fileIdx, path := range manifest.Files {
 		rel := filepath.Join(string(ManifestsComponentDir), fmt.Sprintf("%s-%d.yaml", manifest.Name, fileIdx))
 		dst := filepath.Join(compBuildPath, rel)
@@ -486,18 +509,71 @@ func PackageManifest(ctx context.Context, manifest v1alpha1.ZarfManifest, compBu
 		rel := filepath.Join(string(ManifestsComponentDir), kname)
 		dst := filepath.Join(compBuildPath, rel)
 
-		if !helpers.IsURL(path) && !filepath.IsAbs(path) {
+		// kustomizations can use non-standard urls, so we need to check if the path exists on the local filesystem
+		if !helpers.IsURL(path) && !filepath.IsAbs(path) && !helpers.InvalidPath(filepath.Join(packagePath, path)) {
 			path = filepath.Join(packagePath, path)
 		}
-		if err := kustomize.Build(path, dst, manifest.KustomizeAllowAnyDirectory, manifest.EnableKustomizePlugins); err != nil {
+		opts := kustomizeOptions(manifest, packagePath)
+		if cachePath != "" {
+			opts.CachePath = filepath.Join(cachePath, KustomizeDir)
+		}
+
+		// Templated kustomizations are built again at deploy time and remote kustomizations pinned to a commit can be built again offline,
+		// so only those are vendored into the package.
+		if !manifest.IsTemplate() && (!helpers.InvalidPath(path) || !kustomize.IsPinnedRemote(path)) {
+			if err := kustomize.Build(path, dst, opts); err != nil {
+				return fmt.Errorf("unable to build kustomization %s: %w", path, err)
+			}
+			continue
+		}
+		vendorDir := filepath.Join(compBuildPath, string(ManifestsComponentDir), fmt.Sprintf("kustomize-%s-%d", manifest.Name, kustomizeIdx))
+		if err := kustomize.Vendor(path, vendorDir, opts); err != nil {
+			return fmt.Errorf("unable to vendor kustomization %s: %w", path, err)
+		}
+		buildOpts := kustomize.Options{AllowAnyDirectory: manifest.KustomizeAllowAnyDirectory, EnablePlugins: manifest.EnableKustomizePlugins}
+		if err := kustomize.Build(vendorDir, dst, buildOpts); err != nil {
 			return fmt.Errorf("unable to build kustomization %s: %w", path, err)
 		}
 	}
 	return nil
 }
 
-// PackageChart takes a Zarf Chart definition and packs it into a package layout
-func PackageChart(ctx context.Context, chart v1alpha1.ZarfChart, packagePath string, chartPath string, valuesFilePath string) error {
+// kustomizeOptions returns the options to build the kustomizations of a Zarf manifest with.
+func kustomizeOptions(manifest v1alpha1.ZarfManifest, packagePath string) kustomize.Options {
+	opts := kustomize.Options{
+		AllowAnyDirectory: manifest.KustomizeAllowAnyDirectory,
+		EnablePlugins:     manifest.EnableKustomizePlugins,
+	}
+	if manifest.KustomizeAllowAnyDirectory {
+		// Files outside of the kustomization directory are vendored when they are within the package directory.
+		opts.Scope = packagePath
+	}
+	for _, component := range manifest.KustomizeComponents {
+		if !helpers.IsURL(component) && !filepath.IsAbs(component) && !helpers.InvalidPath(filepath.Join(packagePath, component)) {
+			component = filepath.Join(packagePath, component)
+		}
+		opts.Components = append(opts.Components, component)
+	}
+	for _, patch := range manifest.KustomizePatches {
+		p := krustytypes.Patch{Patch: patch.Patch}
+		if patch.Target != nil {
+			p.Target = &krustytypes.Selector{
+				ResId: resid.ResId{
+					Gvk:       resid.Gvk{Group: patch.Target.Group, Version: patch.Target.Version, Kind: patch.Target.Kind},
+					Name:      patch.Target.Name,
+					Namespace: patch.Target.Namespace,
+				},
+				LabelSelector:      patch.Target.LabelSelector,
+				AnnotationSelector: patch.Target.AnnotationSelector,
+			}
+		}
+		opts.Patches = append(opts.Patches, p)
+	}
+	return opts
+}
+
+// PackageChart takes a Zarf Chart definition and packs it into a package layout, returning the dependencies packed into the chart
+func PackageChart(ctx context.Context, chart v1alpha1.ZarfChart, packagePath string, chartPath string, valuesFilePath string) (v1alpha1.ZarfBuildChart, error) {
 	if chart.LocalPath != "" && !filepath.IsAbs(chart.LocalPath) {
 		chart.LocalPath = filepath.Join(packagePath, chart.LocalPath)
 	}
@@ -511,10 +587,10 @@ func PackageChart(ctx context.Context, chart v1alpha1.ZarfChart, packagePath str
 	}
 	chart.ValuesFiles = valuesFiles
 	if err := helm.PackageChart(ctx, chart, chartPath, valuesFilePath); err != nil {
-		return err
+		return v1alpha1.ZarfBuildChart{}, err
 	}
 	chart.ValuesFiles = oldValuesFiles
-	return nil
+	return helm.ChartDependencies(ctx, chart, chartPath)
 }
 
 func assembleSkeletonComponent(ctx context.Context, component v1alpha1.ZarfComponent, packagePath, buildPath string) (err error) {
@@ -674,13 +750,15 @@ func assembleSkeletonComponent(ctx context.Context, component v1alpha1.ZarfCompo
 			}
 
 			// Build() requires the path be present - otherwise will throw an error.
-			if err := kustomize.Build(path, dst, manifest.KustomizeAllowAnyDirectory, manifest.EnableKustomizePlugins); err != nil {
+			if err := kustomize.Build(path, dst, kustomizeOptions(manifest, packagePath)); err != nil {
 				return fmt.Errorf("unable to build kustomization %s: %w", path, err)
 			}
 		}
 
 		// remove kustomizations
 		component.Manifests[manifestIdx].Kustomizations = nil
+		component.Manifests[manifestIdx].KustomizeComponents = nil
+		component.Manifests[manifestIdx].KustomizePatches = nil
 	}
 
 	// Write the tar component.
@@ -707,8 +785,7 @@ func assembleSkeletonComponent(ctx context.Context, component v1alpha1.ZarfCompo
 	return nil
 }
 
-func recordPackageMetadata(pkg v1alpha1.ZarfPackage, flavor string, registryOverrides []images.RegistryOverride, withBuildMachineInfo bool) v1alpha1.ZarfPackage {
-	now := time.Now()
+func recordPackageMetadata(pkg v1alpha1.ZarfPackage, flavor string, registryOverrides []images.RegistryOverride, withBuildMachineInfo bool, buildTime time.Time) v1alpha1.ZarfPackage {
 	if withBuildMachineInfo {
 		// Just use $USER env variable to avoid CGO issue.
 		// https://groups.google.com/g/golang-dev/c/ZFDDX3ZiJ84.
@@ -735,7 +812,7 @@ func recordPackageMetadata(pkg v1alpha1.ZarfPackage, flavor string, registryOver
 	pkg.Build.Version = config.CLIVersion
 
 	// Record the time of package creation.
-	pkg.Build.Timestamp = now.Format(v1alpha1.BuildTimestampFormat)
+	pkg.Build.Timestamp = buildTime.Format(v1alpha1.BuildTimestampFormat)
 
 	// Record the flavor of Zarf used to build this package (if any).
 	pkg.Build.Flavor = flavor
@@ -10,6 +10,9 @@ import (
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/internal/packager/kustomize"
+	"github.com/zarf-dev/zarf/src/test/testutil"
 )
 
 func TestGetChecksum(t *testing.T) {
@@ -56,3 +59,99 @@ func TestCreateReproducibleTarballFromDir(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "c09d17f612f241cdf549e5fb97c9e063a8ad18ae7a9f3af066332ed6b38556ad", shaSum)
 }
+
+func TestPackageManifestKustomize(t *testing.T) {
+	t.Parallel()
+
+	packagePath := t.TempDir()
+	files := map[string]string{
+		"base/kustomization.yaml": "resources:\n  - configmap.yaml\n",
+		"base/configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndata:\n  replicas: \"1\"\n",
+		"component/kustomization.yaml": "apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: Component\n" +
+			"labels:\n  - pairs:\n      component: enabled\n",
+	}
+	for k, v := range files {
+		err := os.MkdirAll(filepath.Join(packagePath, filepath.Dir(k)), 0o700)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(packagePath, k), []byte(v), 0o600)
+		require.NoError(t, err)
+	}
+	manifest := v1alpha1.ZarfManifest{
+		Name:                "app",
+		Template:            helpers.BoolPtr(true),
+		Kustomizations:      []string{"base"},
+		KustomizeComponents: []string{"component"},
+		KustomizePatches: []v1alpha1.ZarfKustomizePatch{
+			{
+				Patch:  "- op: replace\n  path: /data/replicas\n  value: \"3\"\n",
+				Target: &v1alpha1.ZarfKustomizePatchTarget{Kind: "ConfigMap", Name: "app"},
+			},
+		},
+	}
+	expected := "apiVersion: v1\ndata:\n  replicas: \"3\"\nkind: ConfigMap\nmetadata:\n  labels:\n    component: enabled\n  name: app\n"
+
+	compBuildPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(compBuildPath, string(ManifestsComponentDir)), 0o700))
+	err := PackageManifest(testutil.TestContext(t), manifest, compBuildPath, packagePath, "")
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(compBuildPath, string(ManifestsComponentDir), "kustomization-app-0.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+
+	// The vendored kustomization builds the same manifests without the package sources.
+	require.NoError(t, os.RemoveAll(packagePath))
+	rebuilt := filepath.Join(t.TempDir(), "rebuilt.yaml")
+	err = kustomize.Build(filepath.Join(compBuildPath, string(ManifestsComponentDir), "kustomize-app-0"), rebuilt, kustomize.Options{})
+	require.NoError(t, err)
+	b, err = os.ReadFile(rebuilt)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(b))
+
+	// Local kustomizations that are not templated are not vendored.
+	manifest.Template = nil
+	for k, v := range files {
+		err := os.MkdirAll(filepath.Join(packagePath, filepath.Dir(k)), 0o700)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(packagePath, k), []byte(v), 0o600)
+		require.NoError(t, err)
+	}
+	compBuildPath = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(compBuildPath, string(ManifestsComponentDir)), 0o700))
+	err = PackageManifest(testutil.TestContext(t), manifest, compBuildPath, packagePath, "")
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(compBuildPath, string(ManifestsComponentDir), "kustomization-app-0.yaml"))
+	require.NoDirExists(t, filepath.Join(compBuildPath, string(ManifestsComponentDir), "kustomize-app-0"))
+}
+
+func TestPackageManifestKustomizeVendorFailure(t *testing.T) {
+	t.Parallel()
+
+	// The configmap is outside of the package so it can be built but not vendored.
+	packagePath := t.TempDir()
+	outsidePath := t.TempDir()
+	rel, err := filepath.Rel(filepath.Join(packagePath, "base"), filepath.Join(outsidePath, "configmap.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(packagePath, "base"), 0o700))
+	err = os.WriteFile(filepath.Join(packagePath, "base", "kustomization.yaml"), []byte("resources:\n  - "+rel+"\n"), 0o600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(outsidePath, "configmap.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"), 0o600)
+	require.NoError(t, err)
+	manifest := v1alpha1.ZarfManifest{
+		Name:                       "app",
+		Kustomizations:             []string{"base"},
+		KustomizeAllowAnyDirectory: true,
+	}
+
+	// Kustomizations that are not templated are built from their source.
+	compBuildPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(compBuildPath, string(ManifestsComponentDir)), 0o700))
+	err = PackageManifest(testutil.TestContext(t), manifest, compBuildPath, packagePath, "")
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(compBuildPath, string(ManifestsComponentDir), "kustomization-app-0.yaml"))
+	require.NoDirExists(t, filepath.Join(compBuildPath, string(ManifestsComponentDir), "kustomize-app-0"))
+
+	// Templated kustomizations are built again at deploy time so they must be vendored.
+	manifest.Template = helpers.BoolPtr(true)
+	err = PackageManifest(testutil.TestContext(t), manifest, t.TempDir(), packagePath, "")
+	require.ErrorContains(t, err, "unable to vendor kustomization")
+}
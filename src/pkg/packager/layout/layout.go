@@ -17,6 +17,7 @@ const (
 	ImagesDir     = "images"
 	ComponentsDir = "components"
 	ValuesDir     = "values"
+	KustomizeDir  = "kustomize"
 
 	SBOMDir = "zarf-sbom"
 	SBOMTar = "sboms.tar"
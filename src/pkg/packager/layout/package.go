@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	goyaml "github.com/goccy/go-yaml"
@@ -383,7 +384,16 @@ func (p *PackageLayout) Archive(ctx context.Context, dirPath string, maxPackageS
 	for _, file := range files {
 		filePaths = append(filePaths, filepath.Join(p.dirPath, file.Name()))
 	}
-	err = archive.Compress(ctx, filePaths, tarballPath, archive.CompressOpts{})
+	compressOpts := archive.CompressOpts{}
+	// Reproducible packages use the build timestamp for every file so the archive does not depend on when it was written.
+	if p.Pkg.Build.Reproducible {
+		modTime, err := time.Parse(v1alpha1.BuildTimestampFormat, p.Pkg.Build.Timestamp)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse the build timestamp: %w", err)
+		}
+		compressOpts.ModTime = &modTime
+	}
+	err = archive.Compress(ctx, filePaths, tarballPath, compressOpts)
 	if err != nil {
 		return "", fmt.Errorf("unable to create package: %w", err)
 	}
@@ -4,6 +4,7 @@
 package layout
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
@@ -42,11 +43,14 @@ import (
 
 const componentPrefix = "zarf-component-"
 
+// reproducibleSBOMRoot replaces the temporary directory component files are scanned from in reproducible SBOMs.
+const reproducibleSBOMRoot = "/zarf-sbom"
+
 //go:embed viewer/*
 var viewerAssets embed.FS
 var transformRegex = regexp.MustCompile(`(?m)[^a-zA-Z0-9\.\-]`)
 
-func generateSBOM(ctx context.Context, pkg v1alpha1.ZarfPackage, buildPath string, images []transform.Image, cachePath string) (err error) {
+func generateSBOM(ctx context.Context, pkg v1alpha1.ZarfPackage, buildPath string, images []transform.Image, cachePath string, reproducible bool) (err error) {
 	l := logger.From(ctx)
 	outputPath, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
 	if err != nil {
@@ -88,7 +92,7 @@ func generateSBOM(ctx context.Context, pkg v1alpha1.ZarfPackage, buildPath strin
 		if len(comp.DataInjections) == 0 && len(comp.Files) == 0 {
 			continue
 		}
-		jsonData, err := createFileSBOM(ctx, comp, outputPath, buildPath)
+		jsonData, err := createFileSBOM(ctx, comp, outputPath, buildPath, reproducible)
 		if err != nil {
 			return err
 		}
@@ -146,7 +150,7 @@ func createImageSBOM(ctx context.Context, cachePath, outputPath string, img v1.I
 	return jsonData, nil
 }
 
-func createFileSBOM(ctx context.Context, component v1alpha1.ZarfComponent, outputPath, buildPath string) (_ []byte, err error) {
+func createFileSBOM(ctx context.Context, component v1alpha1.ZarfComponent, outputPath, buildPath string, reproducible bool) (_ []byte, err error) {
 	l := logger.From(ctx)
 	tmpDir, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
 	if err != nil {
@@ -188,7 +192,12 @@ func createFileSBOM(ctx context.Context, component v1alpha1.ZarfComponent, outpu
 		}
 	}
 
-	parentSource, err := directorysource.NewFromPath(tmpDir)
+	parentCfg := directorysource.Config{Path: tmpDir}
+	if reproducible {
+		// Derive the source ID from the component instead of the temporary directory
+		parentCfg.Alias = source.Alias{Name: fmt.Sprintf("%s%s", componentPrefix, component.Name)}
+	}
+	parentSource, err := directorysource.New(parentCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -255,6 +264,10 @@ func createFileSBOM(ctx context.Context, component v1alpha1.ZarfComponent, outpu
 	if err != nil {
 		return nil, err
 	}
+	if reproducible {
+		// The files are scanned from a temporary directory, record their paths relative to it instead
+		jsonData = bytes.ReplaceAll(jsonData, []byte(filepath.ToSlash(tmpDir)), []byte(reproducibleSBOMRoot))
+	}
 
 	filename := fmt.Sprintf("%s%s.json", componentPrefix, component.Name)
 	path := filepath.Join(outputPath, getNormalizedFileName(filename))
@@ -415,6 +415,8 @@ func overrideResources(comp v1alpha1.ZarfComponent, override v1alpha1.ZarfCompon
 				}
 				comp.Manifests[idx].Files = append(comp.Manifests[idx].Files, overrideManifest.Files...)
 				comp.Manifests[idx].Kustomizations = append(comp.Manifests[idx].Kustomizations, overrideManifest.Kustomizations...)
+				comp.Manifests[idx].KustomizeComponents = append(comp.Manifests[idx].KustomizeComponents, overrideManifest.KustomizeComponents...)
+				comp.Manifests[idx].KustomizePatches = append(comp.Manifests[idx].KustomizePatches, overrideManifest.KustomizePatches...)
 
 				existing = true
 			}
@@ -470,6 +472,12 @@ func fixPaths(child v1alpha1.ZarfComponent, relativeToHead, packagePath string)
 				child.Manifests[manifestIdx].Kustomizations[kustomizeIdx] = composed
 			}
 		}
+		for componentIdx, component := range manifest.KustomizeComponents {
+			composed := makePathRelativeTo(component, relativeToHead)
+			if !helpers.InvalidPath(filepath.Join(packagePath, composed)) {
+				child.Manifests[manifestIdx].KustomizeComponents[componentIdx] = composed
+			}
+		}
 	}
 
 	for dataInjectionsIdx, dataInjection := range child.DataInjections {
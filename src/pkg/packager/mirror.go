@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/avast/retry-go/v4"
@@ -32,10 +33,12 @@ type ImagePushOptions struct {
 	NoImageChecksum bool
 	Retries         int
 	OCIConcurrency  int
+	// Named registries that images are routed to instead of the specified registry
+	Registries []state.NamedRegistryInfo
 	RemoteOptions
 }
 
-// PushImagesToRegistry pushes images in the package layout to the specified registry
+// PushImagesToRegistry pushes images in the package layout to the specified registry, or the named registries they are routed to
 func PushImagesToRegistry(ctx context.Context, pkgLayout *layout.PackageLayout, registryInfo state.RegistryInfo, opts ImagePushOptions) error {
 	if pkgLayout == nil {
 		return fmt.Errorf("package layout is required")
@@ -46,45 +49,110 @@ func PushImagesToRegistry(ctx context.Context, pkgLayout *layout.PackageLayout,
 	if opts.Retries == 0 {
 		opts.Retries = config.ZarfDefaultRetries
 	}
-	refs := []transform.Image{}
-	for _, component := range pkgLayout.Pkg.Components {
+	pushes, err := routeImages(pkgLayout.Pkg.Components, registryInfo, opts.Registries)
+	if err != nil {
+		return err
+	}
+	rules, err := transform.NewImageRules(pkgLayout.Pkg.Transforms.Images)
+	if err != nil {
+		return err
+	}
+	for _, push := range pushes {
+		pushConfig := images.PushConfig{
+			OCIConcurrency:        opts.OCIConcurrency,
+			SourceDirectory:       pkgLayout.GetImageDirPath(),
+			RegistryInfo:          push.registry.RegistryInfo,
+			ImageList:             push.images,
+			PlainHTTP:             opts.PlainHTTP,
+			NoChecksum:            opts.NoImageChecksum,
+			Arch:                  pkgLayout.Pkg.Build.Architecture,
+			Retries:               opts.Retries,
+			InsecureSkipTLSVerify: opts.InsecureSkipTLSVerify,
+			Cluster:               opts.Cluster,
+			Transforms:            rules,
+		}
+		if err := images.Push(ctx, pushConfig); err != nil {
+			return fmt.Errorf("failed to push images to %s: %w", push.registry.Address, err)
+		}
+		// Images pushed to the Zarf registry in mirror mode are only pulled by their original name once their hosts are mirrored
+		if opts.Cluster != nil && push.registry.Name == "" && push.registry.RegistryMode == state.RegistryModeMirror {
+			s, err := opts.Cluster.LoadState(ctx)
+			if err != nil {
+				return err
+			}
+			if err := opts.Cluster.UpdateRegistryMirrors(ctx, s, push.images); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// registryPush is the set of images pushed to a single registry.
+type registryPush struct {
+	registry state.NamedRegistryInfo
+	images   []transform.Image
+}
+
+// routeImages groups the images of the components by the registries they are routed to.
+// An image is pushed to every registry that workloads in the namespaces of its component pull it from.
+func routeImages(components []v1alpha1.ZarfComponent, registryInfo state.RegistryInfo, registries []state.NamedRegistryInfo) ([]registryPush, error) {
+	pushes := []registryPush{}
+	seen := map[string]map[string]bool{}
+	for _, component := range components {
 		for _, img := range component.Images {
 			ref, err := transform.ParseImageRef(img)
 			if err != nil {
-				return fmt.Errorf("failed to create ref for image %s: %w", img, err)
+				return nil, fmt.Errorf("failed to create ref for image %s: %w", img, err)
+			}
+			for _, namespace := range componentNamespaces(component) {
+				registry := state.RouteRegistry(registryInfo, registries, img, namespace)
+				idx := slices.IndexFunc(pushes, func(p registryPush) bool {
+					return p.registry.Name == registry.Name
+				})
+				if idx == -1 {
+					pushes = append(pushes, registryPush{registry: registry})
+					seen[registry.Name] = map[string]bool{}
+					idx = len(pushes) - 1
+				}
+				if seen[registry.Name][ref.Reference] {
+					continue
+				}
+				seen[registry.Name][ref.Reference] = true
+				pushes[idx].images = append(pushes[idx].images, ref)
 			}
-			refs = append(refs, ref)
 		}
 	}
-	if len(refs) == 0 {
-		return nil
-	}
-	pushConfig := images.PushConfig{
-		OCIConcurrency:        opts.OCIConcurrency,
-		SourceDirectory:       pkgLayout.GetImageDirPath(),
-		RegistryInfo:          registryInfo,
-		ImageList:             refs,
-		PlainHTTP:             opts.PlainHTTP,
-		NoChecksum:            opts.NoImageChecksum,
-		Arch:                  pkgLayout.Pkg.Build.Architecture,
-		Retries:               opts.Retries,
-		InsecureSkipTLSVerify: opts.InsecureSkipTLSVerify,
-		Cluster:               opts.Cluster,
-	}
-	err := images.Push(ctx, pushConfig)
-	if err != nil {
-		return fmt.Errorf("failed to push images: %w", err)
+	return pushes, nil
+}
+
+// componentNamespaces returns the namespaces the charts and manifests of the component are deployed to.
+// A single empty namespace is returned when none are set so that only source prefix routes apply.
+func componentNamespaces(component v1alpha1.ZarfComponent) []string {
+	namespaces := []string{}
+	for _, chart := range component.Charts {
+		namespaces = append(namespaces, chart.Namespace)
 	}
-	return nil
+	for _, manifest := range component.Manifests {
+		namespaces = append(namespaces, manifest.Namespace)
+	}
+	namespaces = slices.DeleteFunc(namespaces, func(ns string) bool { return ns == "" })
+	if len(namespaces) == 0 {
+		return []string{""}
+	}
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces)
 }
 
 // RepoPushOptions are optional parameters to push repos in a zarf package to a Git server
 type RepoPushOptions struct {
 	Cluster *cluster.Cluster
 	Retries int
+	// Named git servers that repositories are routed to instead of the specified git server
+	GitServers []state.NamedGitServerInfo
 }
 
-// PushReposToRepository pushes Git repositories in the package layout to the Git server
+// PushReposToRepository pushes Git repositories in the package layout to the Git server, or the named git servers they are routed to
 func PushReposToRepository(ctx context.Context, pkgLayout *layout.PackageLayout, gitInfo state.GitServerInfo, opts RepoPushOptions) error {
 	if pkgLayout == nil {
 		return fmt.Errorf("package layout is required")
@@ -96,7 +164,7 @@ func PushReposToRepository(ctx context.Context, pkgLayout *layout.PackageLayout,
 		return fmt.Errorf("git server address must be specified")
 	}
 	for _, component := range pkgLayout.Pkg.Components {
-		err := pushComponentReposToRegistry(ctx, component, pkgLayout, gitInfo, opts.Cluster, opts.Retries)
+		err := pushComponentReposToRegistry(ctx, component, pkgLayout, gitInfo, opts.GitServers, opts.Cluster, opts.Retries)
 		if err != nil {
 			return err
 		}
@@ -104,69 +172,106 @@ func PushReposToRepository(ctx context.Context, pkgLayout *layout.PackageLayout,
 	return nil
 }
 
+// routeRepo returns the git servers that workloads in the namespaces of the component pull the repository from.
+func routeRepo(component v1alpha1.ZarfComponent, repoURL string, gitInfo state.GitServerInfo, gitServers []state.NamedGitServerInfo) []state.GitServerInfo {
+	routed := []state.GitServerInfo{}
+	for _, namespace := range componentNamespaces(component) {
+		gs := state.RouteGitServer(gitInfo, gitServers, repoURL, namespace)
+		if !slices.Contains(routed, gs.GitServerInfo) {
+			routed = append(routed, gs.GitServerInfo)
+		}
+	}
+	return routed
+}
+
 func pushComponentReposToRegistry(ctx context.Context, component v1alpha1.ZarfComponent,
-	pkgLayout *layout.PackageLayout, gitInfo state.GitServerInfo, c *cluster.Cluster, retries int) (err error) {
-	l := logger.From(ctx)
+	pkgLayout *layout.PackageLayout, defaultGitInfo state.GitServerInfo, gitServers []state.NamedGitServerInfo, c *cluster.Cluster, retries int) error {
 	for _, repoURL := range component.Repos {
-		tmpDir, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+		for _, gitInfo := range routeRepo(component, repoURL, defaultGitInfo, gitServers) {
+			if err := pushRepoToGitServer(ctx, component, pkgLayout, repoURL, gitInfo, c, retries); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func pushRepoToGitServer(ctx context.Context, component v1alpha1.ZarfComponent, pkgLayout *layout.PackageLayout,
+	repoURL string, gitInfo state.GitServerInfo, c *cluster.Cluster, retries int) (err error) {
+	l := logger.From(ctx)
+	tmpDir, err := utils.MakeTempDir(config.CommonOptions.TempDirectory)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, os.RemoveAll(tmpDir))
+	}()
+	reposPath, err := pkgLayout.GetComponentDir(ctx, tmpDir, component.Name, layout.RepoComponentDir)
+	if err != nil {
+		return err
+	}
+	repository, err := git.Open(reposPath, repoURL)
+	if err != nil {
+		return err
+	}
+	rules, err := transform.NewRepoRules(pkgLayout.Pkg.Transforms.Repos)
+	if err != nil {
+		return err
+	}
+	// Point the submodules at the git server so that they resolve in the air gap
+	err = repository.RewriteSubmoduleURLs(ctx, gitInfo.Address, gitInfo.PushUsername, rules)
+	if err != nil {
+		return err
+	}
+	submoduleURLs, err := repository.SubmoduleURLs()
+	if err != nil {
+		return err
+	}
+	err = retry.Do(func() error {
+		if !dns.IsServiceURL(gitInfo.Address) {
+			l.Info("pushing repository to server", "repo", repoURL, "server", gitInfo.Address)
+			err = repository.Push(ctx, gitInfo.Address, gitInfo.PushUsername, gitInfo.PushPassword, rules)
+			if err != nil {
+				return err
+			}
+			return nil
+		}
+
+		if c == nil {
+			return retry.Unrecoverable(errors.New("cannot push to internal Git server when cluster is nil"))
+		}
+		namespace, name, port, err := dns.ParseServiceURL(gitInfo.Address)
+		if err != nil {
+			return retry.Unrecoverable(err)
+		}
+		tunnel, err := c.NewTunnel(namespace, cluster.SvcResource, name, "", 0, port)
 		if err != nil {
 			return err
 		}
-		defer func() {
-			err = errors.Join(err, os.RemoveAll(tmpDir))
-		}()
-		reposPath, err := pkgLayout.GetComponentDir(ctx, tmpDir, component.Name, layout.RepoComponentDir)
+		_, err = tunnel.Connect(ctx)
 		if err != nil {
 			return err
 		}
-		repository, err := git.Open(reposPath, repoURL)
+		defer tunnel.Close()
+		// tunnel is create with the default listenAddress - there will only be one endpoint until otherwise supported
+		endpoints := tunnel.HTTPEndpoints()
+		if len(endpoints) == 0 {
+			return errors.New("no tunnel endpoints found")
+		}
+		giteaClient, err := gitea.NewClient(endpoints[0], gitInfo.PushUsername, gitInfo.PushPassword)
 		if err != nil {
 			return err
 		}
-		err = retry.Do(func() error {
-			if !dns.IsServiceURL(gitInfo.Address) {
-				l.Info("pushing repository to server", "repo", repoURL, "server", gitInfo.Address)
-				err = repository.Push(ctx, gitInfo.Address, gitInfo.PushUsername, gitInfo.PushPassword)
-				if err != nil {
-					return err
-				}
-				return nil
-			}
-
-			if c == nil {
-				return retry.Unrecoverable(errors.New("cannot push to internal Git server when cluster is nil"))
-			}
-			namespace, name, port, err := dns.ParseServiceURL(gitInfo.Address)
-			if err != nil {
-				return retry.Unrecoverable(err)
-			}
-			tunnel, err := c.NewTunnel(namespace, cluster.SvcResource, name, "", 0, port)
-			if err != nil {
-				return err
-			}
-			_, err = tunnel.Connect(ctx)
+		return tunnel.Wrap(func() error {
+			l.Info("pushing repository to server", "repo", repoURL, "server", endpoints[0])
+			err = repository.Push(ctx, endpoints[0], gitInfo.PushUsername, gitInfo.PushPassword, rules)
 			if err != nil {
 				return err
 			}
-			defer tunnel.Close()
-			// tunnel is create with the default listenAddress - there will only be one endpoint until otherwise supported
-			endpoints := tunnel.HTTPEndpoints()
-			if len(endpoints) == 0 {
-				return errors.New("no tunnel endpoints found")
-			}
-			giteaClient, err := gitea.NewClient(endpoints[0], gitInfo.PushUsername, gitInfo.PushPassword)
-			if err != nil {
-				return err
-			}
-			return tunnel.Wrap(func() error {
-				l.Info("pushing repository to server", "repo", repoURL, "server", endpoints[0])
-				err = repository.Push(ctx, endpoints[0], gitInfo.PushUsername, gitInfo.PushPassword)
-				if err != nil {
-					return err
-				}
-				// Add the read-only user to this repo
-				// TODO: This should not be done here. Or the function name should be changed.
-				repoName, err := transform.GitURLtoRepoName(repoURL)
+			// Add the read-only user to this repo and its submodules
+			// TODO: This should not be done here. Or the function name should be changed.
+			for _, u := range append([]string{repoURL}, submoduleURLs...) {
+				repoName, err := transform.GitURLtoRepoNameWithRules(u, rules)
 				if err != nil {
 					return retry.Unrecoverable(err)
 				}
@@ -174,12 +279,12 @@ func pushComponentReposToRegistry(ctx context.Context, component v1alpha1.ZarfCo
 				if err != nil {
 					return fmt.Errorf("unable to add the read only user to the repo %s: %w", repoName, err)
 				}
-				return nil
-			})
-		}, retry.Context(ctx), retry.Attempts(uint(retries)), retry.Delay(500*time.Millisecond))
-		if err != nil {
-			return fmt.Errorf("unable to push repo %s to the Git Server: %w", repoURL, err)
-		}
+			}
+			return nil
+		})
+	}, retry.Context(ctx), retry.Attempts(uint(retries)), retry.Delay(500*time.Millisecond))
+	if err != nil {
+		return fmt.Errorf("unable to push repo %s to the Git Server: %w", repoURL, err)
 	}
 	return nil
 }
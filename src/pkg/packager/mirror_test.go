@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+)
+
+func TestRouteImages(t *testing.T) {
+	t.Parallel()
+
+	registryInfo := state.RegistryInfo{Address: "127.0.0.1:31999"}
+	registries := []state.NamedRegistryInfo{
+		{
+			Name:         "tenant-a",
+			RegistryInfo: state.RegistryInfo{Address: "registry.tenant-a.example.com"},
+			Routes:       state.RoutingRules{Namespaces: []string{"tenant-a"}},
+		},
+		{
+			Name:         "tenant-b",
+			RegistryInfo: state.RegistryInfo{Address: "registry.tenant-b.example.com"},
+			Routes:       state.RoutingRules{SourcePrefixes: []string{"ghcr.io/tenant-b"}},
+		},
+	}
+	components := []v1alpha1.ZarfComponent{
+		{
+			Name:   "shared",
+			Images: []string{"nginx:1.27", "ghcr.io/tenant-b/app:1.0.0"},
+			Charts: []v1alpha1.ZarfChart{{Namespace: "tenant-a"}, {Namespace: "shared"}},
+		},
+		{
+			Name:   "no-namespace",
+			Images: []string{"nginx:1.27", "busybox:1.36"},
+		},
+	}
+
+	pushes, err := routeImages(components, registryInfo, registries)
+	require.NoError(t, err)
+
+	routed := map[string][]string{}
+	for _, push := range pushes {
+		for _, img := range push.images {
+			routed[push.registry.Address] = append(routed[push.registry.Address], img.Reference)
+		}
+	}
+	expected := map[string][]string{
+		"registry.tenant-a.example.com": {"docker.io/library/nginx:1.27", "ghcr.io/tenant-b/app:1.0.0"},
+		"127.0.0.1:31999":               {"docker.io/library/nginx:1.27", "docker.io/library/busybox:1.36"},
+		"registry.tenant-b.example.com": {"ghcr.io/tenant-b/app:1.0.0"},
+	}
+	require.Equal(t, expected, routed)
+}
+
+func TestComponentNamespaces(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{""}, componentNamespaces(v1alpha1.ZarfComponent{}))
+	component := v1alpha1.ZarfComponent{
+		Charts:    []v1alpha1.ZarfChart{{Namespace: "b"}, {Namespace: "a"}},
+		Manifests: []v1alpha1.ZarfManifest{{Namespace: "b"}, {}},
+	}
+	require.Equal(t, []string{"a", "b"}, componentNamespaces(component))
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"sigs.k8s.io/kustomize/api/konfig"
+	krustytypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadOverlays reads the kustomize components in dir that are applied to the charts of a package at deploy time.
+// Each overlay is a kustomize component in the directory <component>/<chart> of dir, where chart is the name of a chart or manifest of the component.
+func LoadOverlays(dir string) ([]state.DeployedOverlay, error) {
+	componentDirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the overlay directory: %w", err)
+	}
+	overlays := []state.DeployedOverlay{}
+	for _, componentDir := range componentDirs {
+		if !componentDir.IsDir() {
+			continue
+		}
+		chartDirs, err := os.ReadDir(filepath.Join(dir, componentDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, chartDir := range chartDirs {
+			if !chartDir.IsDir() {
+				continue
+			}
+			overlayDir := filepath.Join(dir, componentDir.Name(), chartDir.Name())
+			files := map[string][]byte{}
+			err := filepath.WalkDir(overlayDir, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if entry.IsDir() {
+					return nil
+				}
+				rel, err := filepath.Rel(overlayDir, path)
+				if err != nil {
+					return err
+				}
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				files[filepath.ToSlash(rel)] = b
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			overlay := state.DeployedOverlay{
+				Component: componentDir.Name(),
+				Chart:     chartDir.Name(),
+				Files:     files,
+			}
+			if err := validateOverlayFiles(overlay); err != nil {
+				return nil, err
+			}
+			overlays = append(overlays, overlay)
+		}
+	}
+	return overlays, nil
+}
+
+// validateOverlays checks that every overlay is a kustomize component that applies to a chart or manifest of the package.
+// Overlays reused from a previous deployment are read from the cluster, so they are validated on every deploy.
+func validateOverlays(pkg v1alpha1.ZarfPackage, overlays []state.DeployedOverlay) error {
+	var err error
+	for _, overlay := range overlays {
+		if filesErr := validateOverlayFiles(overlay); filesErr != nil {
+			err = errors.Join(err, filesErr)
+			continue
+		}
+		idx := slices.IndexFunc(pkg.Components, func(component v1alpha1.ZarfComponent) bool {
+			return component.Name == overlay.Component
+		})
+		if idx == -1 {
+			err = errors.Join(err, fmt.Errorf("overlay for chart %s has no matching component %s in the package", overlay.Chart, overlay.Component))
+			continue
+		}
+		component := pkg.Components[idx]
+		hasChart := slices.ContainsFunc(component.Charts, func(chart v1alpha1.ZarfChart) bool {
+			return chart.Name == overlay.Chart
+		})
+		hasManifest := slices.ContainsFunc(component.Manifests, func(manifest v1alpha1.ZarfManifest) bool {
+			return manifest.Name == overlay.Chart
+		})
+		if !hasChart && !hasManifest {
+			err = errors.Join(err, fmt.Errorf("overlay has no matching chart or manifest %s in the component %s", overlay.Chart, overlay.Component))
+		}
+	}
+	return err
+}
+
+// validateOverlayFiles checks that the files of an overlay stay within the overlay and define a kustomize component.
+func validateOverlayFiles(overlay state.DeployedOverlay) error {
+	name := path.Join(overlay.Component, overlay.Chart)
+	for file := range overlay.Files {
+		if !filepath.IsLocal(filepath.FromSlash(file)) {
+			return fmt.Errorf("overlay %s contains the file %s outside of the overlay", name, file)
+		}
+	}
+	idx := slices.IndexFunc(konfig.RecognizedKustomizationFileNames(), func(file string) bool {
+		_, ok := overlay.Files[file]
+		return ok
+	})
+	if idx == -1 {
+		return fmt.Errorf("overlay %s does not contain a kustomization file", name)
+	}
+	var kustomization krustytypes.Kustomization
+	if err := yaml.Unmarshal(overlay.Files[konfig.RecognizedKustomizationFileNames()[idx]], &kustomization); err != nil {
+		return fmt.Errorf("unable to read the kustomization of overlay %s: %w", name, err)
+	}
+	if kustomization.Kind != krustytypes.ComponentKind {
+		return fmt.Errorf("overlay %s must be a kustomize %s, not %q", name, krustytypes.ComponentKind, kustomization.Kind)
+	}
+	return nil
+}
+
+// overlayFor returns the files of the overlay applied to a chart or manifest of a component.
+func overlayFor(overlays []state.DeployedOverlay, componentName string, chartName string) map[string][]byte {
+	for _, overlay := range overlays {
+		if overlay.Component == componentName && overlay.Chart == chartName {
+			return overlay.Files
+		}
+	}
+	return nil
+}
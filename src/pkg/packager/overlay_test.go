@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+)
+
+func TestLoadOverlays(t *testing.T) {
+	t.Parallel()
+
+	t.Run("overlays are read per component and chart", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		overlayDir := filepath.Join(dir, "podinfo", "podinfo-chart")
+		require.NoError(t, os.MkdirAll(filepath.Join(overlayDir, "patches"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "kustomization.yaml"), []byte("kind: Component\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "patches", "replicas.yaml"), []byte("replicas: 2\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o600))
+
+		overlays, err := LoadOverlays(dir)
+		require.NoError(t, err)
+		expected := []state.DeployedOverlay{
+			{
+				Component: "podinfo",
+				Chart:     "podinfo-chart",
+				Files: map[string][]byte{
+					"kustomization.yaml":    []byte("kind: Component\n"),
+					"patches/replicas.yaml": []byte("replicas: 2\n"),
+				},
+			},
+		}
+		require.Equal(t, expected, overlays)
+	})
+
+	t.Run("overlays require a kustomization", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		overlayDir := filepath.Join(dir, "podinfo", "podinfo-chart")
+		require.NoError(t, os.MkdirAll(overlayDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "patch.yaml"), []byte("replicas: 2\n"), 0o600))
+
+		_, err := LoadOverlays(dir)
+		require.ErrorContains(t, err, "does not contain a kustomization file")
+	})
+
+	t.Run("overlays must be kustomize components", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		overlayDir := filepath.Join(dir, "podinfo", "podinfo-chart")
+		require.NoError(t, os.MkdirAll(overlayDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(overlayDir, "kustomization.yaml"), []byte("resources:\n  - deployment.yaml\n"), 0o600))
+
+		_, err := LoadOverlays(dir)
+		require.EqualError(t, err, `overlay podinfo/podinfo-chart must be a kustomize Component, not ""`)
+	})
+}
+
+func TestValidateOverlays(t *testing.T) {
+	t.Parallel()
+
+	pkg := v1alpha1.ZarfPackage{
+		Components: []v1alpha1.ZarfComponent{
+			{
+				Name:      "podinfo",
+				Charts:    []v1alpha1.ZarfChart{{Name: "podinfo-chart"}},
+				Manifests: []v1alpha1.ZarfManifest{{Name: "podinfo-manifests"}},
+			},
+		},
+	}
+
+	files := map[string][]byte{"kustomization.yaml": []byte("kind: Component\n")}
+	err := validateOverlays(pkg, []state.DeployedOverlay{
+		{Component: "podinfo", Chart: "podinfo-chart", Files: files},
+		{Component: "podinfo", Chart: "podinfo-manifests", Files: files},
+	})
+	require.NoError(t, err)
+
+	err = validateOverlays(pkg, []state.DeployedOverlay{
+		{Component: "missing", Chart: "podinfo-chart", Files: files},
+		{Component: "podinfo", Chart: "missing", Files: files},
+	})
+	require.EqualError(t, err, "overlay for chart podinfo-chart has no matching component missing in the package\noverlay has no matching chart or manifest missing in the component podinfo")
+
+	// Overlays of a previous deployment are read from the cluster and validated like new ones
+	err = validateOverlays(pkg, []state.DeployedOverlay{
+		{Component: "podinfo", Chart: "podinfo-chart", Files: map[string][]byte{"kustomization.yaml": []byte("kind: Kustomization\n")}},
+		{Component: "podinfo", Chart: "podinfo-chart", Files: map[string][]byte{"patch.yaml": []byte("replicas: 2\n")}},
+		{Component: "podinfo", Chart: "podinfo-chart", Files: map[string][]byte{"kustomization.yaml": []byte("kind: Component\n"), "../manifests.yaml": nil}},
+		{Component: "podinfo", Chart: "podinfo-chart", Files: map[string][]byte{"kustomization.yaml": []byte("kind: Component\n"), "/etc/passwd": nil}},
+	})
+	require.EqualError(t, err, `overlay podinfo/podinfo-chart must be a kustomize Component, not "Kustomization"
+overlay podinfo/podinfo-chart does not contain a kustomization file
+overlay podinfo/podinfo-chart contains the file ../manifests.yaml outside of the overlay
+overlay podinfo/podinfo-chart contains the file /etc/passwd outside of the overlay`)
+}
@@ -0,0 +1,352 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/internal/gitea"
+	"github.com/zarf-dev/zarf/src/internal/packager/helm"
+	ptmpl "github.com/zarf-dev/zarf/src/internal/packager/template"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+)
+
+// RotateCredentialsOptions are optional parameters to RotateCredentials
+type RotateCredentialsOptions struct {
+	// Services to rotate the credentials of, defaults to every service
+	Services []string
+	// Timeout for the Helm upgrades of the registry and agent
+	Timeout time.Duration
+	// Attempts made to verify the rotated credentials before rolling back, defaults to 5
+	VerifyAttempts uint
+}
+
+// rotationStep applies part of a credential rotation to the cluster and knows how to undo it.
+type rotationStep struct {
+	name  string
+	apply func(ctx context.Context) error
+	// undo restores the previous credentials, it is nil for steps that do not change the cluster
+	undo func(ctx context.Context) error
+}
+
+// RotateCredentials regenerates every internal credential in the Zarf state and applies them to the cluster.
+// The internal git server users, artifact token, registry, Zarf managed pull secrets, and agent are updated in
+// order and the new credentials are verified before the state is saved. If any step fails, every completed step
+// is rolled back to the previous credentials.
+func RotateCredentials(ctx context.Context, c *cluster.Cluster, opts RotateCredentialsOptions) (*state.State, error) {
+	l := logger.From(ctx)
+	if len(opts.Services) == 0 {
+		opts.Services = []string{state.RegistryKey, state.GitKey, state.ArtifactKey, state.AgentKey}
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = config.ZarfDefaultTimeout
+	}
+	if opts.VerifyAttempts == 0 {
+		opts.VerifyAttempts = 5
+	}
+
+	oldState, err := c.LoadState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(opts.Services, state.AgentKey) {
+		if err := c.LoadAgentCA(ctx, oldState); err != nil {
+			return nil, err
+		}
+	}
+	newState, err := state.Merge(oldState, state.MergeOptions{Services: opts.Services})
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate the new Zarf credentials: %w", err)
+	}
+	if slices.Contains(opts.Services, state.AgentKey) && newState.AgentInfo.TLS.CertManagerIssuer != nil {
+		if err := c.RenewAgentTLS(ctx, newState); err != nil {
+			return nil, err
+		}
+	}
+	internalGitServerExists, err := c.InternalGitServerExists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The state restored on rollback, the artifact token can not be restored and is replaced instead
+	restoredState := *oldState
+	helmOpts := func(s *state.State) helm.InstallUpgradeOptions {
+		return helm.InstallUpgradeOptions{
+			VariableConfig: ptmpl.GetZarfVariableConfig(ctx, false),
+			State:          s,
+			Cluster:        c,
+			AirgapMode:     true,
+			Timeout:        opts.Timeout,
+		}
+	}
+	verify := func(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+		return retry.Do(func() error {
+			return fn(ctx)
+		}, retry.Context(ctx), retry.Attempts(opts.VerifyAttempts), retry.Delay(time.Second), retry.LastErrorOnly(true),
+			retry.OnRetry(func(n uint, err error) {
+				l.Debug("retrying credential verification", "service", name, "attempt", n+1, "error", err.Error())
+			}))
+	}
+
+	steps := []rotationStep{}
+	verifications := []rotationStep{}
+	if slices.Contains(opts.Services, state.GitKey) && newState.GitServer.IsInternal() && internalGitServerExists {
+		steps = append(steps, rotationStep{
+			name: "git server users",
+			apply: func(ctx context.Context) error {
+				return c.UpdateInternalGitServerSecret(ctx, oldState.GitServer, newState.GitServer)
+			},
+			undo: func(ctx context.Context) error {
+				return c.UpdateInternalGitServerSecret(ctx, newState.GitServer, oldState.GitServer)
+			},
+		})
+		verifications = append(verifications, rotationStep{
+			name: "verify git server users",
+			apply: func(ctx context.Context) error {
+				return verify(ctx, state.GitKey, func(ctx context.Context) error {
+					return verifyGitServerCredentials(ctx, c, newState.GitServer)
+				})
+			},
+		})
+	}
+	if slices.Contains(opts.Services, state.ArtifactKey) && newState.ArtifactServer.IsInternal() && internalGitServerExists {
+		steps = append(steps, artifactTokenStep(c, newState, &restoredState, func(ctx context.Context) (string, error) {
+			return c.UpdateInternalArtifactServerToken(ctx, newState.GitServer)
+		}))
+		verifications = append(verifications, rotationStep{
+			name: "verify artifact server token",
+			apply: func(ctx context.Context) error {
+				return verify(ctx, state.ArtifactKey, func(ctx context.Context) error {
+					return verifyArtifactServerToken(ctx, c, newState.GitServer, newState.ArtifactServer)
+				})
+			},
+		})
+	}
+	if slices.Contains(opts.Services, state.RegistryKey) && newState.RegistryInfo.IsInternal() {
+		steps = append(steps,
+			rotationStep{
+				name: "registry htpasswd",
+				apply: func(ctx context.Context) error {
+					return helm.UpdateZarfRegistryValues(ctx, helmOpts(newState))
+				},
+				undo: func(ctx context.Context) error {
+					return helm.UpdateZarfRegistryValues(ctx, helmOpts(&restoredState))
+				},
+			},
+			rotationStep{
+				name: "registry pull secrets",
+				apply: func(ctx context.Context) error {
+					return c.UpdateZarfManagedImageSecrets(ctx, newState)
+				},
+				undo: func(ctx context.Context) error {
+					return c.UpdateZarfManagedImageSecrets(ctx, &restoredState)
+				},
+			},
+		)
+		verifications = append(verifications, rotationStep{
+			name: "verify registry users",
+			apply: func(ctx context.Context) error {
+				return verify(ctx, state.RegistryKey, func(ctx context.Context) error {
+					return verifyRegistryCredentials(ctx, c, newState.RegistryInfo)
+				})
+			},
+		})
+	}
+	if slices.Contains(opts.Services, state.GitKey) && newState.GitServer.IsInternal() {
+		steps = append(steps, rotationStep{
+			name: "git server pull secrets",
+			apply: func(ctx context.Context) error {
+				return c.UpdateZarfManagedGitSecrets(ctx, newState)
+			},
+			undo: func(ctx context.Context) error {
+				return c.UpdateZarfManagedGitSecrets(ctx, &restoredState)
+			},
+		})
+	}
+	if slices.Contains(opts.Services, state.AgentKey) {
+		steps = append(steps, rotationStep{
+			name: "agent",
+			apply: func(ctx context.Context) error {
+				return helm.UpdateZarfAgentValues(ctx, helmOpts(newState))
+			},
+			undo: func(ctx context.Context) error {
+				return helm.UpdateZarfAgentValues(ctx, helmOpts(&restoredState))
+			},
+		})
+	}
+	steps = append(steps, verifications...)
+	// The state is saved last so it is never rolled back, steps that can not restore the previous credentials save the state they restore
+	steps = append(steps, rotationStep{
+		name: "state",
+		apply: func(ctx context.Context) error {
+			return c.SaveState(ctx, newState, state.AuditOperationUpdateCreds)
+		},
+	})
+
+	if err := runRotation(ctx, steps); err != nil {
+		return nil, err
+	}
+	l.Info("rotated the Zarf credentials", "services", opts.Services)
+	return newState, nil
+}
+
+// artifactTokenStep replaces the artifact server token with one created by createToken.
+// Creating a token revokes the previous one, so on rollback a replacement is created and saved to the state right away
+// as the state still references the revoked token.
+func artifactTokenStep(c *cluster.Cluster, newState, restoredState *state.State, createToken func(ctx context.Context) (string, error)) rotationStep {
+	return rotationStep{
+		name: "artifact server token",
+		apply: func(ctx context.Context) error {
+			token, err := createToken(ctx)
+			if err != nil {
+				return err
+			}
+			newState.ArtifactServer.PushToken = token
+			return nil
+		},
+		undo: func(ctx context.Context) error {
+			token, err := createToken(ctx)
+			if err != nil {
+				return err
+			}
+			restoredState.ArtifactServer.PushToken = token
+			return c.SaveState(ctx, restoredState, state.AuditOperationRollbackCreds)
+		},
+	}
+}
+
+// runRotation applies the steps in order. If a step fails the completed steps are undone in reverse order.
+func runRotation(ctx context.Context, steps []rotationStep) error {
+	l := logger.From(ctx)
+	for i, step := range steps {
+		l.Info("rotating credentials", "step", step.name)
+		err := step.apply(ctx)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("unable to rotate the %s credentials: %w", step.name, err)
+		l.Error("rolling back the credential rotation", "error", err.Error())
+
+		// The rollback must run even if the rotation was canceled
+		rollbackCtx := context.WithoutCancel(ctx)
+		var rollbackErrs []error
+		for j := i - 1; j >= 0; j-- {
+			if steps[j].undo == nil {
+				continue
+			}
+			l.Info("rolling back credentials", "step", steps[j].name)
+			if undoErr := steps[j].undo(rollbackCtx); undoErr != nil {
+				rollbackErrs = append(rollbackErrs, fmt.Errorf("unable to roll back the %s credentials: %w", steps[j].name, undoErr))
+			}
+		}
+		if len(rollbackErrs) > 0 {
+			return errors.Join(append([]error{err}, rollbackErrs...)...)
+		}
+		return fmt.Errorf("%w, the previous credentials were restored", err)
+	}
+	return nil
+}
+
+// verifyRegistryCredentials checks that the registry accepts the push and pull users.
+func verifyRegistryCredentials(ctx context.Context, c *cluster.Cluster, registryInfo state.RegistryInfo) error {
+	endpoint, tunnel, err := c.ConnectToZarfRegistryEndpoint(ctx, registryInfo)
+	if err != nil {
+		return err
+	}
+	if tunnel != nil {
+		defer tunnel.Close()
+	}
+	users := map[string]string{
+		registryInfo.PushUsername: registryInfo.PushPassword,
+		registryInfo.PullUsername: registryInfo.PullPassword,
+	}
+	for username, password := range users {
+		if err := checkBasicAuth(ctx, fmt.Sprintf("http://%s/v2/", endpoint), username, password); err != nil {
+			return fmt.Errorf("the registry did not accept the credentials for %s: %w", username, err)
+		}
+	}
+	return nil
+}
+
+// verifyGitServerCredentials checks that the internal git server accepts the push and pull users.
+func verifyGitServerCredentials(ctx context.Context, c *cluster.Cluster, gitServer state.GitServerInfo) error {
+	return withGitServerTunnel(ctx, c, func(endpoint string) error {
+		users := map[string]string{
+			gitServer.PushUsername: gitServer.PushPassword,
+			gitServer.PullUsername: gitServer.PullPassword,
+		}
+		for username, password := range users {
+			giteaClient, err := gitea.NewClient(endpoint, username, password)
+			if err != nil {
+				return err
+			}
+			if err := giteaClient.CheckAuth(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// verifyArtifactServerToken checks that the internal artifact server accepts the push token.
+func verifyArtifactServerToken(ctx context.Context, c *cluster.Cluster, gitServer state.GitServerInfo, artifactServer state.ArtifactServerInfo) error {
+	return withGitServerTunnel(ctx, c, func(endpoint string) error {
+		giteaClient, err := gitea.NewClient(endpoint, gitServer.PushUsername, artifactServer.PushToken)
+		if err != nil {
+			return err
+		}
+		return giteaClient.CheckAuth(ctx)
+	})
+}
+
+func withGitServerTunnel(ctx context.Context, c *cluster.Cluster, fn func(endpoint string) error) error {
+	tunnel, err := c.NewTunnel(state.ZarfNamespaceName, cluster.SvcResource, cluster.ZarfGitServerName, "", 0, cluster.ZarfGitServerPort)
+	if err != nil {
+		return err
+	}
+	_, err = tunnel.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Close()
+	// tunnel is created with the default listenAddress - there will only be one endpoint until otherwise supported
+	tunnelURLs := tunnel.HTTPEndpoints()
+	if len(tunnelURLs) == 0 {
+		return errors.New("no tunnel endpoints found")
+	}
+	return tunnel.Wrap(func() error {
+		return fn(tunnelURLs[0])
+	})
+}
+
+// checkBasicAuth checks that the endpoint responds with a 200 to a request with the given credentials.
+func checkBasicAuth(ctx context.Context, endpoint, username, password string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(username, password)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, resp.Body.Close())
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/cluster"
+	"github.com/zarf-dev/zarf/src/pkg/state"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunRotation(t *testing.T) {
+	t.Parallel()
+
+	newStep := func(name string, calls *[]string, applyErr, undoErr error, undoable bool) rotationStep {
+		step := rotationStep{
+			name: name,
+			apply: func(_ context.Context) error {
+				*calls = append(*calls, "apply "+name)
+				return applyErr
+			},
+		}
+		if undoable {
+			step.undo = func(_ context.Context) error {
+				*calls = append(*calls, "undo "+name)
+				return undoErr
+			}
+		}
+		return step
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		calls := []string{}
+		err := runRotation(context.Background(), []rotationStep{
+			newStep("git", &calls, nil, nil, true),
+			newStep("registry", &calls, nil, nil, true),
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"apply git", "apply registry"}, calls)
+	})
+
+	t.Run("completed steps are undone in reverse order", func(t *testing.T) {
+		t.Parallel()
+
+		calls := []string{}
+		err := runRotation(context.Background(), []rotationStep{
+			newStep("git", &calls, nil, nil, true),
+			newStep("registry", &calls, nil, nil, true),
+			newStep("verify", &calls, nil, nil, false),
+			newStep("state", &calls, errors.New("conflict"), nil, true),
+			newStep("never", &calls, nil, nil, true),
+		})
+		require.EqualError(t, err, "unable to rotate the state credentials: conflict, the previous credentials were restored")
+		require.Equal(t, []string{"apply git", "apply registry", "apply verify", "apply state", "undo registry", "undo git"}, calls)
+	})
+
+	t.Run("rollback failures are reported", func(t *testing.T) {
+		t.Parallel()
+
+		calls := []string{}
+		err := runRotation(context.Background(), []rotationStep{
+			newStep("git", &calls, nil, nil, true),
+			newStep("registry", &calls, nil, errors.New("timeout"), true),
+			newStep("agent", &calls, errors.New("not found"), nil, true),
+		})
+		require.ErrorContains(t, err, "unable to rotate the agent credentials: not found")
+		require.ErrorContains(t, err, "unable to roll back the registry credentials: timeout")
+		require.Equal(t, []string{"apply git", "apply registry", "apply agent", "undo registry", "undo git"}, calls)
+	})
+
+	t.Run("rollback runs after cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		undone := false
+		err := runRotation(ctx, []rotationStep{
+			{
+				name:  "git",
+				apply: func(_ context.Context) error { return nil },
+				undo: func(ctx context.Context) error {
+					undone = true
+					return ctx.Err()
+				},
+			},
+			{
+				name: "registry",
+				apply: func(_ context.Context) error {
+					cancel()
+					return context.Canceled
+				},
+			},
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.True(t, undone)
+	})
+}
+
+func TestRotationRestoresArtifactToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := &cluster.Cluster{Clientset: fake.NewClientset()}
+	oldState := &state.State{
+		Distro:         "test",
+		GitServer:      state.GitServerInfo{PushUsername: "zarf-git-user", PushPassword: "git-push"},
+		ArtifactServer: state.ArtifactServerInfo{PushUsername: "zarf-git-user", PushToken: "old-token"},
+	}
+	require.NoError(t, c.SaveState(ctx, oldState, state.AuditOperationInit))
+
+	newState := *oldState
+	newState.GitServer.PushPassword = "rotated-git-push"
+	restoredState := *oldState
+	tokens := []string{"rotated-token", "replacement-token"}
+	createToken := func(_ context.Context) (string, error) {
+		token := tokens[0]
+		tokens = tokens[1:]
+		return token, nil
+	}
+	err := runRotation(ctx, []rotationStep{
+		artifactTokenStep(c, &newState, &restoredState, createToken),
+		{
+			name:  "registry htpasswd",
+			apply: func(_ context.Context) error { return errors.New("timeout") },
+		},
+		{
+			name: "state",
+			apply: func(ctx context.Context) error {
+				return c.SaveState(ctx, &newState, state.AuditOperationUpdateCreds)
+			},
+		},
+	})
+	require.EqualError(t, err, "unable to rotate the registry htpasswd credentials: timeout, the previous credentials were restored")
+
+	// The revoked token is replaced in the saved state while the other credentials are kept
+	saved, err := c.LoadState(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "replacement-token", saved.ArtifactServer.PushToken)
+	require.Equal(t, "git-push", saved.GitServer.PushPassword)
+	entries, err := c.GetAuditEntries(ctx, state.AuditFilter{Operation: state.AuditOperationRollbackCreds})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	updates, err := c.GetAuditEntries(ctx, state.AuditFilter{Operation: state.AuditOperationUpdateCreds})
+	require.NoError(t, err)
+	require.Empty(t, updates)
+}
+
+func TestCheckBasicAuth(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "zarf-push" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	require.NoError(t, checkBasicAuth(context.Background(), srv.URL+"/v2/", "zarf-push", "secret"))
+	err := checkBasicAuth(context.Background(), srv.URL+"/v2/", "zarf-push", "old")
+	require.EqualError(t, err, "status code 401")
+}
@@ -9,52 +9,212 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"github.com/defenseunicorns/pkg/helpers/v2"
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/parser"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/internal/packager/images"
 	"github.com/zarf-dev/zarf/src/pkg/logger"
 	"github.com/zarf-dev/zarf/src/pkg/packager/layout"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
 )
 
+// UpdateImagesOptions are the optional parameters to UpdateImages
+type UpdateImagesOptions struct {
+	// Pin resolves image tags to the digest of their manifest and writes them as repo:tag@digest
+	Pin bool
+	// Prune removes the images of components where the scan found no images, which are otherwise left as they are
+	Prune bool
+	// Architecture of the image manifests to pin, defaults to the package architecture
+	Architecture string
+	// CachePath is the Zarf cache used to resolve digests when the registry cannot be reached
+	CachePath string
+	RemoteOptions
+}
+
+// ImageChangeType is the kind of change made to the images of a component.
+type ImageChangeType string
+
+// The kinds of changes made to the images of a component.
+const (
+	ImageAdded   ImageChangeType = "added"
+	ImageUpdated ImageChangeType = "updated"
+	ImageRemoved ImageChangeType = "removed"
+)
+
+// ImageChange is a change made to the images of a component by UpdateImages.
+type ImageChange struct {
+	ComponentName string
+	Type          ImageChangeType
+	Image         string
+	// Previous is the image that was replaced for updated images
+	Previous string
+}
+
 // UpdateImages updates the images field for components in a zarf.yaml
-func UpdateImages(ctx context.Context, baseDir string, imagesScans []ComponentImageScan) error {
+func UpdateImages(ctx context.Context, baseDir string, imagesScans []ComponentImageScan, opts UpdateImagesOptions) ([]ImageChange, error) {
 	l := logger.From(ctx)
 	packageConfigFile := filepath.Join(baseDir, layout.ZarfYAML)
 
 	packageConfigBytes, err := os.ReadFile(packageConfigFile)
 	if err != nil {
-		return fmt.Errorf("failed to read %s: %w", packageConfigFile, err)
+		return nil, fmt.Errorf("failed to read %s: %w", packageConfigFile, err)
 	}
 
 	zarfPackage := v1alpha1.ZarfPackage{}
 	if err := yaml.Unmarshal(packageConfigBytes, &zarfPackage); err != nil {
-		return fmt.Errorf("failed to parse zarf.yaml: %w", err)
+		return nil, fmt.Errorf("failed to parse zarf.yaml: %w", err)
 	}
 
+	if opts.Pin {
+		resolveCfg := images.ResolveConfig{
+			Arch:                  config.GetArch(opts.Architecture, zarfPackage.Metadata.Architecture),
+			PlainHTTP:             opts.PlainHTTP,
+			InsecureSkipTLSVerify: opts.InsecureSkipTLSVerify,
+		}
+		if opts.CachePath != "" {
+			resolveCfg.CacheDirectory = filepath.Join(opts.CachePath, layout.ImagesDir)
+		}
+		imagesScans = pinImageScans(ctx, imagesScans, resolveCfg)
+	}
+
+	imagesScans, changes := mergeImageScans(zarfPackage, imagesScans, opts.Pin, opts.Prune)
+
 	if !updateNeeded(zarfPackage, imagesScans) {
 		l.Info("no update needed, images are already up to date", "path", packageConfigFile)
-		return nil
+		return nil, nil
 	}
 
 	astFile, err := parser.ParseBytes(packageConfigBytes, parser.ParseComments)
 	if err != nil {
-		return fmt.Errorf("failed to parse %s as AST: %w", packageConfigFile, err)
+		return nil, fmt.Errorf("failed to parse %s as AST: %w", packageConfigFile, err)
 	}
 
 	updatedZarfYaml, err := createUpdate(zarfPackage, imagesScans, astFile)
 	if err != nil {
-		return fmt.Errorf("failed to create update: %w", err)
+		return nil, fmt.Errorf("failed to create update: %w", err)
 	}
 
 	if err := os.WriteFile(packageConfigFile, []byte(updatedZarfYaml), helpers.ReadAllWriteUser); err != nil {
-		return fmt.Errorf("failed to write updated %s: %w", packageConfigFile, err)
+		return nil, fmt.Errorf("failed to write updated %s: %w", packageConfigFile, err)
 	}
 
 	l.Info("successfully updated images", "path", packageConfigFile)
-	return nil
+	return changes, nil
+}
+
+// pinImageScans resolves the tagged images found by a scan to repo:tag@digest.
+// Images that cannot be resolved are left unpinned.
+func pinImageScans(ctx context.Context, imagesScans []ComponentImageScan, cfg images.ResolveConfig) []ComponentImageScan {
+	l := logger.From(ctx)
+	resolved := map[string]string{}
+	pin := func(list []string) []string {
+		pinned := make([]string, 0, len(list))
+		for _, image := range list {
+			if strings.Contains(image, "@") {
+				pinned = append(pinned, image)
+				continue
+			}
+			if _, ok := resolved[image]; !ok {
+				digest, err := images.Resolve(ctx, image, cfg)
+				if err != nil {
+					l.Warn("unable to pin image to a digest", "image", image, "error", err)
+					resolved[image] = image
+				} else {
+					resolved[image] = fmt.Sprintf("%s@%s", image, digest)
+				}
+			}
+			pinned = append(pinned, resolved[image])
+		}
+		return pinned
+	}
+	pinnedScans := make([]ComponentImageScan, 0, len(imagesScans))
+	for _, scan := range imagesScans {
+		scan.Matches = pin(scan.Matches)
+		scan.PotentialMatches = pin(scan.PotentialMatches)
+		pinnedScans = append(pinnedScans, scan)
+	}
+	return pinnedScans
+}
+
+// mergeImageScans replaces the images listed by each component with the images found by a scan and reports the changes.
+// Components without any found images are left as they are unless prune is set, in which case their images are removed.
+// When pin is set a listed repo:tag@digest is kept if the scan found the same repo:tag, so pins are not lost when an image cannot be resolved.
+func mergeImageScans(zarfPackage v1alpha1.ZarfPackage, imagesScans []ComponentImageScan, pin, prune bool) ([]ComponentImageScan, []ImageChange) {
+	componentImages := make(map[string][]string, len(zarfPackage.Components))
+	for _, component := range zarfPackage.Components {
+		componentImages[component.Name] = component.Images
+	}
+
+	changes := []ImageChange{}
+	mergedScans := make([]ComponentImageScan, 0, len(imagesScans))
+	for _, scan := range imagesScans {
+		existing := componentImages[scan.ComponentName]
+		found := slices.Concat(scan.Matches, scan.PotentialMatches, scan.CosignArtifacts)
+		if len(found) == 0 && !prune {
+			continue
+		}
+
+		merged := []string{}
+		for _, image := range found {
+			if pin && !strings.Contains(image, "@") {
+				if idx := slices.IndexFunc(existing, func(e string) bool { return stripImageDigest(e) == image }); idx >= 0 {
+					image = existing[idx]
+				}
+			}
+			if !slices.Contains(merged, image) {
+				merged = append(merged, image)
+			}
+		}
+
+		replaced := map[string]bool{}
+		for _, image := range merged {
+			if slices.Contains(existing, image) {
+				continue
+			}
+			change := ImageChange{ComponentName: scan.ComponentName, Type: ImageAdded, Image: image}
+			for _, previous := range existing {
+				if !replaced[previous] && !slices.Contains(merged, previous) && imageRepositoryKey(previous) == imageRepositoryKey(image) {
+					replaced[previous] = true
+					change.Type = ImageUpdated
+					change.Previous = previous
+					break
+				}
+			}
+			changes = append(changes, change)
+		}
+		for _, image := range existing {
+			if replaced[image] || slices.Contains(merged, image) {
+				continue
+			}
+			changes = append(changes, ImageChange{ComponentName: scan.ComponentName, Type: ImageRemoved, Image: image})
+		}
+		mergedScans = append(mergedScans, ComponentImageScan{ComponentName: scan.ComponentName, Matches: merged})
+	}
+	return mergedScans, changes
+}
+
+// imageRepositoryKey returns the normalized repository of an image, keeping cosign artifacts of a repository distinct from its images.
+func imageRepositoryKey(image string) string {
+	ref, err := transform.ParseImageRef(image)
+	if err != nil {
+		return image
+	}
+	for _, suffix := range []string{".sig", ".att", ".sbom"} {
+		if strings.HasSuffix(ref.Tag, suffix) {
+			return ref.Name + suffix
+		}
+	}
+	return ref.Name
+}
+
+func stripImageDigest(image string) string {
+	name, _, _ := strings.Cut(image, "@")
+	return name
 }
 
 func createUpdate(zarfPackage v1alpha1.ZarfPackage, imagesScans []ComponentImageScan, astFile *ast.File) (string, error) {
@@ -65,10 +225,6 @@ func createUpdate(zarfPackage v1alpha1.ZarfPackage, imagesScans []ComponentImage
 	}
 
 	for _, scan := range imagesScans {
-		if len(scan.Matches)+len(scan.PotentialMatches)+len(scan.CosignArtifacts) == 0 {
-			continue
-		}
-
 		componentIndex, exists := componentToIndex[scan.ComponentName]
 		if !exists {
 			continue
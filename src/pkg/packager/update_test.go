@@ -4,11 +4,15 @@
 package packager
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/goccy/go-yaml/parser"
 	"github.com/stretchr/testify/require"
 	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/pkg/packager/layout"
+	"github.com/zarf-dev/zarf/src/test/testutil"
 )
 
 func TestUpdateNeeded(t *testing.T) {
@@ -235,3 +239,150 @@ components:
 		})
 	}
 }
+
+func TestMergeImageScans(t *testing.T) {
+	t.Parallel()
+
+	zarfPackage := v1alpha1.ZarfPackage{
+		Components: []v1alpha1.ZarfComponent{
+			{
+				Name: "podinfo",
+				Images: []string{
+					"ghcr.io/stefanprodan/podinfo:6.3.0",
+					"ghcr.io/stefanprodan/podinfo:sha256-4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48.sig",
+					"docker.io/library/redis:7.2@sha256:5b6c2e97055cfe69fe8996f48b53db039c136210dbc98c5631864a9e573d0e20",
+					"busybox:1.36",
+				},
+			},
+		},
+	}
+	matches := []string{
+		"ghcr.io/stefanprodan/podinfo:6.4.0",
+		"docker.io/library/redis:7.2",
+		"nginx:1.27",
+	}
+
+	tests := []struct {
+		name            string
+		matches         []string
+		pin             bool
+		prune           bool
+		expectedImages  []string
+		expectedChanges []ImageChange
+	}{
+		{
+			name:    "replaces the listed images",
+			matches: matches,
+			expectedImages: []string{
+				"ghcr.io/stefanprodan/podinfo:6.4.0",
+				"docker.io/library/redis:7.2",
+				"nginx:1.27",
+			},
+			expectedChanges: []ImageChange{
+				{ComponentName: "podinfo", Type: ImageUpdated, Image: "ghcr.io/stefanprodan/podinfo:6.4.0", Previous: "ghcr.io/stefanprodan/podinfo:6.3.0"},
+				{ComponentName: "podinfo", Type: ImageUpdated, Image: "docker.io/library/redis:7.2", Previous: "docker.io/library/redis:7.2@sha256:5b6c2e97055cfe69fe8996f48b53db039c136210dbc98c5631864a9e573d0e20"},
+				{ComponentName: "podinfo", Type: ImageAdded, Image: "nginx:1.27"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "ghcr.io/stefanprodan/podinfo:sha256-4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48.sig"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "busybox:1.36"},
+			},
+		},
+		{
+			name:    "keeps pins of images that could not be resolved",
+			matches: matches,
+			pin:     true,
+			expectedImages: []string{
+				"ghcr.io/stefanprodan/podinfo:6.4.0",
+				"docker.io/library/redis:7.2@sha256:5b6c2e97055cfe69fe8996f48b53db039c136210dbc98c5631864a9e573d0e20",
+				"nginx:1.27",
+			},
+			expectedChanges: []ImageChange{
+				{ComponentName: "podinfo", Type: ImageUpdated, Image: "ghcr.io/stefanprodan/podinfo:6.4.0", Previous: "ghcr.io/stefanprodan/podinfo:6.3.0"},
+				{ComponentName: "podinfo", Type: ImageAdded, Image: "nginx:1.27"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "ghcr.io/stefanprodan/podinfo:sha256-4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48.sig"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "busybox:1.36"},
+			},
+		},
+		{
+			name: "replaces pins with pinned images",
+			matches: []string{
+				"ghcr.io/stefanprodan/podinfo:6.3.0@sha256:4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48",
+				"docker.io/library/redis:7.2@sha256:0e3d8a1b1c2f9f6e1e5b7a2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f5a6",
+			},
+			pin: true,
+			expectedImages: []string{
+				"ghcr.io/stefanprodan/podinfo:6.3.0@sha256:4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48",
+				"docker.io/library/redis:7.2@sha256:0e3d8a1b1c2f9f6e1e5b7a2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f5a6",
+			},
+			expectedChanges: []ImageChange{
+				{ComponentName: "podinfo", Type: ImageUpdated, Image: "ghcr.io/stefanprodan/podinfo:6.3.0@sha256:4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48", Previous: "ghcr.io/stefanprodan/podinfo:6.3.0"},
+				{ComponentName: "podinfo", Type: ImageUpdated, Image: "docker.io/library/redis:7.2@sha256:0e3d8a1b1c2f9f6e1e5b7a2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f5a6", Previous: "docker.io/library/redis:7.2@sha256:5b6c2e97055cfe69fe8996f48b53db039c136210dbc98c5631864a9e573d0e20"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "ghcr.io/stefanprodan/podinfo:sha256-4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48.sig"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "busybox:1.36"},
+			},
+		},
+		{
+			name:           "prunes components without found images",
+			prune:          true,
+			expectedImages: []string{},
+			expectedChanges: []ImageChange{
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "ghcr.io/stefanprodan/podinfo:6.3.0"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "ghcr.io/stefanprodan/podinfo:sha256-4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48.sig"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "docker.io/library/redis:7.2@sha256:5b6c2e97055cfe69fe8996f48b53db039c136210dbc98c5631864a9e573d0e20"},
+				{ComponentName: "podinfo", Type: ImageRemoved, Image: "busybox:1.36"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			imageScans := []ComponentImageScan{{ComponentName: "podinfo", Matches: tt.matches}}
+			merged, changes := mergeImageScans(zarfPackage, imageScans, tt.pin, tt.prune)
+			require.Len(t, merged, 1)
+			require.Equal(t, tt.expectedImages, merged[0].Matches)
+			require.Equal(t, tt.expectedChanges, changes)
+		})
+	}
+
+	// Components without found images are left as they are
+	merged, changes := mergeImageScans(zarfPackage, []ComponentImageScan{{ComponentName: "podinfo"}}, false, false)
+	require.Empty(t, merged)
+	require.Empty(t, changes)
+}
+
+func TestUpdateImages(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.TestContext(t)
+	baseDir := t.TempDir()
+	zarfYAML := `kind: ZarfPackageConfig
+metadata:
+  name: test
+components:
+  - name: podinfo
+    images:
+      - ghcr.io/stefanprodan/podinfo:6.3.0
+      - ghcr.io/stefanprodan/podinfo:sha256-4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48.sig
+      - busybox:1.36
+`
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, layout.ZarfYAML), []byte(zarfYAML), 0o644))
+
+	// Images that are no longer found are removed without --prune
+	imageScans := []ComponentImageScan{{ComponentName: "podinfo", Matches: []string{"ghcr.io/stefanprodan/podinfo:6.4.0"}}}
+	changes, err := UpdateImages(ctx, baseDir, imageScans, UpdateImagesOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []ImageChange{
+		{ComponentName: "podinfo", Type: ImageUpdated, Image: "ghcr.io/stefanprodan/podinfo:6.4.0", Previous: "ghcr.io/stefanprodan/podinfo:6.3.0"},
+		{ComponentName: "podinfo", Type: ImageRemoved, Image: "ghcr.io/stefanprodan/podinfo:sha256-4c75ca6c24ceb1f1bd7e935d9287a93e4f925c512f206763ec5a47de3ef3ff48.sig"},
+		{ComponentName: "podinfo", Type: ImageRemoved, Image: "busybox:1.36"},
+	}, changes)
+	b, err := os.ReadFile(filepath.Join(baseDir, layout.ZarfYAML))
+	require.NoError(t, err)
+	require.Equal(t, `kind: ZarfPackageConfig
+metadata:
+  name: test
+components:
+  - name: podinfo
+    images:
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+`, string(b))
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	goyaml "github.com/goccy/go-yaml"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+	"github.com/zarf-dev/zarf/src/config"
+	"github.com/zarf-dev/zarf/src/internal/packager/images"
+	"github.com/zarf-dev/zarf/src/pkg/logger"
+	"github.com/zarf-dev/zarf/src/pkg/packager/layout"
+	"github.com/zarf-dev/zarf/src/pkg/packager/load"
+)
+
+// VerifyBuildOptions are the optional parameters to VerifyBuild
+type VerifyBuildOptions struct {
+	SetVariables            map[string]string
+	OCIConcurrency          int
+	CachePath               string
+	PublicKeyPath           string
+	SkipSignatureValidation bool
+	// SkipVersionCheck skips version requirement validation
+	SkipVersionCheck bool
+	// applicable when the package is pulled from an OCI registry
+	RemoteOptions
+}
+
+// BuildDifference is a file that differs between a package and its rebuild.
+type BuildDifference struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// VerifyBuild rebuilds the package definition at packagePath the same way the reproducible package at source was built
+// and returns the files that differ between the two.
+func VerifyBuild(ctx context.Context, packagePath string, source string, opts VerifyBuildOptions) (_ []BuildDifference, err error) {
+	l := logger.From(ctx)
+
+	expectedLayout, err := LoadPackage(ctx, source, LoadOptions{
+		PublicKeyPath:           opts.PublicKeyPath,
+		SkipSignatureValidation: opts.SkipSignatureValidation,
+		OCIConcurrency:          opts.OCIConcurrency,
+		CachePath:               opts.CachePath,
+		RemoteOptions:           opts.RemoteOptions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the package %s: %w", source, err)
+	}
+	defer func() {
+		err = errors.Join(err, expectedLayout.Cleanup())
+	}()
+	expected := expectedLayout.Pkg
+
+	if !expected.Build.Reproducible {
+		return nil, fmt.Errorf("the package %s was not created with --reproducible", expected.Metadata.Name)
+	}
+	if expected.Build.Differential {
+		return nil, fmt.Errorf("the differential package %s cannot be verified", expected.Metadata.Name)
+	}
+	if expected.Build.Version != config.CLIVersion {
+		l.Warn("the package was created with a different version of Zarf, the rebuild may differ", "expected", expected.Build.Version, "actual", config.CLIVersion)
+	}
+	buildTime, err := time.Parse(v1alpha1.BuildTimestampFormat, expected.Build.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the build timestamp of the package: %w", err)
+	}
+
+	pkg, err := load.PackageDefinition(ctx, packagePath, load.DefinitionOptions{
+		Flavor:           expected.Build.Flavor,
+		SetVariables:     opts.SetVariables,
+		CachePath:        opts.CachePath,
+		SkipVersionCheck: opts.SkipVersionCheck,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if pkg.Metadata.Architecture != expected.Build.Architecture {
+		return nil, fmt.Errorf("the package was built for the %s architecture but would be rebuilt for %s, set --architecture to match", expected.Build.Architecture, pkg.Metadata.Architecture)
+	}
+
+	registryOverrides := []images.RegistryOverride{}
+	for source, override := range expected.Build.RegistryOverrides {
+		registryOverrides = append(registryOverrides, images.RegistryOverride{Source: source, Override: override})
+	}
+	slices.SortFunc(registryOverrides, func(a, b images.RegistryOverride) int {
+		return strings.Compare(a.Source, b.Source)
+	})
+
+	l.Info("rebuilding package", "name", pkg.Metadata.Name, "timestamp", expected.Build.Timestamp)
+	actualLayout, err := layout.AssemblePackage(ctx, pkg, packagePath, layout.AssembleOptions{
+		SkipSBOM:          !expectedLayout.ContainsSBOM(),
+		OCIConcurrency:    opts.OCIConcurrency,
+		Flavor:            expected.Build.Flavor,
+		RegistryOverrides: registryOverrides,
+		CachePath:         opts.CachePath,
+		Reproducible:      true,
+		SourceDateEpoch:   &buildTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, actualLayout.Cleanup())
+	}()
+
+	return compareBuilds(expectedLayout, actualLayout)
+}
+
+// compareBuilds compares the checksums and package definitions of two package layouts.
+func compareBuilds(expectedLayout, actualLayout *layout.PackageLayout) ([]BuildDifference, error) {
+	expectedSums, err := readChecksums(expectedLayout.DirPath())
+	if err != nil {
+		return nil, err
+	}
+	actualSums, err := readChecksums(actualLayout.DirPath())
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := []BuildDifference{}
+	for path, sum := range expectedSums {
+		if actualSums[path] != sum {
+			diffs = append(diffs, BuildDifference{Path: path, Expected: sum, Actual: actualSums[path]})
+		}
+	}
+	for path, sum := range actualSums {
+		if _, ok := expectedSums[path]; !ok {
+			diffs = append(diffs, BuildDifference{Path: path, Actual: sum})
+		}
+	}
+
+	// The signature is not reproducible, so the package definitions are compared as if neither was signed.
+	expectedPkg := expectedLayout.Pkg
+	actualPkg := actualLayout.Pkg
+	expectedPkg.Build.Signed = nil
+	actualPkg.Build.Signed = nil
+	expectedYAML, err := goyaml.Marshal(expectedPkg)
+	if err != nil {
+		return nil, err
+	}
+	actualYAML, err := goyaml.Marshal(actualPkg)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(expectedYAML, actualYAML) {
+		diffs = append(diffs, BuildDifference{
+			Path:     layout.ZarfYAML,
+			Expected: expectedPkg.Metadata.AggregateChecksum,
+			Actual:   actualPkg.Metadata.AggregateChecksum,
+		})
+	}
+
+	slices.SortFunc(diffs, func(a, b BuildDifference) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return diffs, nil
+}
+
+// readChecksums returns the checksum of each file recorded in the checksums.txt of a package.
+func readChecksums(dirPath string) (map[string]string, error) {
+	b, err := os.ReadFile(filepath.Join(dirPath, layout.Checksums))
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if line == "" {
+			continue
+		}
+		sum, path, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid checksum entry %q", line)
+		}
+		sums[path] = sum
+	}
+	return sums, nil
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package packager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/lint"
+	"github.com/zarf-dev/zarf/src/test/testutil"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestVerifyBuild(t *testing.T) {
+	lint.ZarfSchema = testutil.LoadSchema(t, "../../../zarf.schema.json")
+	ctx := testutil.TestContext(t)
+
+	packagePath := t.TempDir()
+	for _, name := range []string{"zarf.yaml", "data.txt", "configmap.yaml"} {
+		b, err := os.ReadFile(filepath.Join("testdata", "verify-build", name))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(packagePath, name), b, 0o600))
+	}
+
+	sourceDateEpoch := time.Unix(1700000000, 0)
+	createOpts := CreateOptions{
+		Reproducible:    true,
+		SourceDateEpoch: &sourceDateEpoch,
+		CachePath:       t.TempDir(),
+	}
+	first, err := Create(ctx, packagePath, t.TempDir(), createOpts)
+	require.NoError(t, err)
+	second, err := Create(ctx, packagePath, t.TempDir(), createOpts)
+	require.NoError(t, err)
+	firstSum, err := helpers.GetSHA256OfFile(first)
+	require.NoError(t, err)
+	secondSum, err := helpers.GetSHA256OfFile(second)
+	require.NoError(t, err)
+	require.Equal(t, firstSum, secondSum)
+
+	verifyOpts := VerifyBuildOptions{
+		SkipSignatureValidation: true,
+		CachePath:               t.TempDir(),
+	}
+	diffs, err := VerifyBuild(ctx, packagePath, first, verifyOpts)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(packagePath, "data.txt"), []byte("changed\n"), 0o600))
+	diffs, err = VerifyBuild(ctx, packagePath, first, verifyOpts)
+	require.NoError(t, err)
+	paths := []string{}
+	for _, diff := range diffs {
+		paths = append(paths, diff.Path)
+	}
+	require.Contains(t, paths, "components/files.tar")
+	require.Contains(t, paths, "zarf.yaml")
+
+	nonReproducible, err := Create(ctx, packagePath, t.TempDir(), CreateOptions{CachePath: t.TempDir(), SkipSBOM: true})
+	require.NoError(t, err)
+	_, err = VerifyBuild(ctx, packagePath, nonReproducible, verifyOpts)
+	require.ErrorContains(t, err, "was not created with --reproducible")
+}
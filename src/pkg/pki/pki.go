@@ -6,11 +6,15 @@ package pki
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
@@ -29,9 +33,22 @@ const org = "Zarf Cluster"
 // 13 months is the max length allowed by browsers.
 const validFor = time.Hour * 24 * 375
 
+// The fraction of a certificate's validity that must remain before it should be rotated.
+const rotationThreshold = 0.2
+
 // provides a simple way to mock time.Now() in tests
 var now = time.Now
 
+// KeyAlgorithm is the algorithm used to generate private keys.
+type KeyAlgorithm string
+
+const (
+	// KeyAlgorithmRSA generates 2048 bit RSA keys.
+	KeyAlgorithmRSA KeyAlgorithm = "rsa"
+	// KeyAlgorithmECDSA generates P-256 ECDSA keys.
+	KeyAlgorithmECDSA KeyAlgorithm = "ecdsa"
+)
+
 // GeneratedPKI is a struct for storing generated PKI data.
 type GeneratedPKI struct {
 	CA   []byte `json:"ca"`
@@ -39,37 +56,196 @@ type GeneratedPKI struct {
 	Key  []byte `json:"key"`
 }
 
+// Options configures how a PKI is generated.
+type Options struct {
+	// Algorithm of the generated keys, defaults to RSA
+	KeyAlgorithm KeyAlgorithm
+	// PEM encoded CA certificate used to sign the server certificate instead of an ephemeral CA
+	CACert []byte
+	// PEM encoded private key of the CA certificate
+	CAKey []byte
+}
+
 // GeneratePKI create a CA and signed server keypair.
 func GeneratePKI(host string, dnsNames ...string) (GeneratedPKI, error) {
+	return GeneratePKIWithOptions(Options{}, host, dnsNames...)
+}
+
+// GeneratePKIWithOptions creates a signed server keypair using the given options.
+// If a CA is provided it signs the server certificate, otherwise an ephemeral CA is created.
+func GeneratePKIWithOptions(opts Options, host string, dnsNames ...string) (GeneratedPKI, error) {
 	notAfter := now().Add(validFor)
-	return generatePKI(host, notAfter, dnsNames...)
+	return generatePKI(opts, host, notAfter, dnsNames...)
 }
 
-func generatePKI(host string, notAfter time.Time, dnsNames ...string) (GeneratedPKI, error) {
-	results := GeneratedPKI{}
-	ca, caKey, err := generateCA(notAfter)
+func generatePKI(opts Options, host string, notAfter time.Time, dnsNames ...string) (GeneratedPKI, error) {
+	ca, caKey, notAfter, err := loadOrGenerateCA(opts, notAfter)
 	if err != nil {
-		return GeneratedPKI{}, fmt.Errorf("unable to generate the ephemeral CA: %w", err)
+		return GeneratedPKI{}, err
 	}
-	hostCert, hostKey, err := generateCert(host, ca, caKey, notAfter, dnsNames...)
+	hostCert, hostKey, err := generateCert(opts.KeyAlgorithm, host, ca, caKey, notAfter, dnsNames...)
 	if err != nil {
 		return GeneratedPKI{}, fmt.Errorf("unable to generate the cert for %s: %w", host, err)
 	}
+	return encodePKI(ca, hostCert, hostKey)
+}
+
+// GenerateMutualTLS creates a server keypair and a client keypair signed by the same CA for mutual TLS.
+// If a CA is provided it signs both certificates, otherwise an ephemeral CA is created.
+func GenerateMutualTLS(opts Options, host, clientName string, dnsNames ...string) (server GeneratedPKI, client GeneratedPKI, err error) {
+	ca, caKey, notAfter, err := loadOrGenerateCA(opts, now().Add(validFor))
+	if err != nil {
+		return GeneratedPKI{}, GeneratedPKI{}, err
+	}
+	serverCert, serverKey, err := generateCert(opts.KeyAlgorithm, host, ca, caKey, notAfter, dnsNames...)
+	if err != nil {
+		return GeneratedPKI{}, GeneratedPKI{}, fmt.Errorf("unable to generate the cert for %s: %w", host, err)
+	}
+	clientCert, clientKey, err := generateClientCert(opts.KeyAlgorithm, clientName, ca, caKey, notAfter)
+	if err != nil {
+		return GeneratedPKI{}, GeneratedPKI{}, fmt.Errorf("unable to generate the client cert for %s: %w", clientName, err)
+	}
+	server, err = encodePKI(ca, serverCert, serverKey)
+	if err != nil {
+		return GeneratedPKI{}, GeneratedPKI{}, err
+	}
+	client, err = encodePKI(ca, clientCert, clientKey)
+	if err != nil {
+		return GeneratedPKI{}, GeneratedPKI{}, err
+	}
+	return server, client, nil
+}
+
+// loadOrGenerateCA returns the provided CA or a new ephemeral CA, along with the latest expiry of the certificates it signs.
+func loadOrGenerateCA(opts Options, notAfter time.Time) (*x509.Certificate, crypto.Signer, time.Time, error) {
+	if err := opts.KeyAlgorithm.validate(); err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	if len(opts.CACert) > 0 || len(opts.CAKey) > 0 {
+		ca, caKey, err := parseCA(opts.CACert, opts.CAKey)
+		if err != nil {
+			return nil, nil, time.Time{}, fmt.Errorf("unable to load the provided CA: %w", err)
+		}
+		// A certificate cannot outlive the CA that signed it
+		if ca.NotAfter.Before(notAfter) {
+			notAfter = ca.NotAfter
+		}
+		return ca, caKey, notAfter, nil
+	}
+	ca, caKey, err := generateCA(opts.KeyAlgorithm, notAfter)
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("unable to generate the ephemeral CA: %w", err)
+	}
+	return ca, caKey, notAfter, nil
+}
+
+func encodePKI(ca, cert *x509.Certificate, key crypto.Signer) (GeneratedPKI, error) {
+	results := GeneratedPKI{}
 	results.CA = pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
 		Bytes: ca.Raw,
 	})
 	results.Cert = pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
-		Bytes: hostCert.Raw,
-	})
-	results.Key = pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(hostKey),
+		Bytes: cert.Raw,
 	})
+	var err error
+	results.Key, err = encodePrivateKey(key)
+	if err != nil {
+		return GeneratedPKI{}, err
+	}
 	return results, nil
 }
 
+func (ka KeyAlgorithm) validate() error {
+	switch ka {
+	case "", KeyAlgorithmRSA, KeyAlgorithmECDSA:
+		return nil
+	default:
+		return fmt.Errorf("unsupported key algorithm %q, must be one of %s or %s", ka, KeyAlgorithmRSA, KeyAlgorithmECDSA)
+	}
+}
+
+// parseCA loads a PEM encoded CA certificate and its private key.
+func parseCA(certPEM, keyPEM []byte) (*x509.Certificate, crypto.Signer, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, errors.New("failed to decode the CA certificate pem data")
+	}
+	ca, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the CA certificate: %w", err)
+	}
+	if !ca.IsCA {
+		return nil, nil, errors.New("the provided certificate is not a CA")
+	}
+	if ca.NotAfter.Before(now()) {
+		return nil, nil, fmt.Errorf("the provided CA expired on %s", ca.NotAfter)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, errors.New("failed to decode the CA key pem data")
+	}
+	key, err := parsePrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !publicKeysEqual(ca.PublicKey, key.Public()) {
+		return nil, nil, errors.New("the CA key does not match the CA certificate")
+	}
+	return ca, key, nil
+}
+
+// parsePrivateKey parses a DER encoded PKCS#1, PKCS#8 or SEC 1 private key.
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.New("failed to parse the private key, must be a PKCS#1, PKCS#8 or EC private key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	type equaler interface {
+		Equal(crypto.PublicKey) bool
+	}
+	e, ok := a.(equaler)
+	return ok && e.Equal(b)
+}
+
+// encodePrivateKey PEM encodes the private key in the format matching its algorithm.
+func encodePrivateKey(key crypto.Signer) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(k),
+		}), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal the EC private key: %w", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: der,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
 // newCertificate creates a new template.
 func newCertificate(notAfter time.Time) (*x509.Certificate, error) {
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
@@ -94,7 +270,10 @@ func newCertificate(notAfter time.Time) (*x509.Certificate, error) {
 }
 
 // newPrivateKey creates a new private key.
-func newPrivateKey() (*rsa.PrivateKey, error) {
+func newPrivateKey(algorithm KeyAlgorithm) (crypto.Signer, error) {
+	if algorithm == KeyAlgorithmECDSA {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
 	return rsa.GenerateKey(rand.Reader, rsaBits)
 }
 
@@ -102,7 +281,7 @@ func newPrivateKey() (*rsa.PrivateKey, error) {
 // and returns the x509 certificate and crypto private key. This
 // private key should never be saved to disk, but rather used to
 // immediately generate further certificates.
-func generateCA(notAfter time.Time) (*x509.Certificate, *rsa.PrivateKey, error) {
+func generateCA(algorithm KeyAlgorithm, notAfter time.Time) (*x509.Certificate, crypto.Signer, error) {
 	template, err := newCertificate(notAfter)
 	if err != nil {
 		return nil, nil, err
@@ -113,7 +292,7 @@ func generateCA(notAfter time.Time) (*x509.Certificate, *rsa.PrivateKey, error)
 	template.Subject.CommonName = "ca.private.zarf.dev"
 	template.Subject.Organization = []string{"Zarf Community"}
 
-	priv, err := newPrivateKey()
+	priv, err := newPrivateKey(algorithm)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -134,7 +313,7 @@ func generateCA(notAfter time.Time) (*x509.Certificate, *rsa.PrivateKey, error)
 // generateCert generates a new certificate for the given host using the
 // provided certificate authority. The cert and key files are stored in
 // the provided files.
-func generateCert(host string, ca *x509.Certificate, caKey *rsa.PrivateKey, notAfter time.Time, dnsNames ...string) (*x509.Certificate, *rsa.PrivateKey, error) {
+func generateCert(algorithm KeyAlgorithm, host string, ca *x509.Certificate, caKey crypto.Signer, notAfter time.Time, dnsNames ...string) (*x509.Certificate, crypto.Signer, error) {
 	template, err := newCertificate(notAfter)
 	if err != nil {
 		return nil, nil, err
@@ -152,10 +331,14 @@ func generateCert(host string, ca *x509.Certificate, caKey *rsa.PrivateKey, notA
 
 	template.Subject.CommonName = host
 
-	privateKey, err := newPrivateKey()
+	privateKey, err := newPrivateKey(algorithm)
 	if err != nil {
 		return nil, nil, err
 	}
+	// Key encipherment is only meaningful for RSA keys
+	if _, ok := privateKey.(*ecdsa.PrivateKey); ok {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+	}
 
 	derBytes, err := x509.CreateCertificate(rand.Reader, template, ca, privateKey.Public(), caKey)
 	if err != nil {
@@ -170,28 +353,68 @@ func generateCert(host string, ca *x509.Certificate, caKey *rsa.PrivateKey, notA
 	return cert, privateKey, nil
 }
 
-// CheckForExpiredCert checks if the certificate is expired
-func CheckForExpiredCert(ctx context.Context, pk GeneratedPKI) error {
-	block, _ := pem.Decode(pk.Cert)
-	if block == nil {
-		return fmt.Errorf("failed to decode pem data")
+// generateClientCert generates a new client certificate with the given common name using the provided certificate authority.
+func generateClientCert(algorithm KeyAlgorithm, commonName string, ca *x509.Certificate, caKey crypto.Signer, notAfter time.Time) (*x509.Certificate, crypto.Signer, error) {
+	template, err := newCertificate(notAfter)
+	if err != nil {
+		return nil, nil, err
 	}
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	template.Subject.CommonName = commonName
 
-	cert, err := x509.ParseCertificate(block.Bytes)
+	privateKey, err := newPrivateKey(algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := privateKey.(*ecdsa.PrivateKey); ok {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+	}
+
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, ca, privateKey.Public(), caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(derBytes)
 	if err != nil {
-		return fmt.Errorf("failed to parse certificate: %w", err)
+		return nil, nil, err
+	}
+
+	return cert, privateKey, nil
+}
+
+// CheckForExpiredCert checks if the certificate is expired
+func CheckForExpiredCert(ctx context.Context, pk GeneratedPKI) error {
+	cert, err := parseCert(pk.Cert)
+	if err != nil {
+		return err
 	}
 
 	if cert.NotAfter.Before(now()) {
 		return fmt.Errorf("the Zarf agent certificate is expired as of %s, run `zarf tools update-creds agent` to update", cert.NotAfter)
 	}
 
+	if needsRotation(cert) {
+		logger.From(ctx).Warn("the Zarf agent certificate is expiring soon, run `zarf tools update-creds agent` to update", "expiration", cert.NotAfter)
+	}
+	return nil
+}
+
+func needsRotation(cert *x509.Certificate) bool {
 	remainingTime := cert.NotAfter.Sub(now())
 	totalTime := cert.NotAfter.Sub(cert.NotBefore)
-	certHas20PercentRemainingTime := (float64(remainingTime) / float64(totalTime)) > 0.2
+	return (float64(remainingTime) / float64(totalTime)) <= rotationThreshold
+}
 
-	if !certHas20PercentRemainingTime {
-		logger.From(ctx).Warn("the Zarf agent certificate is expiring soon, run `zarf tools update-creds agent` to update", "expiration", cert.NotAfter)
+func parseCert(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode pem data")
 	}
-	return nil
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return cert, nil
 }
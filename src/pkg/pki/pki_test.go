@@ -6,6 +6,10 @@ package pki
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
 	"time"
 
@@ -59,7 +63,7 @@ func TestCheckForExpiredCert1(t *testing.T) {
 
 			// Create cert with fixed time
 			now = func() time.Time { return tt.timeAtCreation }
-			pki, err := generatePKI("localhost", tt.certExpiration)
+			pki, err := generatePKI(Options{}, "localhost", tt.certExpiration)
 			require.NoError(t, err)
 
 			// Check cert with fixed time
@@ -77,3 +81,98 @@ func TestCheckForExpiredCert1(t *testing.T) {
 		})
 	}
 }
+
+func TestGeneratePKIWithOptions(t *testing.T) {
+	now = time.Now
+	t.Run("ecdsa keys", func(t *testing.T) {
+		pk, err := GeneratePKIWithOptions(Options{KeyAlgorithm: KeyAlgorithmECDSA}, "agent-hook.zarf.svc")
+		require.NoError(t, err)
+
+		keyPair, err := tls.X509KeyPair(pk.Cert, pk.Key)
+		require.NoError(t, err)
+		require.IsType(t, &ecdsa.PrivateKey{}, keyPair.PrivateKey)
+		verifyCert(t, pk, "agent-hook.zarf.svc")
+	})
+
+	t.Run("unsupported key algorithm", func(t *testing.T) {
+		_, err := GeneratePKIWithOptions(Options{KeyAlgorithm: "dsa"}, "agent-hook.zarf.svc")
+		require.ErrorContains(t, err, `unsupported key algorithm "dsa"`)
+	})
+
+	t.Run("provided CA", func(t *testing.T) {
+		ca, caKey := newTestCA(t, KeyAlgorithmRSA, time.Now().Add(24*time.Hour))
+		pk, err := GeneratePKIWithOptions(Options{KeyAlgorithm: KeyAlgorithmECDSA, CACert: ca, CAKey: caKey}, "agent-hook.zarf.svc")
+		require.NoError(t, err)
+		require.Equal(t, ca, pk.CA)
+		cert := verifyCert(t, pk, "agent-hook.zarf.svc")
+
+		// The certificate must not outlive the CA
+		caCert, err := parseCert(ca)
+		require.NoError(t, err)
+		require.Equal(t, caCert.NotAfter, cert.NotAfter)
+	})
+
+	t.Run("provided CA with a mismatched key", func(t *testing.T) {
+		ca, _ := newTestCA(t, KeyAlgorithmRSA, time.Now().Add(24*time.Hour))
+		_, otherKey := newTestCA(t, KeyAlgorithmRSA, time.Now().Add(24*time.Hour))
+		_, err := GeneratePKIWithOptions(Options{CACert: ca, CAKey: otherKey}, "agent-hook.zarf.svc")
+		require.ErrorContains(t, err, "the CA key does not match the CA certificate")
+	})
+
+	t.Run("provided certificate is not a CA", func(t *testing.T) {
+		pk, err := GeneratePKI("agent-hook.zarf.svc")
+		require.NoError(t, err)
+		_, err = GeneratePKIWithOptions(Options{CACert: pk.Cert, CAKey: pk.Key}, "agent-hook.zarf.svc")
+		require.ErrorContains(t, err, "the provided certificate is not a CA")
+	})
+}
+
+func TestGenerateMutualTLS(t *testing.T) {
+	now = time.Now
+	t.Run("ephemeral CA", func(t *testing.T) {
+		server, client, err := GenerateMutualTLS(Options{KeyAlgorithm: KeyAlgorithmECDSA}, "zarf-docker-registry", "zarf-registry-proxy", "zarf-docker-registry.zarf.svc")
+		require.NoError(t, err)
+		require.Equal(t, server.CA, client.CA)
+		verifyCert(t, server, "zarf-docker-registry.zarf.svc")
+
+		roots := x509.NewCertPool()
+		require.True(t, roots.AppendCertsFromPEM(client.CA))
+		cert, err := parseCert(client.Cert)
+		require.NoError(t, err)
+		require.Equal(t, "zarf-registry-proxy", cert.Subject.CommonName)
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		require.NoError(t, err)
+		// The client certificate cannot be used to serve TLS
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+		require.Error(t, err)
+	})
+
+	t.Run("provided CA", func(t *testing.T) {
+		ca, caKey := newTestCA(t, KeyAlgorithmRSA, time.Now().Add(24*time.Hour))
+		server, client, err := GenerateMutualTLS(Options{KeyAlgorithm: KeyAlgorithmRSA, CACert: ca, CAKey: caKey}, "zarf-docker-registry", "zarf-registry-proxy")
+		require.NoError(t, err)
+		require.Equal(t, ca, server.CA)
+		require.Equal(t, ca, client.CA)
+		verifyCert(t, server, "zarf-docker-registry")
+	})
+}
+
+func newTestCA(t *testing.T, algorithm KeyAlgorithm, notAfter time.Time) ([]byte, []byte) {
+	t.Helper()
+	ca, key, err := generateCA(algorithm, notAfter)
+	require.NoError(t, err)
+	keyPEM, err := encodePrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), keyPEM
+}
+
+func verifyCert(t *testing.T, pk GeneratedPKI, host string) *x509.Certificate {
+	t.Helper()
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(pk.CA))
+	cert, err := parseCert(pk.Cert)
+	require.NoError(t, err)
+	_, err = cert.Verify(x509.VerifyOptions{DNSName: host, Roots: roots})
+	require.NoError(t, err)
+	return cert
+}
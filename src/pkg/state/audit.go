@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ZarfAuditLogLabel marks the config maps in the Zarf namespace that hold the audit log.
+const ZarfAuditLogLabel = "zarf.dev/audit-log"
+
+// AuditOperation is a change to the Zarf state or deployed package secrets recorded in the audit log.
+type AuditOperation string
+
+const (
+	// AuditOperationInit is recorded when the Zarf state is saved by zarf init.
+	AuditOperationInit AuditOperation = "init"
+	// AuditOperationUpdateCreds is recorded when credentials or the registry mode are changed by zarf tools update-creds or a credential rotation.
+	AuditOperationUpdateCreds AuditOperation = "update-creds"
+	// AuditOperationRollbackCreds is recorded when a failed credential rotation saves the credentials it restored.
+	AuditOperationRollbackCreds AuditOperation = "rollback-creds"
+	// AuditOperationUpdateRegistryMirrors is recorded when registries are mirrored during a deployment.
+	AuditOperationUpdateRegistryMirrors AuditOperation = "update-registry-mirrors"
+	// AuditOperationDestroy is logged by zarf destroy before the Zarf resources and the audit log are removed.
+	AuditOperationDestroy AuditOperation = "destroy"
+	// AuditOperationRecordPackageDeployment is recorded when a deployed package secret is written during a deployment.
+	AuditOperationRecordPackageDeployment AuditOperation = "record-package-deployment"
+	// AuditOperationUpdateDeployedPackage is recorded when a deployed package secret is updated during a removal.
+	AuditOperationUpdateDeployedPackage AuditOperation = "update-deployed-package"
+	// AuditOperationDeleteDeployedPackage is recorded when a deployed package secret is deleted.
+	AuditOperationDeleteDeployedPackage AuditOperation = "delete-deployed-package"
+)
+
+// AuditOperations are the operations recorded in the audit log, the destroy is only logged as it removes the audit log.
+var AuditOperations = []AuditOperation{
+	AuditOperationInit,
+	AuditOperationUpdateCreds,
+	AuditOperationRollbackCreds,
+	AuditOperationUpdateRegistryMirrors,
+	AuditOperationRecordPackageDeployment,
+	AuditOperationUpdateDeployedPackage,
+	AuditOperationDeleteDeployedPackage,
+}
+
+// AuditEntry is a single change recorded in the audit log.
+type AuditEntry struct {
+	// Time of the change
+	Timestamp time.Time `json:"timestamp"`
+	// Version of the Zarf CLI that made the change
+	CLIVersion string `json:"cliVersion"`
+	// OS user that ran the Zarf CLI
+	User string `json:"user"`
+	// Operation that made the change
+	Operation AuditOperation `json:"operation"`
+	// Name of the deployed package that was changed
+	Package string `json:"package,omitempty"`
+	// Generation of the deployed package that was changed
+	Generation int `json:"generation,omitempty"`
+	// Fields that changed, with sensitive values redacted
+	Changes []AuditChange `json:"changes,omitempty"`
+}
+
+// AuditChange is a field that changed in an audited object.
+type AuditChange struct {
+	// Path of the field, e.g. registryInfo.address or components.podinfo
+	Path string `json:"path"`
+	// Previous value of the field, empty if it was added
+	Old string `json:"old,omitempty"`
+	// New value of the field, empty if it was removed
+	New string `json:"new,omitempty"`
+}
+
+// AuditFilter selects entries from the audit log, zero values match every entry.
+type AuditFilter struct {
+	Package   string
+	Operation AuditOperation
+	Since     time.Time
+	Until     time.Time
+}
+
+// Matches returns true if the entry is selected by the filter.
+func (f AuditFilter) Matches(entry AuditEntry) bool {
+	if f.Package != "" && entry.Package != f.Package {
+		return false
+	}
+	if f.Operation != "" && entry.Operation != f.Operation {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Validate ensures the filter selects a known operation and a valid time range.
+func (f AuditFilter) Validate() error {
+	if f.Operation != "" && !slices.Contains(AuditOperations, f.Operation) {
+		return fmt.Errorf("invalid audit operation %q, must be one of %v", f.Operation, AuditOperations)
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return fmt.Errorf("the end of the time range %s is before the start %s", f.Until.Format(time.RFC3339), f.Since.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// auditRecordedFields are the fields whose values are written to the audit log, the values of every other field
+// are redacted so that new credentials are never recorded by accident. Array indices in a path are matched by [*]
+// and a trailing .* matches every field nested below the path.
+var auditRecordedFields = []string{
+	// State
+	"zarfAppliance",
+	"distro",
+	"architecture",
+	"storageClass",
+	"ipFamily",
+	"injectorInfo.*",
+	"gitServer.pushUsername",
+	"gitServer.pullUsername",
+	"gitServer.address",
+	"registryInfo.pushUsername",
+	"registryInfo.pullUsername",
+	"registryInfo.address",
+	"registryInfo.nodePort",
+	"registryInfo.registryMode",
+	"registryInfo.mirrors[*]",
+	"registryInfo.s3.bucket",
+	"registryInfo.s3.region",
+	"registryInfo.s3.endpoint",
+	"registryInfo.s3.rootDirectory",
+	"registryInfo.s3.insecure",
+	"registryInfo.s3.forcePathStyle",
+	"artifactServer.pushUsername",
+	"artifactServer.address",
+	"registries[*].name",
+	"registries[*].pushUsername",
+	"registries[*].pullUsername",
+	"registries[*].address",
+	"registries[*].registryMode",
+	"registries[*].routes.*",
+	"gitServers[*].name",
+	"gitServers[*].pushUsername",
+	"gitServers[*].pullUsername",
+	"gitServers[*].address",
+	"gitServers[*].routes.*",
+	"agentInfo.imagePolicy.*",
+	"agentInfo.audit.*",
+	"agentInfo.tls.keyAlgorithm",
+	"agentInfo.tls.certManagerIssuer.*",
+	"credentialStore.*",
+	// DeployedPackage.AuditView
+	"version",
+	"cliVersion",
+	"generation",
+	"namespaceOverride",
+	"components.*",
+}
+
+var auditIndexRegex = regexp.MustCompile(`\[\d+\]`)
+
+const (
+	auditRedactedValue = "**sanitized**"
+	// Values longer than this are truncated so that large fields do not fill the audit log
+	auditMaxValueLength = 256
+)
+
+// AuditView returns the fields of a deployed package recorded in the audit log.
+// The package definition is left out as it is large and may contain sensitive variable defaults.
+func (d *DeployedPackage) AuditView() any {
+	components := map[string]string{}
+	for _, component := range d.DeployedComponents {
+		components[component.Name] = string(component.Status)
+	}
+	return struct {
+		Version           string            `json:"version,omitempty"`
+		CLIVersion        string            `json:"cliVersion,omitempty"`
+		Generation        int               `json:"generation,omitempty"`
+		NamespaceOverride string            `json:"namespaceOverride,omitempty"`
+		Components        map[string]string `json:"components,omitempty"`
+	}{
+		Version:           d.Data.Metadata.Version,
+		CLIVersion:        d.CLIVersion,
+		Generation:        d.Generation,
+		NamespaceOverride: d.NamespaceOverride,
+		Components:        components,
+	}
+}
+
+// AuditChanges returns the fields that differ between two objects, with sensitive values redacted.
+// A nil object is treated as empty, so every field of the other object is a change.
+func AuditChanges(oldObj, newObj any) ([]AuditChange, error) {
+	oldFields, err := flattenForAudit(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := flattenForAudit(newObj)
+	if err != nil {
+		return nil, err
+	}
+	paths := map[string]struct{}{}
+	for path := range oldFields {
+		paths[path] = struct{}{}
+	}
+	for path := range newFields {
+		paths[path] = struct{}{}
+	}
+	changes := []AuditChange{}
+	for path := range paths {
+		oldValue, newValue := oldFields[path], newFields[path]
+		if oldValue == newValue {
+			continue
+		}
+		if !isAuditRecorded(path) {
+			oldValue, newValue = redactAuditValue(oldValue), redactAuditValue(newValue)
+		}
+		changes = append(changes, AuditChange{
+			Path: path,
+			Old:  truncateAuditValue(oldValue),
+			New:  truncateAuditValue(newValue),
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// flattenForAudit converts an object into a map of field paths to their JSON scalar values.
+func flattenForAudit(obj any) (map[string]string, error) {
+	fields := map[string]string{}
+	if obj == nil {
+		return fields, nil
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	flattenValue("", v, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, v any, fields map[string]string) {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, nested := range value {
+			nestedPath := k
+			if path != "" {
+				nestedPath = path + "." + k
+			}
+			flattenValue(nestedPath, nested, fields)
+		}
+	case []any:
+		for i, nested := range value {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), nested, fields)
+		}
+	// Zero values are left out so that they match fields that are not set
+	case nil:
+		return
+	case string:
+		if value != "" {
+			fields[path] = value
+		}
+	case bool:
+		if value {
+			fields[path] = "true"
+		}
+	case float64:
+		if value != 0 {
+			fields[path] = fmt.Sprint(value)
+		}
+	default:
+		fields[path] = fmt.Sprint(value)
+	}
+}
+
+func isAuditRecorded(path string) bool {
+	path = auditIndexRegex.ReplaceAllString(path, "[*]")
+	for _, field := range auditRecordedFields {
+		if prefix, ok := strings.CutSuffix(field, "*"); ok && strings.HasPrefix(path, prefix) {
+			return true
+		}
+		if path == field {
+			return true
+		}
+	}
+	return false
+}
+
+func redactAuditValue(v string) string {
+	if v == "" {
+		return ""
+	}
+	return auditRedactedValue
+}
+
+func truncateAuditValue(v string) string {
+	if len(v) <= auditMaxValueLength {
+		return v
+	}
+	return v[:auditMaxValueLength] + "..."
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package state
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CredentialStoreBackend is the backend that stores the sensitive fields of the state.
+type CredentialStoreBackend string
+
+const (
+	// CredentialStoreInline keeps the credentials in the state secret, this is the default.
+	CredentialStoreInline CredentialStoreBackend = "inline"
+	// CredentialStoreVault keeps the credentials in a Vault compatible KV version 2 secrets engine.
+	CredentialStoreVault CredentialStoreBackend = "vault"
+	// CredentialStoreEnvelope keeps the credentials in a Kubernetes secret encrypted with data keys wrapped by a Vault transit key.
+	CredentialStoreEnvelope CredentialStoreBackend = "envelope"
+)
+
+// Names of the secrets in the Zarf namespace used by the credential store backends.
+const (
+	// CredentialStoreSecretName holds the token used to authenticate with Vault by the vault and envelope backends.
+	CredentialStoreSecretName = "zarf-credential-store"
+	// CredentialStoreTokenKey is the key of the Vault token in the credential store secret.
+	CredentialStoreTokenKey = "vaultToken"
+	// EnvelopeCredentialsSecretName holds the encrypted credentials of the envelope backend.
+	EnvelopeCredentialsSecretName = "zarf-state-credentials"
+)
+
+// Keys of the credentials kept in a credential store.
+const (
+	CredentialRegistryPushPassword = "registry.pushPassword"
+	CredentialRegistryPullPassword = "registry.pullPassword"
+	CredentialRegistrySecret       = "registry.secret"
+	CredentialGitPushPassword      = "git.pushPassword"
+	CredentialGitPullPassword      = "git.pullPassword"
+	CredentialArtifactPushToken    = "artifact.pushToken"
+	CredentialAgentKey             = "agent.key"
+	CredentialRegistryProxyServer  = "registryProxy.serverKey"
+	CredentialRegistryProxyClient  = "registryProxy.clientKey"
+	CredentialRegistryS3AccessKey  = "registry.s3AccessKey"
+	CredentialRegistryS3SecretKey  = "registry.s3SecretKey"
+)
+
+// Credential keys of the named registries and git servers are prefixed with their name.
+const (
+	credentialNamedRegistryFormat  = "registries.%s.%s"
+	credentialNamedGitServerFormat = "gitServers.%s.%s"
+)
+
+// CredentialStore configures where the sensitive fields of the state are kept.
+// When an external backend is used the state secret only holds this configuration as a reference to the credentials.
+type CredentialStore struct {
+	// Backend that stores the credentials, defaults to inline
+	Backend CredentialStoreBackend `json:"backend,omitempty"`
+	// Configuration of the Vault backend
+	Vault *VaultCredentialStore `json:"vault,omitempty"`
+	// Configuration of the envelope backend
+	Envelope *EnvelopeCredentialStore `json:"envelope,omitempty"`
+}
+
+// VaultCredentialStore configures a Vault compatible KV version 2 secrets engine.
+// The token is read from the zarf-credential-store secret so that it is never written to the state.
+type VaultCredentialStore struct {
+	// Address of the Vault server (e.g. https://vault.example.com:8200)
+	Address string `json:"address"`
+	// Mount path of the KV version 2 secrets engine, defaults to secret
+	Mount string `json:"mount,omitempty"`
+	// Path of the secret in the secrets engine, defaults to zarf/state
+	Path string `json:"path,omitempty"`
+}
+
+// EnvelopeCredentialStore configures the key encryption key of the envelope backend, kept in a Vault compatible transit secrets engine.
+// The token is read from the zarf-credential-store secret so that it is never written to the state.
+type EnvelopeCredentialStore struct {
+	// Address of the Vault server (e.g. https://vault.example.com:8200)
+	Address string `json:"address"`
+	// Mount path of the transit secrets engine, defaults to transit
+	Mount string `json:"mount,omitempty"`
+	// Name of the transit key that wraps the data keys
+	Key string `json:"key"`
+}
+
+// IsExternal returns true if the credentials are kept outside of the state secret.
+func (cs CredentialStore) IsExternal() bool {
+	return cs.Backend != "" && cs.Backend != CredentialStoreInline
+}
+
+// Validate ensures the credential store is configured correctly.
+func (cs CredentialStore) Validate() error {
+	switch cs.Backend {
+	case "", CredentialStoreInline:
+		return nil
+	case CredentialStoreVault:
+		if cs.Vault == nil || cs.Vault.Address == "" {
+			return fmt.Errorf("the %s credential store requires an address", cs.Backend)
+		}
+		return nil
+	case CredentialStoreEnvelope:
+		if cs.Envelope == nil || cs.Envelope.Address == "" {
+			return fmt.Errorf("the %s credential store requires an address", cs.Backend)
+		}
+		if cs.Envelope.Key == "" {
+			return fmt.Errorf("the %s credential store requires a transit key", cs.Backend)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid credential store backend %q, must be %q, %q, or %q", cs.Backend, CredentialStoreInline, CredentialStoreVault, CredentialStoreEnvelope)
+	}
+}
+
+// Credentials returns the sensitive fields of the state keyed by their credential name.
+func (s *State) Credentials() map[string]string {
+	creds := map[string]string{
+		CredentialRegistryPushPassword: s.RegistryInfo.PushPassword,
+		CredentialRegistryPullPassword: s.RegistryInfo.PullPassword,
+		CredentialRegistrySecret:       s.RegistryInfo.Secret,
+		CredentialGitPushPassword:      s.GitServer.PushPassword,
+		CredentialGitPullPassword:      s.GitServer.PullPassword,
+		CredentialArtifactPushToken:    s.ArtifactServer.PushToken,
+		CredentialAgentKey:             string(s.AgentTLS.Key),
+	}
+	if s.RegistryProxyTLS != nil {
+		creds[CredentialRegistryProxyServer] = string(s.RegistryProxyTLS.Server.Key)
+		creds[CredentialRegistryProxyClient] = string(s.RegistryProxyTLS.Client.Key)
+	}
+	if s.RegistryInfo.S3 != nil {
+		creds[CredentialRegistryS3AccessKey] = s.RegistryInfo.S3.AccessKey
+		creds[CredentialRegistryS3SecretKey] = s.RegistryInfo.S3.SecretKey
+	}
+	for _, r := range s.Registries {
+		creds[fmt.Sprintf(credentialNamedRegistryFormat, r.Name, "pushPassword")] = r.PushPassword
+		creds[fmt.Sprintf(credentialNamedRegistryFormat, r.Name, "pullPassword")] = r.PullPassword
+		creds[fmt.Sprintf(credentialNamedRegistryFormat, r.Name, "secret")] = r.Secret
+	}
+	for _, gs := range s.GitServers {
+		creds[fmt.Sprintf(credentialNamedGitServerFormat, gs.Name, "pushPassword")] = gs.PushPassword
+		creds[fmt.Sprintf(credentialNamedGitServerFormat, gs.Name, "pullPassword")] = gs.PullPassword
+	}
+	return creds
+}
+
+// SetCredentials sets the sensitive fields of the state from credentials keyed by their credential name.
+func (s *State) SetCredentials(creds map[string]string) {
+	s.RegistryInfo.PushPassword = creds[CredentialRegistryPushPassword]
+	s.RegistryInfo.PullPassword = creds[CredentialRegistryPullPassword]
+	s.RegistryInfo.Secret = creds[CredentialRegistrySecret]
+	s.GitServer.PushPassword = creds[CredentialGitPushPassword]
+	s.GitServer.PullPassword = creds[CredentialGitPullPassword]
+	s.ArtifactServer.PushToken = creds[CredentialArtifactPushToken]
+	s.AgentTLS.Key = bytesOrNil(creds[CredentialAgentKey])
+	if s.RegistryProxyTLS != nil {
+		proxyTLS := *s.RegistryProxyTLS
+		proxyTLS.Server.Key = bytesOrNil(creds[CredentialRegistryProxyServer])
+		proxyTLS.Client.Key = bytesOrNil(creds[CredentialRegistryProxyClient])
+		s.RegistryProxyTLS = &proxyTLS
+	}
+	if s.RegistryInfo.S3 != nil {
+		s3 := *s.RegistryInfo.S3
+		s3.AccessKey = creds[CredentialRegistryS3AccessKey]
+		s3.SecretKey = creds[CredentialRegistryS3SecretKey]
+		s.RegistryInfo.S3 = &s3
+	}
+	// The slices are copied so that a shallow copy of the state does not share its credentials
+	s.Registries = slices.Clone(s.Registries)
+	for i := range s.Registries {
+		r := &s.Registries[i]
+		r.PushPassword = creds[fmt.Sprintf(credentialNamedRegistryFormat, r.Name, "pushPassword")]
+		r.PullPassword = creds[fmt.Sprintf(credentialNamedRegistryFormat, r.Name, "pullPassword")]
+		r.Secret = creds[fmt.Sprintf(credentialNamedRegistryFormat, r.Name, "secret")]
+	}
+	s.GitServers = slices.Clone(s.GitServers)
+	for i := range s.GitServers {
+		gs := &s.GitServers[i]
+		gs.PushPassword = creds[fmt.Sprintf(credentialNamedGitServerFormat, gs.Name, "pushPassword")]
+		gs.PullPassword = creds[fmt.Sprintf(credentialNamedGitServerFormat, gs.Name, "pullPassword")]
+	}
+}
+
+func bytesOrNil(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(s)
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/pkg/pki"
+)
+
+func TestCredentialStoreValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		store       CredentialStore
+		expectedErr string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:  "envelope",
+			store: CredentialStore{Backend: CredentialStoreEnvelope, Envelope: &EnvelopeCredentialStore{Address: "https://vault.example.com", Key: "zarf"}},
+		},
+		{
+			name:        "envelope without address",
+			store:       CredentialStore{Backend: CredentialStoreEnvelope},
+			expectedErr: "the envelope credential store requires an address",
+		},
+		{
+			name:        "envelope without transit key",
+			store:       CredentialStore{Backend: CredentialStoreEnvelope, Envelope: &EnvelopeCredentialStore{Address: "https://vault.example.com"}},
+			expectedErr: "the envelope credential store requires a transit key",
+		},
+		{
+			name:  "vault",
+			store: CredentialStore{Backend: CredentialStoreVault, Vault: &VaultCredentialStore{Address: "https://vault.example.com"}},
+		},
+		{
+			name:        "vault without address",
+			store:       CredentialStore{Backend: CredentialStoreVault},
+			expectedErr: "the vault credential store requires an address",
+		},
+		{
+			name:        "unknown backend",
+			store:       CredentialStore{Backend: "kms"},
+			expectedErr: `invalid credential store backend "kms", must be "inline", "vault", or "envelope"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.store.Validate()
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	t.Parallel()
+
+	s := &State{
+		RegistryInfo:   RegistryInfo{PushPassword: "push", PullPassword: "pull", Secret: "secret"},
+		GitServer:      GitServerInfo{PushPassword: "git-push", PullPassword: "git-pull"},
+		ArtifactServer: ArtifactServerInfo{PushToken: "token"},
+		AgentTLS:       pki.GeneratedPKI{Key: []byte("key")},
+	}
+	creds := s.Credentials()
+	require.Equal(t, "push", creds[CredentialRegistryPushPassword])
+	require.Equal(t, "key", creds[CredentialAgentKey])
+
+	restored := &State{}
+	restored.SetCredentials(creds)
+	require.Equal(t, s, restored)
+
+	restored.SetCredentials(map[string]string{})
+	require.Equal(t, &State{}, restored)
+}
+
+func TestCredentialsNamedTargets(t *testing.T) {
+	t.Parallel()
+
+	s := &State{
+		Registries: []NamedRegistryInfo{{Name: "tenant-a", RegistryInfo: RegistryInfo{Address: "registry.example.com", PushPassword: "push", PullPassword: "pull", Secret: "secret"}}},
+		GitServers: []NamedGitServerInfo{{Name: "tenant-a", GitServerInfo: GitServerInfo{Address: "https://git.example.com", PushPassword: "git-push", PullPassword: "git-pull"}}},
+	}
+	creds := s.Credentials()
+	require.Equal(t, "push", creds["registries.tenant-a.pushPassword"])
+	require.Equal(t, "git-pull", creds["gitServers.tenant-a.pullPassword"])
+
+	// Clearing the credentials of a shallow copy must not clear the original
+	referenced := *s
+	referenced.SetCredentials(map[string]string{})
+	require.Empty(t, referenced.Registries[0].PushPassword)
+	require.Empty(t, referenced.GitServers[0].PullPassword)
+	require.Equal(t, "registry.example.com", referenced.Registries[0].Address)
+	require.Equal(t, "push", s.Registries[0].PushPassword)
+
+	referenced.SetCredentials(creds)
+	require.Equal(t, s, &referenced)
+}
+
+func TestCredentialsRegistryProxyTLS(t *testing.T) {
+	t.Parallel()
+
+	s := &State{
+		RegistryProxyTLS: &RegistryProxyTLS{
+			Server: pki.GeneratedPKI{CA: []byte("ca"), Cert: []byte("server-cert"), Key: []byte("server-key")},
+			Client: pki.GeneratedPKI{CA: []byte("ca"), Cert: []byte("client-cert"), Key: []byte("client-key")},
+		},
+	}
+	creds := s.Credentials()
+	require.Equal(t, "server-key", creds[CredentialRegistryProxyServer])
+	require.Equal(t, "client-key", creds[CredentialRegistryProxyClient])
+
+	// Clearing the credentials of a shallow copy must not clear the original
+	referenced := *s
+	referenced.SetCredentials(map[string]string{})
+	require.Empty(t, referenced.RegistryProxyTLS.Server.Key)
+	require.Equal(t, []byte("client-cert"), referenced.RegistryProxyTLS.Client.Cert)
+	require.Equal(t, []byte("server-key"), s.RegistryProxyTLS.Server.Key)
+
+	referenced.SetCredentials(creds)
+	require.Equal(t, s, &referenced)
+}
+
+func TestCredentialsRegistryS3(t *testing.T) {
+	t.Parallel()
+
+	s := &State{
+		RegistryInfo: RegistryInfo{
+			S3: &RegistryS3Storage{Bucket: "zarf", Region: "us-east-1", AccessKey: "access", SecretKey: "secret"},
+		},
+	}
+	creds := s.Credentials()
+	require.Equal(t, "access", creds[CredentialRegistryS3AccessKey])
+	require.Equal(t, "secret", creds[CredentialRegistryS3SecretKey])
+
+	// Clearing the credentials of a shallow copy must not clear the original
+	referenced := *s
+	referenced.SetCredentials(map[string]string{})
+	require.Empty(t, referenced.RegistryInfo.S3.SecretKey)
+	require.Equal(t, "zarf", referenced.RegistryInfo.S3.Bucket)
+	require.Equal(t, "secret", s.RegistryInfo.S3.SecretKey)
+
+	referenced.SetCredentials(creds)
+	require.Equal(t, s, &referenced)
+}
@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/zarf-dev/zarf/src/pkg/transform"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// RoutingRules select the images or repositories that are routed to a named registry or git server.
+// Every configured rule must match, a target without any rules is never routed to.
+type RoutingRules struct {
+	// Source registry or repository prefixes routed to the target (e.g. ghcr.io/tenant-a or https://github.com/tenant-a)
+	SourcePrefixes []string `json:"sourcePrefixes,omitempty"`
+	// Namespaces whose workloads are routed to the target
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// IsEmpty returns true if no routing rules are configured.
+func (rr RoutingRules) IsEmpty() bool {
+	return len(rr.SourcePrefixes) == 0 && len(rr.Namespaces) == 0
+}
+
+// Matches returns true if any of the source references used in the namespace is selected by the rules.
+func (rr RoutingRules) Matches(namespace string, sources ...string) bool {
+	if rr.IsEmpty() {
+		return false
+	}
+	return rr.matchesNamespace(namespace) && rr.matchesSource(sources...)
+}
+
+func (rr RoutingRules) matchesNamespace(namespace string) bool {
+	return len(rr.Namespaces) == 0 || rr.SelectsNamespace(namespace)
+}
+
+// SelectsNamespace returns true if the namespace is listed by the rules.
+// Rules without namespaces select images and repositories by their source only and never select a namespace.
+func (rr RoutingRules) SelectsNamespace(namespace string) bool {
+	return slices.Contains(rr.Namespaces, namespace)
+}
+
+func (rr RoutingRules) matchesSource(sources ...string) bool {
+	if len(rr.SourcePrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range rr.SourcePrefixes {
+		for _, source := range sources {
+			if hasReferencePrefix(source, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasReferencePrefix returns true if the reference starts with the prefix on a path boundary, ignoring any URL scheme.
+func hasReferencePrefix(ref, prefix string) bool {
+	ref, prefix = trimScheme(ref), strings.TrimSuffix(trimScheme(prefix), "/")
+	if prefix == "" || !strings.HasPrefix(ref, prefix) {
+		return false
+	}
+	rest := ref[len(prefix):]
+	return rest == "" || strings.ContainsAny(rest[:1], "/:@")
+}
+
+func trimScheme(ref string) string {
+	if _, after, ok := strings.Cut(ref, "://"); ok {
+		return after
+	}
+	return ref
+}
+
+// NamedRegistryInfo is an additional registry that images are routed to by its rules.
+type NamedRegistryInfo struct {
+	// Name of the registry, used to reference it in logs and secrets
+	Name string `json:"name"`
+	RegistryInfo
+	// Rules selecting the images pushed to and pulled from this registry
+	Routes RoutingRules `json:"routes"`
+}
+
+// NamedGitServerInfo is an additional git server that repositories are routed to by its rules.
+type NamedGitServerInfo struct {
+	// Name of the git server, used to reference it in logs and secrets
+	Name string `json:"name"`
+	GitServerInfo
+	// Rules selecting the repositories pushed to and pulled from this git server
+	Routes RoutingRules `json:"routes"`
+}
+
+// RoutingConfig holds the named registries and git servers configured during init or update-creds.
+type RoutingConfig struct {
+	Registries []NamedRegistryInfo  `json:"registries,omitempty"`
+	GitServers []NamedGitServerInfo `json:"gitServers,omitempty"`
+}
+
+// Validate ensures every named registry and git server has a unique name, an address and routing rules.
+// Targets with credentials must select namespaces as their credentials are only added to the pull secrets of those namespaces.
+func (rc RoutingConfig) Validate() error {
+	var errs []error
+	registryNames := map[string]bool{}
+	for _, r := range rc.Registries {
+		hasCreds := r.PushUsername != "" || r.PushPassword != "" || r.PullUsername != "" || r.PullPassword != ""
+		errs = append(errs, validateRoutedTarget("registry", r.Name, r.Address, r.Routes, hasCreds, registryNames))
+		if r.RegistryMode != "" && r.RegistryMode != RegistryModeExternal {
+			errs = append(errs, fmt.Errorf("the registry %q must be external, got registry mode %q", r.Name, r.RegistryMode))
+		}
+	}
+	gitServerNames := map[string]bool{}
+	for _, gs := range rc.GitServers {
+		hasCreds := gs.PushUsername != "" || gs.PushPassword != "" || gs.PullUsername != "" || gs.PullPassword != ""
+		errs = append(errs, validateRoutedTarget("git server", gs.Name, gs.Address, gs.Routes, hasCreds, gitServerNames))
+	}
+	return errors.Join(errs...)
+}
+
+func validateRoutedTarget(kind, name, address string, routes RoutingRules, hasCreds bool, seen map[string]bool) error {
+	if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+		return fmt.Errorf("invalid %s name %q: %s", kind, name, strings.Join(msgs, ", "))
+	}
+	if seen[name] {
+		return fmt.Errorf("the %s name %q is used more than once", kind, name)
+	}
+	seen[name] = true
+	if address == "" {
+		return fmt.Errorf("the %s %q requires an address", kind, name)
+	}
+	if routes.IsEmpty() {
+		return fmt.Errorf("the %s %q requires at least one source prefix or namespace route", kind, name)
+	}
+	if hasCreds && len(routes.Namespaces) == 0 {
+		return fmt.Errorf("the %s %q has credentials and requires a namespace route", kind, name)
+	}
+	return nil
+}
+
+// FillInEmptyValues sets every necessary value of the named registries and git servers not already set.
+// Named targets are always external so their pull credentials default to the push credentials.
+func (rc *RoutingConfig) FillInEmptyValues() error {
+	for i := range rc.Registries {
+		if err := rc.Registries[i].FillInEmptyValues(IPFamilyDualStack); err != nil {
+			return err
+		}
+	}
+	for i := range rc.GitServers {
+		if err := rc.GitServers[i].FillInEmptyValues(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRouting replaces the named registries and git servers of the state.
+func (s *State) SetRouting(rc RoutingConfig) {
+	s.Registries = rc.Registries
+	s.GitServers = rc.GitServers
+}
+
+// AllRegistries returns the default registry followed by the named registries.
+func (s *State) AllRegistries() []RegistryInfo {
+	registries := []RegistryInfo{s.RegistryInfo}
+	for _, r := range s.Registries {
+		registries = append(registries, r.RegistryInfo)
+	}
+	return registries
+}
+
+// AllGitServers returns the default git server followed by the named git servers.
+func (s *State) AllGitServers() []GitServerInfo {
+	gitServers := []GitServerInfo{s.GitServer}
+	for _, gs := range s.GitServers {
+		gitServers = append(gitServers, gs.GitServerInfo)
+	}
+	return gitServers
+}
+
+// RegistryFor returns the registry an image used in the namespace is routed to.
+// Images that already point at a named registry stay on it, otherwise the first named registry
+// whose rules match is used. The default registry is returned with an empty name when none match.
+func (s *State) RegistryFor(image, namespace string) NamedRegistryInfo {
+	return RouteRegistry(s.RegistryInfo, s.Registries, image, namespace)
+}
+
+// RouteRegistry returns the registry an image used in the namespace is routed to out of the default and named registries.
+func RouteRegistry(defaultRegistry RegistryInfo, registries []NamedRegistryInfo, image, namespace string) NamedRegistryInfo {
+	sources := []string{strings.TrimPrefix(image, helpers.OCIURLPrefix)}
+	if ref, err := transform.ParseImageRef(image); err == nil {
+		sources = append(sources, ref.Name)
+	}
+	for _, r := range registries {
+		for _, source := range sources {
+			if hasReferencePrefix(source, r.Address) {
+				return r
+			}
+		}
+	}
+	for _, r := range registries {
+		if r.Routes.Matches(namespace, sources...) {
+			return r
+		}
+	}
+	return NamedRegistryInfo{RegistryInfo: defaultRegistry}
+}
+
+// RegistriesForNamespace returns the default registry and the named registries that workloads in the namespace may pull from.
+// Named registries are only returned for the namespaces their rules select so their credentials never reach other namespaces.
+func (s *State) RegistriesForNamespace(namespace string) []RegistryInfo {
+	registries := []RegistryInfo{s.RegistryInfo}
+	for _, r := range s.Registries {
+		if r.Routes.SelectsNamespace(namespace) {
+			registries = append(registries, r.RegistryInfo)
+		}
+	}
+	return registries
+}
+
+// GitServerFor returns the git server a repository used in the namespace is routed to.
+// Repositories that already point at a named git server stay on it, otherwise the first named git server
+// whose rules match is used. The default git server is returned with an empty name when none match.
+func (s *State) GitServerFor(repoURL, namespace string) NamedGitServerInfo {
+	return RouteGitServer(s.GitServer, s.GitServers, repoURL, namespace)
+}
+
+// RouteGitServer returns the git server a repository used in the namespace is routed to out of the default and named git servers.
+func RouteGitServer(defaultGitServer GitServerInfo, gitServers []NamedGitServerInfo, repoURL, namespace string) NamedGitServerInfo {
+	if host := urlHost(repoURL); host != "" {
+		for _, gs := range gitServers {
+			if urlHost(gs.Address) == host {
+				return gs
+			}
+		}
+	}
+	for _, gs := range gitServers {
+		if gs.Routes.Matches(namespace, repoURL) {
+			return gs
+		}
+	}
+	return NamedGitServerInfo{GitServerInfo: defaultGitServer}
+}
+
+// GitServersForNamespace returns the default git server and the named git servers that workloads in the namespace may pull from.
+// Named git servers are only returned for the namespaces their rules select so their credentials never reach other namespaces.
+func (s *State) GitServersForNamespace(namespace string) []NamedGitServerInfo {
+	gitServers := []NamedGitServerInfo{{GitServerInfo: s.GitServer}}
+	for _, gs := range s.GitServers {
+		if gs.Routes.SelectsNamespace(namespace) {
+			gitServers = append(gitServers, gs)
+		}
+	}
+	return gitServers
+}
+
+// SecretName returns the name of the pull secret for the git server, the default name is used for the default git server.
+func (gs NamedGitServerInfo) SecretName(defaultName string) string {
+	if gs.Name == "" {
+		return defaultName
+	}
+	return fmt.Sprintf("%s-%s", defaultName, gs.Name)
+}
+
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testRoutingState() *State {
+	return &State{
+		RegistryInfo: RegistryInfo{Address: "127.0.0.1:31999"},
+		GitServer:    GitServerInfo{Address: ZarfInClusterGitServiceURL},
+		Registries: []NamedRegistryInfo{
+			{
+				Name:         "tenant-a",
+				RegistryInfo: RegistryInfo{Address: "registry.tenant-a.example.com"},
+				Routes:       RoutingRules{Namespaces: []string{"tenant-a"}},
+			},
+			{
+				Name:         "tenant-b",
+				RegistryInfo: RegistryInfo{Address: "registry.tenant-b.example.com"},
+				Routes:       RoutingRules{SourcePrefixes: []string{"ghcr.io/tenant-b"}, Namespaces: []string{"tenant-b", "shared"}},
+			},
+			{
+				Name:         "hub",
+				RegistryInfo: RegistryInfo{Address: "registry.example.com/hub"},
+				Routes:       RoutingRules{SourcePrefixes: []string{"docker.io/library"}},
+			},
+		},
+		GitServers: []NamedGitServerInfo{
+			{
+				Name:          "tenant-a",
+				GitServerInfo: GitServerInfo{Address: "https://git.tenant-a.example.com"},
+				Routes:        RoutingRules{SourcePrefixes: []string{"github.com/tenant-a"}},
+			},
+		},
+	}
+}
+
+func TestRegistryFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		image     string
+		namespace string
+		expected  string
+	}{
+		{
+			name:     "default registry",
+			image:    "quay.io/podinfo/podinfo:6.4.0",
+			expected: "",
+		},
+		{
+			name:      "namespace route",
+			image:     "quay.io/podinfo/podinfo:6.4.0",
+			namespace: "tenant-a",
+			expected:  "tenant-a",
+		},
+		{
+			name:      "source prefix and namespace route",
+			image:     "ghcr.io/tenant-b/app:1.0.0",
+			namespace: "shared",
+			expected:  "tenant-b",
+		},
+		{
+			name:      "source prefix without namespace match",
+			image:     "ghcr.io/tenant-b/app:1.0.0",
+			namespace: "other",
+			expected:  "",
+		},
+		{
+			name:      "source prefix on a path boundary",
+			image:     "ghcr.io/tenant-bb/app:1.0.0",
+			namespace: "tenant-b",
+			expected:  "",
+		},
+		{
+			name:     "short image name matches its fully qualified source",
+			image:    "nginx:1.27",
+			expected: "hub",
+		},
+		{
+			name:      "image already on a named registry stays on it",
+			image:     "registry.tenant-b.example.com/tenant-b/app:1.0.0-zarf-1234",
+			namespace: "tenant-a",
+			expected:  "tenant-b",
+		},
+		{
+			name:     "oci url",
+			image:    "oci://ghcr.io/tenant-b/chart:1.0.0",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := testRoutingState()
+			registry := s.RegistryFor(tt.image, tt.namespace)
+			require.Equal(t, tt.expected, registry.Name)
+			if tt.expected == "" {
+				require.Equal(t, s.RegistryInfo, registry.RegistryInfo)
+			}
+		})
+	}
+}
+
+func TestRegistriesForNamespace(t *testing.T) {
+	t.Parallel()
+
+	s := testRoutingState()
+	addresses := func(registries []RegistryInfo) []string {
+		out := []string{}
+		for _, r := range registries {
+			out = append(out, r.Address)
+		}
+		return out
+	}
+	// Registries routed by source prefix only are never added to the pull secrets
+	require.Equal(t, []string{"127.0.0.1:31999"}, addresses(s.RegistriesForNamespace("default")))
+	require.Equal(t, []string{"127.0.0.1:31999", "registry.tenant-a.example.com"}, addresses(s.RegistriesForNamespace("tenant-a")))
+	require.Equal(t, []string{"127.0.0.1:31999", "registry.tenant-b.example.com"}, addresses(s.RegistriesForNamespace("shared")))
+}
+
+func TestGitServerFor(t *testing.T) {
+	t.Parallel()
+
+	s := testRoutingState()
+	gs := s.GitServerFor("https://github.com/tenant-a/podinfo.git", "default")
+	require.Equal(t, "tenant-a", gs.Name)
+	require.Equal(t, "private-git-server-tenant-a", gs.SecretName("private-git-server"))
+
+	gs = s.GitServerFor("https://git.tenant-a.example.com/zarf-git-user/podinfo-1646971829.git", "default")
+	require.Equal(t, "tenant-a", gs.Name)
+
+	gs = s.GitServerFor("https://github.com/stefanprodan/podinfo.git", "default")
+	require.Empty(t, gs.Name)
+	require.Equal(t, s.GitServer, gs.GitServerInfo)
+	require.Equal(t, "private-git-server", gs.SecretName("private-git-server"))
+
+	require.Len(t, s.GitServersForNamespace("default"), 1)
+}
+
+func TestRoutingConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	routes := RoutingRules{Namespaces: []string{"tenant-a"}}
+	tests := []struct {
+		name        string
+		config      RoutingConfig
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			config: RoutingConfig{
+				Registries: []NamedRegistryInfo{{Name: "tenant-a", RegistryInfo: RegistryInfo{Address: "registry.example.com"}, Routes: routes}},
+				GitServers: []NamedGitServerInfo{{Name: "tenant-a", GitServerInfo: GitServerInfo{Address: "https://git.example.com"}, Routes: routes}},
+			},
+		},
+		{
+			name: "invalid name",
+			config: RoutingConfig{
+				Registries: []NamedRegistryInfo{{Name: "Tenant_A", RegistryInfo: RegistryInfo{Address: "registry.example.com"}, Routes: routes}},
+			},
+			expectedErr: `invalid registry name "Tenant_A"`,
+		},
+		{
+			name: "duplicate name",
+			config: RoutingConfig{
+				GitServers: []NamedGitServerInfo{
+					{Name: "tenant-a", GitServerInfo: GitServerInfo{Address: "https://git.example.com"}, Routes: routes},
+					{Name: "tenant-a", GitServerInfo: GitServerInfo{Address: "https://git.example.com"}, Routes: routes},
+				},
+			},
+			expectedErr: `the git server name "tenant-a" is used more than once`,
+		},
+		{
+			name: "missing address",
+			config: RoutingConfig{
+				Registries: []NamedRegistryInfo{{Name: "tenant-a", Routes: routes}},
+			},
+			expectedErr: `the registry "tenant-a" requires an address`,
+		},
+		{
+			name: "missing routes",
+			config: RoutingConfig{
+				Registries: []NamedRegistryInfo{{Name: "tenant-a", RegistryInfo: RegistryInfo{Address: "registry.example.com"}}},
+			},
+			expectedErr: `the registry "tenant-a" requires at least one source prefix or namespace route`,
+		},
+		{
+			name: "internal registry",
+			config: RoutingConfig{
+				Registries: []NamedRegistryInfo{{Name: "tenant-a", RegistryInfo: RegistryInfo{Address: "registry.example.com", RegistryMode: RegistryModeNodePort}, Routes: routes}},
+			},
+			expectedErr: `the registry "tenant-a" must be external, got registry mode "nodeport"`,
+		},
+		{
+			name: "anonymous registry routed by source prefix",
+			config: RoutingConfig{
+				Registries: []NamedRegistryInfo{{Name: "hub", RegistryInfo: RegistryInfo{Address: "registry.example.com"}, Routes: RoutingRules{SourcePrefixes: []string{"docker.io/library"}}}},
+			},
+		},
+		{
+			name: "registry with credentials routed by source prefix",
+			config: RoutingConfig{
+				Registries: []NamedRegistryInfo{{Name: "hub", RegistryInfo: RegistryInfo{Address: "registry.example.com", PullPassword: "secret"}, Routes: RoutingRules{SourcePrefixes: []string{"docker.io/library"}}}},
+			},
+			expectedErr: `the registry "hub" has credentials and requires a namespace route`,
+		},
+		{
+			name: "git server with credentials routed by source prefix",
+			config: RoutingConfig{
+				GitServers: []NamedGitServerInfo{{Name: "tenant-a", GitServerInfo: GitServerInfo{Address: "https://git.example.com", PushUsername: "push"}, Routes: RoutingRules{SourcePrefixes: []string{"github.com/tenant-a"}}}},
+			},
+			expectedErr: `the git server "tenant-a" has credentials and requires a namespace route`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.config.Validate()
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRoutingConfigFillInEmptyValues(t *testing.T) {
+	t.Parallel()
+
+	rc := RoutingConfig{
+		Registries: []NamedRegistryInfo{{Name: "tenant-a", RegistryInfo: RegistryInfo{Address: "registry.example.com", PushUsername: "push", PushPassword: "secret"}}},
+	}
+	require.NoError(t, rc.FillInEmptyValues())
+	require.Equal(t, RegistryModeExternal, rc.Registries[0].RegistryMode)
+	require.Equal(t, "push", rc.Registries[0].PullUsername)
+	require.Equal(t, "secret", rc.Registries[0].PullPassword)
+	require.False(t, rc.Registries[0].IsInternal())
+}
@@ -6,6 +6,7 @@ package state
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 
@@ -20,11 +21,15 @@ import (
 // TODO(mkcp): Remove Zarf prefix, that's the project name.
 // TODO(mkcp): Provide semantic doccomments for how these are used.
 const (
-	ZarfManagedByLabel   = "app.kubernetes.io/managed-by"
-	ZarfNamespaceName    = "zarf"
-	ZarfStateSecretName  = "zarf-state"
-	ZarfStateDataKey     = "state"
-	ZarfPackageInfoLabel = "package-deploy-info"
+	ZarfManagedByLabel    = "app.kubernetes.io/managed-by"
+	ZarfNamespaceName     = "zarf"
+	ZarfStateSecretName   = "zarf-state"
+	ZarfStateDataKey      = "state"
+	ZarfAgentCASecretName = "zarf-agent-ca"
+	ZarfPackageInfoLabel  = "package-deploy-info"
+
+	// ZarfRegistryS3SecretName is the secret the registry reads its S3 access and secret keys from.
+	ZarfRegistryS3SecretName = "zarf-registry-s3"
 )
 
 // Credential keys
@@ -79,6 +84,9 @@ const (
 	ZarfGitReadUser = "zarf-git-read-user"
 	ZarfAgentHost   = "agent-hook.zarf.svc"
 
+	ZarfRegistryProxyServerHost = "zarf-docker-registry"
+	ZarfRegistryProxyClientName = "zarf-registry-proxy"
+
 	ZarfInClusterGitServiceURL      = "http://zarf-gitea-http.zarf.svc.cluster.local:3000"
 	ZarfInClusterArtifactServiceURL = ZarfInClusterGitServiceURL + "/api/packages/" + ZarfGitPushUser
 )
@@ -101,6 +109,8 @@ type State struct {
 	// PKI certificate information for the agent pods Zarf manages
 	AgentTLS     pki.GeneratedPKI `json:"agentTLS"`
 	InjectorInfo InjectorInfo     `json:"injectorInfo"`
+	// mTLS certificates between the registry proxy on each node and the registry
+	RegistryProxyTLS *RegistryProxyTLS `json:"registryProxyTLS,omitempty"`
 
 	// Information about the repository Zarf is configured to use
 	GitServer GitServerInfo `json:"gitServer"`
@@ -108,6 +118,116 @@ type State struct {
 	RegistryInfo RegistryInfo `json:"registryInfo"`
 	// Information about the artifact registry Zarf is configured to use
 	ArtifactServer ArtifactServerInfo `json:"artifactServer"`
+	// Additional registries that images are routed to instead of the registry above
+	Registries []NamedRegistryInfo `json:"registries,omitempty"`
+	// Additional git servers that repositories are routed to instead of the git server above
+	GitServers []NamedGitServerInfo `json:"gitServers,omitempty"`
+	// Configuration of the Zarf agent admission webhooks
+	AgentInfo AgentInfo `json:"agentInfo"`
+	// Backend that stores the sensitive fields of the state
+	CredentialStore CredentialStore `json:"credentialStore"`
+}
+
+// AgentInfo contains the configuration of the Zarf agent admission webhooks.
+type AgentInfo struct {
+	// Policy for the images the agent admits into the cluster
+	ImagePolicy ImagePolicy `json:"imagePolicy"`
+	// Hooks that run in audit mode
+	Audit AgentAudit `json:"audit"`
+	// Source of the agent TLS certificate
+	TLS AgentTLSConfig `json:"tls"`
+}
+
+// AgentTLSConfig records how the agent TLS certificate is issued so that it can be rotated.
+// If neither a CA nor a cert-manager issuer is set an ephemeral self-signed CA is generated.
+type AgentTLSConfig struct {
+	// Algorithm of the agent private keys (rsa or ecdsa)
+	KeyAlgorithm pki.KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+	// PEM encoded CA certificate used to sign the agent certificate
+	CACert []byte `json:"caCert,omitempty"`
+	// PEM encoded private key of the CA, stored in its own secret rather than the state
+	CAKey []byte `json:"-"`
+	// cert-manager issuer that issues the agent certificate
+	CertManagerIssuer *CertManagerIssuerRef `json:"certManagerIssuer,omitempty"`
+}
+
+// CertManagerIssuerRef references a cert-manager Issuer or ClusterIssuer.
+type CertManagerIssuerRef struct {
+	// Name of the issuer
+	Name string `json:"name"`
+	// Kind of the issuer, either Issuer (in the Zarf namespace) or ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+}
+
+// GenerateAgentTLS creates a new agent certificate signed by the configured CA or an ephemeral CA.
+// Certificates issued by cert-manager are managed by the cluster and cannot be generated locally.
+func (c AgentTLSConfig) GenerateAgentTLS() (pki.GeneratedPKI, error) {
+	if c.CertManagerIssuer != nil {
+		return pki.GeneratedPKI{}, errors.New("the agent certificate is issued by cert-manager and cannot be generated locally")
+	}
+	opts := pki.Options{
+		KeyAlgorithm: c.KeyAlgorithm,
+		CACert:       c.CACert,
+		CAKey:        c.CAKey,
+	}
+	return pki.GeneratePKIWithOptions(opts, ZarfAgentHost)
+}
+
+// GenerateRegistryProxyTLS creates the mTLS certificates of the registry proxy signed by the configured CA.
+// An ephemeral CA is used when no CA is configured or the agent certificate is issued by cert-manager.
+func (c AgentTLSConfig) GenerateRegistryProxyTLS() (*RegistryProxyTLS, error) {
+	opts := pki.Options{
+		KeyAlgorithm: c.KeyAlgorithm,
+	}
+	if c.CertManagerIssuer == nil {
+		opts.CACert = c.CACert
+		opts.CAKey = c.CAKey
+	}
+	dnsNames := []string{
+		ZarfRegistryProxyServerHost + "." + ZarfNamespaceName,
+		ZarfRegistryProxyServerHost + "." + ZarfNamespaceName + ".svc",
+		ZarfRegistryProxyServerHost + "." + ZarfNamespaceName + ".svc.cluster.local",
+	}
+	server, client, err := pki.GenerateMutualTLS(opts, ZarfRegistryProxyServerHost, ZarfRegistryProxyClientName, dnsNames...)
+	if err != nil {
+		return nil, err
+	}
+	return &RegistryProxyTLS{Server: server, Client: client}, nil
+}
+
+// RegistryProxyTLS contains the certificates used for mTLS between the registry proxy and the registry.
+type RegistryProxyTLS struct {
+	// Certificate served by the registry to the proxy
+	Server pki.GeneratedPKI `json:"server"`
+	// Certificate presented by the proxy to the registry
+	Client pki.GeneratedPKI `json:"client"`
+}
+
+// AgentAudit configures which agent hooks run in audit mode.
+// A hook in audit mode admits objects unmodified and records the patch it would have applied.
+type AgentAudit struct {
+	// Run every hook in audit mode
+	All bool `json:"all,omitempty"`
+	// Names of the hooks to run in audit mode (e.g. pod, flux-gitrepository)
+	Hooks []string `json:"hooks,omitempty"`
+}
+
+// IsAudited returns true if the named hook runs in audit mode.
+func (aa AgentAudit) IsAudited(hook string) bool {
+	return aa.All || slices.Contains(aa.Hooks, hook)
+}
+
+// ImagePolicy defines which pod images the Zarf agent admits into the cluster.
+type ImagePolicy struct {
+	// Deny pods with images that have not been pushed to the Zarf registry
+	RequireImagesInRegistry bool `json:"requireImagesInRegistry,omitempty"`
+	// Source registries (optionally including a repository path prefix) pod images are allowed to originate from. All registries are allowed when empty
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+}
+
+// IsEnabled returns true if the image policy requires the agent to validate pods.
+func (ip ImagePolicy) IsEnabled() bool {
+	return ip.RequireImagesInRegistry || len(ip.AllowedRegistries) > 0
 }
 
 // InjectorInfo contains information on how to run the long lived Daemonset Injector
@@ -219,6 +339,8 @@ const (
 	RegistryModeProxy RegistryMode = "proxy"
 	// RegistryModeExternal is used when the user has an external registry
 	RegistryModeExternal RegistryMode = "external"
+	// RegistryModeMirror accesses the registry via NodePort service through containerd registry mirrors configured on each node
+	RegistryModeMirror RegistryMode = "mirror"
 )
 
 // RegistryInfo contains information Zarf uses to communicate with a container registry to push/pull images.
@@ -237,8 +359,32 @@ type RegistryInfo struct {
 	NodePort int `json:"nodePort"`
 	// Secret value that the registry was seeded with
 	Secret string `json:"secret"`
-	// RegistryMode defines how the registry is accessed (nodeport, proxy, or external)
+	// RegistryMode defines how the registry is accessed (nodeport, proxy, mirror, or external)
 	RegistryMode RegistryMode `json:"registryMode"`
+	// Source registry hosts that are mirrored to the registry on each node. Only used in mirror mode.
+	Mirrors []string `json:"mirrors,omitempty"`
+	// S3-compatible object storage the internal registry stores blobs in instead of a PVC
+	S3 *RegistryS3Storage `json:"s3,omitempty"`
+}
+
+// RegistryS3Storage configures the S3 storage driver of the internal registry.
+type RegistryS3Storage struct {
+	// Name of the bucket the registry stores blobs in
+	Bucket string `json:"bucket"`
+	// Region of the bucket, defaults to us-east-1
+	Region string `json:"region"`
+	// Endpoint of an S3-compatible service (e.g. https://minio.example.com:9000), defaults to AWS S3
+	Endpoint string `json:"endpoint,omitempty"`
+	// Prefix of the objects the registry stores in the bucket
+	RootDirectory string `json:"rootDirectory,omitempty"`
+	// Access key ID used to authenticate with the bucket
+	AccessKey string `json:"accessKey"`
+	// Secret access key used to authenticate with the bucket
+	SecretKey string `json:"secretKey"`
+	// Connect to the endpoint over plain HTTP
+	Insecure bool `json:"insecure,omitempty"`
+	// Address the bucket in the path instead of the host name, required by most S3-compatible services
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
 }
 
 // IsInternal returns true if the registry URL is equivalent to the registry deployed through the default init package
@@ -251,6 +397,24 @@ func (ri RegistryInfo) IsInternal() bool {
 		ri.Address == fmt.Sprintf("[%s]:%d", IPV6Localhost, ri.NodePort)
 }
 
+// SwitchMode changes the mode of the internal registry and moves it to the default port of the new mode.
+func (ri *RegistryInfo) SwitchMode(mode RegistryMode, ipFamily IPFamily) error {
+	if !ri.IsInternal() {
+		return errors.New("the registry mode can only be changed for the internal registry")
+	}
+	switch mode {
+	case RegistryModeNodePort, RegistryModeMirror:
+		ri.NodePort = ZarfInClusterContainerRegistryNodePort
+	case RegistryModeProxy:
+		ri.NodePort = ZarfRegistryHostPort
+	default:
+		return fmt.Errorf("invalid registry mode %q, must be %q, %q, or %q", mode, RegistryModeNodePort, RegistryModeProxy, RegistryModeMirror)
+	}
+	ri.RegistryMode = mode
+	ri.Address = LocalhostRegistryAddress(ipFamily, ri.NodePort)
+	return nil
+}
+
 // FillInEmptyValues sets every necessary value not already set to a reasonable default
 func (ri *RegistryInfo) FillInEmptyValues(ipFamily IPFamily) error {
 	var err error
@@ -267,7 +431,7 @@ func (ri *RegistryInfo) FillInEmptyValues(ipFamily IPFamily) error {
 	if ri.NodePort == 0 && ri.Address == "" {
 		switch ri.RegistryMode {
 		// Set default NodePort if none was provided and the registry is internal
-		case RegistryModeNodePort:
+		case RegistryModeNodePort, RegistryModeMirror:
 			ri.NodePort = ZarfInClusterContainerRegistryNodePort
 		// In proxy mode, we should avoid using a port in the nodeport range as Kubernetes will still randomly assign nodeports even on already claimed hostports
 		case RegistryModeProxy:
@@ -317,9 +481,35 @@ func (ri *RegistryInfo) FillInEmptyValues(ipFamily IPFamily) error {
 		}
 	}
 
+	if ri.S3 != nil {
+		if !ri.IsInternal() {
+			return errors.New("S3 storage can only be configured for the internal registry")
+		}
+		if ri.S3.Bucket == "" {
+			return errors.New("S3 storage for the registry requires a bucket")
+		}
+		if ri.S3.Region == "" {
+			ri.S3.Region = "us-east-1"
+		}
+	}
+
 	return nil
 }
 
+// AddMirrors adds the source registry hosts to the mirrored hosts and returns true if any were not already mirrored.
+func (ri *RegistryInfo) AddMirrors(hosts ...string) bool {
+	added := false
+	for _, host := range hosts {
+		if host == "" || slices.Contains(ri.Mirrors, host) {
+			continue
+		}
+		ri.Mirrors = append(ri.Mirrors, host)
+		added = true
+	}
+	slices.Sort(ri.Mirrors)
+	return added
+}
+
 // Default returns a default State with default values filled in for the registry, git server, and artifact server
 func Default() (*State, error) {
 	state := &State{}
@@ -341,7 +531,9 @@ type MergeOptions struct {
 	GitServer      GitServerInfo
 	RegistryInfo   RegistryInfo
 	ArtifactServer ArtifactServerInfo
-	Services       []string
+	// Replaces how the agent certificate is issued when the agent service is merged
+	AgentTLS *AgentTLSConfig
+	Services []string
 }
 
 // Merge merges init options for provided services into the provided state to create a new state struct
@@ -390,11 +582,17 @@ func Merge(oldState *State, opts MergeOptions) (*State, error) {
 		}
 	}
 	if slices.Contains(opts.Services, AgentKey) {
-		agentTLS, err := pki.GeneratePKI(ZarfAgentHost)
-		if err != nil {
-			return nil, err
+		if opts.AgentTLS != nil {
+			newState.AgentInfo.TLS = *opts.AgentTLS
+		}
+		// Certificates issued by cert-manager are synced from the cluster by the caller
+		if newState.AgentInfo.TLS.CertManagerIssuer == nil {
+			agentTLS, err := newState.AgentInfo.TLS.GenerateAgentTLS()
+			if err != nil {
+				return nil, err
+			}
+			newState.AgentTLS = agentTLS
 		}
-		newState.AgentTLS = agentTLS
 	}
 
 	return &newState, nil
@@ -416,6 +614,16 @@ func sanitizeState(s *State) *State {
 	s.AgentTLS.CA = []byte("**sanitized**")
 	s.AgentTLS.Cert = []byte("**sanitized**")
 	s.AgentTLS.Key = []byte("**sanitized**")
+	if len(s.AgentInfo.TLS.CAKey) > 0 {
+		s.AgentInfo.TLS.CAKey = []byte("**sanitized**")
+	}
+	// Overwrite the registry proxy keys, copying the certificates so the original state is untouched
+	if s.RegistryProxyTLS != nil {
+		proxyTLS := *s.RegistryProxyTLS
+		proxyTLS.Server.Key = []byte("**sanitized**")
+		proxyTLS.Client.Key = []byte("**sanitized**")
+		s.RegistryProxyTLS = &proxyTLS
+	}
 
 	// Overwrite the GitServer passwords
 	s.GitServer.PushPassword = "**sanitized**"
@@ -426,9 +634,30 @@ func sanitizeState(s *State) *State {
 	s.RegistryInfo.PullPassword = "**sanitized**"
 	s.RegistryInfo.Secret = "**sanitized**"
 
+	// Overwrite the registry S3 credentials, copying the storage so the original state is untouched
+	if s.RegistryInfo.S3 != nil {
+		s3 := *s.RegistryInfo.S3
+		s3.AccessKey = "**sanitized**"
+		s3.SecretKey = "**sanitized**"
+		s.RegistryInfo.S3 = &s3
+	}
+
 	// Overwrite the ArtifactServer secret
 	s.ArtifactServer.PushToken = "**sanitized**"
 
+	// Overwrite the passwords of the named registries and git servers, copying the slices so the original state is untouched
+	s.Registries = slices.Clone(s.Registries)
+	for i := range s.Registries {
+		s.Registries[i].PushPassword = "**sanitized**"
+		s.Registries[i].PullPassword = "**sanitized**"
+		s.Registries[i].Secret = "**sanitized**"
+	}
+	s.GitServers = slices.Clone(s.GitServers)
+	for i := range s.GitServers {
+		s.GitServers[i].PushPassword = "**sanitized**"
+		s.GitServers[i].PullPassword = "**sanitized**"
+	}
+
 	return s
 }
 
@@ -442,6 +671,13 @@ func WithPackageNamespaceOverride(namespaceOverride string) DeployedPackageOptio
 	}
 }
 
+// WithPackageOverlays sets the overlays applied to the charts of a package during deployment
+func WithPackageOverlays(overlays []DeployedOverlay) DeployedPackageOptions {
+	return func(o *DeployedPackage) {
+		o.Overlays = overlays
+	}
+}
+
 // DeployedPackage contains information about a Zarf Package that has been deployed to a cluster
 // This object is saved as the data of a k8s secret within the 'Zarf' namespace (not as part of the ZarfState secret).
 type DeployedPackage struct {
@@ -453,6 +689,18 @@ type DeployedPackage struct {
 	ConnectStrings     ConnectStrings       `json:"connectStrings,omitempty"`
 	// [ALPHA] Optional namespace override - exported/json-tag for storage in deployed package state secret
 	NamespaceOverride string `json:"namespaceOverride,omitempty"`
+	// Overlays applied to the charts of the package, stored so they are applied again when the package is upgraded
+	Overlays []DeployedOverlay `json:"overlays,omitempty"`
+}
+
+// DeployedOverlay is a kustomize component supplied at deploy time and applied to the rendered manifests of a chart.
+type DeployedOverlay struct {
+	// Name of the component the chart belongs to
+	Component string `json:"component"`
+	// Name of the chart or manifest the overlay is applied to
+	Chart string `json:"chart"`
+	// Files of the kustomize component keyed by their path relative to the overlay directory
+	Files map[string][]byte `json:"files"`
 }
 
 // GetSecretName returns the k8s secret name for the deployed package
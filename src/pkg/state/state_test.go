@@ -282,6 +282,26 @@ func TestMergeStateAgent(t *testing.T) {
 	})
 	require.NoError(t, err)
 	require.NotEqual(t, oldState.AgentTLS, newState.AgentTLS)
+
+	// A new issuer configuration replaces the existing one and is used to generate the certificate
+	newState, err = Merge(oldState, MergeOptions{
+		AgentTLS: &AgentTLSConfig{KeyAlgorithm: pki.KeyAlgorithmECDSA},
+		Services: []string{AgentKey},
+	})
+	require.NoError(t, err)
+	require.Equal(t, pki.KeyAlgorithmECDSA, newState.AgentInfo.TLS.KeyAlgorithm)
+	require.Contains(t, string(newState.AgentTLS.Key), "EC PRIVATE KEY")
+
+	// Certificates issued by cert-manager are left for the cluster to sync
+	certManagerState := &State{
+		AgentTLS:  agentTLS,
+		AgentInfo: AgentInfo{TLS: AgentTLSConfig{CertManagerIssuer: &CertManagerIssuerRef{Name: "zarf-ca", Kind: "ClusterIssuer"}}},
+	}
+	newState, err = Merge(certManagerState, MergeOptions{
+		Services: []string{AgentKey},
+	})
+	require.NoError(t, err)
+	require.Equal(t, agentTLS, newState.AgentTLS)
 }
 
 func TestMergeInstalledChartsForComponent(t *testing.T) {
@@ -365,3 +385,52 @@ func TestMergeInstalledChartsForComponent(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryInfoAddMirrors(t *testing.T) {
+	t.Parallel()
+
+	ri := RegistryInfo{RegistryMode: RegistryModeMirror}
+	require.NoError(t, ri.FillInEmptyValues(IPFamilyIPv4))
+	require.Equal(t, ZarfInClusterContainerRegistryNodePort, ri.NodePort)
+
+	require.True(t, ri.AddMirrors("ghcr.io", "docker.io", "ghcr.io"))
+	require.Equal(t, []string{"docker.io", "ghcr.io"}, ri.Mirrors)
+	require.False(t, ri.AddMirrors("docker.io", ""))
+	require.True(t, ri.AddMirrors("quay.io"))
+	require.Equal(t, []string{"docker.io", "ghcr.io", "quay.io"}, ri.Mirrors)
+}
+
+func TestRegistryInfoSwitchMode(t *testing.T) {
+	t.Parallel()
+
+	ri := RegistryInfo{RegistryMode: RegistryModeProxy}
+	require.NoError(t, ri.FillInEmptyValues(IPFamilyIPv6))
+	require.Equal(t, "[::1]:5000", ri.Address)
+
+	require.NoError(t, ri.SwitchMode(RegistryModeNodePort, IPFamilyIPv6))
+	require.Equal(t, RegistryModeNodePort, ri.RegistryMode)
+	require.Equal(t, ZarfInClusterContainerRegistryNodePort, ri.NodePort)
+	require.Equal(t, "[::1]:31999", ri.Address)
+
+	require.NoError(t, ri.SwitchMode(RegistryModeProxy, IPFamilyIPv4))
+	require.Equal(t, "127.0.0.1:5000", ri.Address)
+
+	require.ErrorContains(t, ri.SwitchMode(RegistryModeExternal, IPFamilyIPv4), "invalid registry mode")
+
+	external := RegistryInfo{Address: "registry.example.com", RegistryMode: RegistryModeExternal}
+	require.ErrorContains(t, external.SwitchMode(RegistryModeProxy, IPFamilyIPv4), "only be changed for the internal registry")
+}
+
+func TestRegistryInfoFillInEmptyValuesS3(t *testing.T) {
+	t.Parallel()
+
+	ri := RegistryInfo{S3: &RegistryS3Storage{Bucket: "zarf"}}
+	require.NoError(t, ri.FillInEmptyValues(IPFamilyIPv4))
+	require.Equal(t, "us-east-1", ri.S3.Region)
+
+	ri = RegistryInfo{S3: &RegistryS3Storage{}}
+	require.ErrorContains(t, ri.FillInEmptyValues(IPFamilyIPv4), "requires a bucket")
+
+	ri = RegistryInfo{Address: "registry.example.com", S3: &RegistryS3Storage{Bucket: "zarf"}}
+	require.ErrorContains(t, ri.FillInEmptyValues(IPFamilyIPv4), "only be configured for the internal registry")
+}
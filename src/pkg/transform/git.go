@@ -92,7 +92,11 @@ func GitURL(targetBaseURL string, sourceURL string, pushUser string) (*url.URL,
 	if err != nil {
 		return nil, err
 	}
+	return gitURL(targetBaseURL, sourceURL, pushUser, repoName)
+}
 
+// gitURL returns the url of the repository with the repo name in the git server at the base URL.
+func gitURL(targetBaseURL string, sourceURL string, pushUser string, repoName string) (*url.URL, error) {
 	// Get the full path
 	matches := gitURLRegex.FindStringSubmatch(sourceURL)
 	idx := gitURLRegex.SubexpIndex
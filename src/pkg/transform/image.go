@@ -61,6 +61,22 @@ func ImageTransformHostWithoutChecksum(targetHost, srcReference string) (string,
 	return fmt.Sprintf("%s/%s%s", targetHost, image.Path, image.TagOrDigest), nil
 }
 
+// ImageTransformMirror replaces the base url for an image and keeps the original host as the first path element so that the
+// registry can be used as a mirror of every source registry without collisions (note image refs are not full URLs).
+func ImageTransformMirror(targetHost, srcReference string) (string, error) {
+	image, err := ParseImageRef(srcReference)
+	if err != nil {
+		return "", err
+	}
+
+	// check if image has already been transformed
+	if strings.HasPrefix(targetHost, image.Host) {
+		return srcReference, nil
+	}
+
+	return fmt.Sprintf("%s/%s/%s%s", targetHost, image.Host, image.Path, image.TagOrDigest), nil
+}
+
 // ParseImageRef parses a source reference into an Image struct
 func ParseImageRef(srcReference string) (Image, error) {
 	srcReference = strings.TrimPrefix(srcReference, helpers.OCIURLPrefix)
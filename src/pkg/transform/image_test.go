@@ -79,6 +79,31 @@ func TestImageTransformHostWithoutChecksum(t *testing.T) {
 	}
 }
 
+func TestImageTransformMirror(t *testing.T) {
+	var expectedResult = []string{
+		"gitlab.com/project/docker.io/library/nginx:latest",
+		"gitlab.com/project/docker.io/library/nginx:1.23.3",
+		"gitlab.com/project/docker.io/zarf-dev/zarf-agent:v0.22.1",
+		"gitlab.com/project/docker.io/zarf-dev/zarf-agent@sha256:84605f731c6a18194794c51e70021c671ab064654b751aa57e905bce55be13de",
+		"gitlab.com/project/docker.io/library/busybox@sha256:3fbc632167424a6d997e74f52b878d7cc478225cffac6bc977eedfe51c7f4e79",
+		"gitlab.com/project/ghcr.io/stefanprodan/podinfo:6.3.3",
+		"gitlab.com/project/registry1.dso.mil/ironbank/opensource/zarf-dev/zarf/zarf-agent:v0.25.0",
+		"gitlab.com/project/gitea/gitea:1.19.3-rootless-zarf-3431384023",
+		"gitlab.com/project/10.43.130.183:5000/stefanprodan/manifests/podinfo:latest",
+	}
+
+	for idx, ref := range imageRefs {
+		newRef, err := ImageTransformMirror("gitlab.com/project", ref)
+		require.NoError(t, err)
+		require.Equal(t, expectedResult[idx], newRef)
+	}
+
+	for _, ref := range badImageRefs {
+		_, err := ImageTransformMirror("gitlab.com/project", ref)
+		require.Error(t, err)
+	}
+}
+
 func TestParseImageRef(t *testing.T) {
 	var expectedResult = [][]string{
 		{"docker.io/", "library/nginx", "latest", ""},
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package transform
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/defenseunicorns/pkg/helpers/v2"
+	"github.com/distribution/reference"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+)
+
+var repoNameRegex = regexp.MustCompile(`^[\w\-\.]+$`)
+
+// ImageRules are the compiled image transform rules of a package.
+type ImageRules []imageRule
+
+type imageRule struct {
+	v1alpha1.ZarfImageTransform
+	match *regexp.Regexp
+}
+
+// NewImageRules compiles the image transform rules of a package.
+func NewImageRules(transforms []v1alpha1.ZarfImageTransform) (ImageRules, error) {
+	rules := ImageRules{}
+	for _, t := range transforms {
+		match, err := regexp.Compile(t.Match)
+		if err != nil {
+			return nil, fmt.Errorf("image transform match %q is not a valid regular expression: %w", t.Match, err)
+		}
+		rules = append(rules, imageRule{ZarfImageTransform: t, match: match})
+	}
+	return rules, nil
+}
+
+// ImageTransformHostWithRules replaces the base url for an image using the first rule that matches its repository, images that match no rule are transformed by ImageTransformHost.
+func ImageTransformHostWithRules(targetHost, srcReference string, rules ImageRules) (string, error) {
+	return imageTransformHostWithRules(targetHost, srcReference, rules, true)
+}
+
+// ImageTransformHostWithRulesWithoutChecksum replaces the base url for an image using the first rule that matches its repository but avoids adding a checksum of the original url,
+// images that match no rule are transformed by ImageTransformHostWithoutChecksum.
+func ImageTransformHostWithRulesWithoutChecksum(targetHost, srcReference string, rules ImageRules) (string, error) {
+	return imageTransformHostWithRules(targetHost, srcReference, rules, false)
+}
+
+func imageTransformHostWithRules(targetHost, srcReference string, rules ImageRules, checksum bool) (string, error) {
+	image, err := ParseImageRef(srcReference)
+	if err != nil {
+		return "", err
+	}
+
+	// check if image has already been transformed
+	if strings.HasPrefix(targetHost, image.Host) {
+		return srcReference, nil
+	}
+
+	idx := -1
+	for i, rule := range rules {
+		if rule.match.MatchString(image.Name) {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		if checksum {
+			return ImageTransformHost(targetHost, srcReference)
+		}
+		return ImageTransformHostWithoutChecksum(targetHost, srcReference)
+	}
+	rule := rules[idx]
+
+	path := image.Path
+	if rule.Path != "" {
+		path = string(rule.match.ExpandString(nil, rule.Path, image.Name, rule.match.FindStringSubmatchIndex(image.Name)))
+	}
+	if rule.Flatten {
+		if namespace, rest, ok := strings.Cut(path, "/"); ok {
+			path = fmt.Sprintf("%s/%s", namespace, strings.ReplaceAll(rest, "/", "-"))
+		}
+	}
+
+	var out string
+	if image.Digest != "" {
+		// Digests already identify a specific image so neither the suffix nor the checksum are added
+		out = fmt.Sprintf("%s/%s@%s", targetHost, path, image.Digest)
+	} else {
+		tag := image.Tag + rule.TagSuffix
+		if checksum && !rule.NoChecksum {
+			tag = fmt.Sprintf("%s-zarf-%d", tag, helpers.GetCRCHash(image.Name))
+		}
+		out = fmt.Sprintf("%s/%s:%s", targetHost, path, tag)
+	}
+	if _, err := reference.ParseAnyReference(out); err != nil {
+		return "", fmt.Errorf("image transform %q produced the invalid reference %s for %s: %w", rule.Match, out, srcReference, err)
+	}
+	return out, nil
+}
+
+// RepoRules are the compiled git repository transform rules of a package.
+type RepoRules []repoRule
+
+type repoRule struct {
+	v1alpha1.ZarfRepoTransform
+	match *regexp.Regexp
+}
+
+// NewRepoRules compiles the git repository transform rules of a package.
+func NewRepoRules(transforms []v1alpha1.ZarfRepoTransform) (RepoRules, error) {
+	rules := RepoRules{}
+	for _, t := range transforms {
+		match, err := regexp.Compile(t.Match)
+		if err != nil {
+			return nil, fmt.Errorf("repo transform match %q is not a valid regular expression: %w", t.Match, err)
+		}
+		rules = append(rules, repoRule{ZarfRepoTransform: t, match: match})
+	}
+	return rules, nil
+}
+
+// GitURLtoRepoNameWithRules takes a git url and returns the name of the repo in the remote airgap repository using the first rule that matches it,
+// repositories that match no rule are named by GitURLtoRepoName.
+func GitURLtoRepoNameWithRules(sourceURL string, rules RepoRules) (string, error) {
+	get, err := helpers.MatchRegex(gitURLRegex, sourceURL)
+	if err != nil {
+		// Unable to find a substring match for the regex
+		return "", fmt.Errorf("unable to get extract the repo name from the url %s", sourceURL)
+	}
+
+	// The same sanitized URL the checksum is generated from so that the scheme and .git suffix do not change the match
+	sanitizedURL := fmt.Sprintf("%s/%s", get("hostPath"), get("repo"))
+	for _, rule := range rules {
+		if !rule.match.MatchString(sanitizedURL) {
+			continue
+		}
+		repoName := get("repo")
+		if rule.Name != "" {
+			repoName = string(rule.match.ExpandString(nil, rule.Name, sanitizedURL, rule.match.FindStringSubmatchIndex(sanitizedURL)))
+		}
+		if !rule.NoChecksum {
+			repoName = fmt.Sprintf("%s-%d", repoName, helpers.GetCRCHash(sanitizedURL))
+		}
+		if !repoNameRegex.MatchString(repoName) {
+			return "", fmt.Errorf("repo transform %q produced the invalid repository name %q for %s", rule.Match, repoName, sourceURL)
+		}
+		return repoName, nil
+	}
+	return GitURLtoRepoName(sourceURL)
+}
+
+// GitURLWithRules takes a base URL, a source url, a username and the repo transform rules of a package and returns a Zarf-compatible url.
+func GitURLWithRules(targetBaseURL string, sourceURL string, pushUser string, rules RepoRules) (*url.URL, error) {
+	repoName, err := GitURLtoRepoNameWithRules(sourceURL, rules)
+	if err != nil {
+		return nil, err
+	}
+	return gitURL(targetBaseURL, sourceURL, pushUser, repoName)
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+// SPDX-FileCopyrightText: 2021-Present The Zarf Authors
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zarf-dev/zarf/src/api/v1alpha1"
+)
+
+func TestImageTransformHostWithRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := NewImageRules([]v1alpha1.ZarfImageTransform{
+		{Match: `^docker\.io/library/(.*)$`, Path: "platform/dockerhub/$1", NoChecksum: true},
+		{Match: `^ghcr\.io/.*$`, Path: "platform/${0}", Flatten: true, TagSuffix: "-airgap"},
+		{Match: `^registry1\.dso\.mil/`, Flatten: true},
+		{Match: `^quay\.io/`, Path: "Invalid Path"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		image            string
+		expected         string
+		expectedNoCheck  string
+		expectedErrMatch string
+	}{
+		{
+			name:            "rewritten path without checksum",
+			image:           "nginx:1.23.3",
+			expected:        "127.0.0.1:31999/platform/dockerhub/nginx:1.23.3",
+			expectedNoCheck: "127.0.0.1:31999/platform/dockerhub/nginx:1.23.3",
+		},
+		{
+			name:            "flattened path with tag suffix",
+			image:           "ghcr.io/stefanprodan/podinfo:6.3.3",
+			expected:        "127.0.0.1:31999/platform/ghcr.io-stefanprodan-podinfo:6.3.3-airgap-zarf-2985051089",
+			expectedNoCheck: "127.0.0.1:31999/platform/ghcr.io-stefanprodan-podinfo:6.3.3-airgap",
+		},
+		{
+			name:            "flattened source path",
+			image:           "registry1.dso.mil/ironbank/opensource/zarf-dev/zarf/zarf-agent:v0.25.0",
+			expected:        "127.0.0.1:31999/ironbank/opensource-zarf-dev-zarf-zarf-agent:v0.25.0-zarf-1211467612",
+			expectedNoCheck: "127.0.0.1:31999/ironbank/opensource-zarf-dev-zarf-zarf-agent:v0.25.0",
+		},
+		{
+			name:            "digests keep no suffix",
+			image:           "ghcr.io/stefanprodan/podinfo@sha256:84605f731c6a18194794c51e70021c671ab064654b751aa57e905bce55be13de",
+			expected:        "127.0.0.1:31999/platform/ghcr.io-stefanprodan-podinfo@sha256:84605f731c6a18194794c51e70021c671ab064654b751aa57e905bce55be13de",
+			expectedNoCheck: "127.0.0.1:31999/platform/ghcr.io-stefanprodan-podinfo@sha256:84605f731c6a18194794c51e70021c671ab064654b751aa57e905bce55be13de",
+		},
+		{
+			name:            "unmatched images keep the default naming",
+			image:           "zarf-dev/zarf-agent:v0.22.1",
+			expected:        "127.0.0.1:31999/zarf-dev/zarf-agent:v0.22.1-zarf-2183797434",
+			expectedNoCheck: "127.0.0.1:31999/zarf-dev/zarf-agent:v0.22.1",
+		},
+		{
+			name:            "already transformed",
+			image:           "127.0.0.1:31999/platform/dockerhub/nginx:1.23.3",
+			expected:        "127.0.0.1:31999/platform/dockerhub/nginx:1.23.3",
+			expectedNoCheck: "127.0.0.1:31999/platform/dockerhub/nginx:1.23.3",
+		},
+		{
+			name:             "invalid result",
+			image:            "quay.io/argoproj/argocd:v2.9.3",
+			expectedErrMatch: "produced the invalid reference",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := ImageTransformHostWithRules("127.0.0.1:31999", tt.image, rules)
+			if tt.expectedErrMatch != "" {
+				require.ErrorContains(t, err, tt.expectedErrMatch)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, out)
+
+			out, err = ImageTransformHostWithRulesWithoutChecksum("127.0.0.1:31999", tt.image, rules)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedNoCheck, out)
+		})
+	}
+
+	_, err = NewImageRules([]v1alpha1.ZarfImageTransform{{Match: "("}})
+	require.ErrorContains(t, err, "is not a valid regular expression")
+}
+
+func TestGitURLWithRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := NewRepoRules([]v1alpha1.ZarfRepoTransform{
+		{Match: `^github\.com/([\w-]+)/([\w-]+)$`, Name: "$1-$2", NoChecksum: true},
+		{Match: `^gitlab\.com/`},
+		{Match: `^invalid\.com/`, Name: "a/b"},
+	})
+	require.NoError(t, err)
+
+	out, err := GitURLWithRules("http://gitea.example.com", "https://github.com/stefanprodan/podinfo.git@6.4.0", "zarf-git-user", rules)
+	require.NoError(t, err)
+	require.Equal(t, "http://gitea.example.com/zarf-git-user/stefanprodan-podinfo.git", out.String())
+
+	// Rules without a name keep the default name and checksum
+	withRule, err := GitURLWithRules("http://gitea.example.com", "https://gitlab.com/gitlab-org/build/omnibus-mirror/pcre2.git", "zarf-git-user", rules)
+	require.NoError(t, err)
+	withoutRule, err := GitURL("http://gitea.example.com", "https://gitlab.com/gitlab-org/build/omnibus-mirror/pcre2.git", "zarf-git-user")
+	require.NoError(t, err)
+	require.Equal(t, withoutRule, withRule)
+
+	_, err = GitURLWithRules("http://gitea.example.com", "https://invalid.com/org/repo.git", "zarf-git-user", rules)
+	require.ErrorContains(t, err, "produced the invalid repository name")
+}
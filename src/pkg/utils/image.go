@@ -38,6 +38,10 @@ func LoadOCIImage(imgPath string, refInfo transform.Image) (v1.Image, error) {
 			// A backwards compatibility shim for older Zarf versions that would leave docker.io off of image annotations
 			(manifest.Annotations[ocispec.AnnotationBaseImageName] == refInfo.Path+refInfo.TagOrDigest && refInfo.Host == "docker.io") ||
 			manifest.Annotations[ocispec.AnnotationRefName] == refInfo.Reference {
+			// An index records the platform of the image that is deployed from it
+			if manifest.MediaType.IsIndex() {
+				return loadIndexImage(imgIdx, manifest, refInfo)
+			}
 			// This is the image we are looking for, load it and then return
 			img, err := layoutPath.Image(manifest.Digest)
 			if err != nil {
@@ -50,6 +54,31 @@ func LoadOCIImage(imgPath string, refInfo transform.Image) (v1.Image, error) {
 	return nil, fmt.Errorf("unable to find image (%s) at the path (%s)", refInfo.Reference, imgPath)
 }
 
+func loadIndexImage(imgIdx v1.ImageIndex, desc v1.Descriptor, refInfo transform.Image) (v1.Image, error) {
+	if desc.Platform == nil {
+		return nil, fmt.Errorf("the index of image %s does not record a platform", refInfo.Reference)
+	}
+	idx, err := imgIdx.ImageIndex(desc.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup index %s: %w", refInfo.Reference, err)
+	}
+	idxManifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index manifest: %w", err)
+	}
+	for _, manifest := range idxManifest.Manifests {
+		if manifest.Platform == nil || manifest.Platform.OS != desc.Platform.OS || manifest.Platform.Architecture != desc.Platform.Architecture {
+			continue
+		}
+		img, err := idx.Image(manifest.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lookup image %s: %w", refInfo.Reference, err)
+		}
+		return img, nil
+	}
+	return nil, fmt.Errorf("the index of image %s has no image for the platform %s", refInfo.Reference, desc.Platform)
+}
+
 // AddImageNameAnnotation adds an annotation to the index.json file so that the deploying code can figure out what the image reference <-> digest shasum will be.
 func AddImageNameAnnotation(ociPath string, referenceToDigest map[string]string) error {
 	indexPath := filepath.Join(ociPath, "index.json")
@@ -6,6 +6,7 @@ package zoci
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -148,7 +149,7 @@ func (r *Remote) LayersFromComponents(ctx context.Context, pkg v1alpha1.ZarfPack
 }
 
 // LayersFromImages returns the layers for the given images to pull from OCI.
-func (r *Remote) LayersFromImages(ctx context.Context, images map[string]bool) ([]ocispec.Descriptor, error) {
+func (r *Remote) LayersFromImages(ctx context.Context, imageRefs map[string]bool) ([]ocispec.Descriptor, error) {
 	root, err := r.FetchRoot(ctx)
 	if err != nil {
 		return []ocispec.Descriptor{}, err
@@ -163,7 +164,7 @@ func (r *Remote) LayersFromImages(ctx context.Context, images map[string]bool) (
 
 	layers = append(layers, root.Locate(layout.IndexPath), root.Locate(layout.OCILayoutPath))
 
-	for image := range images {
+	for image := range imageRefs {
 		// use docker's transform lib to parse the image ref
 		// this properly mirrors the logic within create
 		refInfo, err := transform.ParseImageRef(image)
@@ -177,20 +178,34 @@ func (r *Remote) LayersFromImages(ctx context.Context, images map[string]bool) (
 				(layer.Annotations[ocispec.AnnotationBaseImageName] == refInfo.Path+refInfo.TagOrDigest && refInfo.Host == "docker.io")
 		})
 
-		// even though these are technically image manifests, we store them as Zarf blobs
-		manifestDescriptor.MediaType = ZarfLayerMediaTypeBlob
-
-		manifest, err := r.FetchManifest(ctx, manifestDescriptor)
-		if err != nil {
-			return nil, err
+		manifestDescriptors := []ocispec.Descriptor{manifestDescriptor}
+		// An image pinned to an index is stored with every image of the index
+		if manifestDescriptor.MediaType == ocispec.MediaTypeImageIndex || manifestDescriptor.MediaType == images.DockerMediaTypeManifestList {
+			manifestDescriptor.MediaType = ZarfLayerMediaTypeBlob
+			idx, err := oci.FetchUnmarshal[*ocispec.Index](ctx, r.FetchLayer, json.Unmarshal, manifestDescriptor)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, root.Locate(filepath.Join(layout.ImagesBlobsDir, manifestDescriptor.Digest.Encoded())))
+			manifestDescriptors = idx.Manifests
 		}
 
-		layers = append(layers, root.Locate(filepath.Join(layout.ImagesBlobsDir, manifestDescriptor.Digest.Encoded())))
-		layers = append(layers, root.Locate(filepath.Join(layout.ImagesBlobsDir, manifest.Config.Digest.Encoded())))
+		for _, manifestDescriptor := range manifestDescriptors {
+			// even though these are technically image manifests, we store them as Zarf blobs
+			manifestDescriptor.MediaType = ZarfLayerMediaTypeBlob
+
+			manifest, err := r.FetchManifest(ctx, manifestDescriptor)
+			if err != nil {
+				return nil, err
+			}
+
+			layers = append(layers, root.Locate(filepath.Join(layout.ImagesBlobsDir, manifestDescriptor.Digest.Encoded())))
+			layers = append(layers, root.Locate(filepath.Join(layout.ImagesBlobsDir, manifest.Config.Digest.Encoded())))
 
-		for _, layer := range manifest.Layers {
-			layerPath := filepath.Join(layout.ImagesBlobsDir, layer.Digest.Encoded())
-			layers = append(layers, root.Locate(layerPath))
+			for _, layer := range manifest.Layers {
+				layerPath := filepath.Join(layout.ImagesBlobsDir, layer.Digest.Encoded())
+				layers = append(layers, root.Locate(layerPath))
+			}
 		}
 	}
 	return layers, nil
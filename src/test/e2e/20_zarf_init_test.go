@@ -171,7 +171,7 @@ func verifyZarfPodLabels(t *testing.T) {
 	// agent
 	podHash, _, err = e2e.Kubectl(t, "get", "-n=zarf", "--selector=app=agent-hook", "pods", `-o=jsonpath="{.items[0].metadata.labels['pod-template-hash']}"`)
 	require.NoError(t, err)
-	expectedLabels = fmt.Sprintf(`'{"app":"agent-hook","pod-template-hash":%s,"zarf.dev/agent":"ignore","zarf.dev/package":"init"}'`, podHash)
+	expectedLabels = fmt.Sprintf(`'{"app":"agent-hook","app.kubernetes.io/component":"webhook","app.kubernetes.io/name":"zarf-agent","pod-template-hash":%s,"zarf.dev/agent":"ignore","zarf.dev/package":"init"}'`, podHash)
 	actualLabels, _, err = e2e.Kubectl(t, "get", "-n=zarf", "--selector=app=agent-hook", "pods", "-o=jsonpath='{.items[0].metadata.labels}'")
 	require.NoError(t, err)
 	require.Equal(t, expectedLabels, actualLabels)